@@ -27,6 +27,7 @@ func environment() *cobra.Command {
 		cli.NewDeleteCommand(environmentDeleteCmd, environmentDeleteRun, nil, withAllCommandModifiers()...),
 		environmentKey(),
 		environmentVariable(),
+		environmentDeployment(),
 		cli.NewCommand(environmentExportCmd, environmentExportRun, nil, withAllCommandModifiers()...),
 		cli.NewCommand(environmentImportCmd, environmentImportRun, nil, withAllCommandModifiers()...),
 	})
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+var environmentDeploymentCmd = cli.Command{
+	Name:  "deployment",
+	Short: "Manage CDS environment deployments",
+}
+
+func environmentDeployment() *cobra.Command {
+	return cli.NewCommand(environmentDeploymentCmd, nil, []*cobra.Command{
+		cli.NewListCommand(environmentDeploymentListCmd, environmentDeploymentListRun, nil, withAllCommandModifiers()...),
+		cli.NewCommand(environmentDeploymentRollbackCmd, environmentDeploymentRollbackRun, nil, withAllCommandModifiers()...),
+	})
+}
+
+var environmentDeploymentListCmd = cli.Command{
+	Name:  "list",
+	Short: "List the deployments on a CDS environment, the last deployed first",
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "env-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "application",
+			Usage: "List only the deployments of this application",
+		},
+		{
+			Name:    "limit",
+			Usage:   "Maximum number of deployments to list",
+			Default: "20",
+		},
+	},
+}
+
+func environmentDeploymentListRun(v cli.Values) (cli.ListResult, error) {
+	var mods []cdsclient.RequestModifier
+	if app := v.GetString("application"); app != "" {
+		mods = append(mods, cdsclient.WithQueryParameter("application", app))
+	}
+	if limit := v.GetString("limit"); limit != "" {
+		if _, err := strconv.Atoi(limit); err != nil {
+			return nil, fmt.Errorf("limit invalid: not a integer")
+		}
+		mods = append(mods, cdsclient.WithQueryParameter("limit", limit))
+	}
+	deployments, err := client.EnvironmentDeploymentList(v.GetString(_ProjectKey), v.GetString("env-name"), mods...)
+	if err != nil {
+		return nil, err
+	}
+	return cli.AsListResult(deployments), nil
+}
+
+var environmentDeploymentRollbackCmd = cli.Command{
+	Name:  "rollback",
+	Short: "Rollback a CDS environment to a previous deployment",
+	Long: `Run again the pipeline that made a deployment, in the workflow run of the deployment, with the same payload,
+pipeline parameters and artifacts. The deployment ids are given by the list command:
+
+	cdsctl environment deployment list MYPROJECT production --application my-app
+	cdsctl environment deployment rollback MYPROJECT production 42
+`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+	},
+	Args: []cli.Arg{
+		{Name: "env-name"},
+		{Name: "deployment-id"},
+	},
+}
+
+func environmentDeploymentRollbackRun(v cli.Values) error {
+	deploymentID, err := v.GetInt64("deployment-id")
+	if err != nil {
+		return err
+	}
+
+	d, err := client.EnvironmentDeploymentGet(v.GetString(_ProjectKey), v.GetString("env-name"), deploymentID)
+	if err != nil {
+		return err
+	}
+
+	run, err := client.WorkflowDeploymentRollback(v.GetString(_ProjectKey), d.WorkflowName, d.ID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Rollback of application %s on environment %s to version %s started in workflow %s #%d\n", d.ApplicationName, d.EnvironmentName, d.Version, d.WorkflowName, run.Number)
+	return nil
+}
@@ -45,6 +45,7 @@ func queueRun(v cli.Values) (cli.ListResult, error) {
 }
 
 type jobCLI struct {
+	Rank         int           `cli:"rank"`
 	Run          string        `cli:"run,key"`
 	ProjectKey   string        `cli:"project_key"`
 	WorkflowName string        `cli:"workflow_name"`
@@ -55,6 +56,7 @@ type jobCLI struct {
 	Duration     time.Duration `cli:"-"`
 	BookedBy     string        `cli:"booked_by"`
 	TriggeredBy  string        `cli:"triggered_by"`
+	Priority     int           `cli:"priority"`
 }
 
 func getJobQueue(status ...string) ([]jobCLI, error) {
@@ -72,6 +74,7 @@ func getJobQueue(status ...string) ([]jobCLI, error) {
 
 	for k, jr := range jobs {
 		jobsUI[k] = jobCLI{
+			Rank:         jr.QueueRank,
 			Run:          getVarsInPbj("cds.run", jr.Parameters),
 			ProjectKey:   getVarsInPbj("cds.project", jr.Parameters),
 			WorkflowName: getVarsInPbj("cds.workflow", jr.Parameters),
@@ -82,6 +85,7 @@ func getJobQueue(status ...string) ([]jobCLI, error) {
 			Duration:     time.Since(jr.Queued),
 			BookedBy:     jr.BookedBy.Name,
 			TriggeredBy:  getVarsInPbj("cds.triggered_by.username", jr.Parameters),
+			Priority:     jr.Priority,
 		}
 	}
 
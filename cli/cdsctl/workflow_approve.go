@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ovh/cds/cli"
+	"github.com/ovh/cds/sdk"
+)
+
+var workflowApproveCmd = cli.Command{
+	Name:  "approve",
+	Short: "Approve or reject a workflow node run waiting for approval",
+	Long:  "Approve or reject a workflow node run waiting for approval, only members of the approvers groups of the node can take a decision",
+	Example: `cdsctl workflow approve MYPROJECT myworkflow 5 deploy # To approve the node deploy on workflow run 5
+cdsctl workflow approve MYPROJECT myworkflow 5 deploy --reject --comment "not during the freeze" # To reject it
+	`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Args: []cli.Arg{
+		{Name: "run-number"},
+		{Name: "node-name"},
+	},
+	Flags: []cli.Flag{
+		{
+			Name:  "reject",
+			Usage: "Reject the node run instead of approving it",
+			Type:  cli.FlagBool,
+		},
+		{
+			Name:  "comment",
+			Usage: "Comment recorded with the decision",
+		},
+	},
+}
+
+func workflowApproveRun(v cli.Values) error {
+	runNumber, err := v.GetInt64("run-number")
+	if err != nil {
+		return err
+	}
+
+	wr, err := client.WorkflowRunGet(v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber)
+	if err != nil {
+		return err
+	}
+
+	var nodeRunID int64
+	for _, wnrs := range wr.WorkflowNodeRuns {
+		if len(wnrs) > 0 && wnrs[0].WorkflowNodeName == v.GetString("node-name") && wnrs[0].Status == sdk.StatusWaitingApproval {
+			nodeRunID = wnrs[0].ID
+			break
+		}
+	}
+	if nodeRunID == 0 {
+		return fmt.Errorf("no node %s waiting for approval in workflow run %d", v.GetString("node-name"), runNumber)
+	}
+
+	nr, err := client.WorkflowNodeRunApprove(v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber, nodeRunID, sdk.WorkflowNodeRunApprovalRequest{
+		Approved: !v.GetBool("reject"),
+		Comment:  v.GetString("comment"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if v.GetBool("reject") {
+		fmt.Printf("Workflow node %s from workflow %s #%d has been rejected\n", v.GetString("node-name"), v.GetString(_WorkflowName), nr.Number)
+	} else {
+		fmt.Printf("Workflow node %s from workflow %s #%d has been approved (status: %s)\n", v.GetString("node-name"), v.GetString(_WorkflowName), nr.Number, nr.Status)
+	}
+	return nil
+}
@@ -85,6 +85,11 @@ var workflowRunManualCmd = cli.Command{
 			Usage:     "Synchronise your pipelines with your last editions. Must be used with flag run-number",
 			Type:      cli.FlagBool,
 		},
+		{
+			Name:  "failed-only",
+			Usage: "Run again only the failed and stopped jobs of the node, with the payload and parameters of its last execution. Must be used with flags run-number and node-name",
+			Type:  cli.FlagBool,
+		},
 	},
 }
 
@@ -92,6 +97,12 @@ func workflowRunManualRun(v cli.Values) error {
 	if v.GetBool("sync") && v.GetString("run-number") == "" {
 		return fmt.Errorf("Could not use flag --sync without flag --run-number")
 	}
+	if v.GetBool("failed-only") && (v.GetString("run-number") == "" || v.GetString("node-name") == "") {
+		return fmt.Errorf("Could not use flag --failed-only without flags --run-number and --node-name")
+	}
+	if v.GetBool("failed-only") && v.GetBool("sync") {
+		return fmt.Errorf("Could not use flag --failed-only with flag --sync")
+	}
 
 	manual := sdk.WorkflowNodeRunManual{}
 	if strings.TrimSpace(v.GetString("data")) != "" {
@@ -149,7 +160,7 @@ func workflowRunManualRun(v cli.Values) error {
 		}
 	}
 
-	var runNumber, fromNodeID int64
+	var runNumber, fromNodeID, nodeRunID int64
 
 	if v.GetString("run-number") != "" {
 		var errp error
@@ -173,18 +184,32 @@ func workflowRunManualRun(v cli.Values) error {
 		if err != nil {
 			return err
 		}
+		var lastSubNumber int64 = -1
 		for _, wnrs := range wr.WorkflowNodeRuns {
 			for _, wnr := range wnrs {
 				wn := wr.Workflow.WorkflowData.NodeByID(wnr.WorkflowNodeID)
 				if wn.Name == v.GetString("node-name") {
 					fromNodeID = wnr.WorkflowNodeID
-					break
+					// Keep the last execution of the node
+					if wnr.SubNumber > lastSubNumber {
+						lastSubNumber = wnr.SubNumber
+						nodeRunID = wnr.ID
+					}
 				}
 			}
 		}
 	}
 
-	w, err := client.WorkflowRunFromManual(v.GetString(_ProjectKey), v.GetString(_WorkflowName), manual, runNumber, fromNodeID)
+	var w *sdk.WorkflowRun
+	var err error
+	if v.GetBool("failed-only") {
+		if nodeRunID == 0 {
+			return fmt.Errorf("Node %s has not been run in workflow run %d", v.GetString("node-name"), runNumber)
+		}
+		w, err = client.WorkflowNodeRunFailedJobs(v.GetString(_ProjectKey), v.GetString(_WorkflowName), runNumber, nodeRunID)
+	} else {
+		w, err = client.WorkflowRunFromManual(v.GetString(_ProjectKey), v.GetString(_WorkflowName), manual, runNumber, fromNodeID)
+	}
 	if err != nil {
 		return err
 	}
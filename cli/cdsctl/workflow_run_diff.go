@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ovh/cds/cli"
+)
+
+var workflowRunDiffCmd = cli.Command{
+	Name:  "diff",
+	Short: "Compare two runs of a CDS workflow",
+	Long: `Compare two runs of a workflow: workflow definition, commits, build parameters, jobs with their durations,
+requirements and worker models, test results and artifacts.
+
+	cdsctl workflow run diff MYPROJECT myworkflow 12 13
+`,
+	Ctx: []cli.Arg{
+		{Name: _ProjectKey},
+		{Name: _WorkflowName},
+	},
+	Args: []cli.Arg{
+		{Name: "from-run-number"},
+		{Name: "to-run-number"},
+	},
+}
+
+func workflowRunDiffRun(v cli.Values) error {
+	from, err := strconv.ParseInt(v.GetString("from-run-number"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("from-run-number invalid: not a integer")
+	}
+	to, err := strconv.ParseInt(v.GetString("to-run-number"), 10, 64)
+	if err != nil {
+		return fmt.Errorf("to-run-number invalid: not a integer")
+	}
+
+	diff, err := client.WorkflowRunDiff(v.GetString(_ProjectKey), v.GetString(_WorkflowName), from, to)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Workflow %s: #%d (%s) -> #%d (%s)\n", v.GetString(_WorkflowName), diff.From.Number, diff.From.Status, diff.To.Number, diff.To.Status)
+
+	fmt.Printf("\nWorkflow definition: ")
+	if !diff.Workflow.Changed() {
+		fmt.Println("unchanged")
+	} else {
+		fmt.Printf("modified (%s -> %s)\n", diff.Workflow.FromLastModified.Format(time.RFC3339), diff.Workflow.ToLastModified.Format(time.RFC3339))
+		if len(diff.Workflow.Pipelines) > 0 {
+			fmt.Printf("  pipelines: %s\n", strings.Join(diff.Workflow.Pipelines, ", "))
+		}
+	}
+
+	if len(diff.Commits) > 0 {
+		fmt.Println("\nCommits:")
+		for _, c := range diff.Commits {
+			fmt.Printf("  %s %s..%s\n", c.Repository, shortHash(c.From), shortHash(c.To))
+			for _, commit := range c.Commits {
+				fmt.Printf("    %s %s: %s\n", shortHash(commit.Hash), commit.Author.Name, strings.SplitN(commit.Message, "\n", 2)[0])
+			}
+		}
+	}
+
+	if len(diff.Parameters) > 0 {
+		fmt.Println("\nParameters:")
+		for _, p := range diff.Parameters {
+			fmt.Printf("  %s %s: %s\n", p.Node, p.Name, diffValue(p.From, p.To))
+		}
+	}
+
+	if len(diff.Jobs) > 0 {
+		fmt.Println("\nJobs:")
+		for _, j := range diff.Jobs {
+			fmt.Printf("  %s/%s: %s, %s", j.Node, j.Job, diffValue(j.FromStatus, j.ToStatus),
+				diffValue((time.Duration(j.FromDuration)*time.Second).String(), (time.Duration(j.ToDuration)*time.Second).String()))
+			if j.FromModel != j.ToModel {
+				fmt.Printf(", model %s", diffValue(j.FromModel, j.ToModel))
+			}
+			fmt.Println()
+			for _, r := range j.Requirements {
+				fmt.Printf("    requirement %s: %s\n", r.Name, diffValue(r.From, r.To))
+			}
+		}
+	}
+
+	if len(diff.Tests) > 0 {
+		fmt.Println("\nTests:")
+		for _, t := range diff.Tests {
+			fmt.Printf("  %s: %s\n", t.Node, diffValue(fmt.Sprintf("%d/%d KO", t.FromKO, t.FromTotal), fmt.Sprintf("%d/%d KO", t.ToKO, t.ToTotal)))
+			for _, name := range t.NewFailures {
+				fmt.Printf("    new failure: %s\n", name)
+			}
+			for _, name := range t.Fixed {
+				fmt.Printf("    fixed: %s\n", name)
+			}
+		}
+	}
+
+	if len(diff.Artifacts) > 0 {
+		fmt.Println("\nArtifacts:")
+		for _, a := range diff.Artifacts {
+			switch {
+			case a.FromMD5 == "" && a.FromSize == 0:
+				fmt.Printf("  %s/%s: added (%d bytes)\n", a.Node, a.Name, a.ToSize)
+			case a.ToMD5 == "" && a.ToSize == 0:
+				fmt.Printf("  %s/%s: removed\n", a.Node, a.Name)
+			default:
+				fmt.Printf("  %s/%s: modified (%d -> %d bytes)\n", a.Node, a.Name, a.FromSize, a.ToSize)
+			}
+		}
+	}
+
+	return nil
+}
+
+func diffValue(from, to string) string {
+	if from == "" {
+		from = "-"
+	}
+	if to == "" {
+		to = "-"
+	}
+	if from == to {
+		return from
+	}
+	return from + " -> " + to
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -16,6 +16,12 @@ func insertActionChild(db gorp.SqlExecutor, child sdk.Action, actionID int64, ex
 		child.StepName = ""
 	}
 
+	if child.Conditions != nil {
+		if err := child.Conditions.IsValid(); err != nil {
+			return sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid conditions for step %s", child.Name))
+		}
+	}
+
 	ae := actionEdge{
 		ParentID:       actionID,
 		ChildID:        child.ID,
@@ -25,6 +31,9 @@ func insertActionChild(db gorp.SqlExecutor, child sdk.Action, actionID int64, ex
 		AlwaysExecuted: child.AlwaysExecuted,
 		Enabled:        child.Enabled,
 	}
+	if child.Conditions != nil {
+		ae.Conditions = *child.Conditions
+	}
 	if err := insertEdge(db, &ae); err != nil {
 		return err
 	}
@@ -66,6 +75,60 @@ func CheckChildrenForGroupIDs(ctx context.Context, db gorp.SqlExecutor, a *sdk.A
 	return handleChildrenError(a, children)
 }
 
+// LoadChildrenForGroupIDs replaces the given action steps by the actions found for given group ids,
+// with the attributes and parameter values of the steps, like children loaded from action edges.
+func LoadChildrenForGroupIDs(ctx context.Context, db gorp.SqlExecutor, a *sdk.Action, groupIDs []int64) error {
+	if len(a.Actions) == 0 {
+		return nil
+	}
+
+	children, err := LoadAllByIDsWithTypeBuiltinOrPluginOrDefaultInGroupIDs(ctx, db, a.ToUniqueChildrenIDs(), groupIDs,
+		LoadOptions.WithRequirements,
+		LoadOptions.WithParameters,
+		LoadOptions.WithChildren,
+		LoadOptions.WithGroup,
+	)
+	if err != nil {
+		return err
+	}
+	if err := handleChildrenError(a, children); err != nil {
+		return err
+	}
+
+	mChildren := make(map[int64]sdk.Action, len(children))
+	for i := range children {
+		mChildren[children[i].ID] = children[i]
+	}
+
+	for i := range a.Actions {
+		step := a.Actions[i]
+		child := mChildren[step.ID]
+		child.StepName = step.StepName
+		child.Optional = step.Optional
+		child.AlwaysExecuted = step.AlwaysExecuted
+		child.Enabled = step.Enabled
+		child.Conditions = step.Conditions
+
+		params := make([]sdk.Parameter, len(child.Parameters))
+		for j := range child.Parameters {
+			params[j] = child.Parameters[j]
+			for k := range step.Parameters {
+				if step.Parameters[k].Name == params[j].Name {
+					params[j].Value = step.Parameters[k].Value
+					break
+				}
+			}
+		}
+		child.Parameters = params
+
+		a.Actions[i] = child
+	}
+
+	a.Requirements = a.FlattenRequirements()
+
+	return nil
+}
+
 // CheckChildrenForGroupIDsWithLoop return an error if given children not found or tree loop detected.
 func CheckChildrenForGroupIDsWithLoop(ctx context.Context, db gorp.SqlExecutor, a *sdk.Action, groupIDs []int64) error {
 	return checkChildrenForGroupIDsWithLoopStep(ctx, db, a, a, groupIDs)
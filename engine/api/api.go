@@ -15,6 +15,7 @@ import (
 	"time"
 
 	"github.com/blang/semver"
+	"github.com/go-gorp/gorp"
 	"github.com/gorilla/mux"
 	"github.com/pkg/errors"
 	"go.opencensus.io/stats"
@@ -41,6 +42,7 @@ import (
 	"github.com/ovh/cds/engine/api/migrate"
 	"github.com/ovh/cds/engine/api/notification"
 	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/project"
 	"github.com/ovh/cds/engine/api/purge"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
 	"github.com/ovh/cds/engine/api/services"
@@ -196,7 +198,11 @@ type Configuration struct {
 		StepMaxSize    int64 `toml:"stepMaxSize" default:"15728640" comment:"Max step logs size in bytes (default: 15MB)" json:"stepMaxSize"`
 		ServiceMaxSize int64 `toml:"serviceMaxSize" default:"15728640" comment:"Max service logs size in bytes (default: 15MB)" json:"serviceMaxSize"`
 	} `toml:"log" json:"log" comment:"###########################\n Log settings.\n##########################"`
-	CDN cdn.Configuration `toml:"cdn" json:"cdn" comment:"###########################\n CDN settings.\n##########################"`
+	WorkerCache struct {
+		MaxSizeByProject int64 `toml:"maxSizeByProject" default:"10240" comment:"Maximum size of the worker caches of a project in MB (0: no limit).\n When it is reached, the caches that were not used for the longest time are deleted." json:"maxSizeByProject"`
+	} `toml:"workerCache" json:"workerCache" comment:"###########################\n Worker cache settings.\n##########################"`
+	Queue workflow.QueueConfiguration `toml:"queue" json:"queue" comment:"###########################\n Queue settings.\n##########################"`
+	CDN   cdn.Configuration           `toml:"cdn" json:"cdn" comment:"###########################\n CDN settings.\n##########################"`
 }
 
 // DefaultValues is the struc for API Default configuration default values
@@ -218,6 +224,19 @@ func New() *API {
 	return &API{}
 }
 
+// loadProjectForWorkflowRun loads a project with the data needed to process a workflow run outside of a handler,
+// ex: when a job is failed by the heartbeat or when a node run is released from a concurrency group.
+func loadProjectForWorkflowRun(_ context.Context, db gorp.SqlExecutor, store cache.Store, projectID int64) (*sdk.Project, error) {
+	return project.LoadByID(db, projectID,
+		project.LoadOptions.WithVariables,
+		project.LoadOptions.WithKeys,
+		project.LoadOptions.WithFeatures(store),
+		project.LoadOptions.WithIntegrations,
+		project.LoadOptions.WithApplicationVariables,
+		project.LoadOptions.WithApplicationWithDeploymentStrategies,
+	)
+}
+
 func (*API) Init(i interface{}) (cdsclient.ServiceConfig, error) {
 	return cdsclient.ServiceConfig{}, nil
 }
@@ -752,7 +771,7 @@ func (a *API) Serve(ctx context.Context) error {
 		}, a.PanicDump())
 	sdk.GoRoutine(ctx, "workflow.Initialize",
 		func(ctx context.Context) {
-			workflow.Initialize(ctx, a.DBConnectionFactory.GetDBMap, a.Cache, a.Config.URL.UI, a.Config.DefaultOS, a.Config.DefaultArch)
+			workflow.Initialize(ctx, a.DBConnectionFactory.GetDBMap, a.Cache, loadProjectForWorkflowRun, a.Config.URL.UI, a.Config.DefaultOS, a.Config.DefaultArch, a.Config.Queue)
 		}, a.PanicDump())
 	sdk.GoRoutine(ctx, "PushInElasticSearch",
 		func(ctx context.Context) {
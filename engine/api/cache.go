@@ -8,10 +8,122 @@ import (
 	"github.com/gorilla/mux"
 
 	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/workercache"
 	"github.com/ovh/cds/engine/service"
 	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
 )
 
+// countingReader counts the bytes read from a request body
+type countingReader struct {
+	io.ReadCloser
+	size int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.size += int64(n)
+	return n, err
+}
+
+// saveCacheEntry registers a cache pushed by a worker, then deletes the caches of the project
+// that were not used for the longest time if the project exceeds its maximum size.
+func (api *API) saveCacheEntry(ctx context.Context, projectKey, integrationName, tag string, size int64) error {
+	proj, err := project.Load(ctx, api.mustDB(), projectKey)
+	if err != nil {
+		return err
+	}
+
+	entry := sdk.CacheEntry{
+		ProjectID:       proj.ID,
+		IntegrationName: integrationName,
+		Tag:             tag,
+		Size:            size,
+	}
+	if err := workercache.InsertOrUpdate(api.mustDB(), &entry); err != nil {
+		return err
+	}
+
+	if api.Config.WorkerCache.MaxSizeByProject <= 0 {
+		return nil
+	}
+	entries, err := workercache.LoadAllByProjectID(ctx, api.mustDB(), proj.ID)
+	if err != nil {
+		return err
+	}
+	for _, e := range sdk.CacheEntriesToEvict(entries, api.Config.WorkerCache.MaxSizeByProject*1024*1024) {
+		// The pushed cache is always kept
+		if e.ID == entry.ID {
+			continue
+		}
+		storageDriver, err := objectstore.GetDriver(ctx, api.mustDB(), api.SharedStorage, projectKey, e.IntegrationName)
+		if err != nil {
+			log.Warning(ctx, "saveCacheEntry> unable to get storage driver %s: %v", e.IntegrationName, err)
+			continue
+		}
+		if err := storageDriver.Delete(ctx, &sdk.Cache{Project: projectKey, Name: "cache.tar", Tag: e.Tag}); err != nil {
+			log.Warning(ctx, "saveCacheEntry> unable to delete cache %s of project %s: %v", e.Tag, projectKey, err)
+			continue
+		}
+		if err := workercache.Delete(api.mustDB(), e.ID); err != nil {
+			return err
+		}
+		log.Info(ctx, "saveCacheEntry> cache %s of project %s deleted (%d bytes)", e.Tag, projectKey, e.Size)
+	}
+	return nil
+}
+
+// touchCacheEntry updates the last access time of a cache pulled by a worker
+func (api *API) touchCacheEntry(ctx context.Context, projectKey, integrationName, tag string) {
+	proj, err := project.Load(ctx, api.mustDB(), projectKey)
+	if err != nil {
+		log.Warning(ctx, "touchCacheEntry> unable to load project %s: %v", projectKey, err)
+		return
+	}
+	if err := workercache.UpdateLastAccess(api.mustDB(), proj.ID, integrationName, tag); err != nil {
+		log.Warning(ctx, "touchCacheEntry> %v", err)
+	}
+}
+
+func (api *API) getCacheEntryHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if isWorker := isWorker(ctx); !isWorker {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		vars := mux.Vars(r)
+		key := FormString(r, "key")
+		restoreKeys, err := QueryStrings(r, "restoreKey")
+		if err != nil {
+			return sdk.NewError(sdk.ErrWrongRequest, err)
+		}
+
+		proj, err := project.Load(ctx, api.mustDB(), vars[permProjectKey])
+		if err != nil {
+			return err
+		}
+
+		entries, err := workercache.LoadAllByProjectID(ctx, api.mustDB(), proj.ID)
+		if err != nil {
+			return err
+		}
+		integrationEntries := make([]sdk.CacheEntry, 0, len(entries))
+		for _, e := range entries {
+			if e.IntegrationName == vars["integrationName"] {
+				integrationEntries = append(integrationEntries, e)
+			}
+		}
+
+		entry := sdk.SelectCacheEntry(integrationEntries, key, restoreKeys)
+		if entry == nil {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "no cache found for key %s", key)
+		}
+
+		return service.WriteJSON(w, entry, http.StatusOK)
+	}
+}
+
 func (api *API) postPushCacheHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		if isWorker := isWorker(ctx); !isWorker {
@@ -43,11 +155,12 @@ func (api *API) postPushCacheHandler() service.Handler {
 			return err
 		}
 
-		if _, err := storageDriver.Store(&cacheObject, r.Body); err != nil {
+		body := &countingReader{ReadCloser: r.Body}
+		if _, err := storageDriver.Store(&cacheObject, body); err != nil {
 			return sdk.WrapError(err, "cannot store cache")
 		}
 
-		return nil
+		return api.saveCacheEntry(ctx, vars[permProjectKey], vars["integrationName"], tag, body.size)
 	}
 }
 
@@ -77,6 +190,8 @@ func (api *API) getPullCacheHandler() service.Handler {
 			return err
 		}
 
+		api.touchCacheEntry(ctx, vars[permProjectKey], vars["integrationName"], tag)
+
 		s, temporaryURLSupported := storageDriver.(objectstore.DriverWithRedirect)
 		if storageDriver.TemporaryURLSupported() && temporaryURLSupported { // with temp URL
 			fURL, _, err := s.FetchURL(&cacheObject)
@@ -148,6 +263,48 @@ func (api *API) postPushCacheWithTempURLHandler() service.Handler {
 	}
 }
 
+// postPushCacheWithTempURLCallbackHandler registers a cache uploaded by a worker with a temporary url
+func (api *API) postPushCacheWithTempURLCallbackHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		if isWorker := isWorker(ctx); !isWorker {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		vars := mux.Vars(r)
+		tag := vars["tag"]
+
+		// check tag name pattern
+		regexp := sdk.NamePatternRegex
+		if !regexp.MatchString(tag) {
+			return sdk.WithStack(sdk.ErrInvalidName)
+		}
+
+		storageDriver, err := objectstore.GetDriver(ctx, api.mustDB(), api.SharedStorage, vars[permProjectKey], vars["integrationName"])
+		if err != nil {
+			return err
+		}
+
+		store, ok := storageDriver.(objectstore.DriverWithRedirect)
+		if !ok {
+			return sdk.WrapError(sdk.ErrNotImplemented, "cast error")
+		}
+
+		cacheObject := sdk.Cache{
+			Name:    "cache.tar",
+			Project: vars[permProjectKey],
+			Tag:     tag,
+		}
+
+		// The cache is registered only if it was uploaded, with the size known by the storage
+		size, err := store.Size(&cacheObject)
+		if err != nil {
+			return sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrNotFound, "cannot find uploaded cache %s", tag))
+		}
+
+		return api.saveCacheEntry(ctx, vars[permProjectKey], vars["integrationName"], tag, size)
+	}
+}
+
 func (api *API) getPullCacheWithTempURLHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		if isWorker := isWorker(ctx); !isWorker {
@@ -183,6 +340,7 @@ func (api *API) getPullCacheWithTempURLHandler() service.Handler {
 		if err != nil {
 			return sdk.WrapError(err, "cannot get tmp URL")
 		}
+		api.touchCacheEntry(ctx, vars[permProjectKey], vars["integrationName"], tag)
 		cacheObject.TmpURL = url
 		cacheObject.SecretKey = key
 
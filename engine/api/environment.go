@@ -357,6 +357,7 @@ func (api *API) updateEnvironmentHandler() service.Handler {
 
 		oldEnv := env
 		env.Name = envPost.Name
+		env.Freezes = envPost.Freezes
 
 		tx, errBegin := api.mustDB().Begin()
 		if errBegin != nil {
@@ -426,6 +427,7 @@ func (api *API) cloneEnvironmentHandler() service.Handler {
 			ProjectID:  p.ID,
 			ProjectKey: p.Key,
 			Variables:  variables,
+			Freezes:    env.Freezes,
 		}
 
 		tx, err := api.mustDB().Begin()
@@ -0,0 +1,57 @@
+package environment
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// InsertDeployment records a deployment in the deployment registry of an environment
+func InsertDeployment(db gorp.SqlExecutor, d *sdk.EnvironmentDeployment) error {
+	dbDeployment := dbEnvironmentDeployment(*d)
+	if err := gorpmapping.Insert(db, &dbDeployment); err != nil {
+		return sdk.WrapError(err, "unable to insert deployment of application %s on environment %s", d.ApplicationName, d.EnvironmentName)
+	}
+	*d = sdk.EnvironmentDeployment(dbDeployment)
+	return nil
+}
+
+// LoadDeployments returns the deployments on an environment, the last deployed first.
+// Deployments can be filtered on an application name, limit is ignored if not positive.
+func LoadDeployments(ctx context.Context, db gorp.SqlExecutor, envID int64, applicationName string, limit int) ([]sdk.EnvironmentDeployment, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	query := gorpmapping.NewQuery(`
+	SELECT * FROM environment_deployment
+	WHERE environment_id = $1 AND ($2 = '' OR application_name = $2)
+	ORDER BY deployed DESC, id DESC
+	LIMIT $3`).Args(envID, applicationName, limit)
+	var res []dbEnvironmentDeployment
+	if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+		return nil, sdk.WrapError(err, "unable to load deployments of environment %d", envID)
+	}
+	deployments := make([]sdk.EnvironmentDeployment, len(res))
+	for i := range res {
+		deployments[i] = sdk.EnvironmentDeployment(res[i])
+	}
+	return deployments, nil
+}
+
+// LoadDeploymentByID returns a deployment from the deployment registry
+func LoadDeploymentByID(ctx context.Context, db gorp.SqlExecutor, id int64) (*sdk.EnvironmentDeployment, error) {
+	query := gorpmapping.NewQuery(`SELECT * FROM environment_deployment WHERE id = $1`).Args(id)
+	var res dbEnvironmentDeployment
+	found, err := gorpmapping.Get(ctx, db, query, &res)
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load deployment %d", id)
+	}
+	if !found {
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	d := sdk.EnvironmentDeployment(res)
+	return &d, nil
+}
@@ -18,7 +18,7 @@ func LoadAllByIDs(db gorp.SqlExecutor, ids []int64) ([]sdk.Environment, error) {
 
 	query := `
     SELECT environment.id, environment.name, environment.project_id, environment.created,
-      environment.last_modified, environment.from_repository, project.projectkey
+      environment.last_modified, environment.from_repository, environment.freezes, project.projectkey
 		FROM environment
 		JOIN project ON project.id = environment.project_id
 		WHERE environment.id = ANY($1)
@@ -36,7 +36,7 @@ func LoadAllByIDs(db gorp.SqlExecutor, ids []int64) ([]sdk.Environment, error) {
 	for rows.Next() {
 		var env sdk.Environment
 		if err := rows.Scan(&env.ID, &env.Name, &env.ProjectID, &env.Created,
-			&env.LastModified, &env.FromRepository, &env.ProjectKey); err != nil {
+			&env.LastModified, &env.FromRepository, &env.Freezes, &env.ProjectKey); err != nil {
 			return envs, sdk.WithStack(err)
 		}
 		envs = append(envs, env)
@@ -57,7 +57,7 @@ func LoadEnvironments(db gorp.SqlExecutor, projectKey string) ([]sdk.Environment
 
 	query := `
     SELECT environment.id, environment.name, environment.project_id, environment.created,
-      environment.last_modified, environment.from_repository, project.projectkey
+      environment.last_modified, environment.from_repository, environment.freezes, project.projectkey
 		FROM environment
 		JOIN project ON project.id = environment.project_id
 		WHERE project.projectKey = $1
@@ -75,7 +75,7 @@ func LoadEnvironments(db gorp.SqlExecutor, projectKey string) ([]sdk.Environment
 	for rows.Next() {
 		var env sdk.Environment
 		if err := rows.Scan(&env.ID, &env.Name, &env.ProjectID, &env.Created,
-			&env.LastModified, &env.FromRepository, &env.ProjectKey); err != nil {
+			&env.LastModified, &env.FromRepository, &env.Freezes, &env.ProjectKey); err != nil {
 			return envs, sdk.WithStack(err)
 		}
 		envs = append(envs, env)
@@ -115,13 +115,13 @@ func LoadEnvironmentByID(db gorp.SqlExecutor, ID int64) (*sdk.Environment, error
 	var env sdk.Environment
 	query := `
     SELECT environment.id, environment.name, environment.project_id, environment.created,
-      environment.last_modified, environment.from_repository, project.projectkey
+      environment.last_modified, environment.from_repository, environment.freezes, project.projectkey
     FROM environment
     JOIN project ON project.id = environment.project_id
     WHERE environment.id = $1
   `
 	if err := db.QueryRow(query, ID).Scan(&env.ID, &env.Name, &env.ProjectID, &env.Created,
-		&env.LastModified, &env.FromRepository, &env.ProjectKey); err != nil {
+		&env.LastModified, &env.FromRepository, &env.Freezes, &env.ProjectKey); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sdk.WithStack(sdk.ErrEnvironmentNotFound)
 		}
@@ -139,13 +139,13 @@ func LoadEnvironmentByName(db gorp.SqlExecutor, projectKey, envName string) (*sd
 	var env sdk.Environment
 	query := `
     SELECT environment.id, environment.name, environment.project_id, environment.created,
-      environment.last_modified, environment.from_repository, project.projectkey
+      environment.last_modified, environment.from_repository, environment.freezes, project.projectkey
     FROM environment
     JOIN project ON project.id = environment.project_id
     WHERE project.projectKey = $1 AND environment.name = $2
   `
 	if err := db.QueryRow(query, projectKey, envName).Scan(&env.ID, &env.Name, &env.ProjectID, &env.Created,
-		&env.LastModified, &env.FromRepository, &env.ProjectKey); err != nil {
+		&env.LastModified, &env.FromRepository, &env.Freezes, &env.ProjectKey); err != nil {
 		if err == sql.ErrNoRows {
 			return nil, sdk.WithData(sdk.ErrEnvironmentNotFound, envName)
 		}
@@ -154,6 +154,18 @@ func LoadEnvironmentByName(db gorp.SqlExecutor, projectKey, envName string) (*sd
 	return &env, sdk.WithStack(loadDependencies(db, &env))
 }
 
+// LoadFreezes loads the freeze windows of the given environment
+func LoadFreezes(db gorp.SqlExecutor, environmentID int64) (sdk.EnvironmentFreezes, error) {
+	var freezes sdk.EnvironmentFreezes
+	if err := db.QueryRow(`SELECT freezes FROM environment WHERE id = $1`, environmentID).Scan(&freezes); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, sdk.WithStack(sdk.ErrEnvironmentNotFound)
+		}
+		return nil, sdk.WithStack(err)
+	}
+	return freezes, nil
+}
+
 // LoadByWorkflowID loads environments from database for a given workflow id
 func LoadByWorkflowID(db gorp.SqlExecutor, workflowID int64) ([]sdk.Environment, error) {
 	envs := []sdk.Environment{}
@@ -227,14 +239,17 @@ func loadDependencies(db gorp.SqlExecutor, env *sdk.Environment) error {
 
 // InsertEnvironment Insert new environment
 func InsertEnvironment(db gorp.SqlExecutor, env *sdk.Environment) error {
-	query := `INSERT INTO environment (name, project_id, from_repository) VALUES($1, $2, $3) RETURNING id, created, last_modified`
+	query := `INSERT INTO environment (name, project_id, from_repository, freezes) VALUES($1, $2, $3, $4) RETURNING id, created, last_modified`
 
 	rx := sdk.NamePatternRegex
 	if !rx.MatchString(env.Name) {
 		return sdk.NewErrorFrom(sdk.ErrInvalidName, "environment name should match pattern %s", sdk.NamePattern)
 	}
+	if err := env.Freezes.IsValid(); err != nil {
+		return err
+	}
 
-	err := db.QueryRow(query, env.Name, env.ProjectID, env.FromRepository).Scan(&env.ID, &env.Created, &env.LastModified)
+	err := db.QueryRow(query, env.Name, env.ProjectID, env.FromRepository, env.Freezes).Scan(&env.ID, &env.Created, &env.LastModified)
 	if err != nil {
 		pqerr, ok := err.(*pq.Error)
 		if ok {
@@ -253,10 +268,13 @@ func UpdateEnvironment(db gorp.SqlExecutor, env *sdk.Environment) error {
 	if !rx.MatchString(env.Name) {
 		return sdk.NewErrorFrom(sdk.ErrInvalidName, "environment name should match pattern %s", sdk.NamePattern)
 	}
+	if err := env.Freezes.IsValid(); err != nil {
+		return err
+	}
 
 	env.LastModified = time.Now()
-	query := `UPDATE environment SET name=$1, from_repository=$2, last_modified=$3 WHERE id=$4`
-	if _, err := db.Exec(query, env.Name, env.FromRepository, env.LastModified, env.ID); err != nil {
+	query := `UPDATE environment SET name=$1, from_repository=$2, last_modified=$3, freezes=$4 WHERE id=$5`
+	if _, err := db.Exec(query, env.Name, env.FromRepository, env.LastModified, env.Freezes, env.ID); err != nil {
 		return sdk.WithStack(err)
 	}
 	return nil
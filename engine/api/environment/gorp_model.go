@@ -12,6 +12,8 @@ import (
 
 type dbEnvironmentVariableAudit sdk.EnvironmentVariableAudit
 
+type dbEnvironmentDeployment sdk.EnvironmentDeployment
+
 type dbEnvironmentKey struct {
 	gorpmapping.SignedEntity
 	sdk.EnvironmentKey
@@ -84,6 +86,7 @@ func init() {
 	gorpmapping.Register(gorpmapping.New(dbEnvironmentVariableAudit{}, "environment_variable_audit", true, "id"))
 	gorpmapping.Register(gorpmapping.New(dbEnvironmentKey{}, "environment_key", true, "id"))
 	gorpmapping.Register(gorpmapping.New(dbEnvironmentVariable{}, "environment_variable", true, "id"))
+	gorpmapping.Register(gorpmapping.New(dbEnvironmentDeployment{}, "environment_deployment", true, "id"))
 }
 
 // PostGet is a db hook
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/engine/api/permission"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+func (api *API) getEnvironmentDeploymentsHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		projectKey := vars[permProjectKey]
+		environmentName := vars["environmentName"]
+
+		env, err := environment.LoadEnvironmentByName(api.mustDB(), projectKey, environmentName)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load environment %s", environmentName)
+		}
+
+		deployments, err := environment.LoadDeployments(ctx, api.mustDB(), env.ID, FormString(r, "application"), FormInt(r, "limit"))
+		if err != nil {
+			return err
+		}
+		return service.WriteJSON(w, deployments, http.StatusOK)
+	}
+}
+
+func (api *API) getEnvironmentDeploymentHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		projectKey := vars[permProjectKey]
+		environmentName := vars["environmentName"]
+		deploymentID, err := requestVarInt(r, "deploymentID")
+		if err != nil {
+			return err
+		}
+
+		env, err := environment.LoadEnvironmentByName(api.mustDB(), projectKey, environmentName)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load environment %s", environmentName)
+		}
+
+		d, err := environment.LoadDeploymentByID(ctx, api.mustDB(), deploymentID)
+		if err != nil {
+			return err
+		}
+		if d.EnvironmentID != env.ID {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "deployment %d not found on environment %s", deploymentID, environmentName)
+		}
+		return service.WriteJSON(w, d, http.StatusOK)
+	}
+}
+
+// postWorkflowDeploymentRollbackHandler runs again the node of a deployment, in the workflow run of the deployment,
+// with the payload and the pipeline parameters used for the deployment.
+func (api *API) postWorkflowDeploymentRollbackHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		workflowName := vars["permWorkflowName"]
+		deploymentID, err := requestVarInt(r, "deploymentID")
+		if err != nil {
+			return err
+		}
+
+		d, err := environment.LoadDeploymentByID(ctx, api.mustDB(), deploymentID)
+		if err != nil {
+			return err
+		}
+
+		workflowRun, err := workflow.LoadRun(ctx, api.mustDB(), key, workflowName, d.Number, workflow.LoadRunOptions{})
+		if err != nil && !sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return sdk.WrapError(err, "unable to load workflow run with number %d for workflow %s", d.Number, workflowName)
+		}
+		if workflowRun == nil || workflowRun.ID != d.WorkflowRunID {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "workflow run %d of deployment %d not found in workflow %s", d.Number, deploymentID, workflowName)
+		}
+
+		var nodeRun *sdk.WorkflowNodeRun
+		for i := range workflowRun.WorkflowNodeRuns[d.WorkflowNodeID] {
+			if workflowRun.WorkflowNodeRuns[d.WorkflowNodeID][i].ID == d.WorkflowNodeRunID {
+				nodeRun = &workflowRun.WorkflowNodeRuns[d.WorkflowNodeID][i]
+			}
+		}
+		if nodeRun == nil {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "node run %d of deployment %d not found in workflow run %d", d.WorkflowNodeRunID, deploymentID, workflowRun.Number)
+		}
+		for _, nr := range workflowRun.WorkflowNodeRuns[d.WorkflowNodeID] {
+			if !sdk.StatusIsTerminated(nr.Status) {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "pipeline %s is already running in %d.%d", nr.WorkflowNodeName, workflowRun.Number, nr.SubNumber)
+			}
+		}
+
+		node := workflowRun.Workflow.WorkflowData.NodeByID(d.WorkflowNodeID)
+		if node == nil {
+			return sdk.WrapError(sdk.ErrWorkflowNodeNotFound, "unable to find node %d", d.WorkflowNodeID)
+		}
+		c := getAPIConsumer(ctx)
+		if !permission.AccessToWorkflowNode(ctx, api.mustDB(), &workflowRun.Workflow, node, c, sdk.PermissionReadExecute) {
+			return sdk.WrapError(sdk.ErrNoPermExecution, "not enough right on node %s", node.Name)
+		}
+
+		opts := &sdk.WorkflowRunPostHandlerOption{
+			Number:      &workflowRun.Number,
+			FromNodeIDs: []int64{d.WorkflowNodeID},
+			Manual: &sdk.WorkflowNodeRunManual{
+				Payload:            nodeRun.Payload,
+				PipelineParameters: nodeRun.PipelineParameters,
+			},
+		}
+
+		wf := &workflowRun.Workflow
+		workflowRun.Status = sdk.StatusWaiting
+		sdk.GoRoutine(context.Background(), fmt.Sprintf("api.initWorkflowRun-%d", workflowRun.ID), func(ctx context.Context) {
+			api.initWorkflowRun(ctx, key, wf, workflowRun, opts, c)
+		}, api.PanicDump())
+
+		return service.WriteJSON(w, workflowRun, http.StatusAccepted)
+	}
+}
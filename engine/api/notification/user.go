@@ -183,7 +183,9 @@ func ShouldSendUserWorkflowNotification(ctx context.Context, notif sdk.WorkflowN
 func checkConditions(ctx context.Context, conditions sdk.WorkflowNodeConditions, params []sdk.Parameter) bool {
 	var conditionsOK bool
 	var errc error
-	if conditions.LuaScript == "" {
+	if conditions.Expression != "" {
+		conditionsOK, errc = sdk.WorkflowCheckConditionsExpression(conditions.Expression, params)
+	} else if conditions.LuaScript == "" {
 		conditionsOK, errc = sdk.WorkflowCheckConditions(conditions.PlainConditions, params)
 	} else {
 		luacheck, err := luascript.NewCheck()
@@ -145,6 +145,19 @@ func (s *AWSS3Store) StoreURL(o Object, contentType string) (string, string, err
 	return urlStr, *key, nil
 }
 
+// Size returns the size in bytes of an object
+func (s *AWSS3Store) Size(o Object) (int64, error) {
+	s3n := s3.New(s.sess)
+	out, err := s3n.HeadObject(&s3.HeadObjectInput{
+		Key:    aws.String(s.getObjectPath(o)),
+		Bucket: aws.String(s.bucketName),
+	})
+	if err != nil {
+		return 0, sdk.WrapError(err, "AWS-S3-Store> Unable to get object %s", s.getObjectPath(o))
+	}
+	return aws.Int64Value(out.ContentLength), nil
+}
+
 func (s *AWSS3Store) Fetch(ctx context.Context, o Object) (io.ReadCloser, error) {
 	s3n := s3.New(s.sess)
 	log.Debug("AWS-S3-Store> Fetching object %s from bucket %s", s.getObjectPath(o), s.bucketName)
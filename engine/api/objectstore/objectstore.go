@@ -36,6 +36,8 @@ type DriverWithRedirect interface {
 	FetchURL(o Object) (url string, key string, err error)
 	// ServeStaticFilesURL returns a temporary url and a secret key to serve static files in a container
 	ServeStaticFilesURL(o Object, entrypoint string) (string, string, error)
+	// Size returns the size in bytes of an object uploaded with a temporary url
+	Size(o Object) (int64, error)
 }
 
 // Kind will define const defining all supported objecstore drivers
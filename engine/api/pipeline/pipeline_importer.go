@@ -30,6 +30,10 @@ func ImportUpdate(ctx context.Context, db gorp.SqlExecutor, proj sdk.Project, pi
 		return sdk.WrapError(sdk.ErrPipelineAsCodeOverride, "unable to update as code pipeline %s/%s.", oldPipeline.FromRepository, pip.FromRepository)
 	}
 
+	if err := pip.CheckFinallyStage(); err != nil {
+		return err
+	}
+
 	// check that action used by job can be used by pipeline's project
 	groupIDs := make([]int64, 0, len(proj.ProjectGroups)+1)
 	groupIDs = append(groupIDs, group.SharedInfraGroup.ID)
@@ -60,6 +64,10 @@ func ImportUpdate(ctx context.Context, db gorp.SqlExecutor, proj sdk.Project, pi
 			return sdk.NewErrorFrom(sdk.ErrInvalidName, "stage name '%s' should match pattern %s", s.Name, sdk.NamePatternSpace)
 		}
 
+		if err := s.CheckJobsNeeds(); err != nil {
+			return err
+		}
+
 		//Insert stage
 		log.Debug("Inserting stage %s", s.Name)
 		s.PipelineID = pip.ID
@@ -150,6 +158,10 @@ func importNew(ctx context.Context, db gorp.SqlExecutor, proj sdk.Project, pip *
 		groupIDs = append(groupIDs, proj.ProjectGroups[i].Group.ID)
 	}
 
+	if err := pip.CheckFinallyStage(); err != nil {
+		return err
+	}
+
 	log.Debug("pipeline.importNew> Creating pipeline %s", pip.Name)
 	//Insert pipeline
 	if err := InsertPipeline(db, pip); err != nil {
@@ -167,6 +179,9 @@ func importNew(ctx context.Context, db gorp.SqlExecutor, proj sdk.Project, pip *
 		s.Enabled = true
 		//Set relation with pipeline
 		s.PipelineID = pip.ID
+		if err := s.CheckJobsNeeds(); err != nil {
+			return err
+		}
 		//Insert stage
 		if err := InsertStage(db, &s); err != nil {
 			return err
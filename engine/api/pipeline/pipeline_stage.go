@@ -17,7 +17,7 @@ import (
 // LoadStage Get a stage from its ID and pipeline ID
 func LoadStage(db gorp.SqlExecutor, pipelineID int64, stageID int64) (*sdk.Stage, error) {
 	query := `
-		SELECT pipeline_stage.id, pipeline_stage.pipeline_id, pipeline_stage.name, pipeline_stage.build_order, pipeline_stage.enabled
+		SELECT pipeline_stage.id, pipeline_stage.pipeline_id, pipeline_stage.name, pipeline_stage.build_order, pipeline_stage.enabled, pipeline_stage.finally
 		FROM pipeline_stage
 		WHERE pipeline_stage.pipeline_id = $1
 		AND pipeline_stage.id = $2;
@@ -34,7 +34,7 @@ func LoadStage(db gorp.SqlExecutor, pipelineID int64, stageID int64) (*sdk.Stage
 	defer rows.Close()
 
 	for rows.Next() {
-		if err := rows.Scan(&stage.ID, &stage.PipelineID, &stage.Name, &stage.BuildOrder, &stage.Enabled); err != nil {
+		if err := rows.Scan(&stage.ID, &stage.PipelineID, &stage.Name, &stage.BuildOrder, &stage.Enabled, &stage.Finally); err != nil {
 			return nil, sdk.WithStack(err)
 		}
 	}
@@ -44,9 +44,9 @@ func LoadStage(db gorp.SqlExecutor, pipelineID int64, stageID int64) (*sdk.Stage
 
 // InsertStage insert given stage into given database
 func InsertStage(db gorp.SqlExecutor, s *sdk.Stage) error {
-	query := `INSERT INTO "pipeline_stage" (pipeline_id, name, build_order, enabled) VALUES($1,$2,$3,$4) RETURNING id`
+	query := `INSERT INTO "pipeline_stage" (pipeline_id, name, build_order, enabled, finally) VALUES($1,$2,$3,$4,$5) RETURNING id`
 
-	if err := db.QueryRow(query, s.PipelineID, s.Name, s.BuildOrder, s.Enabled).Scan(&s.ID); err != nil {
+	if err := db.QueryRow(query, s.PipelineID, s.Name, s.BuildOrder, s.Enabled, s.Finally).Scan(&s.ID); err != nil {
 		return err
 	}
 	return insertStageConditions(db, s)
@@ -54,6 +54,13 @@ func InsertStage(db gorp.SqlExecutor, s *sdk.Stage) error {
 
 // insertStageConditions insert prequisite for given stage in database
 func insertStageConditions(db gorp.SqlExecutor, s *sdk.Stage) error {
+	if err := s.Conditions.IsValid(); err != nil {
+		return err
+	}
+	if s.Conditions.Expression != "" {
+		s.Conditions.PlainConditions = nil
+		s.Conditions.LuaScript = ""
+	}
 	if s.Conditions.LuaScript != "" {
 		s.Conditions.PlainConditions = nil
 	}
@@ -79,13 +86,13 @@ func LoadPipelineStage(ctx context.Context, db gorp.SqlExecutor, p *sdk.Pipeline
 
 	query := `
 	SELECT pipeline_stage_R.id as stage_id, pipeline_stage_R.pipeline_id, pipeline_stage_R.name, pipeline_stage_R.last_modified,
-			pipeline_stage_R.build_order, pipeline_stage_R.enabled, pipeline_stage_R.conditions,
+			pipeline_stage_R.build_order, pipeline_stage_R.enabled, pipeline_stage_R.finally, pipeline_stage_R.conditions,
 			pipeline_action_R.id as pipeline_action_id, pipeline_action_R.action_id, pipeline_action_R.action_last_modified,
 			pipeline_action_R.action_args, pipeline_action_R.action_enabled
 	FROM (
 		SELECT pipeline_stage.id, pipeline_stage.pipeline_id,
 				pipeline_stage.name, pipeline_stage.last_modified, pipeline_stage.build_order,
-				pipeline_stage.enabled,
+				pipeline_stage.enabled, pipeline_stage.finally,
 				pipeline_stage.conditions
 		FROM pipeline_stage
 		WHERE pipeline_id = $1
@@ -117,12 +124,12 @@ func LoadPipelineStage(ctx context.Context, db gorp.SqlExecutor, p *sdk.Pipeline
 		var pipelineActionID, actionID sql.NullInt64
 		var stageName string
 		var stageConditions, actionArgs sql.NullString
-		var stageEnabled, actionEnabled sql.NullBool
+		var stageEnabled, stageFinally, actionEnabled sql.NullBool
 		var stageLastModified, actionLastModified pq.NullTime
 
 		err = rows.Scan(
 			&stageID, &pipelineID, &stageName, &stageLastModified,
-			&stageBuildOrder, &stageEnabled, &stageConditions, &pipelineActionID, &actionID, &actionLastModified,
+			&stageBuildOrder, &stageEnabled, &stageFinally, &stageConditions, &pipelineActionID, &actionID, &actionLastModified,
 			&actionArgs, &actionEnabled)
 		if err != nil {
 			return sdk.WithStack(err)
@@ -136,6 +143,7 @@ func LoadPipelineStage(ctx context.Context, db gorp.SqlExecutor, p *sdk.Pipeline
 				PipelineID:   pipelineID,
 				Name:         stageName,
 				Enabled:      stageEnabled.Bool,
+				Finally:      stageFinally.Bool,
 				BuildOrder:   stageBuildOrder,
 				LastModified: stageLastModified.Time.Unix(),
 			}
@@ -222,8 +230,8 @@ func updateStageOrder(db gorp.SqlExecutor, id int64, order int) error {
 
 // UpdateStage update Stage and all its prequisites
 func UpdateStage(db gorp.SqlExecutor, s *sdk.Stage) error {
-	query := `UPDATE pipeline_stage SET name=$1, build_order=$2, enabled=$3 WHERE id=$4`
-	if _, err := db.Exec(query, s.Name, s.BuildOrder, s.Enabled, s.ID); err != nil {
+	query := `UPDATE pipeline_stage SET name=$1, build_order=$2, enabled=$3, finally=$4 WHERE id=$5`
+	if _, err := db.Exec(query, s.Name, s.BuildOrder, s.Enabled, s.Finally, s.ID); err != nil {
 		return err
 	}
 
@@ -64,6 +64,12 @@ func (api *API) addJobToStageHandler() service.Handler {
 			return sdk.WrapError(sdk.ErrNotFound, "addJobToStageHandler>Stage not found")
 		}
 
+		// check that the new job needs are valid for the stage
+		stage.Jobs = append(stage.Jobs, job)
+		if err := stage.CheckJobsNeeds(); err != nil {
+			return err
+		}
+
 		tx, errb := api.mustDB().Begin()
 		if errb != nil {
 			return errb
@@ -182,6 +188,19 @@ func (api *API) updateJobHandler() service.Handler {
 			return sdk.WrapError(sdk.ErrNotFound, "job not found in pipeline")
 		}
 
+		// check that the updated job needs are valid for the stage
+		updatedStage := stage
+		updatedStage.Jobs = make([]sdk.Job, len(stage.Jobs))
+		for i, j := range stage.Jobs {
+			if j.PipelineActionID == jobID {
+				j = job
+			}
+			updatedStage.Jobs[i] = j
+		}
+		if err := updatedStage.CheckJobsNeeds(); err != nil {
+			return err
+		}
+
 		rx := sdk.NamePatternSpaceRegex
 		// stage name mandatory if there are many stages
 		if len(pipelineData.Stages) > 1 && !rx.MatchString(stage.Name) {
@@ -287,6 +306,13 @@ func (api *API) deleteJobHandler() service.Handler {
 			return sdk.WrapError(sdk.ErrNotFound, "deleteJobHandler>Job not found")
 		}
 
+		// check that the job is not needed by another job of the stage
+		for _, j := range stage.Jobs {
+			if j.PipelineActionID != jobID && j.Action.Needs.Contains(jobToDelete.Action.Name) {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "job %s is needed by job %s", jobToDelete.Action.Name, j.Action.Name)
+			}
+		}
+
 		tx, errb := api.mustDB().Begin()
 		if errb != nil {
 			return sdk.WrapError(errb, "deleteJobHandler> Cannot begin transaction")
@@ -38,12 +38,26 @@ func (api *API) addStageHandler() service.Handler {
 		stageData.PipelineID = pipelineData.ID
 		stageData.Enabled = true
 
+		finallyStage := pipelineData.FinallyStage()
+		if finallyStage != nil && stageData.Finally {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "pipeline %s already has a finally stage %s", pipelineData.Name, finallyStage.Name)
+		}
+
 		tx, err := api.mustDB().Begin()
 		if err != nil {
 			return sdk.WrapError(err, "Cannot start transaction")
 		}
 		defer tx.Rollback() // nolint
 
+		// New stages are added before the finally stage
+		if finallyStage != nil {
+			stageData.BuildOrder = finallyStage.BuildOrder
+			finallyStage.BuildOrder++
+			if err := pipeline.UpdateStage(tx, finallyStage); err != nil {
+				return sdk.WrapError(err, "Cannot update finally stage")
+			}
+		}
+
 		if err := pipeline.CreateAudit(tx, pipelineData, pipeline.AuditAddStage, getAPIConsumer(ctx)); err != nil {
 			return sdk.WrapError(err, "Cannot create pipeline audit")
 		}
@@ -161,6 +175,10 @@ func (api *API) moveStageHandler() service.Handler {
 			return sdk.WrapError(err, "Cannot load stages")
 		}
 
+		if err := pipelineData.CheckFinallyStage(); err != nil {
+			return err
+		}
+
 		if err := tx.Commit(); err != nil {
 			return sdk.WithStack(err)
 		}
@@ -207,6 +225,15 @@ func (api *API) updateStageHandler() service.Handler {
 		}
 		stageData.ID = s.ID
 
+		for i := range pipelineData.Stages {
+			if pipelineData.Stages[i].ID == stageData.ID {
+				pipelineData.Stages[i] = *stageData
+			}
+		}
+		if err := pipelineData.CheckFinallyStage(); err != nil {
+			return err
+		}
+
 		tx, err := api.mustDB().Begin()
 		if err != nil {
 			return sdk.WrapError(err, "Cannot start transaction")
@@ -170,6 +170,104 @@ func (api *API) disableWorkerHandler() service.Handler {
 	}
 }
 
+func (api *API) getWorkerMeHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		wk, err := worker.LoadByConsumerID(ctx, api.mustDB(), getAPIConsumer(ctx).ID)
+		if err != nil {
+			return err
+		}
+		return service.WriteJSON(w, wk, http.StatusOK)
+	}
+}
+
+// postAssignJobToWorkerHandler books a job for an idle warm worker of the calling hatchery.
+func (api *API) postAssignJobToWorkerHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		id := vars["id"]
+
+		jobID, err := requestVarInt(r, "permJobID")
+		if err != nil {
+			return err
+		}
+
+		if ok := isHatchery(ctx); !ok {
+			return sdk.WithStack(sdk.ErrForbidden)
+		}
+
+		hatcherySrv, err := services.LoadByID(ctx, api.mustDB(), getAPIConsumer(ctx).Service.ID)
+		if err != nil {
+			return err
+		}
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		wk, err := worker.LoadAndLockByID(ctx, tx, id)
+		if err != nil {
+			if sdk.ErrorIs(err, sdk.ErrNotFound) {
+				return sdk.NewErrorFrom(sdk.ErrWorkerNotAvailable, "worker %s does not exist or is already being assigned", id)
+			}
+			return err
+		}
+		if wk.HatcheryID != hatcherySrv.ID {
+			return sdk.WrapError(sdk.ErrForbidden, "cannot assign a job to a worker from hatchery (expected: %d/actual: %d)", wk.HatcheryID, hatcherySrv.ID)
+		}
+		if !wk.Warm || wk.Status != sdk.StatusWaiting || wk.JobRunID != nil {
+			return sdk.NewErrorFrom(sdk.ErrWorkerNotAvailable, "worker %s is not an idle warm worker", wk.Name)
+		}
+
+		job, err := workflow.LoadNodeJobRun(ctx, tx, api.Cache, jobID)
+		if err != nil {
+			return err
+		}
+		if job.Status != sdk.StatusWaiting {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "job %d is not waiting (status: %s)", jobID, job.Status)
+		}
+
+		if _, err := workflow.BookNodeJobRun(ctx, api.Cache, jobID, hatcherySrv); err != nil {
+			return sdk.WrapError(err, "job already booked")
+		}
+		// The job should be available for other workers if it was not assigned to this one
+		var assigned bool
+		defer func() {
+			if assigned {
+				return
+			}
+			if err := workflow.FreeNodeJobRun(ctx, api.Cache, jobID); err != nil {
+				log.Error(ctx, "postAssignJobToWorkerHandler> cannot free job %d: %v", jobID, err)
+			}
+		}()
+
+		// The warm worker was registered with the groups of the hatchery, restrict it to the groups of the job
+		// as for a worker spawned for a job.
+		workerConsumer, err := authentication.LoadConsumerByID(ctx, tx, wk.ConsumerID)
+		if err != nil {
+			return err
+		}
+		workerConsumer.GroupIDs = sdk.Groups(job.ExecGroups).ToIDs()
+		if err := authentication.UpdateConsumer(ctx, tx, workerConsumer); err != nil {
+			return err
+		}
+
+		if err := worker.SetJobRunID(ctx, tx, wk.ID, jobID); err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+		assigned = true
+
+		log.Debug("postAssignJobToWorkerHandler> job %d assigned to worker %s", jobID, wk.Name)
+
+		return service.WriteJSON(w, nil, http.StatusOK)
+	}
+}
+
 func (api *API) postRefreshWorkerHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		wk, err := worker.LoadByConsumerID(ctx, api.mustDB(), getAPIConsumer(ctx).ID)
@@ -178,6 +178,41 @@ func SetToBuilding(ctx context.Context, db gorp.SqlExecutor, workerID string, jo
 	return nil
 }
 
+// LoadAndLockByID loads the worker with given id and locks it until the end of the transaction.
+func LoadAndLockByID(ctx context.Context, db gorp.SqlExecutor, id string) (*sdk.Worker, error) {
+	query := gorpmapping.NewQuery("SELECT * FROM worker WHERE id = $1 FOR UPDATE SKIP LOCKED").Args(id)
+	var w dbWorker
+	found, err := gorpmapping.Get(ctx, db, query, &w)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	isValid, err := gorpmapping.CheckSignature(w, w.Signature)
+	if err != nil {
+		return nil, err
+	}
+	if !isValid {
+		return nil, sdk.WithStack(sdk.ErrInvalidData)
+	}
+	return &w.Worker, nil
+}
+
+// SetJobRunID sets the job run booked for given worker
+func SetJobRunID(ctx context.Context, db gorp.SqlExecutor, workerID string, jobRunID int64) error {
+	w, err := LoadByID(ctx, db, workerID)
+	if err != nil {
+		return err
+	}
+	w.JobRunID = &jobRunID
+	dbData := &dbWorker{Worker: *w}
+	if err := gorpmapping.UpdateAndSign(ctx, db, dbData); err != nil {
+		return err
+	}
+	return nil
+}
+
 // LoadWorkerByIDWithDecryptKey load worker with decrypted private key
 func LoadWorkerByIDWithDecryptKey(ctx context.Context, db gorp.SqlExecutor, workerID string) (*sdk.Worker, error) {
 	var work dbWorker
@@ -62,7 +62,7 @@ func RegisterWorker(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "unauthorized to register a worker without a name")
 	}
 
-	if !spawnArgs.RegisterOnly && spawnArgs.JobID == 0 {
+	if !spawnArgs.RegisterOnly && !spawnArgs.Warm && spawnArgs.JobID == 0 {
 		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "unauthorized to register a worker for a job without a JobID")
 	}
 
@@ -95,6 +95,7 @@ func RegisterWorker(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		Version:    registrationForm.Version,
 		OS:         registrationForm.OS,
 		Arch:       registrationForm.Arch,
+		Warm:       spawnArgs.Warm,
 	}
 	if model != nil {
 		w.ModelID = &spawnArgs.Model.ID
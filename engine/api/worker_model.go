@@ -44,6 +44,9 @@ func (api *API) postWorkerModelHandler() service.Handler {
 			if !data.Restricted && data.PatternName == "" {
 				return sdk.NewErrorFrom(sdk.ErrWorkerModelNoPattern, "missing model pattern name")
 			}
+			if err := workermodel.CheckKubernetesOverrides(data); err != nil {
+				return err
+			}
 		}
 
 		tx, err := api.mustDB().Begin()
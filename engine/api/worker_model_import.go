@@ -88,6 +88,9 @@ func (api *API) postWorkerModelImportHandler() service.Handler {
 				if !data.Restricted && data.PatternName == "" {
 					return sdk.NewErrorFrom(sdk.ErrWorkerModelNoPattern, "missing model pattern name")
 				}
+				if err := workermodel.CheckKubernetesOverrides(data); err != nil {
+					return err
+				}
 			}
 
 			// validate worker model type fields
@@ -578,6 +578,129 @@ func Test_postWorkerModelAsAGroupAdminWithoutRestrictWithPattern(t *testing.T) {
 	test.Equal(t, "apt-get install curl -y", newModel.ModelVirtualMachine.PreCmd, "Pre worker command is not good")
 }
 
+func Test_postWorkerModelAsAGroupAdminWithKubernetesOverrides(t *testing.T) {
+	Test_DeleteAllWorkerModels(t)
+
+	api, _, router := newTestAPI(t)
+
+	g := &sdk.Group{
+		Name: sdk.RandomString(10),
+	}
+	u, jwt := assets.InsertLambdaUser(t, api.mustDB(), g)
+	assets.SetUserGroupAdmin(t, api.mustDB(), g.ID, u.ID)
+
+	pattern := sdk.ModelPattern{
+		Name: sdk.RandomString(10),
+		Type: sdk.Docker,
+		Model: sdk.ModelCmds{
+			Shell: "sh -c",
+			Cmd:   "worker",
+		},
+	}
+	require.NoError(t, workermodel.InsertPattern(api.mustDB(), &pattern))
+
+	model := sdk.Model{
+		Name:        "Test1",
+		GroupID:     g.ID,
+		Type:        sdk.Docker,
+		PatternName: pattern.Name,
+		ModelDocker: sdk.ModelDocker{
+			Image: "buildpack-deps:jessie",
+			Kubernetes: &sdk.ModelDockerKubernetes{
+				ServiceAccount: "admin",
+			},
+		},
+	}
+
+	uri := router.GetRoute("POST", api.postWorkerModelHandler, nil)
+	test.NotEmpty(t, uri)
+	req := assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, model)
+	w := httptest.NewRecorder()
+	router.Mux.ServeHTTP(w, req)
+	assert.Equal(t, 403, w.Code, "Status code should equal 403 because only an admin can set Kubernetes overrides on a not restricted model")
+
+	// Kubernetes overrides set by an admin are kept when the model is updated by a group admin
+	model.ModelDocker.Kubernetes = &sdk.ModelDockerKubernetes{
+		ServiceAccount: "builder",
+	}
+	model.Author.Username = u.Username
+	require.NoError(t, workermodel.Insert(context.TODO(), api.mustDB(), &model))
+
+	model.ModelDocker.Kubernetes = &sdk.ModelDockerKubernetes{
+		ServiceAccount: "admin",
+	}
+	uri = router.GetRoute("PUT", api.putWorkerModelHandler, map[string]string{
+		"permGroupName": g.Name,
+		"permModelName": model.Name,
+	})
+	test.NotEmpty(t, uri)
+	req = assets.NewJWTAuthentifiedRequest(t, jwt, "PUT", uri, model)
+	w = httptest.NewRecorder()
+	router.Mux.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var updatedModel sdk.Model
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updatedModel))
+	require.NotNil(t, updatedModel.ModelDocker.Kubernetes)
+	assert.Equal(t, "builder", updatedModel.ModelDocker.Kubernetes.ServiceAccount)
+}
+
+func Test_postWorkerModelAsAGroupAdminWithRestrictAndKubernetesOverrides(t *testing.T) {
+	Test_DeleteAllWorkerModels(t)
+
+	api, _, router := newTestAPI(t)
+
+	g := &sdk.Group{
+		Name: sdk.RandomString(10),
+	}
+	u, jwt := assets.InsertLambdaUser(t, api.mustDB(), g)
+	assets.SetUserGroupAdmin(t, api.mustDB(), g.ID, u.ID)
+
+	model := sdk.Model{
+		Name:       "Test1",
+		GroupID:    g.ID,
+		Type:       sdk.Docker,
+		Restricted: true,
+		ModelDocker: sdk.ModelDocker{
+			Image: "buildpack-deps:jessie",
+			Shell: "sh -c",
+			Cmd:   "worker --api={{.API}}",
+			Kubernetes: &sdk.ModelDockerKubernetes{
+				ServiceAccount: "admin",
+			},
+		},
+	}
+
+	uri := router.GetRoute("POST", api.postWorkerModelHandler, nil)
+	test.NotEmpty(t, uri)
+	req := assets.NewJWTAuthentifiedRequest(t, jwt, "POST", uri, model)
+	w := httptest.NewRecorder()
+	router.Mux.ServeHTTP(w, req)
+	assert.Equal(t, 403, w.Code, "Status code should equal 403 because only an admin can set Kubernetes overrides, even on a restricted model")
+
+	// The overrides given by a group admin are ignored when a restricted model is updated
+	model.ModelDocker.Kubernetes = nil
+	model.Author.Username = u.Username
+	require.NoError(t, workermodel.Insert(context.TODO(), api.mustDB(), &model))
+
+	model.ModelDocker.Kubernetes = &sdk.ModelDockerKubernetes{
+		ServiceAccount: "admin",
+	}
+	uri = router.GetRoute("PUT", api.putWorkerModelHandler, map[string]string{
+		"permGroupName": g.Name,
+		"permModelName": model.Name,
+	})
+	test.NotEmpty(t, uri)
+	req = assets.NewJWTAuthentifiedRequest(t, jwt, "PUT", uri, model)
+	w = httptest.NewRecorder()
+	router.Mux.ServeHTTP(w, req)
+	require.Equal(t, 200, w.Code)
+
+	var updatedModel sdk.Model
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updatedModel))
+	assert.Nil(t, updatedModel.ModelDocker.Kubernetes)
+}
+
 func Test_postWorkerModelAsAWrongGroupMember(t *testing.T) {
 	Test_DeleteAllWorkerModels(t)
 
@@ -0,0 +1,63 @@
+package workercache
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+// InsertOrUpdate saves a cache entry, an existing entry with the same tag is replaced
+func InsertOrUpdate(db gorp.SqlExecutor, e *sdk.CacheEntry) error {
+	now := time.Now()
+	e.Created = now
+	e.LastAccess = now
+	query := `
+	INSERT INTO worker_cache (project_id, integration_name, tag, size, created, last_access)
+	VALUES ($1, $2, $3, $4, $5, $6)
+	ON CONFLICT (project_id, integration_name, tag)
+	DO UPDATE SET size = $4, created = $5, last_access = $6
+	RETURNING id`
+	if err := db.QueryRow(query, e.ProjectID, e.IntegrationName, e.Tag, e.Size, e.Created, e.LastAccess).Scan(&e.ID); err != nil {
+		return sdk.WrapError(err, "unable to save cache %s", e.Tag)
+	}
+	return nil
+}
+
+// LoadAllByProjectID returns the cache entries of a project, the last accessed first
+func LoadAllByProjectID(ctx context.Context, db gorp.SqlExecutor, projectID int64) ([]sdk.CacheEntry, error) {
+	query := gorpmapping.NewQuery(`
+	SELECT * FROM worker_cache
+	WHERE project_id = $1
+	ORDER BY last_access DESC`).Args(projectID)
+	var res []cacheEntry
+	if err := gorpmapping.GetAll(ctx, db, query, &res); err != nil {
+		return nil, sdk.WrapError(err, "unable to load caches of project %d", projectID)
+	}
+	entries := make([]sdk.CacheEntry, len(res))
+	for i := range res {
+		entries[i] = sdk.CacheEntry(res[i])
+	}
+	return entries, nil
+}
+
+// UpdateLastAccess sets the last access time of a cache entry
+func UpdateLastAccess(db gorp.SqlExecutor, projectID int64, integrationName, tag string) error {
+	if _, err := db.Exec(`
+	UPDATE worker_cache SET last_access = $4
+	WHERE project_id = $1 AND integration_name = $2 AND tag = $3`, projectID, integrationName, tag, time.Now()); err != nil {
+		return sdk.WrapError(err, "unable to update cache %s", tag)
+	}
+	return nil
+}
+
+// Delete removes a cache entry
+func Delete(db gorp.SqlExecutor, id int64) error {
+	if _, err := db.Exec(`DELETE FROM worker_cache WHERE id = $1`, id); err != nil {
+		return sdk.WrapError(err, "unable to delete cache %d", id)
+	}
+	return nil
+}
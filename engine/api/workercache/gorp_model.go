@@ -0,0 +1,12 @@
+package workercache
+
+import (
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+)
+
+type cacheEntry sdk.CacheEntry
+
+func init() {
+	gorpmapping.Register(gorpmapping.New(cacheEntry{}, "worker_cache", true, "id"))
+}
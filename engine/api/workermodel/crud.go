@@ -173,5 +173,16 @@ func CopyModelTypeData(old, data *sdk.Model) error {
 		}
 	}
 
+	// the Kubernetes overrides grant rights on the cluster of the hatchery, they can only be changed by an admin
+	data.ModelDocker.Kubernetes = old.ModelDocker.Kubernetes
+
+	return nil
+}
+
+// CheckKubernetesOverrides returns an error if Kubernetes overrides are given, they can only be set by an admin.
+func CheckKubernetesOverrides(data sdk.Model) error {
+	if data.ModelDocker.Kubernetes != nil {
+		return sdk.NewErrorFrom(sdk.ErrForbidden, "only an admin can set Kubernetes overrides on a worker model")
+	}
 	return nil
 }
@@ -66,6 +66,14 @@ func LoadArtifactByIDs(db gorp.SqlExecutor, workflowID, artifactID int64) (*sdk.
 }
 
 func loadArtifactByNodeRunID(db gorp.SqlExecutor, nodeRunID int64) ([]sdk.WorkflowNodeRunArtifact, error) {
+	return loadArtifacts(db, "workflow_node_run_id = $1", nodeRunID)
+}
+
+func loadArtifactByWorkflowRunID(db gorp.SqlExecutor, workflowRunID int64) ([]sdk.WorkflowNodeRunArtifact, error) {
+	return loadArtifacts(db, "workflow_run_id = $1", workflowRunID)
+}
+
+func loadArtifacts(db gorp.SqlExecutor, where string, args ...interface{}) ([]sdk.WorkflowNodeRunArtifact, error) {
 	var artifactsGorp []NodeRunArtifact
 	if _, err := db.Select(&artifactsGorp, `SELECT
 			id,
@@ -82,7 +90,7 @@ func loadArtifactByNodeRunID(db gorp.SqlExecutor, nodeRunID int64) ([]sdk.Workfl
 			workflow_run_id,
 			project_integration_id,
 			coalesce(sha512sum, '') AS sha512sum
-		FROM workflow_node_run_artifacts WHERE workflow_node_run_id = $1`, nodeRunID); err != nil {
+		FROM workflow_node_run_artifacts WHERE `+where, args...); err != nil {
 		return nil, err
 	}
 
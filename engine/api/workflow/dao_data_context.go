@@ -21,6 +21,8 @@ type sqlNodeContextData struct {
 	DefaultPipelineParameters sql.NullString `db:"default_pipeline_parameters"`
 	Conditions                sql.NullString `db:"conditions"`
 	Mutex                     bool           `db:"mutex"`
+	Concurrency               sql.NullString `db:"concurrency"`
+	Approval                  sql.NullString `db:"approval"`
 }
 
 func insertNodeContextData(db gorp.SqlExecutor, w *sdk.Workflow, n *sdk.Node) error {
@@ -57,10 +59,8 @@ func insertNodeContextData(db gorp.SqlExecutor, w *sdk.Workflow, n *sdk.Node) er
 		}
 	}
 
-	for _, cond := range n.Context.Conditions.PlainConditions {
-		if _, ok := sdk.WorkflowConditionsOperators[cond.Operator]; !ok {
-			return sdk.WithStack(sdk.ErrWorkflowConditionBadOperator)
-		}
+	if err := n.Context.Conditions.IsValid(); err != nil {
+		return err
 	}
 
 	var errC error
@@ -71,6 +71,28 @@ func insertNodeContextData(db gorp.SqlExecutor, w *sdk.Workflow, n *sdk.Node) er
 
 	tempContext.Mutex = n.Context.Mutex
 
+	if n.Context.Concurrency != nil {
+		if err := n.Context.Concurrency.IsValid(); err != nil {
+			return err
+		}
+		var errCo error
+		tempContext.Concurrency, errCo = gorpmapping.JSONToNullString(n.Context.Concurrency)
+		if errCo != nil {
+			return sdk.WrapError(errCo, "insertNodeContextData> Cannot stringify concurrency")
+		}
+	}
+
+	if n.Context.Approval != nil {
+		if err := n.Context.Approval.IsValid(); err != nil {
+			return err
+		}
+		var errAp error
+		tempContext.Approval, errAp = gorpmapping.JSONToNullString(n.Context.Approval)
+		if errAp != nil {
+			return sdk.WrapError(errAp, "insertNodeContextData> Cannot stringify approval")
+		}
+	}
+
 	if n.Context.PipelineID != 0 {
 		//Checks pipeline parameters
 		if len(n.Context.DefaultPipelineParameters) > 0 {
@@ -50,7 +50,9 @@ workflow_node_run.uuid,
 workflow_node_run.outgoinghook,
 workflow_node_run.hook_execution_timestamp,
 workflow_node_run.execution_id,
-workflow_node_run.callback
+workflow_node_run.callback,
+workflow_node_run.concurrency_group,
+workflow_node_run.approvals
 `
 
 const nodeRunTestsField string = ", workflow_node_run.tests"
@@ -368,6 +370,10 @@ func fromDBNodeRun(rr NodeRun, opts LoadRunOptions) (*sdk.WorkflowNodeRun, error
 		r.HookExecutionTimeStamp = rr.HookExecutionTimestamp.Int64
 	}
 
+	if rr.ConcurrencyGroup.Valid {
+		r.ConcurrencyGroup = rr.ConcurrencyGroup.String
+	}
+
 	if rr.OutgoingHook.Valid {
 		if err := gorpmapping.JSONNullString(rr.OutgoingHook, &r.OutgoingHook); err != nil {
 			return nil, sdk.WrapError(err, "fromDBNodeRun>Error loading node run %d: OutgoingHook", r.ID)
@@ -380,6 +386,12 @@ func fromDBNodeRun(rr NodeRun, opts LoadRunOptions) (*sdk.WorkflowNodeRun, error
 		}
 	}
 
+	if rr.Approvals.Valid {
+		if err := gorpmapping.JSONNullString(rr.Approvals, &r.Approvals); err != nil {
+			return nil, sdk.WrapError(err, "fromDBNodeRun>Error loading node run %d: Approvals", r.ID)
+		}
+	}
+
 	return r, nil
 }
 
@@ -416,6 +428,10 @@ func makeDBNodeRun(n sdk.WorkflowNodeRun) (*NodeRun, error) {
 	nodeRunDB.HookExecutionTimestamp.Int64 = n.HookExecutionTimeStamp
 	nodeRunDB.UUID.Valid = true
 	nodeRunDB.UUID.String = n.UUID
+	if n.ConcurrencyGroup != "" {
+		nodeRunDB.ConcurrencyGroup.Valid = true
+		nodeRunDB.ConcurrencyGroup.String = n.ConcurrencyGroup
+	}
 
 	if n.TriggersRun != nil {
 		s, err := gorpmapping.JSONToNullString(n.TriggersRun)
@@ -505,6 +521,14 @@ func makeDBNodeRun(n sdk.WorkflowNodeRun) (*NodeRun, error) {
 	}
 	nodeRunDB.OutgoingHook = oh
 
+	if len(n.Approvals) > 0 {
+		ap, err := gorpmapping.JSONToNullString(n.Approvals)
+		if err != nil {
+			return nil, sdk.WrapError(err, "makeDBNodeRun> unable to get json from approvals")
+		}
+		nodeRunDB.Approvals = ap
+	}
+
 	return nodeRunDB, nil
 }
 
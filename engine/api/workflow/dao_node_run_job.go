@@ -27,6 +27,8 @@ type QueueFilter struct {
 	Until        *time.Time
 	Limit        *int
 	Statuses     []string
+	// SkipQuotaExceeded removes the jobs that can't be started because of a quota
+	SkipQuotaExceeded bool
 }
 
 func NewQueueFilter() QueueFilter {
@@ -95,7 +97,7 @@ func LoadNodeJobRunQueue(ctx context.Context, db gorp.SqlExecutor, store cache.S
 	and workflow_node_run_job.status = ANY(string_to_array($3, ','))
 	AND contains_service IN ($4, $5)
 	AND (model_type is NULL OR model_type = '' OR model_type = ANY(string_to_array($6, ',')))
-	ORDER BY workflow_node_run_job.queued ASC
+	ORDER BY workflow_node_run_job.priority DESC, workflow_node_run_job.queued ASC
 	`).Args(
 		*filter.Since,                       // $1
 		*filter.Until,                       // $2
@@ -105,7 +107,7 @@ func LoadNodeJobRunQueue(ctx context.Context, db gorp.SqlExecutor, store cache.S
 		strings.Join(filter.ModelType, ","), // $6
 	)
 
-	return loadNodeJobRunQueue(ctx, db, store, query, filter.Limit)
+	return loadNodeJobRunQueue(ctx, db, store, query, filter)
 }
 
 // LoadNodeJobRunQueueByGroupIDs load all workflow_node_run_job accessible
@@ -174,7 +176,7 @@ func LoadNodeJobRunQueueByGroupIDs(ctx context.Context, db gorp.SqlExecutor, sto
 		OR
 		model_type = '' OR model_type = ANY(string_to_array($6, ','))
 	)
-	ORDER BY workflow_node_run_job.queued ASC
+	ORDER BY workflow_node_run_job.priority DESC, workflow_node_run_job.queued ASC
 	`).Args(
 		*filter.Since,                          // $1
 		*filter.Until,                          // $2
@@ -186,15 +188,16 @@ func LoadNodeJobRunQueueByGroupIDs(ctx context.Context, db gorp.SqlExecutor, sto
 		group.SharedInfraGroup.ID,              // $8
 		filter.Rights,                          // $9
 	)
-	return loadNodeJobRunQueue(ctx, db, store, query, filter.Limit)
+	return loadNodeJobRunQueue(ctx, db, store, query, filter)
 }
 
-func loadNodeJobRunQueue(ctx context.Context, db gorp.SqlExecutor, store cache.Store, query gorpmapping.Query, limit *int) ([]sdk.WorkflowNodeJobRun, error) {
+func loadNodeJobRunQueue(ctx context.Context, db gorp.SqlExecutor, store cache.Store, query gorpmapping.Query, filter QueueFilter) ([]sdk.WorkflowNodeJobRun, error) {
 	ctx, end := observability.Span(ctx, "workflow.loadNodeJobRunQueue")
 	defer end()
 
-	if limit != nil && *limit > 0 {
-		query = query.Limit(*limit)
+	// Jobs are loaded by priority in a window larger than the limit, the fair share between projects is computed on this window
+	if filter.Limit != nil && *filter.Limit > 0 {
+		query = query.Limit(*filter.Limit * queueFairShareWindowFactor)
 	}
 
 	var sqlJobs []JobRun
@@ -214,6 +217,24 @@ func loadNodeJobRunQueue(ctx context.Context, db gorp.SqlExecutor, store cache.S
 		jobs = append(jobs, jr)
 	}
 
+	// The limit is applied once the queue is sorted by priority and fair share
+	running, weights, err := loadQueueFairShare(db)
+	if err != nil {
+		log.Error(ctx, "LoadNodeJobRunQueue> unable to load queue fair share: %v", err)
+	}
+	sortQueue(jobs, running, weights)
+
+	if filter.SkipQuotaExceeded {
+		jobs, err = removeQuotaExceededJobs(ctx, db, jobs)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if filter.Limit != nil && *filter.Limit > 0 && len(jobs) > *filter.Limit {
+		jobs = jobs[:*filter.Limit]
+	}
+
 	return jobs, nil
 }
 
@@ -286,6 +307,36 @@ func LoadDeadNodeJobRun(ctx context.Context, db gorp.SqlExecutor, store cache.St
 	return deadJobs, nil
 }
 
+//LoadTimedOutNodeJobRun load NodeJobRuns which are Building for longer than their job timeout plus the given grace period
+func LoadTimedOutNodeJobRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, gracePeriod time.Duration) ([]sdk.WorkflowNodeJobRun, error) {
+	var jobsDB []JobRun
+	query := `SELECT workflow_node_run_job.* FROM workflow_node_run_job
+	WHERE status = $1
+	AND COALESCE((job->'action'->>'timeout')::BIGINT, 0) > 0
+	AND start + ((job->'action'->>'timeout')::BIGINT + $2) * interval '1 second' < now()`
+	if _, err := db.Select(&jobsDB, query, sdk.StatusBuilding, int64(gracePeriod/time.Second)); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, sdk.WithStack(err)
+	}
+
+	jobs := make([]sdk.WorkflowNodeJobRun, len(jobsDB))
+	for i := range jobsDB {
+		if store != nil {
+			getHatcheryInfo(ctx, store, &jobsDB[i])
+		}
+
+		jr, err := jobsDB[i].WorkflowNodeRunJob()
+		if err != nil {
+			return nil, err
+		}
+		jobs[i] = jr
+	}
+
+	return jobs, nil
+}
+
 //LoadAndLockNodeJobRunWait load for update a NodeJobRun given its ID
 func LoadAndLockNodeJobRunWait(ctx context.Context, db gorp.SqlExecutor, store cache.Store, id int64) (*sdk.WorkflowNodeJobRun, error) {
 	j := JobRun{}
@@ -385,8 +436,14 @@ func getHatcheryInfo(ctx context.Context, store cache.Store, j *JobRun) {
 
 // replaceWorkflowJobRunInQueue restart workflow node job
 func replaceWorkflowJobRunInQueue(db gorp.SqlExecutor, wNodeJob sdk.WorkflowNodeJobRun) error {
+	wNodeJob.Retry++
+	return resetWorkflowJobRunInQueue(db, wNodeJob)
+}
+
+// resetWorkflowJobRunInQueue sets the workflow node job waiting without incrementing its retry counter
+func resetWorkflowJobRunInQueue(db gorp.SqlExecutor, wNodeJob sdk.WorkflowNodeJobRun) error {
 	query := "UPDATE workflow_node_run_job SET status = $1, retry = $2, worker_id = NULL WHERE id = $3"
-	if _, err := db.Exec(query, sdk.StatusWaiting, wNodeJob.Retry+1, wNodeJob.ID); err != nil {
+	if _, err := db.Exec(query, sdk.StatusWaiting, wNodeJob.Retry, wNodeJob.ID); err != nil {
 		return sdk.WrapError(err, "Unable to set workflow_node_run_job id %d with status %s", wNodeJob.ID, sdk.StatusWaiting)
 	}
 
@@ -248,6 +248,20 @@ func LoadLastRuns(db gorp.SqlExecutor, workflowIDs []int64, limit int) ([]sdk.Wo
 	return loadRuns(db, query, pq.Int64Array(workflowIDs), limit)
 }
 
+// LoadRunsSupersededBy returns the runs of the workflow on given branch that are older than the given run and not terminated.
+func LoadRunsSupersededBy(db gorp.SqlExecutor, wr *sdk.WorkflowRun, branch string) ([]sdk.WorkflowRun, error) {
+	query := fmt.Sprintf(`select %s
+	from workflow_run
+	join workflow_run_tag on workflow_run_tag.workflow_run_id = workflow_run.id
+	where workflow_run.workflow_id = $1
+	and workflow_run.num < $2
+	and workflow_run.status = ANY(string_to_array($3, ','))
+	and workflow_run_tag.tag = $4 and workflow_run_tag.value = $5
+	order by workflow_run.num`, wfRunfields)
+	status := []string{sdk.StatusPending, sdk.StatusWaiting, sdk.StatusBuilding, sdk.StatusWaitingApproval, sdk.StatusFrozen}
+	return loadRuns(db, query, wr.WorkflowID, wr.Number, strings.Join(status, ","), tagGitBranch, branch)
+}
+
 // LoadRun returns a specific run
 func LoadRun(ctx context.Context, db gorp.SqlExecutor, projectkey, workflowname string, number int64, loadOpts LoadRunOptions) (*sdk.WorkflowRun, error) {
 	_, end := observability.Span(ctx, "workflow.LoadRun",
@@ -957,12 +971,16 @@ func stopRunsBlocked(ctx context.Context, db *gorp.DbMap) error {
 		FROM workflow_run
 		WHERE (workflow_run.status = $1 or workflow_run.status = $2 or workflow_run.status = $3)
 		AND now() - workflow_run.last_execution > interval '1 day'
+		AND NOT EXISTS (
+			SELECT 1 FROM workflow_node_run
+			WHERE workflow_node_run.workflow_run_id = workflow_run.id AND workflow_node_run.status IN ($4, $5)
+		)
 		LIMIT 30`
 	ids := []struct {
 		ID int64 `db:"id"`
 	}{}
 
-	if _, err := db.Select(&ids, query, sdk.StatusWaiting, sdk.StatusChecking, sdk.StatusBuilding); err != nil {
+	if _, err := db.Select(&ids, query, sdk.StatusWaiting, sdk.StatusChecking, sdk.StatusBuilding, sdk.StatusWaitingApproval, sdk.StatusFrozen); err != nil {
 		if err == sql.ErrNoRows {
 			return nil
 		}
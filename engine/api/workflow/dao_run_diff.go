@@ -0,0 +1,96 @@
+package workflow
+
+import (
+	"context"
+	"sort"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/repositoriesmanager"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// LoadRunDiff compares two runs of a workflow, with the commits between the runs for each repository
+func LoadRunDiff(ctx context.Context, db gorp.SqlExecutor, store cache.Store, projectKey, workflowName string, fromNumber, toNumber int64) (*sdk.WorkflowRunDiff, error) {
+	opts := LoadRunOptions{WithArtifacts: true, WithTests: true}
+	from, err := LoadRun(ctx, db, projectKey, workflowName, fromNumber, opts)
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflow run %d", fromNumber)
+	}
+	to, err := LoadRun(ctx, db, projectKey, workflowName, toNumber, opts)
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflow run %d", toNumber)
+	}
+
+	// Artifacts of the jobs copied from a previous sub number are kept on the previous sub number
+	for _, wr := range []*sdk.WorkflowRun{from, to} {
+		for id, nrs := range wr.WorkflowNodeRuns {
+			if len(nrs) > 0 {
+				wr.WorkflowNodeRuns[id][0].Artifacts = MergeArtifactWithPreviousSubRun(nrs)
+			}
+		}
+	}
+
+	diff := sdk.DiffWorkflowRuns(*from, *to)
+	diff.Commits = loadRunDiffCommits(ctx, db, store, projectKey, *from, *to)
+	return &diff, nil
+}
+
+// loadRunDiffCommits returns the commits between the two runs for each repository of the workflow.
+// Repositories manager errors are logged, the repository is then returned without its commits.
+func loadRunDiffCommits(ctx context.Context, db gorp.SqlExecutor, store cache.Store, projectKey string, from, to sdk.WorkflowRun) []sdk.WorkflowRunDiffCommits {
+	fromNodes, toNodes := from.LastNodeRunsByName(), to.LastNodeRunsByName()
+	names := make([]string, 0, len(toNodes))
+	for name := range toNodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var res []sdk.WorkflowRunDiffCommits
+	done := make(map[string]struct{})
+	for _, name := range names {
+		toNode := toNodes[name]
+		fromNode, has := fromNodes[name]
+		if !has || toNode.ApplicationID == 0 || fromNode.VCSHash == "" || toNode.VCSHash == "" ||
+			fromNode.VCSRepository != toNode.VCSRepository {
+			continue
+		}
+		app, has := to.Workflow.Applications[toNode.ApplicationID]
+		if !has || app.VCSServer == "" {
+			continue
+		}
+		repo := toNode.VCSRepository
+		if repo == "" {
+			repo = app.RepositoryFullname
+		}
+		if _, has := done[repo]; has {
+			continue
+		}
+		done[repo] = struct{}{}
+
+		diff := sdk.WorkflowRunDiffCommits{Repository: repo, From: fromNode.VCSHash, To: toNode.VCSHash}
+		if diff.From != diff.To {
+			commits, err := loadCommitsBetweenRefs(ctx, db, store, projectKey, app.VCSServer, repo, diff.From, diff.To)
+			if err != nil {
+				log.Warning(ctx, "loadRunDiffCommits> unable to get commits of %s between %s and %s: %v", repo, diff.From, diff.To, err)
+			}
+			diff.Commits = commits
+		}
+		res = append(res, diff)
+	}
+	return res
+}
+
+func loadCommitsBetweenRefs(ctx context.Context, db gorp.SqlExecutor, store cache.Store, projectKey, vcsServerName, repo, base, head string) ([]sdk.VCSCommit, error) {
+	vcsServer, err := repositoriesmanager.LoadProjectVCSServerLinkByProjectKeyAndVCSServerName(ctx, db, projectKey, vcsServerName)
+	if err != nil {
+		return nil, err
+	}
+	client, err := repositoriesmanager.AuthorizedClient(ctx, db, store, projectKey, vcsServer)
+	if err != nil {
+		return nil, err
+	}
+	return client.CommitsBetweenRefs(ctx, repo, base, head)
+}
@@ -0,0 +1,137 @@
+package workflow
+
+import (
+	"context"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/action"
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+// AppendDynamicStages validates the pipeline generated by a job and appends its stages to the node run of the job.
+// It returns the names of the added stages.
+func AppendDynamicStages(ctx context.Context, db gorp.SqlExecutor, jobRun *sdk.WorkflowNodeJobRun, data []byte) ([]string, error) {
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.AppendDynamicStages")
+	defer end()
+
+	pipeliner, err := exportentities.ParsePipeline(exportentities.FormatYAML, data)
+	if err != nil {
+		return nil, err
+	}
+	pip, err := pipeliner.Pipeline()
+	if err != nil {
+		return nil, err
+	}
+
+	// Steps are resolved with the groups that can execute the current job
+	groupIDs := append(sdk.Groups(jobRun.ExecGroups).ToIDs(), group.SharedInfraGroup.ID)
+	for i := range pip.Stages {
+		for j := range pip.Stages[i].Jobs {
+			job := &pip.Stages[i].Jobs[j]
+			if err := pipeline.CheckJob(ctx, db, job); err != nil {
+				return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid job %s: %v", job.Action.Name, err))
+			}
+			if err := action.LoadChildrenForGroupIDs(ctx, db, &job.Action, groupIDs); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	nodeRun, err := LoadAndLockNodeRunByID(ctx, db, jobRun.WorkflowNodeRunID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load node run %d", jobRun.WorkflowNodeRunID)
+	}
+
+	names, err := appendDynamicStages(nodeRun, pip.Stages)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := updateNodeRunStatusAndStage(db, nodeRun); err != nil {
+		return nil, sdk.WrapError(err, "unable to update stages of node run %d", nodeRun.ID)
+	}
+	return names, nil
+}
+
+// isDynamicStage returns true if the stage was generated by a job of the node run.
+func isDynamicStage(s sdk.Stage) bool {
+	return s.ID < 0
+}
+
+// appendDynamicStages appends the given stages after the stages of the node run, but before its finally stage.
+// Generated stages and jobs do not exist in database, they get negative ids so run jobs can be matched with their job.
+func appendDynamicStages(nodeRun *sdk.WorkflowNodeRun, stages []sdk.Stage) ([]string, error) {
+	var nbJobs int
+	for _, s := range stages {
+		nbJobs += len(s.Jobs)
+		if s.Finally {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pipeline: generated stage %s cannot be a finally stage", s.Name)
+		}
+	}
+	if nbJobs == 0 {
+		return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pipeline: no job found")
+	}
+
+	var finallyStage *sdk.Stage
+	if len(nodeRun.Stages) > 0 && nodeRun.Stages[len(nodeRun.Stages)-1].Finally {
+		s := nodeRun.Stages[len(nodeRun.Stages)-1]
+		if s.Status != "" {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pipeline: finally stage %s is already started", s.Name)
+		}
+		finallyStage = &s
+	}
+
+	var lastID int64
+	var lastBuildOrder int
+	for _, s := range nodeRun.Stages {
+		for _, newStage := range stages {
+			if s.Name != "" && newStage.Name == s.Name {
+				return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pipeline: stage %s already exists", s.Name)
+			}
+		}
+		if s.ID < lastID {
+			lastID = s.ID
+		}
+		if s.BuildOrder > lastBuildOrder {
+			lastBuildOrder = s.BuildOrder
+		}
+		for _, j := range s.Jobs {
+			if j.PipelineActionID < lastID {
+				lastID = j.PipelineActionID
+			}
+		}
+	}
+
+	if finallyStage != nil {
+		nodeRun.Stages = nodeRun.Stages[:len(nodeRun.Stages)-1]
+	}
+
+	names := make([]string, 0, len(stages))
+	for _, s := range stages {
+		if len(s.Jobs) == 0 {
+			continue
+		}
+		lastID--
+		s.ID = lastID
+		lastBuildOrder++
+		s.BuildOrder = lastBuildOrder
+		for i := range s.Jobs {
+			lastID--
+			s.Jobs[i].PipelineActionID = lastID
+			s.Jobs[i].PipelineStageID = s.ID
+		}
+		nodeRun.Stages = append(nodeRun.Stages, s)
+		names = append(names, s.Name)
+	}
+	if finallyStage != nil {
+		finallyStage.BuildOrder = lastBuildOrder + 1
+		nodeRun.Stages = append(nodeRun.Stages, *finallyStage)
+	}
+	return names, nil
+}
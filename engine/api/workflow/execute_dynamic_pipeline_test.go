@@ -0,0 +1,121 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+)
+
+func TestAppendDynamicStages(t *testing.T) {
+	nodeRun := &sdk.WorkflowNodeRun{
+		Stages: []sdk.Stage{
+			{
+				ID:         10,
+				Name:       "Compute",
+				BuildOrder: 1,
+				Enabled:    true,
+				Jobs:       []sdk.Job{{PipelineActionID: 100, PipelineStageID: 10, Enabled: true, Action: sdk.Action{Name: "compute"}}},
+			},
+		},
+	}
+
+	pipeliner, err := exportentities.ParsePipeline(exportentities.FormatYAML, []byte(`version: v1.0
+stages:
+- Build
+- Deploy
+jobs:
+- job: build-api
+  stage: Build
+  steps:
+  - script: make api
+- job: build-ui
+  stage: Build
+  steps:
+  - script: make ui
+- job: deploy
+  stage: Deploy
+  steps:
+  - script: make deploy
+`))
+	require.NoError(t, err)
+	pip, err := pipeliner.Pipeline()
+	require.NoError(t, err)
+
+	names, err := appendDynamicStages(nodeRun, pip.Stages)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Build", "Deploy"}, names)
+	require.Len(t, nodeRun.Stages, 3)
+
+	build := nodeRun.Stages[1]
+	assert.Equal(t, int64(-1), build.ID)
+	assert.Equal(t, 2, build.BuildOrder)
+	require.Len(t, build.Jobs, 2)
+	assert.Equal(t, int64(-2), build.Jobs[0].PipelineActionID)
+	assert.Equal(t, int64(-3), build.Jobs[1].PipelineActionID)
+	assert.Equal(t, int64(-1), build.Jobs[0].PipelineStageID)
+
+	deploy := nodeRun.Stages[2]
+	assert.Equal(t, int64(-4), deploy.ID)
+	assert.Equal(t, 3, deploy.BuildOrder)
+	assert.Equal(t, int64(-5), deploy.Jobs[0].PipelineActionID)
+
+	// Ids of the next generated stages follow the previous ones
+	pipeliner, err = exportentities.ParsePipeline(exportentities.FormatYAML, []byte(`version: v1.0
+stages:
+- Check
+jobs:
+- job: check
+  stage: Check
+  steps:
+  - script: make check
+`))
+	require.NoError(t, err)
+	pip, err = pipeliner.Pipeline()
+	require.NoError(t, err)
+	_, err = appendDynamicStages(nodeRun, pip.Stages)
+	require.NoError(t, err)
+	assert.Equal(t, int64(-6), nodeRun.Stages[3].ID)
+	assert.Equal(t, int64(-7), nodeRun.Stages[3].Jobs[0].PipelineActionID)
+	assert.Equal(t, 4, nodeRun.Stages[3].BuildOrder)
+
+	// Stage names must be unique
+	_, err = appendDynamicStages(nodeRun, []sdk.Stage{{Name: "Compute", Jobs: []sdk.Job{{Action: sdk.Action{Name: "other"}}}}})
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	// At least one job is expected
+	_, err = appendDynamicStages(nodeRun, []sdk.Stage{{Name: "Empty"}})
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+	assert.Len(t, nodeRun.Stages, 4)
+}
+
+func TestAppendDynamicStagesBeforeFinallyStage(t *testing.T) {
+	nodeRun := &sdk.WorkflowNodeRun{
+		Stages: []sdk.Stage{
+			{ID: 10, Name: "Compute", BuildOrder: 1, Enabled: true, Status: sdk.StatusBuilding},
+			{ID: 11, Name: "Cleanup", BuildOrder: 2, Enabled: true, Finally: true},
+		},
+	}
+
+	names, err := appendDynamicStages(nodeRun, []sdk.Stage{{Name: "Deploy", Jobs: []sdk.Job{{Action: sdk.Action{Name: "deploy"}}}}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Deploy"}, names)
+	require.Len(t, nodeRun.Stages, 3)
+	assert.Equal(t, "Deploy", nodeRun.Stages[1].Name)
+	assert.Equal(t, "Cleanup", nodeRun.Stages[2].Name)
+	assert.True(t, nodeRun.Stages[2].Finally)
+	assert.True(t, nodeRun.Stages[1].BuildOrder < nodeRun.Stages[2].BuildOrder)
+
+	// Generated pipelines cannot contain a finally stage
+	_, err = appendDynamicStages(nodeRun, []sdk.Stage{{Name: "Other", Finally: true, Jobs: []sdk.Job{{Action: sdk.Action{Name: "other"}}}}})
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+
+	// Stages cannot be added once the finally stage is started
+	nodeRun.Stages[2].Status = sdk.StatusBuilding
+	_, err = appendDynamicStages(nodeRun, []sdk.Stage{{Name: "Other", Jobs: []sdk.Job{{Action: sdk.Action{Name: "other"}}}}})
+	assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest))
+	assert.Len(t, nodeRun.Stages, 3)
+}
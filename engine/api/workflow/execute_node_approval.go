@@ -0,0 +1,223 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// ApproveNodeRun records the decision of a user on a node run waiting for approval.
+// The node run is executed once it has enough approvals, and stopped at the first rejection.
+func ApproveNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj sdk.Project, wr *sdk.WorkflowRun, nodeRunID int64,
+	username string, userGroups []string, req sdk.WorkflowNodeRunApprovalRequest) (*ProcessorReport, *sdk.WorkflowNodeRun, error) {
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.ApproveNodeRun")
+	defer end()
+
+	report := new(ProcessorReport)
+
+	nr, err := LoadAndLockNodeRunByID(ctx, db, nodeRunID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if nr.WorkflowRunID != wr.ID {
+		return nil, nil, sdk.WithStack(sdk.ErrNotFound)
+	}
+	if nr.Status != sdk.StatusWaitingApproval {
+		return nil, nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "pipeline %s is not waiting for approval", nr.WorkflowNodeName)
+	}
+
+	n := wr.Workflow.WorkflowData.NodeByID(nr.WorkflowNodeID)
+	if n == nil || n.Context == nil || n.Context.Approval == nil {
+		return nil, nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "pipeline %s has no approval gate", nr.WorkflowNodeName)
+	}
+	approval := n.Context.Approval
+
+	var isApprover bool
+	for _, g := range userGroups {
+		if sdk.IsInArray(g, approval.Groups) {
+			isApprover = true
+			break
+		}
+	}
+	if !isApprover {
+		return nil, nil, sdk.NewErrorFrom(sdk.ErrForbidden, "only members of groups %v can approve pipeline %s", approval.Groups, nr.WorkflowNodeName)
+	}
+	for _, a := range nr.Approvals {
+		if a.Username == username {
+			return nil, nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "user %s already took a decision on pipeline %s", username, nr.WorkflowNodeName)
+		}
+	}
+
+	nr.Approvals = append(nr.Approvals, sdk.WorkflowNodeRunApproval{
+		Username: username,
+		Approved: req.Approved,
+		Comment:  req.Comment,
+		Date:     time.Now(),
+	})
+
+	if !req.Approved {
+		AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeRejected.ID,
+			Args: []interface{}{nr.WorkflowNodeName, username, req.Comment},
+			Type: sdk.MsgWorkflowNodeRejected.Type,
+		})
+		r, err := stopWaitingApprovalNodeRun(ctx, db, wr, nr)
+		report.Merge(ctx, r)
+		return report, nr, err
+	}
+
+	AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeApproved.ID,
+		Args: []interface{}{nr.WorkflowNodeName, username, nr.CountApprovals(), approval.GetMinApprovals(), req.Comment},
+		Type: sdk.MsgWorkflowNodeApproved.Type,
+	})
+
+	if nr.CountApprovals() < approval.GetMinApprovals() {
+		if err := UpdateNodeRun(db, nr); err != nil {
+			return nil, nil, sdk.WrapError(err, "unable to update node run %d", nr.ID)
+		}
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+		}
+		report.Add(ctx, *nr)
+		return report, nr, nil
+	}
+
+	// The environment can have been frozen while the node run was waiting for approval
+	freeze, until, err := getEnvironmentFreeze(ctx, db, wr, n, nr)
+	if err != nil {
+		return nil, nil, err
+	}
+	if freeze != nil {
+		addEnvironmentFreezeInfo(wr, n, freeze, until)
+		if freeze.GetPolicy() == sdk.EnvironmentFreezePolicyRefuse {
+			r, err := stopWaitingApprovalNodeRun(ctx, db, wr, nr)
+			report.Merge(ctx, r)
+			return report, nr, err
+		}
+		nr.Status = sdk.StatusFrozen
+		if err := UpdateNodeRun(db, nr); err != nil {
+			return nil, nil, sdk.WrapError(err, "unable to update node run %d", nr.ID)
+		}
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+		}
+		report.Add(ctx, *nr)
+		return report, nr, nil
+	}
+
+	log.Debug("workflow.ApproveNodeRun> node run %d approved, execute it", nr.ID)
+	nr.Status = sdk.StatusWaiting
+	if err := UpdateNodeRun(db, nr); err != nil {
+		return nil, nil, sdk.WrapError(err, "unable to update node run %d", nr.ID)
+	}
+	if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+		return nil, nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+	}
+	report.Add(ctx, *nr)
+
+	r, err := executeNodeRunIfUnlocked(ctx, db, store, proj, wr, n, nr)
+	report.Merge(ctx, r)
+	if err != nil {
+		return report, nil, err
+	}
+	return report, nr, nil
+}
+
+// stopWaitingApprovalNodeRun stops a node run that was rejected or that was not approved in time
+func stopWaitingApprovalNodeRun(ctx context.Context, db gorp.SqlExecutor, wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) (*ProcessorReport, error) {
+	report := new(ProcessorReport)
+
+	stopWorkflowNodeRunStages(ctx, db, nr)
+	nr.Status = sdk.StatusStopped
+	nr.Done = time.Now()
+	if err := UpdateNodeRun(db, nr); err != nil {
+		return nil, sdk.WrapError(err, "unable to update node run %d", nr.ID)
+	}
+	report.Add(ctx, *nr)
+
+	if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+		return nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+	}
+
+	// Reload the workflow run to resync its status with the stopped node run
+	updatedWorkflowRun, err := LoadRunByID(db, wr.ID, LoadRunOptions{})
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to reload workflow run %d", wr.ID)
+	}
+	r, err := ResyncWorkflowRunStatus(ctx, db, updatedWorkflowRun)
+	report.Merge(ctx, r)
+	if err != nil {
+		return report, sdk.WrapError(err, "unable to resync workflow run %d status", wr.ID)
+	}
+	*wr = *updatedWorkflowRun
+	return report, nil
+}
+
+// manageExpiredApprovals stops all the node runs that were not approved before the expiry of their approval gate
+func manageExpiredApprovals(ctx context.Context, db *gorp.DbMap) error {
+	query := `SELECT workflow_node_run.id
+	FROM workflow_node_run
+	WHERE workflow_node_run.status = $1`
+	var ids []int64
+	if _, err := db.Select(&ids, query, sdk.StatusWaitingApproval); err != nil {
+		return sdk.WrapError(err, "unable to load node runs waiting for approval")
+	}
+
+	for _, id := range ids {
+		if err := stopExpiredApproval(ctx, db, id); err != nil {
+			log.Error(ctx, "manageExpiredApprovals> unable to stop node run %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func stopExpiredApproval(ctx context.Context, db *gorp.DbMap, nodeRunID int64) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer tx.Rollback() // nolint
+
+	nr, err := LoadAndLockNodeRunByID(ctx, tx, nodeRunID)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrLocked) {
+			return nil
+		}
+		return err
+	}
+	if nr.Status != sdk.StatusWaitingApproval {
+		return nil
+	}
+
+	wr, err := LoadRunByID(tx, nr.WorkflowRunID, LoadRunOptions{})
+	if err != nil {
+		return err
+	}
+	n := wr.Workflow.WorkflowData.NodeByID(nr.WorkflowNodeID)
+	if n == nil || n.Context == nil || n.Context.Approval == nil {
+		return nil
+	}
+	expiry := n.Context.Approval.GetExpiry()
+	if expiry == 0 || time.Since(nr.Start) < expiry {
+		return nil
+	}
+
+	AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeApprovalExpired.ID,
+		Args: []interface{}{nr.WorkflowNodeName, expiry.String()},
+		Type: sdk.MsgWorkflowNodeApprovalExpired.Type,
+	})
+	if _, err := stopWaitingApprovalNodeRun(ctx, tx, wr, nr); err != nil {
+		return err
+	}
+
+	return sdk.WithStack(tx.Commit())
+}
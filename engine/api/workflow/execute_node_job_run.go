@@ -156,6 +156,19 @@ func UpdateNodeJobRunStatus(ctx context.Context, db gorp.SqlExecutor, store cach
 			// too late, Nate
 			return nil, nil
 		}
+
+		// A failed job could be replaced in queue if it has a retry policy on failure
+		if status == sdk.StatusFail {
+			requeued, err := RetryNodeJobRun(ctx, db, store, job, sdk.JobRetryOnFailure)
+			if err != nil {
+				return nil, sdk.WrapError(err, "cannot retry node job run %d", job.ID)
+			}
+			if requeued {
+				report.Add(ctx, *job)
+				return report, nil
+			}
+		}
+
 		job.Done = time.Now()
 		job.Status = status
 
@@ -633,28 +646,11 @@ func RestartWorkflowNodeJob(ctx context.Context, db gorp.SqlExecutor, wNodeJob s
 	ctx, end = observability.Span(ctx, "workflow.RestartWorkflowNodeJob")
 	defer end()
 
-	for iS := range wNodeJob.Job.StepStatus {
-		step := &wNodeJob.Job.StepStatus[iS]
-		if step.Status == sdk.StatusNeverBuilt || step.Status == sdk.StatusSkipped || step.Status == sdk.StatusDisabled {
-			continue
-		}
-		l, errL := LoadStepLogs(db, wNodeJob.ID, int64(step.StepOrder))
-		if errL != nil {
-			return sdk.WrapError(errL, "RestartWorkflowNodeJob> error while load step logs")
-		}
-		wNodeJob.Job.Reason = "Killed (Reason: Timeout)\n"
-		step.Status = sdk.StatusWaiting
-		step.Done = time.Time{}
-		if l != nil { // log could be nil here
-			l.Done = nil
-			logbuf := bytes.NewBufferString(l.Val)
-			logbuf.WriteString("\n\n\n-=-=-=-=-=- Worker timeout: job replaced in queue -=-=-=-=-=-\n\n\n")
-			l.Val = logbuf.String()
-			if err := updateLog(db, l); err != nil {
-				return sdk.WrapError(errL, "RestartWorkflowNodeJob> error while update step log")
-			}
-		}
+	wNodeJob.Job.Reason = "Killed (Reason: Timeout)\n"
+	if err := resetStepsForNewAttempt(db, &wNodeJob, "Worker timeout: job replaced in queue"); err != nil {
+		return sdk.WrapError(err, "RestartWorkflowNodeJob> error while reset steps")
 	}
+
 	nodeRun, errNR := LoadAndLockNodeRunByID(ctx, db, wNodeJob.WorkflowNodeRunID)
 	if errNR != nil {
 		return errNR
@@ -679,3 +675,95 @@ func RestartWorkflowNodeJob(ctx context.Context, db gorp.SqlExecutor, wNodeJob s
 
 	return nil
 }
+
+// RetryNodeJobRun replaces the job run in queue if its retry policy matches the given failure condition.
+// Logs and spawn infos of the previous attempts are kept on the job run. Returns true if the job run was requeued.
+func RetryNodeJobRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, job *sdk.WorkflowNodeJobRun, condition string) (bool, error) {
+	if !job.CanRetry(condition) {
+		return false, nil
+	}
+
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.RetryNodeJobRun",
+		observability.Tag(observability.TagWorkflowNodeJobRun, job.ID),
+	)
+	defer end()
+
+	msg := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobRetry.ID, Args: []interface{}{job.Attempt(), job.Job.Action.Retry.MaxAttempts, condition}}
+	infos := []sdk.SpawnInfo{{
+		RemoteTime:  time.Now(),
+		Message:     msg,
+		UserMessage: msg.DefaultUserMessage(),
+	}}
+	if err := AddSpawnInfosNodeJobRun(db, job.WorkflowNodeRunID, job.ID, PrepareSpawnInfos(infos)); err != nil {
+		return false, err
+	}
+
+	if err := resetStepsForNewAttempt(db, job, fmt.Sprintf("Attempt %d failed (%s): job replaced in queue", job.Attempt(), condition)); err != nil {
+		return false, err
+	}
+	job.Job.Reason = msg.DefaultUserMessage()
+	job.Attempts++
+	job.Status = sdk.StatusWaiting
+	job.Start = time.Time{}
+	job.Done = time.Time{}
+	if err := UpdateNodeJobRun(ctx, db, job); err != nil {
+		return false, sdk.WrapError(err, "cannot update node job run %d", job.ID)
+	}
+	// The retry counter is kept for the requeues after a dead worker
+	if err := resetWorkflowJobRunInQueue(db, *job); err != nil {
+		return false, sdk.WrapError(err, "cannot replace workflow job in queue")
+	}
+
+	spawnInfos, err := LoadNodeRunJobInfo(ctx, db, job.ID)
+	if err != nil {
+		return false, sdk.WrapError(err, "unable to load spawn infos for runJob: %d", job.ID)
+	}
+	job.SpawnInfos = spawnInfos
+
+	nodeRun, err := LoadAndLockNodeRunByID(ctx, db, job.WorkflowNodeRunID)
+	if err != nil {
+		return false, err
+	}
+	for i := range nodeRun.Stages {
+		syncJobInNodeRun(nodeRun, job, i)
+	}
+	if err := UpdateNodeRun(db, nodeRun); err != nil {
+		return false, sdk.WrapError(err, "cannot update node run")
+	}
+
+	// The job could have been booked by a hatchery for the previous attempt
+	if err := FreeNodeJobRun(ctx, store, job.ID); err != nil {
+		log.Debug("RetryNodeJobRun> %v", err)
+	}
+
+	log.Info(ctx, "RetryNodeJobRun> job %d replaced in queue after attempt %d failed (%s)", job.ID, job.Attempts, condition)
+	return true, nil
+}
+
+// resetStepsForNewAttempt sets all executed steps of the job run to waiting and appends the given separator
+// to their logs, so logs of the previous attempt are kept.
+func resetStepsForNewAttempt(db gorp.SqlExecutor, job *sdk.WorkflowNodeJobRun, separator string) error {
+	for iS := range job.Job.StepStatus {
+		step := &job.Job.StepStatus[iS]
+		if step.Status == sdk.StatusNeverBuilt || step.Status == sdk.StatusSkipped || step.Status == sdk.StatusDisabled {
+			continue
+		}
+		l, err := LoadStepLogs(db, job.ID, int64(step.StepOrder))
+		if err != nil {
+			return sdk.WrapError(err, "error while load step logs")
+		}
+		step.Status = sdk.StatusWaiting
+		step.Done = time.Time{}
+		if l != nil { // log could be nil here
+			l.Done = nil
+			logbuf := bytes.NewBufferString(l.Val)
+			logbuf.WriteString("\n\n\n-=-=-=-=-=- " + separator + " -=-=-=-=-=-\n\n\n")
+			l.Val = logbuf.String()
+			if err := updateLog(db, l); err != nil {
+				return sdk.WrapError(err, "error while update step log")
+			}
+		}
+	}
+	return nil
+}
@@ -14,6 +14,7 @@ import (
 
 	"github.com/ovh/cds/engine/api/action"
 	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/environment"
 	"github.com/ovh/cds/engine/api/group"
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/engine/api/plugin"
@@ -29,6 +30,8 @@ func syncJobInNodeRun(n *sdk.WorkflowNodeRun, j *sdk.WorkflowNodeJobRun, stageIn
 		rj := &stage.RunJobs[i]
 		if rj.ID == j.ID {
 			rj.Status = j.Status
+			rj.Retry = j.Retry
+			rj.Attempts = j.Attempts
 			rj.Start = j.Start
 			rj.Done = j.Done
 			rj.Model = j.Model
@@ -125,15 +128,13 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		return nil, nil
 	}
 
-	var newStatus = workflowNodeRun.Status
-
-	// If no stages ==> success
-	if len(workflowNodeRun.Stages) == 0 {
-		newStatus = sdk.StatusSuccess
-		workflowNodeRun.Done = time.Now()
+	// A node run waiting for approval will be executed once approved, a frozen one at the end of the freeze
+	if workflowNodeRun.Status == sdk.StatusWaitingApproval || workflowNodeRun.Status == sdk.StatusFrozen {
+		return nil, nil
 	}
 
-	stagesTerminated := 0
+	var newStatus = workflowNodeRun.Status
+
 	var previousNodeRun *sdk.WorkflowNodeRun
 	if workflowNodeRun.Manual != nil && workflowNodeRun.Manual.OnlyFailedJobs {
 		previousNodeRun, err = checkRunOnlyFailedJobs(wr, workflowNodeRun)
@@ -142,9 +143,25 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		}
 	}
 
+	// The finally stage is executed once all the other stages are over
+	stages := workflowNodeRun.Stages
+	var finallyStage *sdk.Stage
+	if len(stages) > 0 && stages[len(stages)-1].Finally {
+		finallyStage = &stages[len(stages)-1]
+		stages = stages[:len(stages)-1]
+	}
+
+	// If no stages ==> success
+	if len(stages) == 0 {
+		newStatus = sdk.StatusSuccess
+		workflowNodeRun.Done = time.Now()
+	}
+
+	stagesTerminated := 0
+
 	// Browse stages
-	for stageIndex := range workflowNodeRun.Stages {
-		stage := &workflowNodeRun.Stages[stageIndex]
+	for stageIndex := range stages {
+		stage := &stages[stageIndex]
 		// Initialize stage status at waiting
 		if stage.Status == "" {
 			var previousStage sdk.Stage
@@ -158,14 +175,14 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 				}
 			}
 
-			if previousNodeRun == nil || previousStage.Status == sdk.StatusFail || !sdk.StatusIsTerminated(previousStage.Status) {
+			if previousNodeRun == nil || previousStage.Status == sdk.StatusFail || previousStage.Status == sdk.StatusStopped || !sdk.StatusIsTerminated(previousStage.Status) {
 				stage.Status = sdk.StatusWaiting
 				if stageIndex == 0 {
 					newStatus = sdk.StatusWaiting
 				}
 			} else if sdk.StatusIsTerminated(previousStage.Status) {
 				// If stage terminated, recopy it
-				workflowNodeRun.Stages[stageIndex] = previousStage
+				stages[stageIndex] = previousStage
 				stagesTerminated++
 				continue
 			}
@@ -223,6 +240,19 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 			if errSync != nil {
 				return report, errSync
 			}
+
+			// Jobs waiting for their needs are added to the queue as soon as the needed jobs are over
+			r, jobsWithNeeds, err := addJobsWithNeedsToQueue(ctx, db, stage, wr, workflowNodeRun)
+			report.Merge(ctx, r)
+			if err != nil {
+				return report, err
+			}
+			if len(jobsWithNeeds) > 0 {
+				end, errSync = syncStage(ctx, db, store, stage)
+				if errSync != nil {
+					return report, errSync
+				}
+			}
 			if !end {
 				break
 			} else {
@@ -245,30 +275,39 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 					workflowNodeRun.Done = time.Now()
 				}
 
-				if stageIndex == len(workflowNodeRun.Stages)-1 {
+				if stageIndex == len(stages)-1 {
 					workflowNodeRun.Done = time.Now()
 					newStatus = sdk.StatusSuccess
 					stagesTerminated++
 					break
 				}
-				if stageIndex != len(workflowNodeRun.Stages)-1 {
+				if stageIndex != len(stages)-1 {
 					continue
 				}
 			}
 		}
 	}
 
-	if stagesTerminated >= len(workflowNodeRun.Stages) || (stagesTerminated >= len(workflowNodeRun.Stages)-1 &&
-		(workflowNodeRun.Stages[len(workflowNodeRun.Stages)-1].Status == sdk.StatusDisabled || workflowNodeRun.Stages[len(workflowNodeRun.Stages)-1].Status == sdk.StatusSkipped)) {
+	if stagesTerminated >= len(stages) || (stagesTerminated >= len(stages)-1 &&
+		(stages[len(stages)-1].Status == sdk.StatusDisabled || stages[len(stages)-1].Status == sdk.StatusSkipped)) {
 		var counterStatus statusCounter
-		if len(workflowNodeRun.Stages) > 0 {
-			for _, stage := range workflowNodeRun.Stages {
+		if len(stages) > 0 {
+			for _, stage := range stages {
 				computeRunStatus(stage.Status, &counterStatus)
 			}
 			newStatus = getRunStatus(counterStatus)
 		}
 	}
 
+	if finallyStage != nil && sdk.StatusIsTerminated(newStatus) {
+		r, status, err := executeFinallyStage(ctx, db, store, wr, workflowNodeRun, finallyStage, newStatus)
+		report.Merge(ctx, r)
+		if err != nil {
+			return report, err
+		}
+		newStatus = status
+	}
+
 	workflowNodeRun.Status = newStatus
 
 	if sdk.StatusIsTerminated(workflowNodeRun.Status) && workflowNodeRun.Status != sdk.StatusNeverBuilt {
@@ -286,6 +325,13 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		return nil, sdk.WrapError(err, "unable to reload workflow run id=%d", workflowNodeRun.WorkflowRunID)
 	}
 
+	// A successful node run that deployed an application on an environment is kept in the deployment registry
+	if workflowNodeRun.Status == sdk.StatusSuccess {
+		if err := insertEnvironmentDeployment(db, *updatedWorkflowRun, *workflowNodeRun); err != nil {
+			return nil, err
+		}
+	}
+
 	// If pipeline build succeed, reprocess the workflow (in the same transaction)
 	// Delete jobs only when node is over
 	if sdk.StatusIsTerminated(workflowNodeRun.Status) {
@@ -312,10 +358,33 @@ func executeNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 				return report, err
 			}
 		}
+
+		// If current node run is in a concurrency group, we want to trigger the next node run waiting for the group
+		if workflowNodeRun.ConcurrencyGroup != "" {
+			r, err := releaseConcurrencyGroup(ctx, db, store, proj, workflowNodeRun.ConcurrencyGroup)
+			report.Merge(ctx, r)
+			if err != nil {
+				return report, err
+			}
+		}
 	}
 	return report, nil
 }
 
+// insertEnvironmentDeployment records the deployment made by the node run if its node has an application and an environment
+func insertEnvironmentDeployment(db gorp.SqlExecutor, wr sdk.WorkflowRun, nr sdk.WorkflowNodeRun) error {
+	node := wr.Workflow.WorkflowData.NodeByID(nr.WorkflowNodeID)
+	if node == nil || node.Context == nil || node.Context.ApplicationID == 0 || node.Context.EnvironmentID == 0 {
+		return nil
+	}
+	artifacts, err := loadArtifactByWorkflowRunID(db, wr.ID)
+	if err != nil {
+		return sdk.WrapError(err, "unable to load artifacts of workflow run %d", wr.ID)
+	}
+	d := sdk.NewEnvironmentDeployment(wr, nr, *node.Context, artifacts)
+	return environment.InsertDeployment(db, &d)
+}
+
 func releaseMutex(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj sdk.Project, workflowID int64, nodeName string) (*ProcessorReport, error) {
 	_, next := observability.Span(ctx, "workflow.releaseMutex")
 	defer next()
@@ -385,35 +454,136 @@ func releaseMutex(ctx context.Context, db gorp.SqlExecutor, store cache.Store, p
 	return r, nil
 }
 
-func checkRunOnlyFailedJobs(wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) (*sdk.WorkflowNodeRun, error) {
-	var previousNR *sdk.WorkflowNodeRun
-	nrs, ok := wr.WorkflowNodeRuns[nr.WorkflowNodeID]
-	if !ok {
-		return nil, sdk.WrapError(sdk.ErrWorkflowNodeNotFound, "node %d not found in workflow run %d", nr.WorkflowNodeID, wr.ID)
-	}
+// previousNodeRun returns the execution of the node that precedes the given sub run
+func previousNodeRun(wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) *sdk.WorkflowNodeRun {
+	var previous *sdk.WorkflowNodeRun
+	nrs := wr.WorkflowNodeRuns[nr.WorkflowNodeID]
 	for i := range nrs {
-		if nrs[i].SubNumber < nr.SubNumber {
-			previousNR = &nrs[i]
-			break
+		if nrs[i].SubNumber < nr.SubNumber && (previous == nil || nrs[i].SubNumber > previous.SubNumber) {
+			previous = &nrs[i]
 		}
 	}
+	return previous
+}
 
+// checkRunOnlyFailedJobs returns the previous execution of a node run that only runs the failed jobs again.
+// Stages are matched by id, the stages generated by the jobs of the previous execution are copied before the finally stage.
+func checkRunOnlyFailedJobs(wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) (*sdk.WorkflowNodeRun, error) {
+	if _, ok := wr.WorkflowNodeRuns[nr.WorkflowNodeID]; !ok {
+		return nil, sdk.WrapError(sdk.ErrWorkflowNodeNotFound, "node %d not found in workflow run %d", nr.WorkflowNodeID, wr.ID)
+	}
+
+	previousNR := previousNodeRun(wr, nr)
 	if previousNR == nil {
 		return nil, sdk.WrapError(sdk.ErrNotFound, "unable to find a previous execution of this pipeline")
 	}
 
-	if len(previousNR.Stages) != len(nr.Stages) {
-		return nil, sdk.NewErrorFrom(sdk.ErrForbidden, "you cannot rerun a pipeline that have a different number of stages")
+	stageIDs := make(map[int64]struct{}, len(nr.Stages))
+	for _, s := range nr.Stages {
+		stageIDs[s.ID] = struct{}{}
 	}
 
-	for i, s := range nr.Stages {
-		if len(s.Jobs) != len(previousNR.Stages[i].Jobs) {
+	var dynamicStages []sdk.Stage
+	for _, s := range previousNR.Stages {
+		if _, has := stageIDs[s.ID]; has {
+			continue
+		}
+		if !isDynamicStage(s) {
+			return nil, sdk.NewErrorFrom(sdk.ErrForbidden, "you cannot rerun a pipeline that have different stages")
+		}
+		// Generated stages are executed again like the other stages, only their failed jobs are run
+		s.Status = ""
+		s.RunJobs = nil
+		dynamicStages = append(dynamicStages, s)
+	}
+
+	for _, s := range nr.Stages {
+		var previousStage *sdk.Stage
+		for i := range previousNR.Stages {
+			if previousNR.Stages[i].ID == s.ID {
+				previousStage = &previousNR.Stages[i]
+				break
+			}
+		}
+		if previousStage == nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrForbidden, "you cannot rerun a pipeline that have different stages")
+		}
+		if len(s.Jobs) != len(previousStage.Jobs) {
 			return nil, sdk.NewErrorFrom(sdk.ErrForbidden, "you cannot rerun a pipeline that have a different number of jobs")
 		}
 	}
+
+	if len(dynamicStages) > 0 {
+		stages := make([]sdk.Stage, 0, len(nr.Stages)+len(dynamicStages))
+		var finallyStage *sdk.Stage
+		for i := range nr.Stages {
+			if nr.Stages[i].Finally {
+				finallyStage = &nr.Stages[i]
+				continue
+			}
+			stages = append(stages, nr.Stages[i])
+		}
+		stages = append(stages, dynamicStages...)
+		if finallyStage != nil {
+			finallyStage.BuildOrder = stages[len(stages)-1].BuildOrder + 1
+			stages = append(stages, *finallyStage)
+		}
+		nr.Stages = stages
+	}
+
 	return previousNR, nil
 }
 
+// executeFinallyStage starts or syncs the finally stage of the node run, the status of the other stages is given
+// to its jobs in the cds.pipeline.status variable. Returns the status of the node run.
+func executeFinallyStage(ctx context.Context, db gorp.SqlExecutor, store cache.Store, wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun, stage *sdk.Stage, pipelineStatus string) (*ProcessorReport, string, error) {
+	report := new(ProcessorReport)
+
+	if stage.Status == "" {
+		sdk.ParameterAddOrSetValue(&nr.BuildParameters, "cds.pipeline.status", sdk.StringParameter, pipelineStatus)
+		if err := UpdateNodeRunBuildParameters(db, nr.ID, nr.BuildParameters); err != nil {
+			return report, "", sdk.WrapError(err, "unable to update node run %d build parameters", nr.ID)
+		}
+
+		stage.Status = sdk.StatusWaiting
+		if len(stage.Jobs) == 0 {
+			stage.Status = sdk.StatusSuccess
+		} else {
+			r, err := addJobsToQueue(ctx, db, stage, wr, nr, nil)
+			report.Merge(ctx, r)
+			if err != nil {
+				return report, "", err
+			}
+		}
+	}
+
+	if stage.Status == sdk.StatusBuilding {
+		if _, err := syncStage(ctx, db, store, stage); err != nil {
+			return report, "", err
+		}
+		r, jobsWithNeeds, err := addJobsWithNeedsToQueue(ctx, db, stage, wr, nr)
+		report.Merge(ctx, r)
+		if err != nil {
+			return report, "", err
+		}
+		if len(jobsWithNeeds) > 0 {
+			if _, err := syncStage(ctx, db, store, stage); err != nil {
+				return report, "", err
+			}
+		}
+	}
+
+	if !sdk.StatusIsTerminated(stage.Status) {
+		return report, sdk.StatusBuilding, nil
+	}
+
+	var counterStatus statusCounter
+	for _, s := range nr.Stages {
+		computeRunStatus(s.Status, &counterStatus)
+	}
+	return report, getRunStatus(counterStatus), nil
+}
+
 func addJobsToQueue(ctx context.Context, db gorp.SqlExecutor, stage *sdk.Stage, wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun, previousStage *sdk.Stage) (*ProcessorReport, error) {
 	var end func()
 	ctx, end = observability.Span(ctx, "workflow.addJobsToQueue")
@@ -447,131 +617,272 @@ func addJobsToQueue(ctx context.Context, db gorp.SqlExecutor, stage *sdk.Stage,
 
 	skippedOrDisabledJobs := 0
 	failedJobs := 0
+	var addedJobs []sdk.WorkflowNodeJobRun
+	// Matrix jobs are expanded to one job for each combination
+	jobs := expandStageJobs(stage.Jobs)
 	//Browse the jobs
 jobLoop:
-	for j := range stage.Jobs {
-		job := &stage.Jobs[j]
+	for j := range jobs {
+		job := &jobs[j]
 
 		if previousStage != nil {
 			for _, rj := range previousStage.RunJobs {
-				if rj.Job.PipelineActionID == job.PipelineActionID && rj.Status != sdk.StatusFail && sdk.StatusIsTerminated(rj.Status) {
+				if rj.Job.PipelineActionID == job.PipelineActionID && rj.Job.Matrix.String() == job.Matrix.String() &&
+					rj.Status != sdk.StatusFail && rj.Status != sdk.StatusStopped && sdk.StatusIsTerminated(rj.Status) {
 					stage.RunJobs = append(stage.RunJobs, rj)
 					continue jobLoop
 				}
 			}
 		}
 
-		// errors generated in the loop will be added to job run spawn info
-		spawnErrs := sdk.MultiError{}
-
-		//Process variables for the jobs
-		_, next = observability.Span(ctx, "workflow..getNodeJobRunParameters")
-		jobParams, err := getNodeJobRunParameters(db, *job, nr, stage)
-		next()
-		if err != nil {
-			spawnErrs.Join(*err)
+		// Jobs that need other jobs will be added to the queue when their needs are over
+		if len(job.Action.Needs) > 0 && stage.Enabled && job.Enabled && conditionsOK {
+			continue
 		}
 
-		_, next = observability.Span(ctx, "workflow.processNodeJobRunRequirements")
-		jobRequirements, containsService, wm, err := processNodeJobRunRequirements(ctx, db, *job, nr, sdk.Groups(groups).ToIDs(), integrationPluginBinaries)
-		next()
+		wjob, err := addJobToQueue(ctx, db, stage, wr, nr, *job, groups, integrationPluginBinaries, conditionsOK)
 		if err != nil {
-			spawnErrs.Join(*err)
-		}
-
-		// check that children actions used by job can be used by the project
-		if err := action.CheckChildrenForGroupIDsWithLoop(ctx, db, &job.Action, sdk.Groups(groups).ToIDs()); err != nil {
-			spawnErrs.Append(err)
+			return report, err
 		}
+		report.Add(ctx, *wjob)
+		addedJobs = append(addedJobs, *wjob)
+	}
 
-		// add requirements in job parameters, to use them as {{.job.requirement...}} in job
-		_, next = observability.Span(ctx, "workflow.prepareRequirementsToNodeJobRunParameters")
-		jobParams = append(jobParams, prepareRequirementsToNodeJobRunParameters(jobRequirements)...)
-		next()
+	// Jobs that only need already terminated jobs can be added now
+	r, jobsWithNeeds, err := addJobsWithNeedsToQueue(ctx, db, stage, wr, nr)
+	report.Merge(ctx, r)
+	if err != nil {
+		return report, err
+	}
+	addedJobs = append(addedJobs, jobsWithNeeds...)
 
-		//Create the job run
-		wjob := sdk.WorkflowNodeJobRun{
-			ProjectID:                 wr.ProjectID,
-			WorkflowNodeRunID:         nr.ID,
-			Start:                     time.Time{},
-			Queued:                    time.Now(),
-			Status:                    sdk.StatusWaiting,
-			Parameters:                jobParams,
-			ExecGroups:                groups,
-			IntegrationPluginBinaries: integrationPluginBinaries,
-			Job: sdk.ExecutedJob{
-				Job: *job,
-			},
-			Header:          nr.Header,
-			ContainsService: containsService,
-		}
-		if wm != nil {
-			wjob.ModelType = wm.Type
-		}
-		wjob.Job.Job.Action.Requirements = jobRequirements // Set the interpolated requirements on the job run only
-
-		if !stage.Enabled || !wjob.Job.Enabled {
-			wjob.Status = sdk.StatusDisabled
-			skippedOrDisabledJobs++
-		} else if !conditionsOK {
-			wjob.Status = sdk.StatusSkipped
+	for _, wjob := range addedJobs {
+		switch wjob.Status {
+		case sdk.StatusDisabled, sdk.StatusSkipped:
 			skippedOrDisabledJobs++
+		case sdk.StatusFail:
+			failedJobs++
 		}
+	}
 
-		// If there is any error in the previous operation, mark the job as failed
-		if !spawnErrs.IsEmpty() {
-			failedJobs++
-			wjob.Status = sdk.StatusFail
+	if skippedOrDisabledJobs == len(jobs) {
+		stage.Status = sdk.StatusSkipped
+	}
+
+	if failedJobs > 0 {
+		stage.Status = sdk.StatusFail
+	}
 
-			for _, e := range spawnErrs {
-				msg := sdk.SpawnMsg{
-					ID: sdk.MsgSpawnInfoJobError.ID,
+	return report, nil
+}
+
+// addJobsWithNeedsToQueue adds to the queue the jobs of the stage that were waiting for their needs.
+// If all the needed jobs succeeded the job is queued, else it is skipped. Returns the added run jobs.
+func addJobsWithNeedsToQueue(ctx context.Context, db gorp.SqlExecutor, stage *sdk.Stage, wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) (*ProcessorReport, []sdk.WorkflowNodeJobRun, error) {
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.addJobsWithNeedsToQueue")
+	defer end()
+
+	report := new(ProcessorReport)
+	var addedJobs []sdk.WorkflowNodeJobRun
+
+	var integrationPluginBinaries []sdk.GRPCPluginBinary
+	var groups []sdk.Group
+	var loaded bool
+
+	// Skipped jobs are over as soon as they are added, so the jobs that need them can be added too
+	for {
+		var added bool
+		for _, job := range expandStageJobs(stage.Jobs) {
+			if len(job.Action.Needs) == 0 || stageContainsJobRun(stage, job) {
+				continue
+			}
+			ready, succeeded := checkJobNeeds(stage, job)
+			if !ready {
+				continue
+			}
+
+			if !loaded {
+				var err error
+				integrationPluginBinaries, err = getIntegrationPluginBinaries(db, wr, nr)
+				if err != nil {
+					return report, addedJobs, sdk.WrapError(err, "unable to get integration plugins requirement")
+				}
+				groups, err = getExecutablesGroups(wr, nr)
+				if err != nil {
+					return report, addedJobs, sdk.WrapError(err, "error getting job executables groups")
 				}
-				msg.Args = []interface{}{sdk.Cause(e).Error()}
-				wjob.SpawnInfos = append(wjob.SpawnInfos, sdk.SpawnInfo{
-					APITime:     time.Now(),
-					Message:     msg,
-					RemoteTime:  time.Now(),
-					UserMessage: msg.DefaultUserMessage(),
-				})
+				loaded = true
 			}
-		} else {
-			sp := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobInQueue.ID}
-			wjob.SpawnInfos = []sdk.SpawnInfo{{
-				APITime:     time.Now(),
-				Message:     sp,
-				RemoteTime:  time.Now(),
-				UserMessage: sp.DefaultUserMessage(),
-			}}
+
+			wjob, err := addJobToQueue(ctx, db, stage, wr, nr, job, groups, integrationPluginBinaries, succeeded)
+			if err != nil {
+				return report, addedJobs, err
+			}
+			report.Add(ctx, *wjob)
+			addedJobs = append(addedJobs, *wjob)
+			added = true
 		}
+		if !added {
+			return report, addedJobs, nil
+		}
+	}
+}
 
-		// insert in database
-		_, next = observability.Span(ctx, "workflow.insertWorkflowNodeJobRun")
-		if err := insertWorkflowNodeJobRun(db, &wjob); err != nil {
-			next()
-			return report, sdk.WrapError(err, "unable to insert in table workflow_node_run_job")
+// stageContainsJobRun returns true if a run job already exists in the stage for the given job
+func stageContainsJobRun(stage *sdk.Stage, job sdk.ExecutedJob) bool {
+	for _, rj := range stage.RunJobs {
+		if rj.Job.PipelineActionID == job.PipelineActionID && rj.Job.Matrix.String() == job.Matrix.String() {
+			return true
 		}
-		next()
+	}
+	return false
+}
 
-		if err := AddSpawnInfosNodeJobRun(db, wjob.WorkflowNodeRunID, wjob.ID, PrepareSpawnInfos(wjob.SpawnInfos)); err != nil {
-			return nil, sdk.WrapError(err, "cannot save spawn info job %d", wjob.ID)
+// checkJobNeeds returns true if all the run jobs needed by the given job are over,
+// and if they all succeeded or were disabled
+func checkJobNeeds(stage *sdk.Stage, job sdk.ExecutedJob) (bool, bool) {
+	succeeded := true
+	for _, need := range job.Action.Needs {
+		for _, j := range expandStageJobs(stage.Jobs) {
+			if j.Action.Name != need {
+				continue
+			}
+			var runJob *sdk.WorkflowNodeJobRun
+			for i := range stage.RunJobs {
+				rj := &stage.RunJobs[i]
+				if rj.Job.PipelineActionID == j.PipelineActionID && rj.Job.Matrix.String() == j.Matrix.String() {
+					runJob = rj
+					break
+				}
+			}
+			if runJob == nil || !sdk.StatusIsTerminated(runJob.Status) {
+				return false, false
+			}
+			if runJob.Status != sdk.StatusSuccess && runJob.Status != sdk.StatusDisabled {
+				succeeded = false
+			}
 		}
+	}
+	return true, succeeded
+}
 
-		//Put the job run in database
-		stage.RunJobs = append(stage.RunJobs, wjob)
+// addJobToQueue creates a run job for the given job and inserts it in the queue,
+// the run job is skipped if it should not be run.
+func addJobToQueue(ctx context.Context, db gorp.SqlExecutor, stage *sdk.Stage, wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun, job sdk.ExecutedJob,
+	groups []sdk.Group, integrationPluginBinaries []sdk.GRPCPluginBinary, shouldRun bool) (*sdk.WorkflowNodeJobRun, error) {
+	// errors generated in the loop will be added to job run spawn info
+	spawnErrs := sdk.MultiError{}
 
-		report.Add(ctx, wjob)
+	//Process variables for the jobs
+	_, next := observability.Span(ctx, "workflow..getNodeJobRunParameters")
+	jobParams, err := getNodeJobRunParameters(db, job.Job, nr, stage, job.Matrix)
+	next()
+	if err != nil {
+		spawnErrs.Join(*err)
 	}
 
-	if skippedOrDisabledJobs == len(stage.Jobs) {
-		stage.Status = sdk.StatusSkipped
+	_, next = observability.Span(ctx, "workflow.processNodeJobRunRequirements")
+	jobRequirements, containsService, wm, err := processNodeJobRunRequirements(ctx, db, job.Job, nr, job.Matrix, sdk.Groups(groups).ToIDs(), integrationPluginBinaries)
+	next()
+	if err != nil {
+		spawnErrs.Join(*err)
 	}
 
-	if failedJobs > 0 {
-		stage.Status = sdk.StatusFail
+	// check that children actions used by job can be used by the project
+	if err := action.CheckChildrenForGroupIDsWithLoop(ctx, db, &job.Action, sdk.Groups(groups).ToIDs()); err != nil {
+		spawnErrs.Append(err)
 	}
 
-	return report, nil
+	// add requirements in job parameters, to use them as {{.job.requirement...}} in job
+	_, next = observability.Span(ctx, "workflow.prepareRequirementsToNodeJobRunParameters")
+	jobParams = append(jobParams, prepareRequirementsToNodeJobRunParameters(jobRequirements)...)
+	next()
+
+	//Create the job run
+	wjob := sdk.WorkflowNodeJobRun{
+		ProjectID:                 wr.ProjectID,
+		WorkflowNodeRunID:         nr.ID,
+		Start:                     time.Time{},
+		Queued:                    time.Now(),
+		Status:                    sdk.StatusWaiting,
+		Parameters:                jobParams,
+		ExecGroups:                groups,
+		IntegrationPluginBinaries: integrationPluginBinaries,
+		Job:                       job,
+		Header:                    nr.Header,
+		ContainsService:           containsService,
+		Priority:                  wr.Workflow.Priority + job.Action.Priority,
+	}
+	if wm != nil {
+		wjob.ModelType = wm.Type
+	}
+	wjob.Job.Job.Action.Requirements = jobRequirements // Set the interpolated requirements on the job run only
+
+	if !stage.Enabled || !wjob.Job.Enabled {
+		wjob.Status = sdk.StatusDisabled
+	} else if !shouldRun {
+		wjob.Status = sdk.StatusSkipped
+	}
+
+	// If there is any error in the previous operation, mark the job as failed
+	if !spawnErrs.IsEmpty() {
+		wjob.Status = sdk.StatusFail
+
+		for _, e := range spawnErrs {
+			msg := sdk.SpawnMsg{
+				ID: sdk.MsgSpawnInfoJobError.ID,
+			}
+			msg.Args = []interface{}{sdk.Cause(e).Error()}
+			wjob.SpawnInfos = append(wjob.SpawnInfos, sdk.SpawnInfo{
+				APITime:     time.Now(),
+				Message:     msg,
+				RemoteTime:  time.Now(),
+				UserMessage: msg.DefaultUserMessage(),
+			})
+		}
+	} else {
+		sp := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobInQueue.ID}
+		wjob.SpawnInfos = []sdk.SpawnInfo{{
+			APITime:     time.Now(),
+			Message:     sp,
+			RemoteTime:  time.Now(),
+			UserMessage: sp.DefaultUserMessage(),
+		}}
+	}
+
+	// insert in database
+	_, next = observability.Span(ctx, "workflow.insertWorkflowNodeJobRun")
+	if err := insertWorkflowNodeJobRun(db, &wjob); err != nil {
+		next()
+		return nil, sdk.WrapError(err, "unable to insert in table workflow_node_run_job")
+	}
+	next()
+
+	if err := AddSpawnInfosNodeJobRun(db, wjob.WorkflowNodeRunID, wjob.ID, PrepareSpawnInfos(wjob.SpawnInfos)); err != nil {
+		return nil, sdk.WrapError(err, "cannot save spawn info job %d", wjob.ID)
+	}
+
+	//Put the job run in database
+	stage.RunJobs = append(stage.RunJobs, wjob)
+
+	return &wjob, nil
+}
+
+// expandStageJobs returns the jobs to execute for a stage, with one job for each combination of a matrix job
+func expandStageJobs(jobs []sdk.Job) []sdk.ExecutedJob {
+	res := make([]sdk.ExecutedJob, 0, len(jobs))
+	for _, j := range jobs {
+		combinations := j.Action.Matrix.Combinations()
+		if len(combinations) == 0 {
+			res = append(res, sdk.ExecutedJob{Job: j})
+			continue
+		}
+		for _, c := range combinations {
+			res = append(res, sdk.ExecutedJob{Job: j, Matrix: c})
+		}
+	}
+	return res
 }
 
 func getIntegrationPluginBinaries(db gorp.SqlExecutor, wr *sdk.WorkflowRun, nr *sdk.WorkflowNodeRun) ([]sdk.GRPCPluginBinary, error) {
@@ -711,7 +1022,7 @@ func NodeBuildParametersFromRun(wr sdk.WorkflowRun, id int64) ([]sdk.Parameter,
 	return params, nil
 }
 
-//NodeBuildParametersFromWorkflow returns build_parameters for a node given its id
+// NodeBuildParametersFromWorkflow returns build_parameters for a node given its id
 func NodeBuildParametersFromWorkflow(ctx context.Context, proj sdk.Project, wf *sdk.Workflow, refNode *sdk.Node, ancestorsIds []int64) ([]sdk.Parameter, error) {
 	runContext := nodeRunContext{}
 	res := []sdk.Parameter{}
@@ -778,13 +1089,19 @@ func NodeBuildParametersFromWorkflow(ctx context.Context, proj sdk.Project, wf *
 	return res, nil
 }
 
-func stopWorkflowNodePipeline(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, proj sdk.Project, nodeRun *sdk.WorkflowNodeRun, stopInfos sdk.SpawnInfo) (*ProcessorReport, error) {
+func stopWorkflowNodePipeline(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, proj sdk.Project, wr *sdk.WorkflowRun, nodeRun *sdk.WorkflowNodeRun, stopInfos sdk.SpawnInfo) (*ProcessorReport, error) {
 	var end func()
 	ctx, end = observability.Span(ctx, "workflow.stopWorkflowNodePipeline")
 	defer end()
 
 	report := new(ProcessorReport)
 
+	// A finally stage that is not started yet is executed once the other stages are stopped
+	var finallyStage *sdk.Stage
+	if s := &nodeRun.Stages[len(nodeRun.Stages)-1]; s.Finally && s.Status == "" {
+		finallyStage = s
+	}
+
 	const stopWorkflowNodeRunNBWorker = 5
 	var wg sync.WaitGroup
 	// Load node job run ID
@@ -824,11 +1141,26 @@ func stopWorkflowNodePipeline(ctx context.Context, dbFunc func() *gorp.DbMap, st
 	}
 	defer tx.Rollback() //nolint
 
-	// Update stages from node run
+	// Update stages from node run, except the finally stage that is not started yet
+	stages := nodeRun.Stages
+	if finallyStage != nil {
+		nodeRun.Stages = stages[:len(stages)-1]
+	}
 	stopWorkflowNodeRunStages(ctx, tx, nodeRun)
+	nodeRun.Stages = stages
 
 	nodeRun.Status = sdk.StatusStopped
-	nodeRun.Done = time.Now()
+	if finallyStage != nil {
+		r, status, err := executeFinallyStage(ctx, tx, store, wr, nodeRun, finallyStage, sdk.StatusStopped)
+		report.Merge(ctx, r)
+		if err != nil {
+			return report, err
+		}
+		nodeRun.Status = status
+	}
+	if sdk.StatusIsTerminated(nodeRun.Status) {
+		nodeRun.Done = time.Now()
+	}
 
 	if errU := UpdateNodeRun(tx, nodeRun); errU != nil {
 		return report, sdk.WrapError(errU, "stopWorkflowNodePipeline> Cannot update node run")
@@ -880,7 +1212,7 @@ func StopWorkflowNodeRun(ctx context.Context, dbFunc func() *gorp.DbMap, store c
 	var r *ProcessorReport
 	var err error
 	if workflowNodeRun.Stages != nil && len(workflowNodeRun.Stages) > 0 {
-		r, err = stopWorkflowNodePipeline(ctx, dbFunc, store, proj, &workflowNodeRun, stopInfos)
+		r, err = stopWorkflowNodePipeline(ctx, dbFunc, store, proj, &workflowRun, &workflowNodeRun, stopInfos)
 	}
 	if workflowNodeRun.OutgoingHook != nil {
 		err = stopWorkflowNodeOutGoingHook(ctx, dbFunc, &workflowNodeRun)
@@ -892,6 +1224,11 @@ func StopWorkflowNodeRun(ctx context.Context, dbFunc func() *gorp.DbMap, store c
 	report.Merge(ctx, r)
 	report.Add(ctx, workflowNodeRun)
 
+	// A node run executing its finally stage releases its mutex and concurrency group once the stage is over
+	if !sdk.StatusIsTerminated(workflowNodeRun.Status) {
+		return report, nil
+	}
+
 	// If current node has a mutex, we want to trigger another node run that can be waiting for the mutex
 	workflowNode := workflowRun.Workflow.WorkflowData.NodeByID(workflowNodeRun.WorkflowNodeID)
 	hasMutex := workflowNode != nil && workflowNode.Context != nil && workflowNode.Context.Mutex
@@ -903,6 +1240,14 @@ func StopWorkflowNodeRun(ctx context.Context, dbFunc func() *gorp.DbMap, store c
 		}
 	}
 
+	if workflowNodeRun.ConcurrencyGroup != "" {
+		r, err = releaseConcurrencyGroup(ctx, dbFunc(), store, proj, workflowNodeRun.ConcurrencyGroup)
+		report.Merge(ctx, r)
+		if err != nil {
+			return report, err
+		}
+	}
+
 	return report, nil
 }
 
@@ -0,0 +1,106 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_checkJobNeeds(t *testing.T) {
+	compile := sdk.Job{PipelineActionID: 1, Action: sdk.Action{Name: "compile", Matrix: sdk.JobMatrix{"go": {"1.13", "1.14"}}}}
+	test := sdk.Job{PipelineActionID: 2, Action: sdk.Action{Name: "test", Needs: []string{"compile"}}}
+	stage := &sdk.Stage{Jobs: []sdk.Job{compile, test}}
+
+	jobs := expandStageJobs(stage.Jobs)
+	assert.Len(t, jobs, 3)
+
+	// needed jobs are not in queue yet
+	ready, _ := checkJobNeeds(stage, jobs[2])
+	assert.False(t, ready)
+
+	stage.RunJobs = []sdk.WorkflowNodeJobRun{
+		{Status: sdk.StatusSuccess, Job: jobs[0]},
+		{Status: sdk.StatusBuilding, Job: jobs[1]},
+	}
+	ready, _ = checkJobNeeds(stage, jobs[2])
+	assert.False(t, ready)
+	assert.True(t, stageContainsJobRun(stage, jobs[1]))
+	assert.False(t, stageContainsJobRun(stage, jobs[2]))
+
+	stage.RunJobs[1].Status = sdk.StatusSuccess
+	ready, succeeded := checkJobNeeds(stage, jobs[2])
+	assert.True(t, ready)
+	assert.True(t, succeeded)
+
+	stage.RunJobs[1].Status = sdk.StatusFail
+	ready, succeeded = checkJobNeeds(stage, jobs[2])
+	assert.True(t, ready)
+	assert.False(t, succeeded)
+}
+
+func Test_checkRunOnlyFailedJobsWithDynamicStages(t *testing.T) {
+	newStages := func() []sdk.Stage {
+		return []sdk.Stage{
+			{ID: 10, Name: "Compute", BuildOrder: 1, Enabled: true, Jobs: []sdk.Job{{PipelineActionID: 100, Action: sdk.Action{Name: "compute"}}}},
+			{ID: 11, Name: "Clean", BuildOrder: 2, Enabled: true, Finally: true, Jobs: []sdk.Job{{PipelineActionID: 110, Action: sdk.Action{Name: "clean"}}}},
+		}
+	}
+
+	// The compute job of the first execution generated a stage with a failed job
+	previous := sdk.WorkflowNodeRun{ID: 1, WorkflowNodeID: 1, SubNumber: 0, Stages: newStages()}
+	_, err := appendDynamicStages(&previous, []sdk.Stage{{
+		Name:    "Deploy",
+		Enabled: true,
+		Jobs:    []sdk.Job{{Action: sdk.Action{Name: "deploy-eu"}}, {Action: sdk.Action{Name: "deploy-us"}}},
+	}})
+	assert.NoError(t, err)
+	previous.Stages[0].Status = sdk.StatusSuccess
+	previous.Stages[0].RunJobs = []sdk.WorkflowNodeJobRun{{Status: sdk.StatusSuccess, Job: sdk.ExecutedJob{Job: previous.Stages[0].Jobs[0]}}}
+	previous.Stages[1].Status = sdk.StatusFail
+	previous.Stages[1].RunJobs = []sdk.WorkflowNodeJobRun{
+		{Status: sdk.StatusSuccess, Job: sdk.ExecutedJob{Job: previous.Stages[1].Jobs[0]}},
+		{Status: sdk.StatusFail, Job: sdk.ExecutedJob{Job: previous.Stages[1].Jobs[1]}},
+	}
+	previous.Stages[2].Status = sdk.StatusSuccess
+
+	// Stages of the new execution come from the pipeline, in a different order
+	stages := newStages()
+	nr := sdk.WorkflowNodeRun{ID: 2, WorkflowNodeID: 1, SubNumber: 1, Stages: []sdk.Stage{stages[1], stages[0]}}
+	wr := &sdk.WorkflowRun{WorkflowNodeRuns: map[int64][]sdk.WorkflowNodeRun{1: {nr, previous}}}
+
+	previousNR, err := checkRunOnlyFailedJobs(wr, &nr)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), previousNR.ID)
+
+	// The generated stage is copied before the finally stage, to run its failed job again
+	if assert.Len(t, nr.Stages, 3) {
+		assert.Equal(t, int64(10), nr.Stages[0].ID)
+		deploy := nr.Stages[1]
+		assert.Equal(t, previous.Stages[1].ID, deploy.ID)
+		assert.Equal(t, "Deploy", deploy.Name)
+		assert.Empty(t, deploy.Status)
+		assert.Empty(t, deploy.RunJobs)
+		assert.Len(t, deploy.Jobs, 2)
+		assert.Equal(t, int64(11), nr.Stages[2].ID)
+		assert.True(t, nr.Stages[2].Finally)
+		assert.Equal(t, deploy.BuildOrder+1, nr.Stages[2].BuildOrder)
+	}
+
+	// Checking the node run again does not copy the generated stage twice
+	_, err = checkRunOnlyFailedJobs(wr, &nr)
+	assert.NoError(t, err)
+	assert.Len(t, nr.Stages, 3)
+
+	// A stage of the pipeline that was removed since the previous execution prevents to run only the failed jobs
+	nr = sdk.WorkflowNodeRun{ID: 2, WorkflowNodeID: 1, SubNumber: 1, Stages: newStages()[:1]}
+	_, err = checkRunOnlyFailedJobs(wr, &nr)
+	assert.Error(t, err)
+
+	stages = newStages()
+	stages[0].Jobs = append(stages[0].Jobs, sdk.Job{PipelineActionID: 101, Action: sdk.Action{Name: "lint"}})
+	nr = sdk.WorkflowNodeRun{ID: 2, WorkflowNodeID: 1, SubNumber: 1, Stages: stages}
+	_, err = checkRunOnlyFailedJobs(wr, &nr)
+	assert.Error(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/go-gorp/gorp"
 
 	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/objectstore"
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
@@ -79,8 +80,9 @@ loop:
 	return report, nil
 }
 
-// UpdateParentWorkflowRun updates the workflow which triggered the current workflow
-func UpdateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, wr *sdk.WorkflowRun, parentProj sdk.Project, parentWR *sdk.WorkflowRun) (*ProcessorReport, error) {
+// UpdateParentWorkflowRun updates the workflow which triggered the current workflow.
+// If the workflow was triggered by a sub workflow node, its outputs and artifacts are passed to the parent workflow run.
+func UpdateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, sharedStorage objectstore.Driver, wr *sdk.WorkflowRun, parentProj sdk.Project, parentWR *sdk.WorkflowRun) (*ProcessorReport, error) {
 	_, end := observability.Span(ctx, "workflow.UpdateParentWorkflowRun")
 	defer end()
 
@@ -95,13 +97,6 @@ func UpdateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, sto
 		return nil, nil
 	}
 
-	tx, err := dbFunc().Begin()
-	if err != nil {
-		return nil, sdk.WrapError(err, "Unable to start transaction")
-	}
-
-	defer tx.Rollback() //nolint
-
 	hookrun := parentWR.GetOutgoingHookRun(wr.RootRun().HookEvent.ParentWorkflow.HookRunID)
 	if hookrun == nil {
 		return nil, sdk.WrapError(sdk.ErrNotFound, "unable to find hookrun")
@@ -117,6 +112,39 @@ func UpdateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, sto
 	hookrun.Callback.Status = wr.Status
 	hookrun.Callback.WorkflowRunNumber = &wr.Number
 
+	// The artifacts are copied in the shared storage before the transaction, the copies are deleted if they are not saved
+	var artifacts []sdk.WorkflowNodeRunArtifact
+	var errResults error
+	if isSubWorkflowHookRun(hookrun) {
+		artifacts, errResults = copySubWorkflowArtifacts(ctx, dbFunc(), sharedStorage, wr, parentWR, hookrun)
+	}
+	var committed bool
+	defer func() {
+		if !committed {
+			deleteSubWorkflowArtifacts(ctx, sharedStorage, artifacts)
+		}
+	}()
+
+	tx, err := dbFunc().Begin()
+	if err != nil {
+		return nil, sdk.WrapError(err, "Unable to start transaction")
+	}
+
+	defer tx.Rollback() //nolint
+
+	if isSubWorkflowHookRun(hookrun) {
+		if errResults == nil {
+			errResults = passSubWorkflowResults(ctx, tx, wr, hookrun, artifacts)
+		}
+		if errResults != nil {
+			deleteSubWorkflowArtifacts(ctx, sharedStorage, artifacts)
+			artifacts = nil
+			log.Error(ctx, "workflow.UpdateParentWorkflowRun> unable to pass sub workflow results to %s/%s#%d: %v", parentProj.Key, parentWR.Workflow.Name, parentWR.Number, errResults)
+			hookrun.Callback.Log += fmt.Sprintf("\nUnable to pass the workflow results: %s", sdk.Cause(errResults))
+			hookrun.Callback.Status = sdk.StatusFail
+		}
+	}
+
 	report, err := UpdateOutgoingHookRunStatus(ctx, tx, store, parentProj, parentWR, wr.RootRun().HookEvent.ParentWorkflow.HookRunID, *hookrun.Callback)
 	if err != nil {
 		log.Error(ctx, "workflow.UpdateParentWorkflowRun> unable to update hook run status run %s/%s#%d: %v",
@@ -130,6 +158,7 @@ func UpdateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, sto
 	if err := tx.Commit(); err != nil {
 		return nil, sdk.WrapError(err, "Unable to commit transaction")
 	}
+	committed = true
 
 	return report, nil
 }
@@ -0,0 +1,224 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/integration"
+	"github.com/ovh/cds/engine/api/objectstore"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// isSubWorkflowHookRun returns true if the outgoing hook run waits for the child workflow run result
+func isSubWorkflowHookRun(hookRun *sdk.WorkflowNodeRun) bool {
+	return hookRun.OutgoingHook != nil && hookRun.OutgoingHook.Config[sdk.HookConfigModelName].Value == sdk.SubWorkflowModelName
+}
+
+// subWorkflowPatterns splits a sub workflow config value in patterns, ex: "cds.outputs.*, cds.build.version"
+func subWorkflowPatterns(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' || r == '\n' })
+}
+
+func matchSubWorkflowPatterns(patterns []string, name string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// subWorkflowOutputs returns the build parameters of the child workflow run matching the given patterns.
+// If several node runs define the same parameter, the value of the last node run is kept.
+func subWorkflowOutputs(child *sdk.WorkflowRun, patterns []string) []sdk.Parameter {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	var nodeRuns []sdk.WorkflowNodeRun
+	for _, runs := range child.WorkflowNodeRuns {
+		if len(runs) == 0 {
+			continue
+		}
+		last := runs[0]
+		for i := range runs {
+			if runs[i].SubNumber > last.SubNumber {
+				last = runs[i]
+			}
+		}
+		nodeRuns = append(nodeRuns, last)
+	}
+	sort.Slice(nodeRuns, func(i, j int) bool { return nodeRuns[i].ID < nodeRuns[j].ID })
+
+	values := make(map[string]sdk.Parameter)
+	for _, nr := range nodeRuns {
+		for _, p := range nr.BuildParameters {
+			if matchSubWorkflowPatterns(patterns, p.Name) {
+				values[p.Name] = p
+			}
+		}
+	}
+
+	outputs := make([]sdk.Parameter, 0, len(values))
+	for _, p := range values {
+		outputs = append(outputs, p)
+	}
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].Name < outputs[j].Name })
+	return outputs
+}
+
+// copySubWorkflowArtifacts stores in the shared storage a copy of the child artifacts matching the patterns of the hook run.
+// The copies are saved as artifacts of the hook run by passSubWorkflowResults. Nothing is kept if an error occurs.
+func copySubWorkflowArtifacts(ctx context.Context, db gorp.SqlExecutor, sharedStorage objectstore.Driver, child *sdk.WorkflowRun, parentWR *sdk.WorkflowRun, hookRun *sdk.WorkflowNodeRun) ([]sdk.WorkflowNodeRunArtifact, error) {
+	patterns := subWorkflowPatterns(hookRun.OutgoingHook.Config[sdk.HookConfigArtifacts].Value)
+	if len(patterns) == 0 {
+		return nil, nil
+	}
+
+	childWR, err := LoadRunByID(db, child.ID, LoadRunOptions{WithArtifacts: true})
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflow run %d", child.ID)
+	}
+
+	var copies []sdk.WorkflowNodeRunArtifact
+	for _, runs := range childWR.WorkflowNodeRuns {
+		if len(runs) == 0 {
+			continue
+		}
+		sort.Slice(runs, func(i, j int) bool {
+			return runs[i].SubNumber > runs[j].SubNumber
+		})
+
+		for _, art := range MergeArtifactWithPreviousSubRun(runs) {
+			if !matchSubWorkflowPatterns(patterns, art.Name) {
+				continue
+			}
+			copied, err := copySubWorkflowArtifact(ctx, db, sharedStorage, childWR.Workflow.ProjectKey, art, parentWR, hookRun)
+			if err != nil {
+				deleteSubWorkflowArtifacts(ctx, sharedStorage, copies)
+				return nil, sdk.WrapError(err, "unable to copy artifact %s", art.Name)
+			}
+			copies = append(copies, *copied)
+		}
+	}
+	sort.Slice(copies, func(i, j int) bool { return copies[i].Name < copies[j].Name })
+	return copies, nil
+}
+
+func copySubWorkflowArtifact(ctx context.Context, db gorp.SqlExecutor, sharedStorage objectstore.Driver, projectKey string, art sdk.WorkflowNodeRunArtifact, parentWR *sdk.WorkflowRun, hookRun *sdk.WorkflowNodeRun) (*sdk.WorkflowNodeRunArtifact, error) {
+	integrationName := sdk.DefaultStorageIntegrationName
+	if art.ProjectIntegrationID != nil && *art.ProjectIntegrationID > 0 {
+		projectIntegration, err := integration.LoadProjectIntegrationByID(db, *art.ProjectIntegrationID)
+		if err != nil {
+			return nil, sdk.WrapError(err, "cannot load project integration %s/%d", projectKey, *art.ProjectIntegrationID)
+		}
+		integrationName = projectIntegration.Name
+	}
+
+	storageDriver, err := objectstore.GetDriver(ctx, db, sharedStorage, projectKey, integrationName)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := storageDriver.Fetch(ctx, &art)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot fetch artifact")
+	}
+	defer f.Close() // nolint
+
+	hash, err := sdk.GenerateHash()
+	if err != nil {
+		return nil, sdk.WrapError(err, "could not generate hash")
+	}
+
+	copied := art
+	copied.ID = 0
+	copied.WorkflowID = parentWR.ID
+	copied.WorkflowNodeRunID = hookRun.ID
+	copied.DownloadHash = hash
+	copied.ProjectIntegrationID = nil
+	copied.Created = time.Now()
+
+	objectPath, err := sharedStorage.Store(&copied, f)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot store artifact")
+	}
+	copied.ObjectPath = objectPath
+	log.Debug("copySubWorkflowArtifact> artifact %s copied to %s", copied.Name, objectPath)
+	return &copied, nil
+}
+
+// deleteSubWorkflowArtifacts removes from the shared storage the artifact copies that cannot be saved.
+func deleteSubWorkflowArtifacts(ctx context.Context, sharedStorage objectstore.Driver, artifacts []sdk.WorkflowNodeRunArtifact) {
+	for i := range artifacts {
+		if err := sharedStorage.Delete(ctx, &artifacts[i]); err != nil {
+			log.Error(ctx, "deleteSubWorkflowArtifacts> unable to delete artifact %s: %v", artifacts[i].ObjectPath, err)
+		}
+	}
+}
+
+// passSubWorkflowResults adds the outputs of the child workflow run to the build parameters of the hook run
+// and saves the copies of the child artifacts in the parent workflow run, so they are available for the next nodes.
+// Nothing is saved if an error occurs.
+func passSubWorkflowResults(ctx context.Context, tx *gorp.Transaction, child *sdk.WorkflowRun, hookRun *sdk.WorkflowNodeRun, artifacts []sdk.WorkflowNodeRunArtifact) error {
+	const savepoint = "sub_workflow_results"
+	if err := tx.Savepoint(savepoint); err != nil {
+		return sdk.WithStack(err)
+	}
+	if err := saveSubWorkflowResults(ctx, tx, child, hookRun, artifacts); err != nil {
+		if errR := tx.RollbackToSavepoint(savepoint); errR != nil {
+			return sdk.WrapError(errR, "unable to rollback the results of workflow run %d", child.ID)
+		}
+		return err
+	}
+	return sdk.WithStack(tx.ReleaseSavepoint(savepoint))
+}
+
+func saveSubWorkflowResults(ctx context.Context, db gorp.SqlExecutor, child *sdk.WorkflowRun, hookRun *sdk.WorkflowNodeRun, artifacts []sdk.WorkflowNodeRunArtifact) error {
+	childWR, err := LoadRunByID(db, child.ID, LoadRunOptions{})
+	if err != nil {
+		return sdk.WrapError(err, "unable to load workflow run %d", child.ID)
+	}
+
+	nodeRun, err := LoadNodeRunByID(db, hookRun.ID, LoadRunOptions{})
+	if err != nil {
+		return err
+	}
+
+	var logs string
+	outputs := subWorkflowOutputs(childWR, subWorkflowPatterns(hookRun.OutgoingHook.Config[sdk.HookConfigOutputs].Value))
+	if len(outputs) > 0 {
+		names := make([]string, len(outputs))
+		for i := range outputs {
+			names[i] = outputs[i].Name
+		}
+		// Child outputs override the parameters of the hook run with the same name
+		mapParams := sdk.ParametersToMap(nodeRun.BuildParameters)
+		nodeRun.BuildParameters = sdk.ParametersFromMap(sdk.ParametersMapMerge(mapParams, sdk.ParametersToMap(outputs)))
+		if err := UpdateNodeRunBuildParameters(db, nodeRun.ID, nodeRun.BuildParameters); err != nil {
+			return sdk.WrapError(err, "unable to update build parameters of node run %d", nodeRun.ID)
+		}
+		logs += fmt.Sprintf("\nOutputs passed to the next nodes: %s", strings.Join(names, ", "))
+	}
+
+	if len(artifacts) > 0 {
+		names := make([]string, len(artifacts))
+		for i := range artifacts {
+			if err := InsertArtifact(db, &artifacts[i]); err != nil {
+				return sdk.WrapError(err, "cannot insert artifact %s", artifacts[i].Name)
+			}
+			names[i] = artifacts[i].Name
+		}
+		logs += fmt.Sprintf("\nArtifacts passed to the next nodes: %s", strings.Join(names, ", "))
+	}
+
+	hookRun.Callback.Log += logs
+	return nil
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestSubWorkflowOutputs(t *testing.T) {
+	child := &sdk.WorkflowRun{
+		WorkflowNodeRuns: map[int64][]sdk.WorkflowNodeRun{
+			1: {
+				{
+					ID:        10,
+					SubNumber: 0,
+					BuildParameters: []sdk.Parameter{
+						{Name: "cds.outputs.build.version", Type: sdk.StringParameter, Value: "1.0.0"},
+						{Name: "cds.build.tag", Type: sdk.StringParameter, Value: "v1"},
+					},
+				},
+				{
+					ID:        12,
+					SubNumber: 1,
+					BuildParameters: []sdk.Parameter{
+						{Name: "cds.outputs.build.version", Type: sdk.StringParameter, Value: "1.0.1"},
+						{Name: "cds.build.tag", Type: sdk.StringParameter, Value: "v2"},
+					},
+				},
+			},
+			2: {
+				{
+					ID: 11,
+					BuildParameters: []sdk.Parameter{
+						{Name: "cds.outputs.deploy.url", Type: sdk.StringParameter, Value: "https://my.app"},
+						{Name: "cds.build.tag", Type: sdk.StringParameter, Value: "v0"},
+						{Name: "git.branch", Type: sdk.StringParameter, Value: "master"},
+					},
+				},
+			},
+		},
+	}
+
+	patterns := subWorkflowPatterns("cds.outputs.*, cds.build.tag")
+	require.Equal(t, []string{"cds.outputs.*", "cds.build.tag"}, patterns)
+
+	outputs := subWorkflowOutputs(child, patterns)
+	assert.Equal(t, []sdk.Parameter{
+		{Name: "cds.build.tag", Type: sdk.StringParameter, Value: "v2"},
+		{Name: "cds.outputs.build.version", Type: sdk.StringParameter, Value: "1.0.1"},
+		{Name: "cds.outputs.deploy.url", Type: sdk.StringParameter, Value: "https://my.app"},
+	}, outputs)
+
+	assert.Empty(t, subWorkflowOutputs(child, subWorkflowPatterns("")))
+}
@@ -71,6 +71,8 @@ type NodeRun struct {
 	HookExecutionTimestamp sql.NullInt64  `db:"hook_execution_timestamp"`
 	ExecutionID            sql.NullString `db:"execution_id"`
 	Callback               sql.NullString `db:"callback"`
+	ConcurrencyGroup       sql.NullString `db:"concurrency_group"`
+	Approvals              sql.NullString `db:"approvals"`
 }
 
 // JobRun is a gorp wrapper around sdk.WorkflowNodeJobRun
@@ -82,6 +84,7 @@ type JobRun struct {
 	Parameters                sql.NullString `db:"variables"`
 	Status                    string         `db:"status"`
 	Retry                     int            `db:"retry"`
+	Attempts                  int            `db:"attempts"`
 	Queued                    time.Time      `db:"queued"`
 	Start                     time.Time      `db:"start"`
 	Done                      time.Time      `db:"done"`
@@ -94,6 +97,7 @@ type JobRun struct {
 	Header                    sql.NullString `db:"header"`
 	HatcheryName              string         `db:"hatchery_name"`
 	WorkerName                string         `db:"worker_name"`
+	Priority                  int            `db:"priority"`
 }
 
 // ToJobRun transform the JobRun with data of the provided sdk.WorkflowNodeJobRun
@@ -111,6 +115,7 @@ func (j *JobRun) ToJobRun(jr *sdk.WorkflowNodeJobRun) (err error) {
 	}
 	j.Status = jr.Status
 	j.Retry = jr.Retry
+	j.Attempts = jr.Attempts
 	j.Queued = jr.Queued
 	j.Start = jr.Start
 	j.Done = jr.Done
@@ -120,6 +125,7 @@ func (j *JobRun) ToJobRun(jr *sdk.WorkflowNodeJobRun) (err error) {
 	j.ExecGroups, err = gorpmapping.JSONToNullString(jr.ExecGroups)
 	j.WorkerName = jr.WorkerName
 	j.HatcheryName = jr.HatcheryName
+	j.Priority = jr.Priority
 	if err != nil {
 		return sdk.WrapError(err, "column exec_groups")
 	}
@@ -142,6 +148,7 @@ func (j JobRun) WorkflowNodeRunJob() (sdk.WorkflowNodeJobRun, error) {
 		WorkflowNodeRunID: j.WorkflowNodeRunID,
 		Status:            j.Status,
 		Retry:             j.Retry,
+		Attempts:          j.Attempts,
 		Queued:            j.Queued,
 		QueuedSeconds:     time.Now().Unix() - j.Queued.Unix(),
 		Start:             j.Start,
@@ -151,6 +158,7 @@ func (j JobRun) WorkflowNodeRunJob() (sdk.WorkflowNodeJobRun, error) {
 		HatcheryName:      j.HatcheryName,
 		WorkerName:        j.WorkerName,
 		Model:             j.Model,
+		Priority:          j.Priority,
 	}
 	if err := gorpmapping.JSONNullString(j.Job, &jr.Job); err != nil {
 		return jr, sdk.WrapError(err, "column job")
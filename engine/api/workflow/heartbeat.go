@@ -2,6 +2,7 @@ package workflow
 
 import (
 	"context"
+	"time"
 
 	"github.com/go-gorp/gorp"
 
@@ -12,9 +13,17 @@ import (
 
 const maxRetry = 3
 
-// manageDeadJob restart all jobs which are building but without worker
-func manageDeadJob(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Store) error {
+// jobTimeoutGracePeriod lets the worker report a job timeout by itself before the API stops the job
+const jobTimeoutGracePeriod = 2 * time.Minute
+
+// manageDeadJob restart all jobs which are building but without worker (using their retry policy if any) and fail all jobs that exceeded their timeout
+func manageDeadJob(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc) error {
 	db := DBFunc()
+
+	if err := manageTimedOutJob(ctx, db, store, loadProject); err != nil {
+		log.Warning(ctx, "manageDeadJob> %v", err)
+	}
+
 	deadJobs, err := LoadDeadNodeJobRun(ctx, db, store)
 	if err != nil {
 		return sdk.WrapError(err, "Cannot load dead node job run")
@@ -28,7 +37,19 @@ func manageDeadJob(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.S
 		}
 
 		if deadJob.Status == sdk.StatusBuilding {
-			if deadJob.Retry >= maxRetry {
+			if isNodeJobRunTimedOut(deadJob) {
+				if _, err := FailTimedOutNodeJobRun(ctx, tx, store, loadProject, deadJob); err != nil {
+					log.Error(ctx, "manageDeadJob> Cannot fail timed out node run job %d : %v", deadJob.ID, err)
+					_ = tx.Rollback()
+					continue
+				}
+			} else if deadJob.CanRetry(sdk.JobRetryOnWorkerLost) {
+				if _, err := RetryNodeJobRun(ctx, tx, store, &deadJob, sdk.JobRetryOnWorkerLost); err != nil {
+					log.Warning(ctx, "manageDeadJob> Cannot retry node job run %d: %v", deadJob.ID, err)
+					_ = tx.Rollback()
+					continue
+				}
+			} else if deadJob.Retry >= maxRetry {
 				if _, err := UpdateNodeJobRunStatus(ctx, tx, store, sdk.Project{}, &deadJob, sdk.StatusStopped); err != nil {
 					log.Error(ctx, "manageDeadJob> Cannot update node run job %d : %v", deadJob.ID, err)
 					_ = tx.Rollback()
@@ -62,3 +83,65 @@ func manageDeadJob(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.S
 
 	return nil
 }
+
+// manageTimedOutJob fails all building jobs that exceeded their timeout and that were not reported by their worker
+func manageTimedOutJob(ctx context.Context, db *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc) error {
+	jobs, err := LoadTimedOutNodeJobRun(ctx, db, store, jobTimeoutGracePeriod)
+	if err != nil {
+		return sdk.WrapError(err, "cannot load timed out node job run")
+	}
+
+	for _, job := range jobs {
+		tx, err := db.Begin()
+		if err != nil {
+			log.Error(ctx, "manageTimedOutJob> Cannot create transaction : %v", err)
+			continue
+		}
+
+		if _, err := FailTimedOutNodeJobRun(ctx, tx, store, loadProject, job); err != nil {
+			log.Error(ctx, "manageTimedOutJob> Cannot fail node run job %d : %v", job.ID, err)
+			_ = tx.Rollback()
+			continue
+		}
+
+		if err := tx.Commit(); err != nil {
+			log.Error(ctx, "manageTimedOutJob> Cannot commit transaction : %v", err)
+		}
+	}
+
+	return nil
+}
+
+func isNodeJobRunTimedOut(job sdk.WorkflowNodeJobRun) bool {
+	timeout := job.Job.Timeout()
+	return timeout > 0 && !job.Start.IsZero() && time.Since(job.Start) > timeout
+}
+
+// FailTimedOutNodeJobRun adds a timeout spawn info to the given job and fails it, like a worker does when it
+// detects the timeout of its job
+func FailTimedOutNodeJobRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, loadProject LoadProjectFunc, job sdk.WorkflowNodeJobRun) (*ProcessorReport, error) {
+	msg := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobTimeout.ID, Args: []interface{}{job.Job.Timeout().String()}}
+	infos := []sdk.SpawnInfo{{
+		RemoteTime:  time.Now(),
+		Message:     msg,
+		UserMessage: msg.DefaultUserMessage(),
+	}}
+	if err := AddSpawnInfosNodeJobRun(db, job.WorkflowNodeRunID, job.ID, PrepareSpawnInfos(infos)); err != nil {
+		return nil, err
+	}
+
+	job.Job.Reason = msg.DefaultUserMessage()
+	for i := range job.Job.StepStatus {
+		if !sdk.StatusIsTerminated(job.Job.StepStatus[i].Status) {
+			job.Job.StepStatus[i].Status = sdk.StatusFail
+			job.Job.StepStatus[i].Done = time.Now()
+		}
+	}
+
+	// A failed job triggers the next stages and nodes, so the project is needed
+	proj, err := loadProject(ctx, db, store, job.ProjectID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot load project %d", job.ProjectID)
+	}
+	return UpdateNodeJobRunStatus(ctx, db, store, *proj, &job, sdk.StatusFail)
+}
@@ -0,0 +1,134 @@
+package workflow_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-gorp/gorp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/api/authentication"
+	"github.com/ovh/cds/engine/api/bootstrap"
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/pipeline"
+	"github.com/ovh/cds/engine/api/project"
+	"github.com/ovh/cds/engine/api/test"
+	"github.com/ovh/cds/engine/api/test/assets"
+	"github.com/ovh/cds/engine/api/workflow"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestFailTimedOutNodeJobRun(t *testing.T) {
+	db, cache := test.SetupPG(t, bootstrap.InitiliazeDB)
+
+	u, _ := assets.InsertAdminUser(t, db)
+	key := sdk.RandomString(10)
+	proj := assets.InsertTestProject(t, db, cache, key, key)
+
+	pip := sdk.Pipeline{
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		Name:       "pip1",
+	}
+	require.NoError(t, pipeline.InsertPipeline(db, &pip))
+	s := sdk.NewStage("stage1")
+	s.Enabled = true
+	s.PipelineID = pip.ID
+	require.NoError(t, pipeline.InsertStage(db, s))
+	j := &sdk.Job{
+		Enabled: true,
+		Action: sdk.Action{
+			Enabled: true,
+			Name:    "job1",
+			Timeout: 60,
+		},
+	}
+	require.NoError(t, pipeline.InsertJob(db, j, s.ID, &pip))
+
+	proj, _ = project.LoadByID(db, proj.ID, project.LoadOptions.WithApplications, project.LoadOptions.WithPipelines, project.LoadOptions.WithEnvironments, project.LoadOptions.WithGroups)
+
+	w := sdk.Workflow{
+		Name:       "test_1",
+		ProjectID:  proj.ID,
+		ProjectKey: proj.Key,
+		WorkflowData: sdk.WorkflowData{
+			Node: sdk.Node{
+				Name: "node1",
+				Ref:  "node1",
+				Type: sdk.NodeTypePipeline,
+				Context: &sdk.NodeContext{
+					PipelineID: pip.ID,
+				},
+			},
+		},
+	}
+	require.NoError(t, workflow.Insert(context.TODO(), db, cache, *proj, &w))
+	w1, err := workflow.Load(context.TODO(), db, cache, *proj, "test_1", workflow.LoadOptions{
+		DeepPipeline: true,
+	})
+	require.NoError(t, err)
+	consumer, _ := authentication.LoadConsumerByTypeAndUserID(context.TODO(), db, sdk.ConsumerLocal, u.ID, authentication.LoadConsumerOptions.WithAuthentifiedUser)
+
+	wr, err := workflow.CreateRun(db, w1, nil, u)
+	require.NoError(t, err)
+	wr.Workflow = *w1
+	_, err = workflow.StartWorkflowRun(context.TODO(), db, cache, *proj, wr, &sdk.WorkflowRunPostHandlerOption{
+		Manual: &sdk.WorkflowNodeRunManual{Username: u.Username},
+	}, consumer, nil)
+	require.NoError(t, err)
+
+	lastrun, err := workflow.LoadLastRun(db, proj.Key, "test_1", workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	nodeRunID := lastrun.WorkflowNodeRuns[w1.WorkflowData.Node.ID][0].ID
+	nodeRun, err := workflow.LoadNodeRunByID(db, nodeRunID, workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	require.Len(t, nodeRun.Stages, 1)
+	require.Len(t, nodeRun.Stages[0].RunJobs, 1)
+
+	jobRun, _, err := workflow.TakeNodeJobRun(context.TODO(), db, cache, *proj, nodeRun.Stages[0].RunJobs[0].ID, "model", "worker", "1", nil, "hatchery_name")
+	require.NoError(t, err)
+
+	// The job is not timed out while its timeout is not reached
+	jobs, err := workflow.LoadTimedOutNodeJobRun(context.TODO(), db, cache, 0)
+	require.NoError(t, err)
+	for _, j := range jobs {
+		assert.NotEqual(t, jobRun.ID, j.ID)
+	}
+
+	jobRun.Start = time.Now().Add(-time.Hour)
+	require.NoError(t, workflow.UpdateNodeJobRun(context.TODO(), db, jobRun))
+
+	jobs, err = workflow.LoadTimedOutNodeJobRun(context.TODO(), db, cache, 0)
+	require.NoError(t, err)
+	var timedOutJob *sdk.WorkflowNodeJobRun
+	for i := range jobs {
+		if jobs[i].ID == jobRun.ID {
+			timedOutJob = &jobs[i]
+		}
+	}
+	require.NotNil(t, timedOutJob)
+
+	_, err = workflow.FailTimedOutNodeJobRun(context.TODO(), db, cache, loadProjectForRun, *timedOutJob)
+	require.NoError(t, err)
+
+	// The job fails like when the timeout is reported by its worker
+	nodeRun, err = workflow.LoadNodeRunByID(db, nodeRunID, workflow.LoadRunOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, sdk.StatusFail, nodeRun.Status)
+	runJob := nodeRun.Stages[0].RunJobs[0]
+	assert.Equal(t, sdk.StatusFail, runJob.Status)
+	assert.Equal(t, "⚠ Job has failed because it exceeded its timeout of 1m0s", runJob.Job.Reason)
+	var timeoutInfoFound bool
+	for _, info := range runJob.SpawnInfos {
+		if info.Message.ID == sdk.MsgSpawnInfoJobTimeout.ID {
+			timeoutInfoFound = true
+		}
+	}
+	assert.True(t, timeoutInfoFound)
+}
+
+func loadProjectForRun(_ context.Context, db gorp.SqlExecutor, _ cache.Store, projectID int64) (*sdk.Project, error) {
+	return project.LoadByID(db, projectID, project.LoadOptions.WithVariables, project.LoadOptions.WithKeys, project.LoadOptions.WithIntegrations)
+}
@@ -7,19 +7,33 @@ import (
 	"github.com/go-gorp/gorp"
 
 	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/log"
 )
 
 var baseUIURL, defaultOS, defaultArch string
 
+// LoadProjectFunc loads a project with the data needed to process a workflow run: variables, keys and integrations.
+// It is given by the API because the project package depends on the workflow package.
+type LoadProjectFunc func(ctx context.Context, db gorp.SqlExecutor, store cache.Store, projectID int64) (*sdk.Project, error)
+
 //Initialize starts goroutines for workflows
-func Initialize(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Store, uiURL, confDefaultOS, confDefaultArch string) {
+func Initialize(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc, uiURL, confDefaultOS, confDefaultArch string, confQueue QueueConfiguration) {
 	baseUIURL = uiURL
 	defaultOS = confDefaultOS
 	defaultArch = confDefaultArch
+	queueConf = confQueue
 	tickStop := time.NewTicker(30 * time.Minute)
 	tickHeart := time.NewTicker(10 * time.Second)
+	tickApproval := time.NewTicker(time.Minute)
+	tickFreeze := time.NewTicker(time.Minute)
+	tickQuota := time.NewTicker(30 * time.Second)
+	tickConcurrency := time.NewTicker(10 * time.Second)
 	defer tickHeart.Stop()
+	defer tickApproval.Stop()
+	defer tickFreeze.Stop()
+	defer tickQuota.Stop()
+	defer tickConcurrency.Stop()
 	defer tickStop.Stop()
 	db := DBFunc()
 
@@ -31,9 +45,25 @@ func Initialize(ctx context.Context, DBFunc func() *gorp.DbMap, store cache.Stor
 				return
 			}
 		case <-tickHeart.C:
-			if err := manageDeadJob(ctx, DBFunc, store); err != nil {
+			if err := manageDeadJob(ctx, DBFunc, store, loadProject); err != nil {
 				log.Warning(ctx, "workflow.manageDeadJob> Error on restartDeadJob : %v", err)
 			}
+		case <-tickApproval.C:
+			if err := manageExpiredApprovals(ctx, db); err != nil {
+				log.Warning(ctx, "workflow.manageExpiredApprovals> Error on manageExpiredApprovals : %v", err)
+			}
+		case <-tickFreeze.C:
+			if err := manageFrozenNodeRuns(ctx, db, store, loadProject); err != nil {
+				log.Warning(ctx, "workflow.manageFrozenNodeRuns> Error on manageFrozenNodeRuns : %v", err)
+			}
+		case <-tickQuota.C:
+			if err := manageQueueQuotas(ctx, db, store); err != nil {
+				log.Warning(ctx, "workflow.manageQueueQuotas> Error on manageQueueQuotas : %v", err)
+			}
+		case <-tickConcurrency.C:
+			if err := manageConcurrencyGroups(ctx, db, store, loadProject); err != nil {
+				log.Warning(ctx, "workflow.manageConcurrencyGroups> Error on manageConcurrencyGroups : %v", err)
+			}
 		case <-tickStop.C:
 			if err := stopRunsBlocked(ctx, db); err != nil {
 				log.Warning(ctx, "workflow.stopRunsBlocked> Error on stopRunsBlocked : %v", err)
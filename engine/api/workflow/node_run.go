@@ -1,6 +1,8 @@
 package workflow
 
 import (
+	"strings"
+
 	"github.com/ovh/cds/sdk"
 )
 
@@ -42,3 +44,16 @@ func MergeArtifactWithPreviousSubRun(runs []sdk.WorkflowNodeRun) []sdk.WorkflowN
 		return artifacts
 	}
 }
+
+// MergeJobParametersWithPreviousSubRun adds to the node run the outputs and the variables exported by the jobs of the previous sub run.
+// When only failed jobs are run again, the succeeded jobs are not executed and their values are taken from the previous sub run.
+func MergeJobParametersWithPreviousSubRun(nr *sdk.WorkflowNodeRun, previous sdk.WorkflowNodeRun) {
+	for _, p := range previous.BuildParameters {
+		if !strings.HasPrefix(p.Name, "cds.outputs.") && !strings.HasPrefix(p.Name, "cds.build.") {
+			continue
+		}
+		if sdk.ParameterFind(nr.BuildParameters, p.Name) == nil {
+			sdk.AddParameter(&nr.BuildParameters, p.Name, p.Type, p.Value)
+		}
+	}
+}
@@ -167,3 +167,30 @@ func TestMergeArtifactWithPreviousSubRun_NonMultipleRestartFailedJobs(t *testing
 	assert.Equal(t, "12", arts[1].MD5sum)
 	assert.Equal(t, "22", arts[2].MD5sum)
 }
+
+// Test that outputs and exported variables of the previous sub run are kept, values of the current run are not overridden
+func TestMergeJobParametersWithPreviousSubRun(t *testing.T) {
+	previous := sdk.WorkflowNodeRun{
+		BuildParameters: []sdk.Parameter{
+			{Name: "cds.version", Type: sdk.StringParameter, Value: "1"},
+			{Name: "cds.build.image", Type: sdk.StringParameter, Value: "my-image:1"},
+			{Name: "cds.outputs.build.version", Type: sdk.StringParameter, Value: "1.0.0"},
+			{Name: "cds.outputs.lint.report", Type: sdk.StringParameter, Value: "old"},
+		},
+	}
+	nr := sdk.WorkflowNodeRun{
+		BuildParameters: []sdk.Parameter{
+			{Name: "cds.version", Type: sdk.StringParameter, Value: "2"},
+			{Name: "cds.outputs.lint.report", Type: sdk.StringParameter, Value: "new"},
+		},
+	}
+
+	MergeJobParametersWithPreviousSubRun(&nr, previous)
+
+	params := sdk.ParametersToMap(nr.BuildParameters)
+	assert.Equal(t, 4, len(params))
+	assert.Equal(t, "2", params["cds.version"])
+	assert.Equal(t, "my-image:1", params["cds.build.image"])
+	assert.Equal(t, "1.0.0", params["cds.outputs.build.version"])
+	assert.Equal(t, "new", params["cds.outputs.lint.report"])
+}
@@ -45,7 +45,9 @@ func setValuesGitInBuildParameters(run *sdk.WorkflowNodeRun, vcsInfos vcsInfos)
 func checkCondition(ctx context.Context, wr *sdk.WorkflowRun, conditions sdk.WorkflowNodeConditions, params []sdk.Parameter) bool {
 	var conditionsOK bool
 	var errc error
-	if conditions.LuaScript == "" {
+	if conditions.Expression != "" {
+		conditionsOK, errc = sdk.WorkflowCheckConditionsExpression(conditions.Expression, params)
+	} else if conditions.LuaScript == "" {
 		conditionsOK, errc = sdk.WorkflowCheckConditions(conditions.PlainConditions, params)
 	} else {
 		luacheck, err := luascript.NewCheck()
@@ -115,7 +117,7 @@ func computeRunStatus(status string, counter *statusCounter) {
 	switch status {
 	case sdk.StatusSuccess:
 		counter.success++
-	case sdk.StatusBuilding, sdk.StatusWaiting:
+	case sdk.StatusBuilding, sdk.StatusWaiting, sdk.StatusWaitingApproval, sdk.StatusFrozen:
 		counter.building++
 	case sdk.StatusFail:
 		counter.failed++
@@ -0,0 +1,285 @@
+package workflow
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/interpolate"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// computeConcurrencyGroup returns the name of the concurrency group of the node run, interpolated with its build parameters
+func computeConcurrencyGroup(n *sdk.Node, nr *sdk.WorkflowNodeRun) (string, error) {
+	if n.Context == nil || n.Context.Concurrency == nil {
+		return "", nil
+	}
+	name, err := interpolate.Do(n.Context.Concurrency.Name, sdk.ParametersToMap(nr.BuildParameters))
+	if err != nil {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "unable to interpolate concurrency group %s: %v", n.Context.Concurrency.Name, err)
+	}
+	if name == "" {
+		return "", sdk.NewErrorFrom(sdk.ErrWrongRequest, "concurrency group %s is empty after interpolation", n.Context.Concurrency.Name)
+	}
+	return name, nil
+}
+
+// checkConcurrencyGroup applies the concurrency policy of the node on the older node runs of its group.
+// It returns false if the node run has to wait for the group to be released.
+func checkConcurrencyGroup(ctx context.Context, db gorp.SqlExecutor, store cache.Store, wr *sdk.WorkflowRun, n *sdk.Node, nr *sdk.WorkflowNodeRun) (*ProcessorReport, bool, error) {
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.checkConcurrencyGroup")
+	defer end()
+
+	report := new(ProcessorReport)
+
+	// Node runs of the current workflow run are never cancelled, they are only queued
+	var statusToCancel []string
+	switch n.Context.Concurrency.GetPolicy() {
+	case sdk.ConcurrencyPolicyCancelPending:
+		statusToCancel = []string{sdk.StatusWaiting}
+	case sdk.ConcurrencyPolicyCancelInProgress:
+		statusToCancel = []string{sdk.StatusWaiting, sdk.StatusBuilding}
+	}
+	if len(statusToCancel) > 0 {
+		query := `SELECT id
+		FROM workflow_node_run
+		WHERE concurrency_group = $1
+		AND id < $2
+		AND workflow_run_id <> $3
+		AND status = ANY(string_to_array($4, ',')::text[])
+		ORDER BY id ASC`
+		var ids []int64
+		if _, err := db.Select(&ids, query, nr.ConcurrencyGroup, nr.ID, nr.WorkflowRunID, strings.Join(statusToCancel, ",")); err != nil {
+			return nil, false, sdk.WrapError(err, "unable to load node runs of concurrency group %s", nr.ConcurrencyGroup)
+		}
+		for _, id := range ids {
+			r, err := cancelConcurrentNodeRun(ctx, db, store, id, fmt.Sprintf("%s#%d", wr.Workflow.Name, wr.Number), nr.ConcurrencyGroup)
+			if err != nil {
+				return nil, false, err
+			}
+			report.Merge(ctx, r)
+		}
+	}
+
+	// in this sql, we use the same rules than for the mutex: a previous node run in waiting status
+	// or another node run in building status in the group locks it
+	query := `SELECT count(1)
+	FROM workflow_node_run
+	WHERE concurrency_group = $1
+	AND (
+		(id < $2 AND status = $3)
+		OR
+		(id <> $2 AND status = $4)
+	)`
+	nb, err := db.SelectInt(query, nr.ConcurrencyGroup, nr.ID, sdk.StatusWaiting, sdk.StatusBuilding)
+	if err != nil {
+		return nil, false, sdk.WrapError(err, "unable to check concurrency group %s", nr.ConcurrencyGroup)
+	}
+	if nb > 0 {
+		log.Debug("Noderun %s processed but not executed because of concurrency group %s", n.Name, nr.ConcurrencyGroup)
+		AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeConcurrency.ID,
+			Args: []interface{}{n.Name, nr.ConcurrencyGroup},
+			Type: sdk.MsgWorkflowNodeConcurrency.Type,
+		})
+		return report, false, nil
+	}
+
+	return report, true, nil
+}
+
+// cancelConcurrentNodeRun stops a node run that was superseded by a newer node run of its concurrency group
+func cancelConcurrentNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, nodeRunID int64, by, group string) (*ProcessorReport, error) {
+	report := new(ProcessorReport)
+
+	nodeRun, err := LoadAndLockNodeRunByID(ctx, db, nodeRunID)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrLocked) {
+			log.Warning(ctx, "cancelConcurrentNodeRun> node run %d is locked, it will not be cancelled", nodeRunID)
+			return report, nil
+		}
+		return nil, err
+	}
+	if sdk.StatusIsTerminated(nodeRun.Status) {
+		return report, nil
+	}
+
+	msg := sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeConcurrencyCancel.ID,
+		Args: []interface{}{nodeRun.WorkflowNodeName, by, group},
+		Type: sdk.MsgWorkflowNodeConcurrencyCancel.Type,
+	}
+	stopInfos := sdk.SpawnInfo{
+		APITime:     time.Now(),
+		RemoteTime:  time.Now(),
+		Message:     msg,
+		UserMessage: msg.DefaultUserMessage(),
+	}
+
+	ids, err := LoadNodeJobRunIDByNodeRunID(db, nodeRun.ID)
+	if err != nil {
+		return nil, sdk.WrapError(err, "cannot load node jobs run ids")
+	}
+	for _, id := range ids {
+		njr, err := LoadAndLockNodeJobRunWait(ctx, db, store, id)
+		if err != nil {
+			return nil, sdk.WrapError(err, "cannot load node job run %d", id)
+		}
+		if err := AddSpawnInfosNodeJobRun(db, njr.WorkflowNodeRunID, njr.ID, []sdk.SpawnInfo{stopInfos}); err != nil {
+			return nil, sdk.WrapError(err, "cannot save spawn info job %d", njr.ID)
+		}
+		// Stopped jobs don't reprocess the node run, so the project is not needed here
+		r, err := UpdateNodeJobRunStatus(ctx, db, store, sdk.Project{}, njr, sdk.StatusStopped)
+		report.Merge(ctx, r)
+		if err != nil {
+			return nil, sdk.WrapError(err, "cannot update node job run %d", njr.ID)
+		}
+	}
+
+	stopWorkflowNodeRunStages(ctx, db, nodeRun)
+	nodeRun.Status = sdk.StatusStopped
+	nodeRun.Done = time.Now()
+	if err := UpdateNodeRun(db, nodeRun); err != nil {
+		return nil, sdk.WrapError(err, "cannot update node run %d", nodeRun.ID)
+	}
+	if err := DeleteNodeJobRuns(db, nodeRun.ID); err != nil {
+		return nil, sdk.WrapError(err, "unable to delete node %d job runs", nodeRun.ID)
+	}
+	report.Add(ctx, *nodeRun)
+
+	wr, err := LoadRunByID(db, nodeRun.WorkflowRunID, LoadRunOptions{})
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to load workflow run %d", nodeRun.WorkflowRunID)
+	}
+	AddWorkflowRunInfo(wr, msg)
+	if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+		return nil, sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+	}
+	r, err := ResyncWorkflowRunStatus(ctx, db, wr)
+	report.Merge(ctx, r)
+	if err != nil {
+		return nil, sdk.WrapError(err, "unable to resync workflow run %d status", wr.ID)
+	}
+
+	return report, nil
+}
+
+// releaseConcurrencyGroup executes the oldest node run waiting for the given concurrency group, if the group is free.
+// A node run of another project is executed by manageConcurrencyGroups, that loads its project.
+func releaseConcurrencyGroup(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj sdk.Project, group string) (*ProcessorReport, error) {
+	_, next := observability.Span(ctx, "workflow.releaseConcurrencyGroup")
+	defer next()
+
+	waitingRun, workflowRun, err := loadAndLockConcurrencyGroupNextRun(ctx, db, group)
+	if err != nil || waitingRun == nil {
+		return nil, err
+	}
+	if workflowRun.ProjectID != proj.ID {
+		return nil, nil
+	}
+	return executeConcurrencyGroupRun(ctx, db, store, proj, group, waitingRun, workflowRun)
+}
+
+// manageConcurrencyGroups executes the node runs waiting for a concurrency group that is free
+func manageConcurrencyGroups(ctx context.Context, db *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc) error {
+	query := `SELECT DISTINCT concurrency_group
+	FROM workflow_node_run
+	WHERE status = $1 AND concurrency_group IS NOT NULL AND concurrency_group <> ''`
+	var groups []string
+	if _, err := db.Select(&groups, query, sdk.StatusWaiting); err != nil {
+		return sdk.WrapError(err, "unable to load concurrency groups")
+	}
+
+	for _, group := range groups {
+		if err := releaseConcurrencyGroupWithProject(ctx, db, store, loadProject, group); err != nil {
+			log.Error(ctx, "manageConcurrencyGroups> unable to release concurrency group %s: %v", group, err)
+		}
+	}
+	return nil
+}
+
+func releaseConcurrencyGroupWithProject(ctx context.Context, db *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc, group string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer tx.Rollback() // nolint
+
+	waitingRun, workflowRun, err := loadAndLockConcurrencyGroupNextRun(ctx, tx, group)
+	if err != nil || waitingRun == nil {
+		return err
+	}
+
+	proj, err := loadProject(ctx, tx, store, workflowRun.ProjectID)
+	if err != nil {
+		return sdk.WrapError(err, "unable to load project %d", workflowRun.ProjectID)
+	}
+	if _, err := executeConcurrencyGroupRun(ctx, tx, store, *proj, group, waitingRun, workflowRun); err != nil {
+		return err
+	}
+	return sdk.WithStack(tx.Commit())
+}
+
+// loadAndLockConcurrencyGroupNextRun returns the oldest node run waiting for the given concurrency group and its workflow run,
+// or nil if the group is not free or if the node run is locked.
+func loadAndLockConcurrencyGroupNextRun(ctx context.Context, db gorp.SqlExecutor, group string) (*sdk.WorkflowNodeRun, *sdk.WorkflowRun, error) {
+	nbBuilding, err := db.SelectInt(`SELECT count(1) FROM workflow_node_run WHERE concurrency_group = $1 AND status = $2`, group, sdk.StatusBuilding)
+	if err != nil {
+		return nil, nil, sdk.WrapError(err, "unable to check concurrency group %s", group)
+	}
+	if nbBuilding > 0 {
+		return nil, nil, nil
+	}
+
+	waitingRunID, err := db.SelectInt(`SELECT id FROM workflow_node_run WHERE concurrency_group = $1 AND status = $2 ORDER BY id ASC LIMIT 1`, group, sdk.StatusWaiting)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, nil, sdk.WrapError(err, "unable to load workflow node run waiting for concurrency group %s", group)
+	}
+	if waitingRunID == 0 {
+		return nil, nil, nil
+	}
+
+	// The node run can be released at the same time by the end of a node run and by manageConcurrencyGroups
+	waitingRun, err := LoadAndLockNodeRunByID(ctx, db, waitingRunID)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrLocked) {
+			return nil, nil, nil
+		}
+		return nil, nil, sdk.WrapError(err, "unable to load workflow node run %d waiting for concurrency group %s", waitingRunID, group)
+	}
+	if waitingRun.Status != sdk.StatusWaiting {
+		return nil, nil, nil
+	}
+
+	workflowRun, err := LoadRunByID(db, waitingRun.WorkflowRunID, LoadRunOptions{})
+	if err != nil {
+		return nil, nil, sdk.WrapError(err, "unable to load workflow run %d waiting for concurrency group %s", waitingRun.WorkflowRunID, group)
+	}
+	return waitingRun, workflowRun, nil
+}
+
+func executeConcurrencyGroupRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj sdk.Project, group string, waitingRun *sdk.WorkflowNodeRun, workflowRun *sdk.WorkflowRun) (*ProcessorReport, error) {
+	AddWorkflowRunInfo(workflowRun, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeConcurrencyRelease.ID,
+		Args: []interface{}{waitingRun.WorkflowNodeName, group},
+		Type: sdk.MsgWorkflowNodeConcurrencyRelease.Type,
+	})
+	if err := UpdateWorkflowRun(ctx, db, workflowRun); err != nil {
+		return nil, sdk.WrapError(err, "unable to update workflow run %d after concurrency group release", workflowRun.ID)
+	}
+
+	log.Debug("workflow.releaseConcurrencyGroup> process the node run %d because concurrency group %s has been released", waitingRun.ID, group)
+	r, err := executeNodeRun(ctx, db, store, proj, waitingRun)
+	if err != nil {
+		return r, sdk.WrapError(err, "unable to reprocess workflow")
+	}
+	return r, nil
+}
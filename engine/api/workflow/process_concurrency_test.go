@@ -0,0 +1,29 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func Test_computeConcurrencyGroup(t *testing.T) {
+	nr := &sdk.WorkflowNodeRun{
+		BuildParameters: []sdk.Parameter{{Name: "cds.env.name", Type: sdk.StringParameter, Value: "prod"}},
+	}
+
+	group, err := computeConcurrencyGroup(&sdk.Node{Context: &sdk.NodeContext{}}, nr)
+	require.NoError(t, err)
+	assert.Empty(t, group)
+
+	n := &sdk.Node{Context: &sdk.NodeContext{Concurrency: &sdk.NodeConcurrency{Name: "deploy-{{.cds.env.name}}"}}}
+	group, err = computeConcurrencyGroup(n, nr)
+	require.NoError(t, err)
+	assert.Equal(t, "deploy-prod", group)
+	assert.Equal(t, sdk.ConcurrencyPolicyQueue, n.Context.Concurrency.GetPolicy())
+
+	assert.Error(t, sdk.NodeConcurrency{Name: "deploy", Policy: "cancel"}.IsValid())
+	assert.Error(t, sdk.NodeConcurrency{Policy: sdk.ConcurrencyPolicyCancelPending}.IsValid())
+}
@@ -0,0 +1,172 @@
+package workflow
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/environment"
+	"github.com/ovh/cds/engine/api/group"
+	"github.com/ovh/cds/engine/api/observability"
+	"github.com/ovh/cds/engine/api/user"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+const freezeDateLayout = "2006-01-02 15:04 MST"
+
+// getEnvironmentFreeze returns the freeze window of the node environment active for the node run and its end, if any.
+// A node run manually started by a member of the override group of the freeze window is not frozen.
+func getEnvironmentFreeze(ctx context.Context, db gorp.SqlExecutor, wr *sdk.WorkflowRun, n *sdk.Node, nr *sdk.WorkflowNodeRun) (*sdk.EnvironmentFreeze, time.Time, error) {
+	if n.Context == nil || n.Context.EnvironmentID == 0 || n.Context.EnvironmentID == sdk.DefaultEnv.ID {
+		return nil, time.Time{}, nil
+	}
+
+	freezes, err := environment.LoadFreezes(db, n.Context.EnvironmentID)
+	if err != nil {
+		return nil, time.Time{}, sdk.WrapError(err, "unable to load freeze windows of environment %d", n.Context.EnvironmentID)
+	}
+	freeze, until := freezes.Active(time.Now())
+	if freeze == nil {
+		return nil, time.Time{}, nil
+	}
+
+	if freeze.OverrideGroup != "" && nr.Manual != nil && nr.Manual.Username != "" {
+		isMember, err := isGroupMember(ctx, db, freeze.OverrideGroup, nr.Manual.Username)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if isMember {
+			AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+				ID:   sdk.MsgWorkflowNodeFreezeOverridden.ID,
+				Args: []interface{}{freeze.Name, wr.Workflow.Environments[n.Context.EnvironmentID].Name, nr.Manual.Username, n.Name},
+				Type: sdk.MsgWorkflowNodeFreezeOverridden.Type,
+			})
+			return nil, time.Time{}, nil
+		}
+	}
+
+	return freeze, until, nil
+}
+
+func isGroupMember(ctx context.Context, db gorp.SqlExecutor, groupName, username string) (bool, error) {
+	g, err := group.LoadByName(ctx, db, groupName)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	u, err := user.LoadByUsername(ctx, db, username)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrUserNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if _, err := group.LoadLinkGroupUserForGroupIDAndUserID(ctx, db, g.ID, u.ID); err != nil {
+		if sdk.ErrorIs(err, sdk.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// addEnvironmentFreezeInfo explains to the users why the node run is held or refused
+func addEnvironmentFreezeInfo(wr *sdk.WorkflowRun, n *sdk.Node, freeze *sdk.EnvironmentFreeze, until time.Time) {
+	msg := sdk.MsgWorkflowNodeFrozen
+	if freeze.GetPolicy() == sdk.EnvironmentFreezePolicyRefuse {
+		msg = sdk.MsgWorkflowNodeFreezeRefused
+	}
+	AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+		ID:   msg.ID,
+		Args: []interface{}{n.Name, freeze.Name, wr.Workflow.Environments[n.Context.EnvironmentID].Name, until.Format(freezeDateLayout)},
+		Type: msg.Type,
+	})
+}
+
+// manageFrozenNodeRuns executes all the node runs held by a freeze window that is over
+func manageFrozenNodeRuns(ctx context.Context, db *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc) error {
+	query := `SELECT workflow_node_run.id
+	FROM workflow_node_run
+	WHERE workflow_node_run.status = $1
+	ORDER BY workflow_node_run.id ASC`
+	var ids []int64
+	if _, err := db.Select(&ids, query, sdk.StatusFrozen); err != nil {
+		return sdk.WrapError(err, "unable to load frozen node runs")
+	}
+
+	for _, id := range ids {
+		if err := releaseFrozenNodeRun(ctx, db, store, loadProject, id); err != nil {
+			log.Error(ctx, "manageFrozenNodeRuns> unable to release node run %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func releaseFrozenNodeRun(ctx context.Context, db *gorp.DbMap, store cache.Store, loadProject LoadProjectFunc, nodeRunID int64) error {
+	var end func()
+	ctx, end = observability.Span(ctx, "workflow.releaseFrozenNodeRun")
+	defer end()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer tx.Rollback() // nolint
+
+	nr, err := LoadAndLockNodeRunByID(ctx, tx, nodeRunID)
+	if err != nil {
+		if sdk.ErrorIs(err, sdk.ErrLocked) {
+			return nil
+		}
+		return err
+	}
+	if nr.Status != sdk.StatusFrozen {
+		return nil
+	}
+
+	wr, err := LoadRunByID(tx, nr.WorkflowRunID, LoadRunOptions{})
+	if err != nil {
+		return err
+	}
+	n := wr.Workflow.WorkflowData.NodeByID(nr.WorkflowNodeID)
+	if n == nil {
+		return sdk.WrapError(sdk.ErrNotFound, "unable to find node %d in workflow run %d", nr.WorkflowNodeID, wr.ID)
+	}
+
+	freeze, _, err := getEnvironmentFreeze(ctx, tx, wr, n, nr)
+	if err != nil {
+		return err
+	}
+	if freeze != nil {
+		return nil
+	}
+
+	log.Debug("workflow.releaseFrozenNodeRun> freeze is over, execute node run %d", nr.ID)
+	AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+		ID:   sdk.MsgWorkflowNodeFreezeEnded.ID,
+		Args: []interface{}{nr.WorkflowNodeName, wr.Workflow.Environments[n.Context.EnvironmentID].Name},
+		Type: sdk.MsgWorkflowNodeFreezeEnded.Type,
+	})
+	nr.Status = sdk.StatusWaiting
+	if err := UpdateNodeRun(tx, nr); err != nil {
+		return sdk.WrapError(err, "unable to update node run %d", nr.ID)
+	}
+	if err := UpdateWorkflowRun(ctx, tx, wr); err != nil {
+		return sdk.WrapError(err, "unable to update workflow run %d", wr.ID)
+	}
+
+	proj, err := loadProject(ctx, tx, store, wr.ProjectID)
+	if err != nil {
+		return sdk.WrapError(err, "unable to load project %d", wr.ProjectID)
+	}
+	if _, err := executeNodeRunIfUnlocked(ctx, tx, store, *proj, wr, n, nr); err != nil {
+		return err
+	}
+
+	return sdk.WithStack(tx.Commit())
+}
@@ -76,7 +76,7 @@ func processNodeRun(ctx context.Context, db gorp.SqlExecutor, store cache.Store,
 		n := wr.Workflow.WorkflowData.NodeByID(parentNodeRuns[0].WorkflowNodeID)
 		// If fork or JOIN and No run conditions
 		if (n.Type == sdk.NodeTypeJoin || n.Type == sdk.NodeTypeFork) &&
-			(n.Context == nil || (n.Context.Conditions.LuaScript == "" && len(n.Context.Conditions.PlainConditions) == 0)) {
+			(n.Context == nil || (n.Context.Conditions.LuaScript == "" && n.Context.Conditions.Expression == "" && len(n.Context.Conditions.PlainConditions) == 0)) {
 			manual = parentNodeRuns[0].Manual
 		}
 	}
@@ -261,6 +261,13 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		setValuesGitInBuildParameters(nr, *vcsInf)
 	}
 
+	// Values of the jobs that will be copied from the previous sub run
+	if manual != nil && manual.OnlyFailedJobs {
+		if previous := previousNodeRun(wr, nr); previous != nil {
+			MergeJobParametersWithPreviousSubRun(nr, *previous)
+		}
+	}
+
 	// CONDITION
 	if !checkCondition(ctx, wr, n.Context.Conditions, nr.BuildParameters) {
 		log.Debug("Conditions failed on processNode %d/%d", wr.ID, n.ID)
@@ -269,6 +276,18 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		return nil, false, nil
 	}
 
+	// Compute the concurrency group with the build parameters
+	concurrencyGroup, err := computeConcurrencyGroup(n, nr)
+	if err != nil {
+		AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowError.ID,
+			Args: []interface{}{sdk.ExtractHTTPError(err, "").Error()},
+			Type: sdk.MsgWorkflowError.Type,
+		})
+		return nil, false, err
+	}
+	nr.ConcurrencyGroup = concurrencyGroup
+
 	// Resync vcsInfos if we dont call func getVCSInfos
 	if !needVCSInfo {
 		vcsInf = &vcsInfos{}
@@ -317,6 +336,31 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		}
 	}
 
+	// A node with an approval gate waits for its approvers before being executed
+	if n.Context.Approval != nil && nr.Status == sdk.StatusWaiting {
+		nr.Status = sdk.StatusWaitingApproval
+	}
+
+	// A node run on a frozen environment is refused or held until the end of the freeze,
+	// the freeze of a node with an approval gate is checked once it is approved
+	if nr.Status == sdk.StatusWaiting || nr.Status == sdk.StatusWaitingApproval {
+		freeze, until, err := getEnvironmentFreeze(ctx, db, wr, n, nr)
+		if err != nil {
+			return nil, false, err
+		}
+		if freeze != nil {
+			switch {
+			case freeze.GetPolicy() == sdk.EnvironmentFreezePolicyRefuse:
+				addEnvironmentFreezeInfo(wr, n, freeze, until)
+				nr.Status = sdk.StatusStopped
+				nr.Done = time.Now()
+			case nr.Status == sdk.StatusWaiting:
+				addEnvironmentFreezeInfo(wr, n, freeze, until)
+				nr.Status = sdk.StatusFrozen
+			}
+		}
+	}
+
 	if err := insertWorkflowNodeRun(db, nr); err != nil {
 		return nil, false, sdk.WrapError(err, "unable to insert run (node id : %d, node name : %s, subnumber : %d)", nr.WorkflowNodeID, nr.WorkflowNodeName, nr.SubNumber)
 	}
@@ -352,6 +396,36 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		return nil, false, sdk.WrapError(err, "unable to update workflow run")
 	}
 
+	//Check the approval gate to know if we have to wait for the approvers
+	if nr.Status == sdk.StatusWaitingApproval {
+		AddWorkflowRunInfo(wr, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowNodeWaitingApproval.ID,
+			Args: []interface{}{n.Name, n.Context.Approval.GetMinApprovals(), strings.Join(n.Context.Approval.Groups, ", ")},
+			Type: sdk.MsgWorkflowNodeWaitingApproval.Type,
+		})
+		if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+			return nil, false, sdk.WrapError(err, "unable to update workflow run")
+		}
+		return report, true, nil
+	}
+
+	// A frozen node run is executed by the workflow ticker at the end of the freeze
+	if nr.Status == sdk.StatusFrozen {
+		return report, true, nil
+	}
+
+	r1, err := executeNodeRunIfUnlocked(ctx, db, store, proj, wr, n, nr)
+	if err != nil {
+		return nil, false, err
+	}
+	report.Merge(ctx, r1)
+	return report, true, nil
+}
+
+// executeNodeRunIfUnlocked executes the node run if its mutex and its concurrency group are free
+func executeNodeRunIfUnlocked(ctx context.Context, db gorp.SqlExecutor, store cache.Store, proj sdk.Project, wr *sdk.WorkflowRun, n *sdk.Node, nr *sdk.WorkflowNodeRun) (*ProcessorReport, error) {
+	report := new(ProcessorReport)
+
 	//Check the context.mutex to know if we are allowed to run it
 	if n.Context.Mutex {
 		//Check if there are previous waiting or builing workflownoderun
@@ -373,7 +447,7 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 		)`
 		nbMutex, err := db.SelectInt(mutexQuery, n.WorkflowID, nr.ID, n.Name, sdk.StatusWaiting, sdk.StatusBuilding)
 		if err != nil {
-			return nil, false, sdk.WrapError(err, "unable to check mutexes")
+			return nil, sdk.WrapError(err, "unable to check mutexes")
 		}
 		if nbMutex > 0 {
 			log.Debug("Noderun %s processed but not executed because of mutex", n.Name)
@@ -383,23 +457,38 @@ func processNode(ctx context.Context, db gorp.SqlExecutor, store cache.Store, pr
 				Type: sdk.MsgWorkflowNodeMutex.Type,
 			})
 			if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
-				return nil, false, sdk.WrapError(err, "unable to update workflow run")
+				return nil, sdk.WrapError(err, "unable to update workflow run")
 			}
 
 			// Mutex is locked, but it is as the workflow is ok to be run (conditions ok).
 			// it's ok exit without error
-			return report, true, nil
+			return report, nil
 		}
 		//Mutex is free, continue
 	}
 
+	//Check the concurrency group to know if we are allowed to run it
+	if nr.ConcurrencyGroup != "" {
+		r, canRun, err := checkConcurrencyGroup(ctx, db, store, wr, n, nr)
+		report.Merge(ctx, r)
+		if err != nil {
+			return nil, sdk.WrapError(err, "unable to check concurrency group")
+		}
+		if !canRun {
+			if err := UpdateWorkflowRun(ctx, db, wr); err != nil {
+				return nil, sdk.WrapError(err, "unable to update workflow run")
+			}
+			return report, nil
+		}
+	}
+
 	//Execute the node run !
 	r1, err := executeNodeRun(ctx, db, store, proj, nr)
 	if err != nil {
-		return nil, false, sdk.WrapError(err, "unable to execute workflow run")
+		return nil, sdk.WrapError(err, "unable to execute workflow run")
 	}
 	report.Merge(ctx, r1)
-	return report, true, nil
+	return report, nil
 }
 
 func getParentsStatus(wr *sdk.WorkflowRun, parents []*sdk.WorkflowNodeRun) string {
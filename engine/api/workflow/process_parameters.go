@@ -13,12 +13,17 @@ import (
 	"github.com/ovh/cds/sdk/interpolate"
 )
 
-func getNodeJobRunParameters(db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, stage *sdk.Stage) ([]sdk.Parameter, *sdk.MultiError) {
-	params := run.BuildParameters
+func getNodeJobRunParameters(db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, stage *sdk.Stage, matrix sdk.JobMatrixCombination) ([]sdk.Parameter, *sdk.MultiError) {
+	// copy build parameters as each job of the stage adds its own parameters
+	params := make([]sdk.Parameter, len(run.BuildParameters))
+	copy(params, run.BuildParameters)
 	tmp := map[string]string{
 		"cds.stage": stage.Name,
 		"cds.job":   j.Action.Name,
 	}
+	for _, p := range matrix.Parameters() {
+		tmp[p.Name] = p.Value
+	}
 	errm := &sdk.MultiError{}
 
 	for k, v := range tmp {
@@ -181,6 +186,14 @@ func getParentParameters(w *sdk.WorkflowRun, nodeRuns []*sdk.WorkflowNodeRun) ([
 				parentParams = append(parentParams, param)
 				continue
 			}
+			// Job outputs can be used as is, or with the parent node name if several parents define the same output
+			if strings.HasPrefix(param.Name, "cds.outputs.") {
+				parentParams = append(parentParams, param)
+
+				param.Name = strings.Replace(param.Name, "cds.", prefix, 1)
+				parentParams = append(parentParams, param)
+				continue
+			}
 			if strings.HasPrefix(param.Name, "gerrit.") {
 				parentParams = append(parentParams, param)
 				continue
@@ -16,12 +16,16 @@ import (
 
 // processNodeJobRunRequirements returns requirements list interpolated, and true or false if at least
 // one requirement is of type "Service"
-func processNodeJobRunRequirements(ctx context.Context, db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, execsGroupIDs []int64, integrationPluginBinaries []sdk.GRPCPluginBinary) (sdk.RequirementList, bool, *sdk.Model, *sdk.MultiError) {
+func processNodeJobRunRequirements(ctx context.Context, db gorp.SqlExecutor, j sdk.Job, run *sdk.WorkflowNodeRun, matrix sdk.JobMatrixCombination, execsGroupIDs []int64, integrationPluginBinaries []sdk.GRPCPluginBinary) (sdk.RequirementList, bool, *sdk.Model, *sdk.MultiError) {
 	var requirements sdk.RequirementList
 	var errm sdk.MultiError
 	var containsService bool
 	var model string
 	var tmp = sdk.ParametersToMap(run.BuildParameters)
+	// matrix values can be used in requirements, ex: {{.cds.matrix.os}}
+	for _, p := range matrix.Parameters() {
+		tmp[p.Name] = p.Value
+	}
 
 	pluginsRequirements := []sdk.Requirement{}
 	for i := range integrationPluginBinaries {
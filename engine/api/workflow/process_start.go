@@ -128,7 +128,7 @@ func processAllJoins(ctx context.Context, db gorp.SqlExecutor, store cache.Store
 			}
 
 			// If there is no conditions on join, keep default condition ( only continue on success )
-			if j.Context == nil || (len(j.Context.Conditions.PlainConditions) == 0 && j.Context.Conditions.LuaScript == "") {
+			if j.Context == nil || (len(j.Context.Conditions.PlainConditions) == 0 && j.Context.Conditions.LuaScript == "" && j.Context.Conditions.Expression == "") {
 				if nodeRun.Status == sdk.StatusFail || nodeRun.Status == sdk.StatusNeverBuilt || nodeRun.Status == sdk.StatusStopped {
 					ok = false
 					break
@@ -0,0 +1,124 @@
+package workflow
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// QueueConfiguration contains the settings used to order and limit the jobs of the queue
+type QueueConfiguration struct {
+	FairShareWeights    map[string]float64        `toml:"fairShareWeights" comment:"Weight of projects when the queue is shared between projects, by project key (default: 1).\n A project with a weight of 2 gets twice as many jobs started as a project with a weight of 1.\n Example: { MYPROJ = 2.0 }" json:"fairShareWeights"`
+	ProjectQuotas       map[string]sdk.QueueQuota `toml:"projectQuotas" comment:"Limits of the building jobs of a project, by project key.\n Example: { MYPROJ = { maxJobs = 20, maxMemory = 40960, maxModels = 10 } }" json:"projectQuotas"`
+	GroupQuotas         map[string]sdk.QueueQuota `toml:"groupQuotas" comment:"Limits of the building jobs that can be executed by a group, by group name.\n Example: { mygroup = { maxJobs = 50 } }" json:"groupQuotas"`
+	DefaultWorkerMemory int64                     `toml:"defaultWorkerMemory" default:"1024" comment:"Memory counted in quotas for a job without memory requirement, in MB" json:"defaultWorkerMemory"`
+}
+
+var queueConf QueueConfiguration
+
+// queueFairShareWindowFactor is the ratio between the count of jobs loaded from the database and the count of
+// jobs returned when the queue is limited. Waiting jobs outside this window are not ranked.
+const queueFairShareWindowFactor = 10
+
+// loadQueueFairShare returns the count of building jobs and the weight of each project
+func loadQueueFairShare(db gorp.SqlExecutor) (map[int64]int, map[int64]float64, error) {
+	running := make(map[int64]int)
+	rows, err := db.Query(`
+	SELECT project_id, COUNT(id)
+	FROM workflow_node_run_job
+	WHERE status = $1
+	GROUP BY project_id`, sdk.StatusBuilding)
+	if err != nil {
+		return nil, nil, sdk.WithStack(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var projectID int64
+		var count int
+		if err := rows.Scan(&projectID, &count); err != nil {
+			return nil, nil, sdk.WithStack(err)
+		}
+		running[projectID] = count
+	}
+
+	weights := make(map[int64]float64, len(queueConf.FairShareWeights))
+	if len(queueConf.FairShareWeights) == 0 {
+		return running, weights, nil
+	}
+	keys := make([]string, 0, len(queueConf.FairShareWeights))
+	for k := range queueConf.FairShareWeights {
+		keys = append(keys, k)
+	}
+	rowsProject, err := db.Query(`SELECT id, projectkey FROM project WHERE projectkey = ANY(string_to_array($1, ','))`, strings.Join(keys, ","))
+	if err != nil {
+		return nil, nil, sdk.WithStack(err)
+	}
+	defer rowsProject.Close()
+	for rowsProject.Next() {
+		var projectID int64
+		var key string
+		if err := rowsProject.Scan(&projectID, &key); err != nil {
+			return nil, nil, sdk.WithStack(err)
+		}
+		weights[projectID] = queueConf.FairShareWeights[key]
+	}
+	return running, weights, nil
+}
+
+// sortQueue orders the waiting jobs by priority then by fair share between projects, jobs are expected in queued order.
+// For a project, the score of its nth waiting job is (building jobs + n) / weight: the job is taken first
+// if its project has fewer jobs running or waiting before it. Waiting jobs are ranked, other jobs are kept at the end.
+func sortQueue(jobs []sdk.WorkflowNodeJobRun, running map[int64]int, weights map[int64]float64) {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		wi, wj := jobs[i].Status == sdk.StatusWaiting, jobs[j].Status == sdk.StatusWaiting
+		if wi != wj {
+			return wi
+		}
+		return wi && jobs[i].Priority > jobs[j].Priority
+	})
+
+	var nbWaiting int
+	for nbWaiting < len(jobs) && jobs[nbWaiting].Status == sdk.StatusWaiting {
+		nbWaiting++
+	}
+	waiting := jobs[:nbWaiting]
+
+	scores := make([]float64, len(waiting))
+	counts := make(map[int64]int)
+	for i, j := range waiting {
+		weight, has := weights[j.ProjectID]
+		if !has || weight <= 0 {
+			weight = 1
+		}
+		counts[j.ProjectID]++
+		scores[i] = float64(running[j.ProjectID]+counts[j.ProjectID]) / weight
+	}
+
+	sort.Stable(waitingJobs{jobs: waiting, scores: scores})
+
+	for i := range waiting {
+		waiting[i].QueueRank = i + 1
+	}
+}
+
+type waitingJobs struct {
+	jobs   []sdk.WorkflowNodeJobRun
+	scores []float64
+}
+
+func (w waitingJobs) Len() int { return len(w.jobs) }
+
+func (w waitingJobs) Swap(i, j int) {
+	w.jobs[i], w.jobs[j] = w.jobs[j], w.jobs[i]
+	w.scores[i], w.scores[j] = w.scores[j], w.scores[i]
+}
+
+func (w waitingJobs) Less(i, j int) bool {
+	if w.jobs[i].Priority != w.jobs[j].Priority {
+		return w.jobs[i].Priority > w.jobs[j].Priority
+	}
+	return w.scores[i] < w.scores[j]
+}
@@ -0,0 +1,275 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-gorp/gorp"
+
+	"github.com/ovh/cds/engine/api/cache"
+	"github.com/ovh/cds/engine/api/database/gorpmapping"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+type queueUsage struct {
+	jobs   int
+	memory int64
+	models int
+}
+
+func (u queueUsage) add(o queueUsage) queueUsage {
+	return queueUsage{jobs: u.jobs + o.jobs, memory: u.memory + o.memory, models: u.models + o.models}
+}
+
+// exceeds returns the limit of the quota exceeded by the usage, or an empty string
+func (u queueUsage) exceeds(q sdk.QueueQuota) string {
+	if q.MaxJobs > 0 && u.jobs > q.MaxJobs {
+		return fmt.Sprintf("%d building jobs", q.MaxJobs)
+	}
+	if q.MaxMemory > 0 && u.memory > q.MaxMemory {
+		return fmt.Sprintf("%d MB of worker memory", q.MaxMemory)
+	}
+	if q.MaxModels > 0 && u.models > q.MaxModels {
+		return fmt.Sprintf("%d workers spawned from a model", q.MaxModels)
+	}
+	return ""
+}
+
+// queueQuotas computes the usage of the projects and groups that have a quota
+type queueQuotas struct {
+	conf     QueueConfiguration
+	projects map[string]queueUsage
+	groups   map[string]queueUsage
+}
+
+func newQueueQuotas(conf QueueConfiguration, building []sdk.WorkflowNodeJobRun) *queueQuotas {
+	q := &queueQuotas{
+		conf:     conf,
+		projects: make(map[string]queueUsage),
+		groups:   make(map[string]queueUsage),
+	}
+	for _, j := range building {
+		q.add(j)
+	}
+	return q
+}
+
+func (q *queueQuotas) usage(j sdk.WorkflowNodeJobRun) queueUsage {
+	u := queueUsage{jobs: 1, memory: q.conf.DefaultWorkerMemory}
+	for _, r := range j.Job.Action.Requirements {
+		switch r.Type {
+		case sdk.MemoryRequirement:
+			if memory, err := strconv.ParseInt(r.Value, 10, 64); err == nil {
+				u.memory = memory
+			}
+		case sdk.ModelRequirement:
+			u.models = 1
+		}
+	}
+	return u
+}
+
+// groupNames returns the names of the groups of the job that have a quota
+func (q *queueQuotas) groupNames(j sdk.WorkflowNodeJobRun) []string {
+	var names []string
+	for _, g := range j.ExecGroups {
+		if _, has := q.conf.GroupQuotas[g.Name]; has && !sdk.IsInArray(g.Name, names) {
+			names = append(names, g.Name)
+		}
+	}
+	return names
+}
+
+func (q *queueQuotas) add(j sdk.WorkflowNodeJobRun) {
+	u := q.usage(j)
+	key := sdk.ParameterValue(j.Parameters, "cds.project")
+	if _, has := q.conf.ProjectQuotas[key]; has {
+		q.projects[key] = q.projects[key].add(u)
+	}
+	for _, name := range q.groupNames(j) {
+		q.groups[name] = q.groups[name].add(u)
+	}
+}
+
+// check returns the reason why the job can't be started, or an empty string
+func (q *queueQuotas) check(j sdk.WorkflowNodeJobRun) string {
+	u := q.usage(j)
+	key := sdk.ParameterValue(j.Parameters, "cds.project")
+	if quota, has := q.conf.ProjectQuotas[key]; has {
+		if limit := q.projects[key].add(u).exceeds(quota); limit != "" {
+			return fmt.Sprintf("project %s is limited to %s", key, limit)
+		}
+	}
+	for _, name := range q.groupNames(j) {
+		if limit := q.groups[name].add(u).exceeds(q.conf.GroupQuotas[name]); limit != "" {
+			return fmt.Sprintf("group %s is limited to %s", name, limit)
+		}
+	}
+	return ""
+}
+
+func hasQueueQuotas() bool {
+	return len(queueConf.ProjectQuotas) > 0 || len(queueConf.GroupQuotas) > 0
+}
+
+func loadBuildingNodeJobRuns(ctx context.Context, db gorp.SqlExecutor) ([]sdk.WorkflowNodeJobRun, error) {
+	query := gorpmapping.NewQuery(`SELECT workflow_node_run_job.* FROM workflow_node_run_job WHERE status = $1`).Args(sdk.StatusBuilding)
+	var sqlJobs []JobRun
+	if err := gorpmapping.GetAll(ctx, db, query, &sqlJobs); err != nil {
+		return nil, sdk.WrapError(err, "unable to load building job runs")
+	}
+	jobs := make([]sdk.WorkflowNodeJobRun, 0, len(sqlJobs))
+	for i := range sqlJobs {
+		jr, err := sqlJobs[i].WorkflowNodeRunJob()
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, jr)
+	}
+	return jobs, nil
+}
+
+// loadBookedNodeJobRuns returns the waiting jobs booked by a hatchery
+func loadBookedNodeJobRuns(ctx context.Context, db gorp.SqlExecutor, store cache.Store) ([]sdk.WorkflowNodeJobRun, error) {
+	query := gorpmapping.NewQuery(`SELECT workflow_node_run_job.* FROM workflow_node_run_job WHERE status = $1`).Args(sdk.StatusWaiting)
+	var sqlJobs []JobRun
+	if err := gorpmapping.GetAll(ctx, db, query, &sqlJobs); err != nil {
+		return nil, sdk.WrapError(err, "unable to load waiting job runs")
+	}
+	jobs := make([]sdk.WorkflowNodeJobRun, 0, len(sqlJobs))
+	for i := range sqlJobs {
+		getHatcheryInfo(ctx, store, &sqlJobs[i])
+		if sqlJobs[i].BookedBy.ID == 0 {
+			continue
+		}
+		jr, err := sqlJobs[i].WorkflowNodeRunJob()
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, jr)
+	}
+	return jobs, nil
+}
+
+// checkQueueQuotas returns the reason why each waiting job exceeds a quota, by job id.
+// Jobs are expected in queue order, booked jobs and jobs before a job in the queue are considered as started.
+func checkQueueQuotas(conf QueueConfiguration, jobs, building []sdk.WorkflowNodeJobRun) map[int64]string {
+	quotas := newQueueQuotas(conf, building)
+	for _, j := range jobs {
+		if j.Status == sdk.StatusWaiting && j.BookedBy.ID != 0 {
+			quotas.add(j)
+		}
+	}
+
+	exceeded := make(map[int64]string)
+	for _, j := range jobs {
+		if j.Status != sdk.StatusWaiting || j.BookedBy.ID != 0 {
+			continue
+		}
+		if reason := quotas.check(j); reason != "" {
+			exceeded[j.ID] = reason
+			continue
+		}
+		quotas.add(j)
+	}
+	return exceeded
+}
+
+// removeQuotaExceededJobs removes from the queue the jobs that can't be started because of a quota
+func removeQuotaExceededJobs(ctx context.Context, db gorp.SqlExecutor, jobs []sdk.WorkflowNodeJobRun) ([]sdk.WorkflowNodeJobRun, error) {
+	if !hasQueueQuotas() {
+		return jobs, nil
+	}
+	building, err := loadBuildingNodeJobRuns(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	exceeded := checkQueueQuotas(queueConf, jobs, building)
+	filtered := make([]sdk.WorkflowNodeJobRun, 0, len(jobs))
+	for _, j := range jobs {
+		if _, has := exceeded[j.ID]; !has {
+			filtered = append(filtered, j)
+		}
+	}
+	return filtered, nil
+}
+
+// CheckNodeJobRunQuotas returns an error if starting the given job exceeds a quota of its project or groups
+func CheckNodeJobRunQuotas(ctx context.Context, db gorp.SqlExecutor, store cache.Store, id int64) error {
+	if !hasQueueQuotas() {
+		return nil
+	}
+	job, err := LoadNodeJobRun(ctx, db, store, id)
+	if err != nil {
+		return err
+	}
+	building, err := loadBuildingNodeJobRuns(ctx, db)
+	if err != nil {
+		return err
+	}
+	booked, err := loadBookedNodeJobRuns(ctx, db, store)
+	if err != nil {
+		return err
+	}
+	// Like in the queue, booked jobs are considered as started
+	quotas := newQueueQuotas(queueConf, building)
+	for _, j := range booked {
+		if j.ID != job.ID {
+			quotas.add(j)
+		}
+	}
+	if reason := quotas.check(*job); reason != "" {
+		return sdk.NewErrorFrom(sdk.ErrQueueQuotaExceeded, "%s", reason)
+	}
+	return nil
+}
+
+// manageQueueQuotas adds a spawn info on the waiting jobs kept in queue because of a quota.
+// The info is added again if the reason changes or after an hour.
+func manageQueueQuotas(ctx context.Context, db *gorp.DbMap, store cache.Store) error {
+	if !hasQueueQuotas() {
+		return nil
+	}
+
+	jobs, err := LoadNodeJobRunQueue(ctx, db, store, NewQueueFilter())
+	if err != nil {
+		return err
+	}
+	building, err := loadBuildingNodeJobRuns(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	exceeded := checkQueueQuotas(queueConf, jobs, building)
+	for _, j := range jobs {
+		reason, has := exceeded[j.ID]
+		if !has {
+			continue
+		}
+		key := cache.Key("workflow", "queue", "quota", strconv.FormatInt(j.ID, 10))
+		var lastReason string
+		if _, err := store.Get(key, &lastReason); err != nil {
+			log.Warning(ctx, "manageQueueQuotas> unable to get quota info of job %d: %v", j.ID, err)
+			continue
+		}
+		if lastReason == reason {
+			continue
+		}
+
+		info := sdk.SpawnInfo{
+			RemoteTime: time.Now(),
+			Message:    sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobQuotaExceeded.ID, Args: []interface{}{reason}},
+		}
+		if err := AddSpawnInfosNodeJobRun(db, j.WorkflowNodeRunID, j.ID, PrepareSpawnInfos([]sdk.SpawnInfo{info})); err != nil {
+			log.Warning(ctx, "manageQueueQuotas> unable to add spawn info on job %d: %v", j.ID, err)
+			continue
+		}
+		if err := store.SetWithTTL(key, reason, 3600); err != nil {
+			log.Warning(ctx, "manageQueueQuotas> unable to set quota info of job %d: %v", j.ID, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestCheckQueueQuotas(t *testing.T) {
+	conf := QueueConfiguration{
+		ProjectQuotas: map[string]sdk.QueueQuota{
+			"PROJ1": {MaxJobs: 2},
+		},
+		GroupQuotas: map[string]sdk.QueueQuota{
+			"team": {MaxMemory: 4000, MaxModels: 3},
+		},
+		DefaultWorkerMemory: 1024,
+	}
+
+	newJob := func(id int64, status, project string, groups []string, reqs ...sdk.Requirement) sdk.WorkflowNodeJobRun {
+		j := sdk.WorkflowNodeJobRun{
+			ID:         id,
+			Status:     status,
+			Parameters: []sdk.Parameter{{Name: "cds.project", Type: sdk.StringParameter, Value: project}},
+		}
+		for _, g := range groups {
+			j.ExecGroups = append(j.ExecGroups, sdk.Group{Name: g})
+		}
+		j.Job.Action.Requirements = reqs
+		return j
+	}
+	model := sdk.Requirement{Type: sdk.ModelRequirement, Value: "docker-go"}
+	memory := sdk.Requirement{Type: sdk.MemoryRequirement, Value: "2048"}
+
+	building := []sdk.WorkflowNodeJobRun{
+		newJob(1, sdk.StatusBuilding, "PROJ1", nil),
+		newJob(2, sdk.StatusBuilding, "PROJ2", []string{"team"}, model),
+	}
+	jobs := []sdk.WorkflowNodeJobRun{
+		newJob(3, sdk.StatusWaiting, "PROJ1", nil),
+		newJob(4, sdk.StatusWaiting, "PROJ1", nil),
+		newJob(5, sdk.StatusWaiting, "PROJ2", []string{"team", "team"}, model, memory),
+		newJob(6, sdk.StatusWaiting, "PROJ2", []string{"team"}, model),
+		newJob(7, sdk.StatusWaiting, "PROJ3", []string{"other"}, model, memory),
+	}
+
+	exceeded := checkQueueQuotas(conf, jobs, building)
+	assert.Equal(t, map[int64]string{
+		4: "project PROJ1 is limited to 2 building jobs",
+		6: "group team is limited to 4000 MB of worker memory",
+	}, exceeded)
+
+	// Booked jobs are considered as started
+	jobs[3].BookedBy = sdk.Service{CanonicalService: sdk.CanonicalService{ID: 1}}
+	exceeded = checkQueueQuotas(conf, jobs, building)
+	assert.Equal(t, "group team is limited to 4000 MB of worker memory", exceeded[5])
+	assert.NotContains(t, exceeded, int64(6))
+
+	conf.GroupQuotas["team"] = sdk.QueueQuota{MaxModels: 2}
+	exceeded = checkQueueQuotas(conf, jobs, building)
+	assert.Equal(t, "group team is limited to 2 workers spawned from a model", exceeded[5])
+}
@@ -0,0 +1,56 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestSortQueue(t *testing.T) {
+	jobIDs := func(jobs []sdk.WorkflowNodeJobRun) []int64 {
+		ids := make([]int64, len(jobs))
+		for i := range jobs {
+			ids[i] = jobs[i].ID
+		}
+		return ids
+	}
+
+	// Project 1 launched a lot of jobs before project 2
+	jobs := []sdk.WorkflowNodeJobRun{
+		{ID: 1, ProjectID: 1, Status: sdk.StatusWaiting},
+		{ID: 2, ProjectID: 1, Status: sdk.StatusWaiting},
+		{ID: 3, ProjectID: 1, Status: sdk.StatusBuilding},
+		{ID: 4, ProjectID: 1, Status: sdk.StatusWaiting},
+		{ID: 5, ProjectID: 2, Status: sdk.StatusWaiting},
+		{ID: 6, ProjectID: 2, Status: sdk.StatusWaiting},
+	}
+	sortQueue(jobs, nil, nil)
+	assert.Equal(t, []int64{1, 5, 2, 6, 4, 3}, jobIDs(jobs))
+	assert.Equal(t, 1, jobs[0].QueueRank)
+	assert.Equal(t, 5, jobs[4].QueueRank)
+	assert.Equal(t, 0, jobs[5].QueueRank)
+
+	// Building jobs and weights are taken into account
+	jobs = []sdk.WorkflowNodeJobRun{
+		{ID: 1, ProjectID: 1, Status: sdk.StatusWaiting},
+		{ID: 2, ProjectID: 1, Status: sdk.StatusWaiting},
+		{ID: 3, ProjectID: 2, Status: sdk.StatusWaiting},
+		{ID: 4, ProjectID: 2, Status: sdk.StatusWaiting},
+	}
+	sortQueue(jobs, map[int64]int{1: 2}, nil)
+	assert.Equal(t, []int64{3, 4, 1, 2}, jobIDs(jobs))
+	sortQueue(jobs, map[int64]int{1: 2}, map[int64]float64{1: 4})
+	assert.Equal(t, []int64{1, 3, 2, 4}, jobIDs(jobs))
+
+	// Jobs with a higher priority are taken first
+	jobs = []sdk.WorkflowNodeJobRun{
+		{ID: 1, ProjectID: 1, Status: sdk.StatusWaiting},
+		{ID: 2, ProjectID: 1, Status: sdk.StatusWaiting, Priority: -10},
+		{ID: 3, ProjectID: 2, Status: sdk.StatusWaiting},
+		{ID: 4, ProjectID: 2, Status: sdk.StatusWaiting, Priority: 50},
+	}
+	sortQueue(jobs, nil, nil)
+	assert.Equal(t, []int64{4, 1, 3, 2}, jobIDs(jobs))
+}
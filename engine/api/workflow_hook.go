@@ -285,7 +285,7 @@ func (api *API) postWorkflowJobHookCallbackHandler() service.Handler {
 
 		go WorkflowSendEvent(context.Background(), api.mustDB(), api.Cache, *proj, report)
 
-		report, err = updateParentWorkflowRun(ctx, api.mustDB, api.Cache, wr)
+		report, err = updateParentWorkflowRun(ctx, api.mustDB, api.Cache, api.SharedStorage, wr)
 		if err != nil {
 			return sdk.WithStack(err)
 		}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-gorp/gorp"
@@ -18,6 +19,7 @@ import (
 	"github.com/ovh/cds/engine/api/group"
 	"github.com/ovh/cds/engine/api/metrics"
 	"github.com/ovh/cds/engine/api/notification"
+	"github.com/ovh/cds/engine/api/objectstore"
 	"github.com/ovh/cds/engine/api/observability"
 	"github.com/ovh/cds/engine/api/project"
 	"github.com/ovh/cds/engine/api/repositoriesmanager"
@@ -222,6 +224,10 @@ func (api *API) postBookWorkflowJobHandler() service.Handler {
 			return err
 		}
 
+		if err := workflow.CheckNodeJobRunQuotas(ctx, api.mustDB(), api.Cache, id); err != nil {
+			return err
+		}
+
 		if _, err := workflow.BookNodeJobRun(ctx, api.Cache, id, s); err != nil {
 			return sdk.WrapError(err, "job already booked")
 		}
@@ -346,14 +352,49 @@ func (api *API) postSpawnInfosWorkflowJobHandler() service.Handler {
 			return err
 		}
 
+		var proj *sdk.Project
+		var report *workflow.ProcessorReport
+		if jobRun.Status == sdk.StatusWaiting && containsSpawnError(s) &&
+			jobRun.Job.Action.Retry != nil && jobRun.Job.Action.Retry.Match(sdk.JobRetryOnSpawnError) {
+			requeued, err := workflow.RetryNodeJobRun(ctx, tx, api.Cache, jobRun, sdk.JobRetryOnSpawnError)
+			if err != nil {
+				return err
+			}
+			// All attempts failed to spawn a worker, the job is considered as failed
+			if !requeued {
+				proj, err = project.LoadProjectByNodeJobRunID(ctx, tx, api.Cache, id, project.LoadOptions.WithVariables)
+				if err != nil {
+					return sdk.WrapError(err, "cannot load project from job %d", id)
+				}
+				report, err = workflow.UpdateNodeJobRunStatus(ctx, tx, api.Cache, *proj, jobRun, sdk.StatusFail)
+				if err != nil {
+					return sdk.WrapError(err, "cannot update NodeJobRun %d status", id)
+				}
+			}
+		}
+
 		if err := tx.Commit(); err != nil {
 			return sdk.WithStack(err)
 		}
 
+		if proj != nil && report != nil {
+			workflow.ResyncNodeRunsWithCommits(ctx, api.mustDB(), api.Cache, *proj, report)
+			go WorkflowSendEvent(context.Background(), api.mustDB(), api.Cache, *proj, report)
+		}
+
 		return nil
 	}
 }
 
+func containsSpawnError(infos []sdk.SpawnInfo) bool {
+	for _, i := range infos {
+		if i.Message.ID == sdk.MsgSpawnInfoHatcheryErrorSpawn.ID {
+			return true
+		}
+	}
+	return false
+}
+
 func (api *API) postWorkflowJobResultHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		id, err := requestVarInt(r, "permJobID")
@@ -400,7 +441,7 @@ func (api *API) postWorkflowJobResultHandler() service.Handler {
 			observability.Tag(observability.TagProjectKey, proj.Key),
 		)
 
-		report, err := postJobResult(customCtx, api.mustDBWithCtx, api.Cache, proj, wk, &res)
+		report, err := postJobResult(customCtx, api.mustDBWithCtx, api.Cache, api.SharedStorage, proj, wk, &res)
 		if err != nil {
 			return sdk.WrapError(err, "unable to post job result")
 		}
@@ -425,7 +466,7 @@ func (api *API) postWorkflowJobResultHandler() service.Handler {
 	}
 }
 
-func postJobResult(ctx context.Context, dbFunc func(context.Context) *gorp.DbMap, store cache.Store, proj *sdk.Project, wr *sdk.Worker, res *sdk.Result) (*workflow.ProcessorReport, error) {
+func postJobResult(ctx context.Context, dbFunc func(context.Context) *gorp.DbMap, store cache.Store, sharedStorage objectstore.Driver, proj *sdk.Project, wr *sdk.Worker, res *sdk.Result) (*workflow.ProcessorReport, error) {
 	var end func()
 	ctx, end = observability.Span(ctx, "postJobResult")
 	defer end()
@@ -502,11 +543,78 @@ func postJobResult(ctx context.Context, dbFunc func(context.Context) *gorp.DbMap
 		}
 	}
 
+	// Manage job outputs, values are saved on the node to be used by next jobs and nodes as cds.outputs.<job>.<name>
+	for _, o := range res.Outputs {
+		output := job.Job.Action.Outputs.Find(o.Name)
+		if output == nil {
+			log.Warning(ctx, "postJobResult> output %s is not declared by job %d", o.Name, job.ID)
+			continue
+		}
+		if err := output.CheckValue(o.Value); err != nil {
+			msg := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobInvalidOutput.ID, Args: []interface{}{sdk.ExtractHTTPError(err, "").Message}}
+			infos := []sdk.SpawnInfo{{
+				RemoteTime:  res.RemoteTime,
+				Message:     msg,
+				UserMessage: msg.DefaultUserMessage(),
+			}}
+			if err := workflow.AddSpawnInfosNodeJobRun(tx, job.WorkflowNodeRunID, job.ID, workflow.PrepareSpawnInfos(infos)); err != nil {
+				return nil, sdk.WrapError(err, "cannot save spawn info job %d", job.ID)
+			}
+			res.Status = sdk.StatusFail
+			continue
+		}
+		sdk.ParameterAddOrSetValue(&node.BuildParameters, sdk.JobOutputParameterName(job.Job.Action.Name, o.Name), sdk.StringParameter, o.Value)
+	}
+	if res.Status == sdk.StatusSuccess {
+		var missingOutputs []string
+		for _, o := range job.Job.Action.Outputs {
+			if sdk.VariableFind(res.Outputs, o.Name) == nil {
+				missingOutputs = append(missingOutputs, o.Name)
+			}
+		}
+		if len(missingOutputs) > 0 {
+			msg := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobMissingOutputs.ID, Args: []interface{}{strings.Join(missingOutputs, ", ")}}
+			infos := []sdk.SpawnInfo{{
+				RemoteTime:  res.RemoteTime,
+				Message:     msg,
+				UserMessage: msg.DefaultUserMessage(),
+			}}
+			if err := workflow.AddSpawnInfosNodeJobRun(tx, job.WorkflowNodeRunID, job.ID, workflow.PrepareSpawnInfos(infos)); err != nil {
+				return nil, sdk.WrapError(err, "cannot save spawn info job %d", job.ID)
+			}
+			res.Status = sdk.StatusFail
+		}
+	}
+
 	if err := workflow.UpdateNodeRunBuildParameters(tx, node.ID, node.BuildParameters); err != nil {
 		return nil, sdk.WrapError(err, "unable to update node run %d", node.ID)
 	}
 	// ^ build variables are now updated on job run and on node
 
+	// Stages generated by the job are appended to the node run, the job fails if they are not valid
+	if res.Status == sdk.StatusSuccess && res.DynamicPipeline != "" {
+		var msg sdk.SpawnMsg
+		stages, err := workflow.AppendDynamicStages(ctx, tx, job, []byte(res.DynamicPipeline))
+		if err != nil {
+			httpErr := sdk.ExtractHTTPError(err, "")
+			if httpErr.Status >= http.StatusInternalServerError {
+				return nil, sdk.WrapError(err, "cannot append stages generated by job %d", job.ID)
+			}
+			msg = sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobDynamicStagesError.ID, Args: []interface{}{httpErr.Message}}
+			res.Status = sdk.StatusFail
+		} else {
+			msg = sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobDynamicStages.ID, Args: []interface{}{strings.Join(stages, ", ")}}
+		}
+		infos := []sdk.SpawnInfo{{
+			RemoteTime:  res.RemoteTime,
+			Message:     msg,
+			UserMessage: msg.DefaultUserMessage(),
+		}}
+		if err := workflow.AddSpawnInfosNodeJobRun(tx, job.WorkflowNodeRunID, job.ID, workflow.PrepareSpawnInfos(infos)); err != nil {
+			return nil, sdk.WrapError(err, "cannot save spawn info job %d", job.ID)
+		}
+	}
+
 	//Update worker status
 	if err := worker.SetStatus(ctx, tx, wr.ID, sdk.StatusWaiting); err != nil {
 		return nil, sdk.WrapError(err, "cannot update worker %s status", wr.ID)
@@ -529,7 +637,7 @@ func postJobResult(ctx context.Context, dbFunc func(context.Context) *gorp.DbMap
 
 	for i := range report.WorkflowRuns() {
 		run := &report.WorkflowRuns()[i]
-		reportParent, err := updateParentWorkflowRun(ctx, newDBFunc, store, run)
+		reportParent, err := updateParentWorkflowRun(ctx, newDBFunc, store, sharedStorage, run)
 		if err != nil {
 			return nil, sdk.WithStack(err)
 		}
@@ -787,6 +895,8 @@ func (api *API) getWorkflowJobQueueHandler() service.Handler {
 		filter.Rights = permissions
 		filter.Statuses = status
 		filter.Limit = &limit
+		// Hatcheries should not start the jobs over a quota
+		filter.SkipQuotaExceeded = isHatchery(ctx)
 		if modelType != "" {
 			filter.ModelType = []string{modelType}
 		}
@@ -17,6 +17,7 @@ import (
 	"github.com/ovh/cds/engine/api/ascode"
 	"github.com/ovh/cds/engine/api/cache"
 	"github.com/ovh/cds/engine/api/event"
+	"github.com/ovh/cds/engine/api/group"
 	"github.com/ovh/cds/engine/api/integration"
 	"github.com/ovh/cds/engine/api/objectstore"
 	"github.com/ovh/cds/engine/api/observability"
@@ -360,7 +361,7 @@ func (api *API) stopWorkflowRunHandler() service.Handler {
 			return sdk.WrapError(err, "unable to load project")
 		}
 
-		report, err := stopWorkflowRun(ctx, api.mustDB, api.Cache, proj, run, getAPIConsumer(ctx), 0)
+		report, err := stopWorkflowRun(ctx, api.mustDB, api.Cache, api.SharedStorage, proj, run, getAPIConsumer(ctx), 0)
 		if err != nil {
 			return sdk.WrapError(err, "unable to stop workflow")
 		}
@@ -397,7 +398,7 @@ func (api *API) stopWorkflowRunHandler() service.Handler {
 	}
 }
 
-func stopWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, p *sdk.Project,
+func stopWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, sharedStorage objectstore.Driver, p *sdk.Project,
 	run *sdk.WorkflowRun, ident sdk.Identifiable, parentWorkflowRunID int64) (*workflow.ProcessorReport, error) {
 	report := new(workflow.ProcessorReport)
 
@@ -448,7 +449,7 @@ func stopWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache
 					model = *m
 					run.Workflow.OutGoingHookModels[wnr.OutgoingHook.HookModelID] = *m
 				}
-				if model.Name == sdk.WorkflowModelName && wnr.Callback != nil && wnr.Callback.WorkflowRunNumber != nil {
+				if (model.Name == sdk.WorkflowModelName || model.Name == sdk.SubWorkflowModelName) && wnr.Callback != nil && wnr.Callback.WorkflowRunNumber != nil {
 					//Stop trigggered workflow
 					targetProject := wnr.OutgoingHook.Config[sdk.HookConfigTargetProject].Value
 					targetWorkflow := wnr.OutgoingHook.Config[sdk.HookConfigTargetWorkflow].Value
@@ -465,7 +466,7 @@ func stopWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache
 						continue
 					}
 
-					r2, err := stopWorkflowRun(ctx, dbFunc, store, targetProj, targetRun, ident, run.ID)
+					r2, err := stopWorkflowRun(ctx, dbFunc, store, sharedStorage, targetProj, targetRun, ident, run.ID)
 					if err != nil {
 						log.Error(ctx, "stopWorkflowRun> Unable to stop workflow %v", err)
 						continue
@@ -488,7 +489,7 @@ func stopWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache
 	}
 
 	if parentWorkflowRunID == 0 {
-		report, err := updateParentWorkflowRun(ctx, dbFunc, store, run)
+		report, err := updateParentWorkflowRun(ctx, dbFunc, store, sharedStorage, run)
 		if err != nil {
 			return nil, sdk.WithStack(err)
 		}
@@ -498,7 +499,7 @@ func stopWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache
 	return report, nil
 }
 
-func updateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, run *sdk.WorkflowRun) (*workflow.ProcessorReport, error) {
+func updateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, store cache.Store, sharedStorage objectstore.Driver, run *sdk.WorkflowRun) (*workflow.ProcessorReport, error) {
 	if !run.HasParentWorkflow() {
 		return nil, nil
 	}
@@ -527,7 +528,7 @@ func updateParentWorkflowRun(ctx context.Context, dbFunc func() *gorp.DbMap, sto
 		return nil, sdk.WrapError(err, "unable to load parent run: %v", run.RootRun().HookEvent)
 	}
 
-	report, err := workflow.UpdateParentWorkflowRun(ctx, dbFunc, store, run, *parentProj, parentWR)
+	report, err := workflow.UpdateParentWorkflowRun(ctx, dbFunc, store, sharedStorage, run, *parentProj, parentWR)
 	if err != nil {
 		return nil, sdk.WithStack(err)
 	}
@@ -653,6 +654,29 @@ func (api *API) getWorkflowCommitsHandler() service.Handler {
 	}
 }
 
+func (api *API) getWorkflowRunDiffHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		name := vars["permWorkflowName"]
+		number, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+		otherNumber, err := requestVarInt(r, "otherNumber")
+		if err != nil {
+			return err
+		}
+
+		diff, err := workflow.LoadRunDiff(ctx, api.mustDB(), api.Cache, key, name, number, otherNumber)
+		if err != nil {
+			return sdk.WrapError(err, "unable to compare workflow %s runs %d and %d", name, number, otherNumber)
+		}
+
+		return service.WriteJSON(w, diff, http.StatusOK)
+	}
+}
+
 func (api *API) stopWorkflowNodeRunHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		vars := mux.Vars(r)
@@ -750,6 +774,66 @@ func (api *API) stopWorkflowNodeRunHandler() service.Handler {
 	}
 }
 
+func (api *API) postWorkflowNodeRunApprovalHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		workflowName := vars["permWorkflowName"]
+		workflowRunNumber, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+		workflowNodeRunID, err := requestVarInt(r, "nodeRunID")
+		if err != nil {
+			return err
+		}
+
+		var req sdk.WorkflowNodeRunApprovalRequest
+		if err := service.UnmarshalBody(r, &req); err != nil {
+			return err
+		}
+
+		consumer := getAPIConsumer(ctx)
+		groups, err := group.LoadAllByIDs(ctx, api.mustDB(), consumer.GetGroupIDs())
+		if err != nil {
+			return err
+		}
+		groupNames := make([]string, len(groups))
+		for i := range groups {
+			groupNames[i] = groups[i].Name
+		}
+
+		p, err := project.Load(ctx, api.mustDB(), key, project.LoadOptions.WithVariables, project.LoadOptions.WithIntegrations)
+		if err != nil {
+			return sdk.WrapError(err, "cannot load project")
+		}
+
+		tx, err := api.mustDB().Begin()
+		if err != nil {
+			return sdk.WithStack(err)
+		}
+		defer tx.Rollback() // nolint
+
+		workflowRun, err := workflow.LoadRun(ctx, tx, p.Key, workflowName, workflowRunNumber, workflow.LoadRunOptions{})
+		if err != nil {
+			return sdk.WrapError(err, "unable to load workflow run with number %d for workflow %s", workflowRunNumber, workflowName)
+		}
+
+		report, nodeRun, err := workflow.ApproveNodeRun(ctx, tx, api.Cache, *p, workflowRun, workflowNodeRunID, consumer.GetUsername(), groupNames, req)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Commit(); err != nil {
+			return sdk.WithStack(err)
+		}
+
+		go WorkflowSendEvent(context.Background(), api.mustDB(), api.Cache, *p, report)
+
+		return service.WriteJSON(w, nodeRun, http.StatusOK)
+	}
+}
+
 func (api *API) getWorkflowNodeRunHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		vars := mux.Vars(r)
@@ -779,6 +863,76 @@ func (api *API) getWorkflowNodeRunHandler() service.Handler {
 	}
 }
 
+// postWorkflowNodeRunFailedJobsHandler runs the failed and stopped jobs of a node run again in a new sub number,
+// succeeded jobs are copied with their artifacts and outputs.
+func (api *API) postWorkflowNodeRunFailedJobsHandler() service.Handler {
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+		vars := mux.Vars(r)
+		key := vars["key"]
+		workflowName := vars["permWorkflowName"]
+		workflowRunNumber, err := requestVarInt(r, "number")
+		if err != nil {
+			return err
+		}
+		workflowNodeRunID, err := requestVarInt(r, "nodeRunID")
+		if err != nil {
+			return err
+		}
+
+		workflowRun, err := workflow.LoadRun(ctx, api.mustDB(), key, workflowName, workflowRunNumber, workflow.LoadRunOptions{})
+		if err != nil {
+			return sdk.WrapError(err, "unable to load workflow run with number %d for workflow %s", workflowRunNumber, workflowName)
+		}
+
+		var nodeRun *sdk.WorkflowNodeRun
+		for _, nrs := range workflowRun.WorkflowNodeRuns {
+			for i := range nrs {
+				if nrs[i].ID == workflowNodeRunID {
+					nodeRun = &nrs[i]
+				}
+			}
+		}
+		if nodeRun == nil {
+			return sdk.NewErrorFrom(sdk.ErrNotFound, "node run %d not found in workflow run %d", workflowNodeRunID, workflowRun.Number)
+		}
+		if nodeRun.Status != sdk.StatusFail && nodeRun.Status != sdk.StatusStopped {
+			return sdk.NewErrorFrom(sdk.ErrWrongRequest, "only the jobs of a failed or stopped pipeline can be run again, pipeline %s is %s", nodeRun.WorkflowNodeName, nodeRun.Status)
+		}
+		for _, nr := range workflowRun.WorkflowNodeRuns[nodeRun.WorkflowNodeID] {
+			if nr.SubNumber > nodeRun.SubNumber {
+				return sdk.NewErrorFrom(sdk.ErrWrongRequest, "pipeline %s has been run again in %d.%d", nodeRun.WorkflowNodeName, workflowRun.Number, nr.SubNumber)
+			}
+		}
+
+		fromNode := workflowRun.Workflow.WorkflowData.NodeByID(nodeRun.WorkflowNodeID)
+		if fromNode == nil {
+			return sdk.WrapError(sdk.ErrWorkflowNodeNotFound, "unable to find node %d", nodeRun.WorkflowNodeID)
+		}
+		c := getAPIConsumer(ctx)
+		if !permission.AccessToWorkflowNode(ctx, api.mustDB(), &workflowRun.Workflow, fromNode, c, sdk.PermissionReadExecute) {
+			return sdk.WrapError(sdk.ErrNoPermExecution, "not enough right on node %s", fromNode.Name)
+		}
+
+		opts := &sdk.WorkflowRunPostHandlerOption{
+			Number:      &workflowRun.Number,
+			FromNodeIDs: []int64{nodeRun.WorkflowNodeID},
+			Manual: &sdk.WorkflowNodeRunManual{
+				Payload:            nodeRun.Payload,
+				PipelineParameters: nodeRun.PipelineParameters,
+				OnlyFailedJobs:     true,
+			},
+		}
+
+		wf := &workflowRun.Workflow
+		workflowRun.Status = sdk.StatusWaiting
+		sdk.GoRoutine(context.Background(), fmt.Sprintf("api.initWorkflowRun-%d", workflowRun.ID), func(ctx context.Context) {
+			api.initWorkflowRun(ctx, key, wf, workflowRun, opts, c)
+		}, api.PanicDump())
+
+		return service.WriteJSON(w, workflowRun, http.StatusAccepted)
+	}
+}
+
 func (api *API) postWorkflowRunHandler() service.Handler {
 	return func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 		vars := mux.Vars(r)
@@ -840,7 +994,9 @@ func (api *API) postWorkflowRunHandler() service.Handler {
 
 			var errc error
 			var conditionsOK bool
-			if conditions.LuaScript == "" {
+			if conditions.Expression != "" {
+				conditionsOK, errc = sdk.WorkflowCheckConditionsExpression(conditions.Expression, params)
+			} else if conditions.LuaScript == "" {
 				conditionsOK, errc = sdk.WorkflowCheckConditions(conditions.PlainConditions, params)
 			} else {
 				luacheck, err := luascript.NewCheck()
@@ -1024,6 +1180,11 @@ func (api *API) initWorkflowRun(ctx context.Context, projKey string, wf *sdk.Wor
 	}
 	workflow.ResyncNodeRunsWithCommits(ctx, api.mustDB(), api.Cache, *p, report)
 
+	// Stop the previous runs on the same branch
+	if wf.AutoCancel && opts.Hook != nil && opts.Number == nil {
+		api.stopSupersededWorkflowRuns(ctx, p, wfRun, u)
+	}
+
 	// Purge workflow run
 	sdk.GoRoutine(ctx, "workflow.PurgeWorkflowRun", func(ctx context.Context) {
 		if err := workflow.PurgeWorkflowRun(ctx, api.mustDB(), *wf, api.Metrics.WorkflowRunsMarkToDelete); err != nil {
@@ -1034,7 +1195,7 @@ func (api *API) initWorkflowRun(ctx context.Context, projKey string, wf *sdk.Wor
 	// Update parent
 	for i := range report.WorkflowRuns() {
 		run := &report.WorkflowRuns()[i]
-		reportParent, err := updateParentWorkflowRun(ctx, api.mustDB, api.Cache, run)
+		reportParent, err := updateParentWorkflowRun(ctx, api.mustDB, api.Cache, api.SharedStorage, run)
 		if err != nil {
 			log.Error(ctx, "unable to update parent workflow run: %v", err)
 		}
@@ -1042,6 +1203,47 @@ func (api *API) initWorkflowRun(ctx context.Context, projKey string, wf *sdk.Wor
 	}
 }
 
+// stopSupersededWorkflowRuns stops the runs of the workflow that are still in progress on the branch of the given run.
+func (api *API) stopSupersededWorkflowRuns(ctx context.Context, p *sdk.Project, wfRun *sdk.WorkflowRun, u *sdk.AuthConsumer) {
+	var branch string
+	for _, t := range wfRun.Tags {
+		if t.Tag == "git.branch" {
+			branch = t.Value
+		}
+	}
+	if branch == "" {
+		return
+	}
+
+	runs, err := workflow.LoadRunsSupersededBy(api.mustDB(), wfRun, branch)
+	if err != nil {
+		log.Error(ctx, "stopSupersededWorkflowRuns> unable to load runs on branch %s: %v", branch, err)
+		return
+	}
+
+	for i := range runs {
+		run, err := workflow.LoadRunByID(api.mustDB(), runs[i].ID, workflow.LoadRunOptions{})
+		if err != nil {
+			log.Error(ctx, "stopSupersededWorkflowRuns> unable to load workflow run %d: %v", runs[i].ID, err)
+			continue
+		}
+
+		workflow.AddWorkflowRunInfo(run, sdk.SpawnMsg{
+			ID:   sdk.MsgWorkflowRunSuperseded.ID,
+			Args: []interface{}{wfRun.Number, branch},
+			Type: sdk.MsgWorkflowRunSuperseded.Type,
+		})
+		report, err := stopWorkflowRun(ctx, api.mustDB, api.Cache, api.SharedStorage, p, run, u, 0)
+		if err != nil {
+			log.Error(ctx, "stopSupersededWorkflowRuns> unable to stop workflow run %d: %v", run.Number, err)
+			continue
+		}
+		log.Info(ctx, "stopSupersededWorkflowRuns> workflow run %s/%s #%d stopped by run #%d on branch %s", p.Key, wfRun.Workflow.Name, run.Number, wfRun.Number, branch)
+
+		go WorkflowSendEvent(context.Background(), api.mustDB(), api.Cache, *p, report)
+	}
+}
+
 func failInitWorkflowRun(ctx context.Context, db *gorp.DbMap, wfRun *sdk.WorkflowRun, err error) *workflow.ProcessorReport {
 	report := new(workflow.ProcessorReport)
 
@@ -15,6 +15,7 @@ import (
 	"github.com/ovh/cds/engine/hatchery/kubernetes"
 	"github.com/ovh/cds/engine/hatchery/local"
 	"github.com/ovh/cds/engine/hatchery/marathon"
+	"github.com/ovh/cds/engine/hatchery/nomad"
 	"github.com/ovh/cds/engine/hatchery/openstack"
 	"github.com/ovh/cds/engine/hatchery/swarm"
 	"github.com/ovh/cds/engine/hatchery/vsphere"
@@ -76,7 +77,7 @@ For advanced usage, Debug and Tracing section can be generated as:
 	$ engine config new debug tracing [µService(s)...]
 
 All options
-	$ engine config new [debug] [tracing] [api] [hatchery:local] [hatchery:marathon] [hatchery:openstack] [hatchery:swarm] [hatchery:vsphere] [elasticsearch] [hooks] [vcs] [repositories] [migrate]
+	$ engine config new [debug] [tracing] [api] [hatchery:local] [hatchery:marathon] [hatchery:nomad] [hatchery:openstack] [hatchery:swarm] [hatchery:vsphere] [elasticsearch] [hooks] [vcs] [repositories] [migrate]
 
 `,
 
@@ -172,6 +173,14 @@ var configCheckCmd = &cobra.Command{
 			}
 		}
 
+		if conf.Hatchery != nil && conf.Hatchery.Nomad != nil && conf.Hatchery.Nomad.API.HTTP.URL != "" {
+			fmt.Printf("checking hatchery:nomad configuration...\n")
+			if err := nomad.New().CheckConfiguration(*conf.Hatchery.Nomad); err != nil {
+				fmt.Printf("hatchery:nomad Configuration: %v\n", err)
+				hasError = true
+			}
+		}
+
 		if conf.Hatchery != nil && conf.Hatchery.Swarm != nil && conf.Hatchery.Swarm.API.HTTP.URL != "" {
 			fmt.Printf("checking hatchery:swarm configuration...\n")
 			if err := swarm.New().CheckConfiguration(*conf.Hatchery.Swarm); err != nil {
@@ -19,6 +19,7 @@ import (
 	"github.com/ovh/cds/engine/hatchery/kubernetes"
 	"github.com/ovh/cds/engine/hatchery/local"
 	"github.com/ovh/cds/engine/hatchery/marathon"
+	"github.com/ovh/cds/engine/hatchery/nomad"
 	"github.com/ovh/cds/engine/hatchery/openstack"
 	"github.com/ovh/cds/engine/hatchery/swarm"
 	"github.com/ovh/cds/engine/hatchery/vsphere"
@@ -85,7 +86,7 @@ This component operates CDS CDN to handle storage
 
 Start all of this with a single command:
 
-	$ engine start [api] [cdn] [hatchery:local] [hatchery:marathon] [hatchery:openstack] [hatchery:swarm] [hatchery:vsphere] [elasticsearch] [hooks] [vcs] [repositories] [migrate] [ui]
+	$ engine start [api] [cdn] [hatchery:local] [hatchery:marathon] [hatchery:nomad] [hatchery:openstack] [hatchery:swarm] [hatchery:vsphere] [elasticsearch] [hooks] [vcs] [repositories] [migrate] [ui]
 
 All the services are using the same configuration file format.
 
@@ -185,6 +186,14 @@ See $ engine config command for more details.
 				names = append(names, conf.Hatchery.Marathon.Name)
 				types = append(types, services.TypeHatchery)
 
+			case services.TypeHatchery + ":nomad":
+				if conf.Hatchery.Nomad == nil {
+					sdk.Exit("Unable to start: missing service %s configuration", a)
+				}
+				serviceConfs = append(serviceConfs, serviceConf{arg: a, service: nomad.New(), cfg: *conf.Hatchery.Nomad})
+				names = append(names, conf.Hatchery.Nomad.Name)
+				types = append(types, services.TypeHatchery)
+
 			case services.TypeHatchery + ":openstack":
 				if conf.Hatchery.Openstack == nil {
 					sdk.Exit("Unable to start: missing service %s configuration", a)
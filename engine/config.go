@@ -25,6 +25,7 @@ import (
 	"github.com/ovh/cds/engine/hatchery/kubernetes"
 	"github.com/ovh/cds/engine/hatchery/local"
 	"github.com/ovh/cds/engine/hatchery/marathon"
+	"github.com/ovh/cds/engine/hatchery/nomad"
 	"github.com/ovh/cds/engine/hatchery/openstack"
 	"github.com/ovh/cds/engine/hatchery/swarm"
 	"github.com/ovh/cds/engine/hatchery/vsphere"
@@ -50,7 +51,7 @@ func configBootstrap(args []string) Configuration {
 	if len(args) == 0 {
 		args = []string{
 			"api", "ui", "migrate", "hooks", "vcs", "repositories", "elasticsearch",
-			"hatchery:local", "hatchery:kubernetes", "hatchery:marathon", "hatchery:openstack", "hatchery:swarm", "hatchery:vsphere",
+			"hatchery:local", "hatchery:kubernetes", "hatchery:marathon", "hatchery:nomad", "hatchery:openstack", "hatchery:swarm", "hatchery:vsphere",
 		}
 	}
 
@@ -98,6 +99,10 @@ func configBootstrap(args []string) Configuration {
 			conf.Hatchery.Marathon = &marathon.HatcheryConfiguration{}
 			defaults.SetDefaults(conf.Hatchery.Marathon)
 			conf.Hatchery.Marathon.Name = "cds-hatchery-marathon-" + namesgenerator.GetRandomNameCDS(0)
+		case services.TypeHatchery + ":nomad":
+			conf.Hatchery.Nomad = &nomad.HatcheryConfiguration{}
+			defaults.SetDefaults(conf.Hatchery.Nomad)
+			conf.Hatchery.Nomad.Name = "cds-hatchery-nomad-" + namesgenerator.GetRandomNameCDS(0)
 		case services.TypeHatchery + ":openstack":
 			conf.Hatchery.Openstack = &openstack.HatcheryConfiguration{}
 			defaults.SetDefaults(conf.Hatchery.Openstack)
@@ -474,6 +479,33 @@ func configSetStartupData(conf *Configuration) (string, error) {
 			privateKeyPEM, _ := jws.ExportPrivateKey(privateKey)
 			h.Kubernetes.RSAPrivateKey = string(privateKeyPEM)
 		}
+		if h.Nomad != nil {
+			var cfg = api.StartupConfigService{
+				ID:          sdk.UUID(),
+				Name:        "hatchery:nomad",
+				Description: "Autogenerated configuration for nomad hatchery",
+				ServiceType: services.TypeHatchery,
+			}
+
+			var c = sdk.AuthConsumer{
+				ID:          cfg.ID,
+				Name:        cfg.Name,
+				Description: cfg.Description,
+				Type:        sdk.ConsumerBuiltin,
+				Data:        map[string]string{},
+				IssuedAt:    iat,
+			}
+
+			conf.Hatchery.Nomad.API.Token, err = builtin.NewSigninConsumerToken(&c)
+			if err != nil {
+				return "", err
+			}
+
+			startupCfg.Consumers = append(startupCfg.Consumers, cfg)
+			privateKey, _ := jws.NewRandomRSAKey()
+			privateKeyPEM, _ := jws.ExportPrivateKey(privateKey)
+			h.Nomad.RSAPrivateKey = string(privateKeyPEM)
+		}
 	}
 
 	if conf.Hooks != nil {
@@ -741,6 +773,24 @@ func getInitTokenFromExistingConfiguration(conf Configuration) (string, error) {
 				ServiceType: services.TypeHatchery,
 			}
 
+			startupCfg.Consumers = append(startupCfg.Consumers, cfg)
+		}
+		if h.Nomad != nil {
+			consumerID, iat, err := builtin.CheckSigninConsumerToken(h.Nomad.API.Token)
+			if err != nil {
+				return "", fmt.Errorf("cannot parse hatchery:nomad signin token: %v", err)
+			}
+			if iat < globalIAT {
+				globalIAT = iat
+			}
+
+			var cfg = api.StartupConfigService{
+				ID:          consumerID,
+				Name:        "hatchery:nomad",
+				Description: "Autogenerated configuration for nomad hatchery",
+				ServiceType: services.TypeHatchery,
+			}
+
 			startupCfg.Consumers = append(startupCfg.Consumers, cfg)
 		}
 	}
@@ -241,12 +241,29 @@ func (h *HatcheryKubernetes) CanSpawn(ctx context.Context, model *sdk.Model, job
 			return false
 		}
 	}
+
+	cpus, err := hatchery.RequiredCPUs(requirements)
+	if err != nil {
+		log.Debug("CanSpawn> Job %d has an invalid cpu requirement: %v", jobID, err)
+		return false
+	}
+	if cpus > 0 && h.Config.MaxCPUs > 0 {
+		reservedCPUs, err := h.requestedCPUs(ctx)
+		if err != nil {
+			log.Error(ctx, "CanSpawn> unable to get cpus requested by workers: %v", err)
+			return false
+		}
+		if reservedCPUs+cpus > h.Config.MaxCPUs {
+			log.Debug("CanSpawn> Job %d requires %v cpus, %v cpus are already requested by workers. Max: %v", jobID, cpus, reservedCPUs, h.Config.MaxCPUs)
+			return false
+		}
+	}
 	return true
 }
 
 // SpawnWorker starts a new worker process
 func (h *HatcheryKubernetes) SpawnWorker(ctx context.Context, spawnArgs hatchery.SpawnArguments) error {
-	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly {
+	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly && !spawnArgs.Warm {
 		return sdk.WithStack(fmt.Errorf("no job ID and no register"))
 	}
 
@@ -260,6 +277,11 @@ func (h *HatcheryKubernetes) SpawnWorker(ctx context.Context, spawnArgs hatchery
 		logJob = fmt.Sprintf("for workflow job %d,", spawnArgs.JobID)
 	}
 
+	cpus, err := hatchery.RequiredCPUs(spawnArgs.Requirements)
+	if err != nil {
+		return err
+	}
+
 	memory := int64(h.Config.DefaultMemory)
 	for _, r := range spawnArgs.Requirements {
 		if r.Type == sdk.MemoryRequirement {
@@ -319,6 +341,9 @@ func (h *HatcheryKubernetes) SpawnWorker(ctx context.Context, spawnArgs hatchery
 	if spawnArgs.JobID > 0 {
 		envsWm["CDS_BOOKED_WORKFLOW_JOB_ID"] = fmt.Sprintf("%d", spawnArgs.JobID)
 	}
+	if cpus > 0 {
+		envsWm["CDS_MODEL_CPU"] = strconv.FormatFloat(cpus, 'f', -1, 64)
+	}
 
 	envTemplated, errEnv := sdk.TemplateEnvs(udataParam, spawnArgs.Model.ModelDocker.Envs)
 	if errEnv != nil {
@@ -432,7 +457,16 @@ func (h *HatcheryKubernetes) SpawnWorker(ctx context.Context, spawnArgs hatchery
 		podSchema.Spec.HostAliases[0].Hostnames[i+1] = strings.ToLower(serv.Name)
 	}
 
-	_, err := h.k8sClient.CoreV1().Pods(h.Config.Namespace).Create(&podSchema)
+	if err := applyModelOverrides(&podSchema, spawnArgs.Model.ModelDocker.Kubernetes); err != nil {
+		return sdk.WrapError(err, "cannot apply kubernetes overrides of model %s", spawnArgs.Model.Path())
+	}
+
+	// The cpu requirement of the job overrides the cpu request of the model
+	if cpus > 0 {
+		applyCPURequirement(&podSchema.Spec.Containers[0], cpus)
+	}
+
+	_, err = h.k8sClient.CoreV1().Pods(h.Config.Namespace).Create(&podSchema)
 
 	log.Debug("hatchery> kubernetes> SpawnWorker> %s > Pod created", spawnArgs.WorkerName)
 
@@ -476,6 +510,30 @@ func (h *HatcheryKubernetes) WorkersStartedByModel(ctx context.Context, model *s
 	return workersLen
 }
 
+// requestedCPUs returns the number of CPUs requested by the running workers of the hatchery.
+func (h *HatcheryKubernetes) requestedCPUs(ctx context.Context) (float64, error) {
+	list, err := h.k8sClient.CoreV1().Pods(h.Config.Namespace).List(metav1.ListOptions{LabelSelector: LABEL_HATCHERY_NAME})
+	if err != nil {
+		return 0, sdk.WrapError(err, "unable to list pods on namespace %s", h.Config.Namespace)
+	}
+	var milliCPUs int64
+	for _, pod := range list.Items {
+		if pod.GetLabels()[LABEL_HATCHERY_NAME] != h.Configuration().Name {
+			continue
+		}
+		if pod.Status.Phase == apiv1.PodSucceeded || pod.Status.Phase == apiv1.PodFailed {
+			continue
+		}
+		for _, c := range pod.Spec.Containers {
+			// The worker container has the name of the pod
+			if c.Name == pod.GetName() {
+				milliCPUs += c.Resources.Requests.Cpu().MilliValue()
+			}
+		}
+	}
+	return float64(milliCPUs) / 1000, nil
+}
+
 // NeedRegistration return true if worker model need regsitration
 func (h *HatcheryKubernetes) NeedRegistration(ctx context.Context, m *sdk.Model) bool {
 	if m.NeedRegistration || m.LastRegistration.Unix() < m.UserLastModified.Unix() {
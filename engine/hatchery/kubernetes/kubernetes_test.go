@@ -7,6 +7,7 @@ import (
 	"gopkg.in/h2non/gock.v1"
 	"io/ioutil"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"net/http"
 	"testing"
@@ -120,3 +121,51 @@ func TestHatcheryKubernetes_Status(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, gock.IsDone())
 }
+
+func TestHatcheryKubernetes_CanSpawnCPURequirement(t *testing.T) {
+	defer gock.Off()
+	h := NewHatcheryKubernetesTest(t)
+	h.Config.MaxCPUs = 4
+
+	workerPod := func(name, hatcheryName, cpu string, phase v1.PodPhase) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: map[string]string{LABEL_HATCHERY_NAME: hatcheryName},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{
+					{
+						Name: name,
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)},
+						},
+					},
+					{
+						Name: "service-1-pg",
+						Resources: v1.ResourceRequirements{
+							Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse("1")},
+						},
+					},
+				},
+			},
+			Status: v1.PodStatus{Phase: phase},
+		}
+	}
+	podsList := v1.PodList{
+		Items: []v1.Pod{
+			workerPod("w1", "kyubi", "1500m", v1.PodRunning),
+			workerPod("w2", "kyubi", "2", v1.PodSucceeded),
+			workerPod("w3", "jubi", "2", v1.PodRunning),
+		},
+	}
+	gock.New("http://lolcat.kube").Get("/api/v1/namespaces/hachibi/pods").Times(2).Reply(http.StatusOK).JSON(podsList)
+
+	assert.True(t, h.CanSpawn(context.TODO(), nil, 1, []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "2.5"}}))
+	assert.False(t, h.CanSpawn(context.TODO(), nil, 1, []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "3"}}))
+	require.True(t, gock.IsDone())
+
+	// Without max cpus, the pods are not listed
+	h.Config.MaxCPUs = 0
+	assert.True(t, h.CanSpawn(context.TODO(), nil, 1, []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "16"}}))
+}
@@ -0,0 +1,131 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ovh/cds/sdk"
+)
+
+const initContainerName = "init"
+
+// applyModelOverrides merges the kubernetes overrides of a worker model into the pod of the worker.
+// The worker container must be the first container of the pod.
+func applyModelOverrides(pod *apiv1.Pod, overrides *sdk.ModelDockerKubernetes) error {
+	if overrides == nil {
+		return nil
+	}
+	if err := overrides.IsValid(); err != nil {
+		return err
+	}
+	if len(pod.Spec.Containers) == 0 {
+		return sdk.WithStack(fmt.Errorf("missing worker container"))
+	}
+	worker := &pod.Spec.Containers[0]
+
+	if len(overrides.NodeSelector) > 0 {
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = make(map[string]string, len(overrides.NodeSelector))
+		}
+		for k, v := range overrides.NodeSelector {
+			pod.Spec.NodeSelector[k] = v
+		}
+	}
+
+	for _, t := range overrides.Tolerations {
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, apiv1.Toleration{
+			Key:               t.Key,
+			Operator:          apiv1.TolerationOperator(t.Operator),
+			Value:             t.Value,
+			Effect:            apiv1.TaintEffect(t.Effect),
+			TolerationSeconds: t.TolerationSeconds,
+		})
+	}
+
+	if overrides.CPURequest != "" {
+		q, err := resource.ParseQuantity(overrides.CPURequest)
+		if err != nil {
+			return sdk.WrapError(err, "invalid cpu request %s", overrides.CPURequest)
+		}
+		if worker.Resources.Requests == nil {
+			worker.Resources.Requests = apiv1.ResourceList{}
+		}
+		worker.Resources.Requests[apiv1.ResourceCPU] = q
+	}
+	if overrides.CPULimit != "" {
+		q, err := resource.ParseQuantity(overrides.CPULimit)
+		if err != nil {
+			return sdk.WrapError(err, "invalid cpu limit %s", overrides.CPULimit)
+		}
+		if worker.Resources.Limits == nil {
+			worker.Resources.Limits = apiv1.ResourceList{}
+		}
+		worker.Resources.Limits[apiv1.ResourceCPU] = q
+	}
+
+	if overrides.SecurityContext != nil {
+		pod.Spec.SecurityContext = &apiv1.PodSecurityContext{
+			RunAsUser:    overrides.SecurityContext.RunAsUser,
+			RunAsGroup:   overrides.SecurityContext.RunAsGroup,
+			RunAsNonRoot: overrides.SecurityContext.RunAsNonRoot,
+			FSGroup:      overrides.SecurityContext.FSGroup,
+		}
+	}
+
+	if overrides.ServiceAccount != "" {
+		pod.Spec.ServiceAccountName = overrides.ServiceAccount
+	}
+
+	var mounts []apiv1.VolumeMount
+	for _, v := range overrides.Volumes {
+		volume := apiv1.Volume{Name: v.Name}
+		switch {
+		case v.ConfigMap != "":
+			volume.ConfigMap = &apiv1.ConfigMapVolumeSource{
+				LocalObjectReference: apiv1.LocalObjectReference{Name: v.ConfigMap},
+			}
+		case v.Secret != "":
+			volume.Secret = &apiv1.SecretVolumeSource{SecretName: v.Secret}
+		default:
+			volume.EmptyDir = &apiv1.EmptyDirVolumeSource{}
+		}
+		pod.Spec.Volumes = append(pod.Spec.Volumes, volume)
+		mounts = append(mounts, apiv1.VolumeMount{
+			Name:      v.Name,
+			MountPath: v.MountPath,
+			ReadOnly:  v.ReadOnly,
+		})
+	}
+	worker.VolumeMounts = append(worker.VolumeMounts, mounts...)
+
+	if overrides.InitContainer != nil {
+		initContainer := apiv1.Container{
+			Name:         initContainerName,
+			Image:        overrides.InitContainer.Image,
+			Command:      overrides.InitContainer.Command,
+			Args:         overrides.InitContainer.Args,
+			VolumeMounts: mounts,
+		}
+		for k, v := range overrides.InitContainer.Envs {
+			initContainer.Env = append(initContainer.Env, apiv1.EnvVar{Name: k, Value: v})
+		}
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+	}
+
+	return nil
+}
+
+// applyCPURequirement sets the cpu request of the worker container, the cpu limit is raised if it
+// is lower than the request.
+func applyCPURequirement(worker *apiv1.Container, cpus float64) {
+	q := *resource.NewMilliQuantity(int64(cpus*1000), resource.DecimalSI)
+	if worker.Resources.Requests == nil {
+		worker.Resources.Requests = apiv1.ResourceList{}
+	}
+	worker.Resources.Requests[apiv1.ResourceCPU] = q
+	if limit, ok := worker.Resources.Limits[apiv1.ResourceCPU]; ok && limit.Cmp(q) < 0 {
+		worker.Resources.Limits[apiv1.ResourceCPU] = q
+	}
+}
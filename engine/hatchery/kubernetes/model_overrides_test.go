@@ -0,0 +1,122 @@
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestApplyModelOverrides(t *testing.T) {
+	newPod := func() apiv1.Pod {
+		return apiv1.Pod{
+			Spec: apiv1.PodSpec{
+				Containers: []apiv1.Container{
+					{
+						Name:  "worker",
+						Image: "golang:1.13",
+						Resources: apiv1.ResourceRequirements{
+							Requests: apiv1.ResourceList{
+								apiv1.ResourceMemory: resource.MustParse("1024"),
+							},
+						},
+					},
+					{Name: "service-1-pg", Image: "postgres:9.5"},
+				},
+			},
+		}
+	}
+
+	pod := newPod()
+	require.NoError(t, applyModelOverrides(&pod, nil))
+	assert.Equal(t, newPod(), pod)
+
+	uid, nonRoot := int64(1000), true
+	overrides := &sdk.ModelDockerKubernetes{
+		NodeSelector: map[string]string{"disktype": "ssd"},
+		Tolerations: []sdk.ModelKubernetesToleration{
+			{Key: "dedicated", Operator: sdk.KubernetesTolerationOpEqual, Value: "cds", Effect: sdk.KubernetesTaintEffectNoSchedule},
+		},
+		CPURequest:      "500m",
+		CPULimit:        "2",
+		SecurityContext: &sdk.ModelKubernetesSecurityContext{RunAsUser: &uid, RunAsNonRoot: &nonRoot},
+		ServiceAccount:  "cds-worker",
+		Volumes: []sdk.ModelKubernetesVolume{
+			{Name: "cache", MountPath: "/cache"},
+			{Name: "config", MountPath: "/etc/config", ReadOnly: true, ConfigMap: "worker-config"},
+			{Name: "certs", MountPath: "/etc/certs", ReadOnly: true, Secret: "worker-certs"},
+		},
+		InitContainer: &sdk.ModelKubernetesInitContainer{
+			Image:   "busybox",
+			Command: []string{"sh", "-c"},
+			Args:    []string{"cp -r /etc/config/* /cache"},
+			Envs:    map[string]string{"FOO": "bar"},
+		},
+	}
+	require.NoError(t, applyModelOverrides(&pod, overrides))
+
+	assert.Equal(t, map[string]string{"disktype": "ssd"}, pod.Spec.NodeSelector)
+	require.Len(t, pod.Spec.Tolerations, 1)
+	assert.Equal(t, apiv1.Toleration{Key: "dedicated", Operator: apiv1.TolerationOpEqual, Value: "cds", Effect: apiv1.TaintEffectNoSchedule}, pod.Spec.Tolerations[0])
+
+	worker := pod.Spec.Containers[0]
+	assert.Equal(t, "500m", worker.Resources.Requests.Cpu().String())
+	assert.Equal(t, "1024", worker.Resources.Requests.Memory().String())
+	assert.Equal(t, "2", worker.Resources.Limits.Cpu().String())
+	assert.Empty(t, pod.Spec.Containers[1].Resources.Requests)
+	assert.Empty(t, pod.Spec.Containers[1].VolumeMounts)
+
+	require.NotNil(t, pod.Spec.SecurityContext)
+	assert.Equal(t, &uid, pod.Spec.SecurityContext.RunAsUser)
+	assert.Equal(t, &nonRoot, pod.Spec.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, "cds-worker", pod.Spec.ServiceAccountName)
+
+	require.Len(t, pod.Spec.Volumes, 3)
+	assert.NotNil(t, pod.Spec.Volumes[0].EmptyDir)
+	require.NotNil(t, pod.Spec.Volumes[1].ConfigMap)
+	assert.Equal(t, "worker-config", pod.Spec.Volumes[1].ConfigMap.Name)
+	require.NotNil(t, pod.Spec.Volumes[2].Secret)
+	assert.Equal(t, "worker-certs", pod.Spec.Volumes[2].Secret.SecretName)
+	require.Len(t, worker.VolumeMounts, 3)
+	assert.Equal(t, apiv1.VolumeMount{Name: "config", MountPath: "/etc/config", ReadOnly: true}, worker.VolumeMounts[1])
+
+	require.Len(t, pod.Spec.InitContainers, 1)
+	initContainer := pod.Spec.InitContainers[0]
+	assert.Equal(t, "busybox", initContainer.Image)
+	assert.Equal(t, []string{"sh", "-c"}, initContainer.Command)
+	assert.Equal(t, []apiv1.EnvVar{{Name: "FOO", Value: "bar"}}, initContainer.Env)
+	assert.Equal(t, worker.VolumeMounts, initContainer.VolumeMounts)
+
+	// Invalid overrides are rejected
+	pod = newPod()
+	assert.Error(t, applyModelOverrides(&pod, &sdk.ModelDockerKubernetes{CPURequest: "two"}))
+}
+
+func TestApplyCPURequirement(t *testing.T) {
+	worker := apiv1.Container{Name: "worker"}
+	applyCPURequirement(&worker, 1.5)
+	assert.Equal(t, "1500m", worker.Resources.Requests.Cpu().String())
+	assert.Empty(t, worker.Resources.Limits)
+
+	// The limit is raised if it's lower than the cpu requirement
+	worker = apiv1.Container{
+		Name: "worker",
+		Resources: apiv1.ResourceRequirements{
+			Requests: apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("500m")},
+			Limits:   apiv1.ResourceList{apiv1.ResourceCPU: resource.MustParse("1")},
+		},
+	}
+	applyCPURequirement(&worker, 2)
+	assert.Equal(t, "2", worker.Resources.Requests.Cpu().String())
+	assert.Equal(t, "2", worker.Resources.Limits.Cpu().String())
+
+	// The limit is kept if it's greater than the cpu requirement
+	worker.Resources.Limits[apiv1.ResourceCPU] = resource.MustParse("4")
+	applyCPURequirement(&worker, 0.5)
+	assert.Equal(t, "500m", worker.Resources.Requests.Cpu().String())
+	assert.Equal(t, "4", worker.Resources.Limits.Cpu().String())
+}
@@ -31,6 +31,8 @@ type HatcheryConfiguration struct {
 	WorkerTTL int `mapstructure:"workerTTL" toml:"workerTTL" default:"10" commented:"false" comment:"Worker TTL (minutes)" json:"workerTTL"`
 	// DefaultMemory Worker default memory
 	DefaultMemory int `mapstructure:"defaultMemory" toml:"defaultMemory" default:"1024" commented:"false" comment:"Worker default memory in Mo" json:"defaultMemory"`
+	// MaxCPUs is the maximum number of CPUs requested by the workers of the hatchery
+	MaxCPUs float64 `mapstructure:"maxCPUs" toml:"maxCPUs" default:"0" commented:"true" comment:"Maximum number of CPUs requested by the workers of this hatchery, checked for jobs with a cpu requirement. 0 for no limit" json:"maxCPUs"`
 	// Namespace is the kubernetes namespace in which workers are spawned"
 	Namespace string `mapstructure:"namespace" toml:"namespace" default:"cds" commented:"false" comment:"Kubernetes namespace in which workers are spawned" json:"namespace"`
 	// KubernetesMasterURL Address of kubernetes master
@@ -0,0 +1,142 @@
+package local
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+
+	"github.com/ovh/cds/sdk"
+)
+
+// IsolatedWorkerInitCommand is the hidden command of the engine used by the hatchery to start an isolated worker.
+// The command prepares the namespaces of the worker then executes the worker binary.
+const IsolatedWorkerInitCommand = "local-worker-init"
+
+// isolatedAPIProxyAddr is the address of the proxy to CDS API started by the hatchery in the network
+// namespace of a worker without network.
+const isolatedAPIProxyAddr = "127.0.0.1:8081"
+
+// isolationSpec is given by the hatchery to the IsolatedWorkerInitCommand to prepare the mount namespace of a worker.
+type isolationSpec struct {
+	// RootDir is the directory on which the read-only view of the host is mounted
+	RootDir string `json:"root_dir"`
+	// HiddenDir is the basedir of the hatchery, it contains the basedirs of the other workers
+	HiddenDir string `json:"hidden_dir"`
+	// Basedir is the only directory of the host writable by the worker
+	Basedir  string `json:"basedir"`
+	Hostname string `json:"hostname"`
+	// UID and GID of the unprivileged user that runs the worker
+	UID int `json:"uid"`
+	GID int `json:"gid"`
+}
+
+type isolationLimits struct {
+	memory int64 // in bytes, 0 for no limit
+	cpus   float64
+}
+
+// isolatedWorker contains the resources created by the hatchery for an isolated worker.
+type isolatedWorker struct {
+	name     string
+	cfg      IsolationConfiguration
+	limits   isolationLimits
+	spec     isolationSpec
+	apiURL   string
+	insecure bool
+	cgroup   string
+	// syncPipe is closed by the hatchery when the setup of the worker is done
+	syncPipe  *os.File
+	childPipe *os.File
+	proxy     *http.Server
+}
+
+func (c IsolationConfiguration) check() error {
+	if runtime.GOOS != "linux" {
+		return fmt.Errorf("isolation of the workers is only available on Linux")
+	}
+	if !filepath.IsAbs(c.CgroupRoot) {
+		return fmt.Errorf("cgroupRoot should be an absolute path")
+	}
+	if c.DefaultMemory < 0 || c.DefaultCPUs < 0 {
+		return fmt.Errorf("defaultMemory and defaultCPUs cannot be negative")
+	}
+	if c.UID <= 0 || c.GID <= 0 {
+		return fmt.Errorf("uid and gid should be the ids of an unprivileged user")
+	}
+	return nil
+}
+
+// isolationLimitsFromRequirements returns the limits of a worker from the memory and cpu requirements of its job,
+// or from the default limits of the configuration.
+func isolationLimitsFromRequirements(cfg IsolationConfiguration, requirements []sdk.Requirement) (isolationLimits, error) {
+	l := isolationLimits{
+		memory: cfg.DefaultMemory * 1024 * 1024,
+		cpus:   cfg.DefaultCPUs,
+	}
+	for _, r := range requirements {
+		switch r.Type {
+		case sdk.MemoryRequirement:
+			memory, err := strconv.ParseInt(r.Value, 10, 64)
+			if err != nil || memory <= 0 {
+				return l, sdk.NewErrorFrom(sdk.ErrInvalidJobRequirement, "invalid memory requirement %q: it should be a positive number of MB", r.Value)
+			}
+			l.memory = memory * 1024 * 1024
+		case sdk.CPURequirement:
+			cpus, err := sdk.ParseCPURequirement(r.Value)
+			if err != nil {
+				return l, err
+			}
+			l.cpus = cpus
+		}
+	}
+	return l, nil
+}
+
+// isolatedWorkerEnv returns the environment of an isolated worker. The environment of the hatchery can contain
+// credentials, only PATH, LANG and the variables allowed by the configuration are given to the worker.
+func isolatedWorkerEnv(cfg IsolationConfiguration, basedir string) []string {
+	// The home directory of the host is read-only for an isolated worker
+	env := []string{"HOME=" + basedir}
+	for _, name := range append([]string{"PATH", "LANG"}, cfg.Env...) {
+		if value, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+func (h *HatcheryLocal) newIsolatedWorker(name, basedir string, requirements []sdk.Requirement) (*isolatedWorker, error) {
+	limits, err := isolationLimitsFromRequirements(h.Config.Isolation, requirements)
+	if err != nil {
+		return nil, err
+	}
+
+	// The basedir is the only directory of the host writable by the worker
+	if err := os.Chown(basedir, h.Config.Isolation.UID, h.Config.Isolation.GID); err != nil {
+		return nil, sdk.WrapError(err, "unable to change owner of basedir of worker %s", name)
+	}
+
+	rootDir := basedir + ".root"
+	if err := os.Mkdir(rootDir, os.FileMode(0700)); err != nil {
+		return nil, sdk.WrapError(err, "unable to create root directory of worker %s", name)
+	}
+
+	return &isolatedWorker{
+		name:     name,
+		cfg:      h.Config.Isolation,
+		limits:   limits,
+		apiURL:   h.Config.API.HTTP.URL,
+		insecure: h.Config.API.HTTP.Insecure,
+		spec: isolationSpec{
+			RootDir:   rootDir,
+			HiddenDir: h.Config.Basedir,
+			Basedir:   basedir,
+			Hostname:  name,
+			UID:       h.Config.Isolation.UID,
+			GID:       h.Config.Isolation.GID,
+		},
+	}, nil
+}
@@ -0,0 +1,516 @@
+// +build linux
+
+package local
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// cgroupCPUPeriod is the period, in microseconds, of the cpu limit of a worker
+const cgroupCPUPeriod = 100000
+
+// initIsolation checks that the hatchery can isolate its workers and creates the parent cgroup of the workers.
+func initIsolation(cfg IsolationConfiguration) error {
+	if os.Geteuid() != 0 {
+		return fmt.Errorf("the hatchery must run as root to isolate the workers")
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(cfg.CgroupRoot), "cgroup.controllers")); err != nil {
+		return sdk.WrapError(err, "cgroup v2 is not available in %s", filepath.Dir(cfg.CgroupRoot))
+	}
+	if err := os.MkdirAll(cfg.CgroupRoot, os.FileMode(0755)); err != nil {
+		return sdk.WrapError(err, "unable to create cgroup %s", cfg.CgroupRoot)
+	}
+	if err := writeCgroupFile(cfg.CgroupRoot, "cgroup.subtree_control", "+cpu +memory"); err != nil {
+		return sdk.WrapError(err, "unable to enable cpu and memory controllers in cgroup %s", cfg.CgroupRoot)
+	}
+	return nil
+}
+
+// command returns the command that starts the worker in its namespaces. The worker binary is executed by
+// the IsolatedWorkerInitCommand once the hatchery has finished the setup of the worker.
+func (w *isolatedWorker) command(ctx context.Context, runner LocalWorkerRunner, workerCmd []string) (*exec.Cmd, error) {
+	spec, err := json.Marshal(w.spec)
+	if err != nil {
+		return nil, sdk.WithStack(err)
+	}
+
+	w.childPipe, w.syncPipe, err = os.Pipe()
+	if err != nil {
+		return nil, sdk.WithStack(err)
+	}
+
+	args := append([]string{IsolatedWorkerInitCommand, string(spec)}, workerCmd...)
+	cmd := runner.NewCmd(ctx, "/proc/self/exe", args...)
+	cmd.ExtraFiles = []*os.File{w.childPipe}
+
+	flags := unix.CLONE_NEWNS | unix.CLONE_NEWPID | unix.CLONE_NEWIPC | unix.CLONE_NEWUTS
+	if w.cfg.DisableNetwork {
+		flags |= unix.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: uintptr(flags),
+		Pdeathsig:  syscall.SIGKILL,
+	}
+	return cmd, nil
+}
+
+// setup is called once the worker process is started: the process is moved in the cgroup of the worker and
+// the proxy to CDS API is started in its network namespace, then the worker binary is executed.
+func (w *isolatedWorker) setup(pid int) error {
+	w.childPipe.Close() // nolint
+
+	if err := w.createCgroup(); err != nil {
+		return err
+	}
+	if err := writeCgroupFile(w.cgroup, "cgroup.procs", strconv.Itoa(pid)); err != nil {
+		return sdk.WrapError(err, "unable to move worker %s in cgroup %s", w.name, w.cgroup)
+	}
+
+	if w.cfg.DisableNetwork {
+		if err := w.startAPIProxy(pid); err != nil {
+			return err
+		}
+	}
+
+	if _, err := w.syncPipe.Write([]byte{0}); err != nil {
+		return sdk.WrapError(err, "unable to start worker %s", w.name)
+	}
+	return sdk.WithStack(w.syncPipe.Close())
+}
+
+// release removes the resources of the worker once its process has exited.
+func (w *isolatedWorker) release(ctx context.Context) {
+	if w.childPipe != nil {
+		w.childPipe.Close() // nolint
+	}
+	if w.syncPipe != nil {
+		w.syncPipe.Close() // nolint
+	}
+	if w.proxy != nil {
+		if err := w.proxy.Close(); err != nil {
+			log.Warning(ctx, "hatchery> local> unable to stop api proxy of worker %s: %v", w.name, err)
+		}
+	}
+	if w.cgroup != "" {
+		// The processes of the cgroup can take some time to exit after the worker
+		var err error
+		for i := 0; i < 10; i++ {
+			if err = os.Remove(w.cgroup); err == nil || os.IsNotExist(err) {
+				break
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			log.Warning(ctx, "hatchery> local> unable to remove cgroup %s: %v", w.cgroup, err)
+		}
+	}
+	if err := os.Remove(w.spec.RootDir); err != nil && !os.IsNotExist(err) {
+		log.Warning(ctx, "hatchery> local> unable to remove root directory %s: %v", w.spec.RootDir, err)
+	}
+}
+
+func (w *isolatedWorker) createCgroup() error {
+	dir := filepath.Join(w.cfg.CgroupRoot, w.name)
+	if err := os.Mkdir(dir, os.FileMode(0755)); err != nil {
+		return sdk.WrapError(err, "unable to create cgroup %s", dir)
+	}
+	w.cgroup = dir
+
+	if w.limits.memory > 0 {
+		if err := writeCgroupFile(dir, "memory.max", strconv.FormatInt(w.limits.memory, 10)); err != nil {
+			return sdk.WrapError(err, "unable to set memory limit of worker %s", w.name)
+		}
+		// Swap is not available on all hosts
+		if err := writeCgroupFile(dir, "memory.swap.max", "0"); err != nil && !os.IsNotExist(sdk.Cause(err)) {
+			return sdk.WrapError(err, "unable to set swap limit of worker %s", w.name)
+		}
+	}
+	if w.limits.cpus > 0 {
+		quota := int64(w.limits.cpus * cgroupCPUPeriod)
+		if err := writeCgroupFile(dir, "cpu.max", fmt.Sprintf("%d %d", quota, cgroupCPUPeriod)); err != nil {
+			return sdk.WrapError(err, "unable to set cpu limit of worker %s", w.name)
+		}
+	}
+	return nil
+}
+
+func writeCgroupFile(dir, file, value string) error {
+	return sdk.WithStack(ioutil.WriteFile(filepath.Join(dir, file), []byte(value), os.FileMode(0644)))
+}
+
+// startAPIProxy starts a proxy to CDS API listening on the loopback of the network namespace of the worker.
+func (w *isolatedWorker) startAPIProxy(pid int) error {
+	target, err := url.Parse(w.apiURL)
+	if err != nil {
+		return sdk.WrapError(err, "invalid api url %s", w.apiURL)
+	}
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	director := proxy.Director
+	proxy.Director = func(req *http.Request) {
+		director(req)
+		req.Host = target.Host
+	}
+	proxy.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: w.insecure},
+	}
+
+	listener, err := listenInNetworkNamespace(pid, isolatedAPIProxyAddr)
+	if err != nil {
+		return sdk.WrapError(err, "unable to start api proxy of worker %s", w.name)
+	}
+
+	w.proxy = &http.Server{Handler: proxy}
+	go func() {
+		if err := w.proxy.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Error(context.Background(), "hatchery> local> api proxy of worker %s: %v", w.name, err)
+		}
+	}()
+	return nil
+}
+
+// listenInNetworkNamespace brings up the loopback interface in the network namespace of given process and
+// returns a listener on given address in this namespace.
+func listenInNetworkNamespace(pid int, addr string) (net.Listener, error) {
+	type result struct {
+		listener net.Listener
+		err      error
+	}
+	c := make(chan result, 1)
+	go func() {
+		// The thread goes back to the network namespace of the hatchery before being unlocked. A locked thread
+		// is terminated when the goroutine exits, it can be the parent of a worker that would then be killed
+		// by its parent death signal.
+		runtime.LockOSThread()
+		restored := true
+		var r result
+		r.listener, r.err = func() (net.Listener, error) {
+			hostFd, err := unix.Open(fmt.Sprintf("/proc/self/task/%d/ns/net", unix.Gettid()), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			if err != nil {
+				return nil, sdk.WithStack(err)
+			}
+			defer unix.Close(hostFd) // nolint
+			fd, err := unix.Open(fmt.Sprintf("/proc/%d/ns/net", pid), unix.O_RDONLY|unix.O_CLOEXEC, 0)
+			if err != nil {
+				return nil, sdk.WithStack(err)
+			}
+			defer unix.Close(fd) // nolint
+			if err := unix.Setns(fd, unix.CLONE_NEWNET); err != nil {
+				return nil, sdk.WithStack(err)
+			}
+			l, err := listenOnLoopback(addr)
+			if errRestore := unix.Setns(hostFd, unix.CLONE_NEWNET); errRestore != nil {
+				log.Error(context.Background(), "hatchery> local> unable to restore network namespace of the hatchery: %v", errRestore)
+				restored = false
+			}
+			return l, err
+		}()
+		// A thread that is not back in the network namespace of the hatchery is terminated with the goroutine
+		if restored {
+			runtime.UnlockOSThread()
+		}
+		c <- r
+	}()
+	r := <-c
+	return r.listener, r.err
+}
+
+// listenOnLoopback brings up the loopback interface of the current network namespace and returns a listener on
+// given address.
+func listenOnLoopback(addr string) (net.Listener, error) {
+	if err := loopbackUp(); err != nil {
+		return nil, err
+	}
+	l, err := net.Listen("tcp", addr)
+	return l, sdk.WithStack(err)
+}
+
+// loopbackUp brings up the loopback interface of the current network namespace.
+func loopbackUp() error {
+	fd, err := unix.Socket(unix.AF_INET, unix.SOCK_DGRAM|unix.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return sdk.WithStack(err)
+	}
+	defer unix.Close(fd) // nolint
+
+	// struct ifreq: the name of the interface followed by its flags
+	var ifreq [40]byte
+	copy(ifreq[:unix.IFNAMSIZ], "lo")
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCGIFFLAGS, uintptr(unsafe.Pointer(&ifreq[0]))); errno != 0 {
+		return sdk.WrapError(errno, "unable to get flags of loopback interface")
+	}
+	flags := (*uint16)(unsafe.Pointer(&ifreq[unix.IFNAMSIZ]))
+	*flags |= unix.IFF_UP
+	if _, _, errno := unix.Syscall(unix.SYS_IOCTL, uintptr(fd), unix.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&ifreq[0]))); errno != 0 {
+		return sdk.WrapError(errno, "unable to bring up loopback interface")
+	}
+	return nil
+}
+
+// InitIsolatedWorker is run by the IsolatedWorkerInitCommand in the namespaces of a worker. It waits for the
+// hatchery to finish the setup of the worker, prepares its mount namespace then executes the worker binary as
+// an unprivileged user.
+// args are the isolation spec followed by the worker command.
+func InitIsolatedWorker(args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("invalid arguments, expected: <spec> <worker binary> [args...]")
+	}
+	var spec isolationSpec
+	if err := json.Unmarshal([]byte(args[0]), &spec); err != nil {
+		return fmt.Errorf("invalid isolation spec: %v", err)
+	}
+	workerBinary := args[1]
+
+	// The hatchery closes the pipe without writing if the setup of the worker failed
+	syncPipe := os.NewFile(3, "sync")
+	if _, err := syncPipe.Read(make([]byte, 1)); err != nil {
+		return fmt.Errorf("setup of the worker aborted by the hatchery: %v", err)
+	}
+	syncPipe.Close() // nolint
+
+	if err := setupMountNamespace(spec, workerBinary); err != nil {
+		return err
+	}
+
+	hostname := spec.Hostname
+	if len(hostname) > 64 {
+		hostname = hostname[:64]
+	}
+	if err := unix.Sethostname([]byte(hostname)); err != nil {
+		return fmt.Errorf("unable to set hostname: %v", err)
+	}
+
+	if err := os.Chdir(spec.Basedir); err != nil {
+		return fmt.Errorf("unable to change directory to %s: %v", spec.Basedir, err)
+	}
+
+	// The credentials are changed for the current thread only, the worker binary has to be executed by this thread
+	runtime.LockOSThread()
+	if err := dropPrivileges(spec.UID, spec.GID); err != nil {
+		return err
+	}
+	return unix.Exec(workerBinary, args[1:], os.Environ())
+}
+
+// dropPrivileges switches the current thread to given unprivileged user and removes all its capabilities, the
+// programs executed by the worker cannot gain privileges, even with a setuid binary.
+func dropPrivileges(uid, gid int) error {
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("unable to set no new privileges: %v", err)
+	}
+	// The bounding set contains the capabilities that can be regained by executing a program
+	for c := 0; ; c++ {
+		err := unix.Prctl(unix.PR_CAPBSET_DROP, uintptr(c), 0, 0, 0)
+		if err == unix.EINVAL {
+			break // c is greater than the last capability of the kernel
+		}
+		if err != nil {
+			return fmt.Errorf("unable to drop capability %d from the bounding set: %v", c, err)
+		}
+	}
+	// Ambient capabilities are not available on old kernels
+	if err := unix.Prctl(unix.PR_CAP_AMBIENT, unix.PR_CAP_AMBIENT_CLEAR_ALL, 0, 0, 0); err != nil && err != unix.EINVAL {
+		return fmt.Errorf("unable to clear ambient capabilities: %v", err)
+	}
+
+	if err := unix.Setgroups(nil); err != nil {
+		return fmt.Errorf("unable to clear supplementary groups: %v", err)
+	}
+	if err := unix.Setresgid(gid, gid, gid); err != nil {
+		return fmt.Errorf("unable to set gid %d: %v", gid, err)
+	}
+	if err := unix.Setresuid(uid, uid, uid); err != nil {
+		return fmt.Errorf("unable to set uid %d: %v", uid, err)
+	}
+
+	// The permitted and effective capabilities are cleared when the uid changes, clear the inheritable ones
+	hdr := unix.CapUserHeader{Version: unix.LINUX_CAPABILITY_VERSION_3}
+	var data [2]unix.CapUserData
+	if err := unix.Capset(&hdr, &data[0]); err != nil {
+		return fmt.Errorf("unable to clear capabilities: %v", err)
+	}
+	return nil
+}
+
+// setupMountNamespace mounts a read-only view of the host in which only the basedir of the worker is writable, then
+// uses this view as the root of the worker. The basedirs of the hatchery and of the other workers are hidden.
+func setupMountNamespace(spec isolationSpec, workerBinary string) error {
+	root := spec.RootDir
+
+	// Do not propagate the mounts of the worker to the host
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("unable to make mounts private: %v", err)
+	}
+
+	if err := unix.Mount("/", root, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("unable to mount host in %s: %v", root, err)
+	}
+	f, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return fmt.Errorf("unable to read mounts: %v", err)
+	}
+	mounts, err := parseMountInfo(f, root)
+	f.Close() // nolint
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if err := unix.Mount("", m.path, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY|m.flags, ""); err != nil {
+			return fmt.Errorf("unable to remount %s read-only: %v", m.path, err)
+		}
+	}
+
+	// Private temporary directories and proc filesystem of the pid namespace
+	for _, dir := range []string{"/tmp", "/var/tmp", "/dev/shm"} {
+		if _, err := os.Stat(filepath.Join(root, dir)); err != nil {
+			continue
+		}
+		if err := unix.Mount("tmpfs", filepath.Join(root, dir), "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=1777"); err != nil {
+			return fmt.Errorf("unable to mount %s: %v", dir, err)
+		}
+	}
+	if err := unix.Mount("proc", filepath.Join(root, "/proc"), "proc", unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("unable to mount /proc: %v", err)
+	}
+
+	// The basedir of the hatchery can be in one of the private temporary directories
+	hidden := filepath.Join(root, spec.HiddenDir)
+	if err := os.MkdirAll(hidden, os.FileMode(0755)); err != nil {
+		return fmt.Errorf("unable to create %s: %v", spec.HiddenDir, err)
+	}
+	if err := unix.Mount("tmpfs", hidden, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "mode=0755"); err != nil {
+		return fmt.Errorf("unable to hide %s: %v", spec.HiddenDir, err)
+	}
+	if err := bindMount(spec.Basedir, filepath.Join(root, spec.Basedir), false); err != nil {
+		return err
+	}
+	if isSubPath(spec.HiddenDir, workerBinary) {
+		if err := bindMount(workerBinary, filepath.Join(root, workerBinary), true); err != nil {
+			return err
+		}
+	}
+	if err := unix.Mount("", hidden, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("unable to remount %s read-only: %v", spec.HiddenDir, err)
+	}
+
+	if err := os.Chdir(root); err != nil {
+		return fmt.Errorf("unable to change directory to %s: %v", root, err)
+	}
+	if err := unix.PivotRoot(".", "."); err != nil {
+		return fmt.Errorf("unable to change root: %v", err)
+	}
+	// The old root is stacked on the new root, detach it
+	if err := unix.Unmount(".", unix.MNT_DETACH); err != nil {
+		return fmt.Errorf("unable to unmount old root: %v", err)
+	}
+	return os.Chdir("/")
+}
+
+// bindMount mounts source on target, target is created in the tmpfs hiding the basedir of the hatchery.
+func bindMount(source, target string, readOnly bool) error {
+	fi, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("unable to mount %s: %v", source, err)
+	}
+	if fi.IsDir() {
+		err = os.MkdirAll(target, os.FileMode(0755))
+	} else if err = os.MkdirAll(filepath.Dir(target), os.FileMode(0755)); err == nil {
+		err = ioutil.WriteFile(target, nil, os.FileMode(0755))
+	}
+	if err != nil {
+		return fmt.Errorf("unable to create mount point %s: %v", target, err)
+	}
+
+	if err := unix.Mount(source, target, "", unix.MS_BIND, ""); err != nil {
+		return fmt.Errorf("unable to mount %s: %v", source, err)
+	}
+	if readOnly {
+		if err := unix.Mount("", target, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+			return fmt.Errorf("unable to remount %s read-only: %v", source, err)
+		}
+	}
+	return nil
+}
+
+func isSubPath(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, "../")
+}
+
+type mountPoint struct {
+	path  string
+	flags uintptr
+}
+
+// mountOptionFlags are the per mount options that have to be kept when a mount is remounted read-only
+var mountOptionFlags = map[string]uintptr{
+	"nosuid":     unix.MS_NOSUID,
+	"nodev":      unix.MS_NODEV,
+	"noexec":     unix.MS_NOEXEC,
+	"noatime":    unix.MS_NOATIME,
+	"nodiratime": unix.MS_NODIRATIME,
+	"relatime":   unix.MS_RELATIME,
+}
+
+// parseMountInfo returns the mount points in given directory from the content of /proc/self/mountinfo,
+// see proc(5).
+func parseMountInfo(r io.Reader, dir string) ([]mountPoint, error) {
+	var mounts []mountPoint
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 6 {
+			return nil, fmt.Errorf("invalid mountinfo line: %s", scanner.Text())
+		}
+		path := unescapeMountPath(fields[4])
+		if path != dir && !strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/") {
+			continue
+		}
+		m := mountPoint{path: path}
+		for _, o := range strings.Split(fields[5], ",") {
+			m.flags |= mountOptionFlags[o]
+		}
+		mounts = append(mounts, m)
+	}
+	return mounts, sdk.WithStack(scanner.Err())
+}
+
+// unescapeMountPath decodes the octal escapes (ex: \040 for a space) of a path in /proc/self/mountinfo.
+func unescapeMountPath(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+4 <= len(s) {
+			if v, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(v))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
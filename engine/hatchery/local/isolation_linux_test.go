@@ -0,0 +1,216 @@
+// +build linux
+
+package local
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// isolatedWorkerTestEnv is set when the test binary is executed as the worker binary of an isolated worker.
+const isolatedWorkerTestEnv = "CDS_TEST_ISOLATED_WORKER"
+
+// TestMain runs the IsolatedWorkerInitCommand and the worker started by TestInitIsolatedWorker.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == IsolatedWorkerInitCommand {
+		if err := InitIsolatedWorker(os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		os.Exit(1)
+	}
+	if os.Getenv(isolatedWorkerTestEnv) != "" {
+		if err := checkIsolatedWorker(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// checkIsolatedWorker is run as an isolated worker, it checks that the worker cannot escape its isolation.
+func checkIsolatedWorker() error {
+	if err := unix.Mount("", "/", "", unix.MS_REMOUNT|unix.MS_BIND, ""); err != unix.EPERM {
+		return fmt.Errorf("remount / read-write: expected %v, got %v", unix.EPERM, err)
+	}
+	if err := ioutil.WriteFile("/cds-isolated-worker", nil, os.FileMode(0644)); err == nil {
+		return fmt.Errorf("write in / should fail")
+	}
+	if err := ioutil.WriteFile("cds-isolated-worker", nil, os.FileMode(0644)); err != nil {
+		return fmt.Errorf("write in basedir: %v", err)
+	}
+
+	status, err := ioutil.ReadFile("/proc/self/status")
+	if err != nil {
+		return err
+	}
+	for _, expected := range []string{
+		"Uid:\t65534\t65534\t65534\t65534",
+		"Gid:\t65534\t65534\t65534\t65534",
+		"CapInh:\t0000000000000000",
+		"CapPrm:\t0000000000000000",
+		"CapEff:\t0000000000000000",
+		"CapBnd:\t0000000000000000",
+		"NoNewPrivs:\t1",
+	} {
+		if !strings.Contains(string(status), expected+"\n") {
+			return fmt.Errorf("expected %q in /proc/self/status:\n%s", expected, status)
+		}
+	}
+	return nil
+}
+
+func TestInitIsolatedWorker(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("the isolation of the workers needs to run as root")
+	}
+
+	hatcheryDir, err := ioutil.TempDir("", "cds-hatchery-local")
+	require.NoError(t, err)
+	defer os.RemoveAll(hatcheryDir) // nolint
+	require.NoError(t, os.Chmod(hatcheryDir, os.FileMode(0755)))
+
+	// The test binary is the worker binary, it runs checkIsolatedWorker
+	testBinary, err := os.Executable()
+	require.NoError(t, err)
+	btes, err := ioutil.ReadFile(testBinary)
+	require.NoError(t, err)
+	workerBinary := filepath.Join(hatcheryDir, "worker")
+	require.NoError(t, ioutil.WriteFile(workerBinary, btes, os.FileMode(0755)))
+
+	basedir := filepath.Join(hatcheryDir, "my-worker")
+	require.NoError(t, os.Mkdir(basedir, os.FileMode(0755)))
+
+	h := New()
+	h.Config.Basedir = hatcheryDir
+	h.Config.Isolation = IsolationConfiguration{UID: 65534, GID: 65534}
+	w, err := h.newIsolatedWorker("my-worker", basedir, nil)
+	require.NoError(t, err)
+	defer w.release(context.TODO())
+
+	cmd, err := w.command(context.TODO(), h.LocalWorkerRunner, []string{workerBinary})
+	require.NoError(t, err)
+	cmd.Dir = basedir
+	cmd.Env = append(os.Environ(), isolatedWorkerTestEnv+"=1")
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	require.NoError(t, cmd.Start())
+
+	// The cgroup of the worker is not needed by the test
+	w.childPipe.Close() // nolint
+	_, err = w.syncPipe.Write([]byte{0})
+	require.NoError(t, err)
+	require.NoError(t, w.syncPipe.Close())
+
+	assert.NoError(t, cmd.Wait(), output.String())
+	_, err = os.Stat(filepath.Join(basedir, "cds-isolated-worker"))
+	assert.NoError(t, err)
+}
+
+func TestListenInNetworkNamespace(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("the network namespace of a worker needs to run as root")
+	}
+
+	cmd := exec.Command("sleep", "60")
+	cmd.SysProcAttr = &unix.SysProcAttr{Cloneflags: unix.CLONE_NEWNET}
+	require.NoError(t, cmd.Start())
+	defer cmd.Wait()         // nolint
+	defer cmd.Process.Kill() // nolint
+
+	l, err := listenInNetworkNamespace(cmd.Process.Pid, "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close() // nolint
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			c.Close() // nolint
+		}
+	}()
+
+	// The listener is not reachable from the network namespace of the hatchery
+	c, err := net.Dial("tcp", l.Addr().String())
+	if err == nil {
+		c.Close() // nolint
+	}
+	assert.Error(t, err)
+
+	// All the threads of the hatchery are back in its network namespace
+	hostNs, err := os.Readlink("/proc/self/ns/net")
+	require.NoError(t, err)
+	tasks, err := ioutil.ReadDir("/proc/self/task")
+	require.NoError(t, err)
+	for _, task := range tasks {
+		ns, err := os.Readlink(filepath.Join("/proc/self/task", task.Name(), "ns/net"))
+		require.NoError(t, err)
+		assert.Equal(t, hostNs, ns, "network namespace of thread %s", task.Name())
+	}
+}
+
+func TestParseMountInfo(t *testing.T) {
+	mountinfo := `22 1 8:1 / / rw,relatime shared:1 - ext4 /dev/sda1 rw
+23 22 0:5 / /dev rw,nosuid,relatime shared:2 - devtmpfs udev rw,size=4010000k
+24 22 8:1 /var/lib/cds-engine/a1b2.root /var/lib/cds-engine/a1b2.root rw,relatime shared:1 - ext4 /dev/sda1 rw
+25 24 8:1 / /var/lib/cds-engine/a1b2.root rw,relatime - ext4 /dev/sda1 rw
+26 25 0:5 / /var/lib/cds-engine/a1b2.root/dev rw,nosuid,noexec,relatime - devtmpfs udev rw,size=4010000k
+27 25 0:45 / /var/lib/cds-engine/a1b2.root/mnt/my\040disk ro,nodev,noatime - ext4 /dev/sdb1 ro
+28 22 0:46 / /var/lib/cds-engine/a1b2.rootfs rw - tmpfs tmpfs rw
+`
+	mounts, err := parseMountInfo(strings.NewReader(mountinfo), "/var/lib/cds-engine/a1b2.root")
+	require.NoError(t, err)
+	assert.Equal(t, []mountPoint{
+		{path: "/var/lib/cds-engine/a1b2.root", flags: unix.MS_RELATIME},
+		{path: "/var/lib/cds-engine/a1b2.root", flags: unix.MS_RELATIME},
+		{path: "/var/lib/cds-engine/a1b2.root/dev", flags: unix.MS_NOSUID | unix.MS_NOEXEC | unix.MS_RELATIME},
+		{path: "/var/lib/cds-engine/a1b2.root/mnt/my disk", flags: unix.MS_NODEV | unix.MS_NOATIME},
+	}, mounts)
+
+	_, err = parseMountInfo(strings.NewReader("22 1 8:1 /\n"), "/")
+	assert.Error(t, err)
+}
+
+func TestIsSubPath(t *testing.T) {
+	assert.True(t, isSubPath("/var/lib/cds-engine", "/var/lib/cds-engine/worker"))
+	assert.True(t, isSubPath("/var/lib/cds-engine", "/var/lib/cds-engine"))
+	assert.False(t, isSubPath("/var/lib/cds-engine", "/var/lib/cds-engine-2/worker"))
+	assert.False(t, isSubPath("/var/lib/cds-engine", "/usr/bin/worker"))
+}
+
+func TestIsolatedWorkerCreateCgroup(t *testing.T) {
+	cgroupRoot, err := ioutil.TempDir("", "cds-hatchery-local-cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(cgroupRoot) // nolint
+
+	w := isolatedWorker{
+		name:   "my-worker",
+		cfg:    IsolationConfiguration{CgroupRoot: cgroupRoot},
+		limits: isolationLimits{memory: 512 * 1024 * 1024, cpus: 1.5},
+	}
+	require.NoError(t, w.createCgroup())
+	assert.Equal(t, filepath.Join(cgroupRoot, "my-worker"), w.cgroup)
+
+	for file, value := range map[string]string{
+		"memory.max":      "536870912",
+		"memory.swap.max": "0",
+		"cpu.max":         "150000 100000",
+	} {
+		btes, err := ioutil.ReadFile(filepath.Join(w.cgroup, file))
+		require.NoError(t, err)
+		assert.Equal(t, value, string(btes), file)
+	}
+
+	// the cgroup of a worker is unique
+	assert.Error(t, w.createCgroup())
+}
@@ -0,0 +1,35 @@
+// +build !linux
+
+package local
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/ovh/cds/sdk"
+)
+
+var errIsolationNotSupported = fmt.Errorf("isolation of the workers is only available on Linux")
+
+func initIsolation(IsolationConfiguration) error {
+	return sdk.WithStack(errIsolationNotSupported)
+}
+
+func (w *isolatedWorker) command(context.Context, LocalWorkerRunner, []string) (*exec.Cmd, error) {
+	return nil, sdk.WithStack(errIsolationNotSupported)
+}
+
+func (w *isolatedWorker) setup(int) error {
+	return sdk.WithStack(errIsolationNotSupported)
+}
+
+func (w *isolatedWorker) release(context.Context) {
+	os.Remove(w.spec.RootDir) // nolint
+}
+
+// InitIsolatedWorker is run by the IsolatedWorkerInitCommand in the namespaces of a worker, only available on Linux.
+func InitIsolatedWorker([]string) error {
+	return errIsolationNotSupported
+}
@@ -0,0 +1,81 @@
+package local
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestIsolationLimitsFromRequirements(t *testing.T) {
+	cfg := IsolationConfiguration{DefaultMemory: 512, DefaultCPUs: 1}
+
+	l, err := isolationLimitsFromRequirements(cfg, nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(512*1024*1024), l.memory)
+	assert.Equal(t, 1.0, l.cpus)
+
+	l, err = isolationLimitsFromRequirements(cfg, []sdk.Requirement{
+		{Type: sdk.BinaryRequirement, Value: "git"},
+		{Type: sdk.MemoryRequirement, Value: "4096"},
+		{Type: sdk.CPURequirement, Value: "0.5"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(4096*1024*1024), l.memory)
+	assert.Equal(t, 0.5, l.cpus)
+
+	_, err = isolationLimitsFromRequirements(cfg, []sdk.Requirement{{Type: sdk.MemoryRequirement, Value: "4G"}})
+	assert.Error(t, err)
+	_, err = isolationLimitsFromRequirements(cfg, []sdk.Requirement{{Type: sdk.CPURequirement, Value: "-1"}})
+	assert.Error(t, err)
+}
+
+func TestHatcheryLocal_CanSpawnIsolation(t *testing.T) {
+	h := New()
+	memory := []sdk.Requirement{{Type: sdk.MemoryRequirement, Value: "1024"}}
+	cpu := []sdk.Requirement{{Type: sdk.CPURequirement, Value: "0.5"}}
+
+	assert.False(t, h.CanSpawn(context.TODO(), nil, 1, memory))
+	assert.False(t, h.CanSpawn(context.TODO(), nil, 1, cpu))
+
+	h.Config.Isolation.Enabled = true
+	assert.True(t, h.CanSpawn(context.TODO(), nil, 1, memory))
+	assert.True(t, h.CanSpawn(context.TODO(), nil, 1, cpu))
+	assert.False(t, h.CanSpawn(context.TODO(), nil, 1, []sdk.Requirement{{Type: sdk.CPURequirement, Value: "100000"}}))
+	assert.False(t, h.CanSpawn(context.TODO(), nil, 1, []sdk.Requirement{{Type: sdk.MemoryRequirement, Value: "lot"}}))
+	assert.False(t, h.CanSpawn(context.TODO(), nil, 1, []sdk.Requirement{{Type: sdk.ServiceRequirement, Name: "pg", Value: "postgres:9.5.3"}}))
+}
+
+func TestIsolatedWorkerEnv(t *testing.T) {
+	// An empty value unsets the variable
+	for name, value := range map[string]string{
+		"PATH":                  "/usr/bin:/bin",
+		"LANG":                  "",
+		"AWS_SECRET_ACCESS_KEY": "secret",
+		"HTTPS_PROXY":           "http://proxy:3128",
+		"NO_PROXY":              "",
+		"CDS_API":               "http://localhost:8081",
+	} {
+		if old, ok := os.LookupEnv(name); ok {
+			defer os.Setenv(name, old) // nolint
+		} else {
+			defer os.Unsetenv(name) // nolint
+		}
+		if value == "" {
+			require.NoError(t, os.Unsetenv(name))
+		} else {
+			require.NoError(t, os.Setenv(name, value))
+		}
+	}
+
+	env := isolatedWorkerEnv(IsolationConfiguration{Env: []string{"HTTPS_PROXY", "NO_PROXY"}}, "/var/lib/cds-engine/a1b2")
+	assert.Equal(t, []string{
+		"HOME=/var/lib/cds-engine/a1b2",
+		"PATH=/usr/bin:/bin",
+		"HTTPS_PROXY=http://proxy:3128",
+	}, env)
+}
@@ -108,6 +108,12 @@ func (h *HatcheryLocal) CheckConfiguration(cfg interface{}) error {
 	} else if err != nil {
 		return fmt.Errorf("Invalid basedir: %v", err)
 	}
+
+	if hconfig.Isolation.Enabled {
+		if err := hconfig.Isolation.check(); err != nil {
+			return fmt.Errorf("Invalid isolation configuration: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -123,6 +129,12 @@ func (h *HatcheryLocal) Serve(ctx context.Context) error {
 		return fmt.Errorf("Invalid basedir: %v", err)
 	}
 
+	if h.Config.Isolation.Enabled {
+		if err := initIsolation(h.Config.Isolation); err != nil {
+			return fmt.Errorf("Cannot isolate workers: %v", err)
+		}
+	}
+
 	if err := h.downloadWorker(); err != nil {
 		return fmt.Errorf("Cannot download worker binary from api: %v", err)
 	}
@@ -156,7 +168,12 @@ func (h *HatcheryLocal) downloadWorker() error {
 	}
 
 	log.Debug("copy worker binary into %s", workerFullPath)
-	fp, err := os.OpenFile(workerFullPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0700)
+	// An isolated worker is executed by an unprivileged user
+	mode := os.FileMode(0700)
+	if h.Config.Isolation.Enabled {
+		mode = 0755
+	}
+	fp, err := os.OpenFile(workerFullPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
 	if err != nil {
 		return sdk.WithStack(err)
 	}
@@ -183,7 +200,7 @@ func (h *HatcheryLocal) Configuration() service.HatcheryCommonConfiguration {
 }
 
 // CanSpawn return wether or not hatchery can spawn model.
-// requirements are not supported
+// memory and cpu requirements are only supported when the workers are isolated
 func (h *HatcheryLocal) CanSpawn(ctx context.Context, _ *sdk.Model, jobID int64, requirements []sdk.Requirement) bool {
 	for _, r := range requirements {
 		ok, err := h.checkRequirement(r)
@@ -194,8 +211,8 @@ func (h *HatcheryLocal) CanSpawn(ctx context.Context, _ *sdk.Model, jobID int64,
 	}
 
 	for _, r := range requirements {
-		if r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement {
-			log.Debug("CanSpawn false service or memory")
+		if r.Type == sdk.ServiceRequirement {
+			log.Debug("CanSpawn false service")
 			return false
 		}
 
@@ -336,6 +353,17 @@ func (h *HatcheryLocal) checkRequirement(r sdk.Requirement) (bool, error) {
 			return false, fmt.Errorf("invalid requirement %s", r.Value)
 		}
 		return osarch[0] == strings.ToLower(sdk.GOOS) && osarch[1] == strings.ToLower(sdk.GOARCH), nil
+	case sdk.MemoryRequirement, sdk.CPURequirement:
+		// limits are set on the cgroup of the worker, only available when the workers are isolated
+		if !h.Config.Isolation.Enabled {
+			log.Debug("checkRequirement> %v requirement needs the isolation of the workers", r.Type)
+			return false, nil
+		}
+		limits, err := isolationLimitsFromRequirements(h.Config.Isolation, []sdk.Requirement{r})
+		if err != nil {
+			return false, err
+		}
+		return limits.cpus <= float64(runtime.NumCPU()), nil
 	case sdk.HostnameRequirement:
 		h, err := os.Hostname()
 		if err != nil {
@@ -13,7 +13,21 @@ import (
 // HatcheryConfiguration is the configuration for local hatchery
 type HatcheryConfiguration struct {
 	service.HatcheryCommonConfiguration `mapstructure:"commonConfiguration" toml:"commonConfiguration" json:"commonConfiguration"`
-	Basedir                             string `mapstructure:"basedir" toml:"basedir" default:"/var/lib/cds-engine" comment:"BaseDir for worker workspace" json:"basedir"`
+	Basedir                             string                 `mapstructure:"basedir" toml:"basedir" default:"/var/lib/cds-engine" comment:"BaseDir for worker workspace" json:"basedir"`
+	Isolation                           IsolationConfiguration `mapstructure:"isolation" toml:"isolation" comment:"Isolation of the workers with Linux namespaces and cgroups" json:"isolation"`
+}
+
+// IsolationConfiguration is the configuration of the isolation of the workers, only available on Linux.
+// When enabled, the hatchery has to run as root on a host with cgroup v2.
+type IsolationConfiguration struct {
+	Enabled        bool     `mapstructure:"enabled" toml:"enabled" default:"false" comment:"Run each worker in its own mount, pid, ipc and uts namespaces and in its own cgroup" json:"enabled"`
+	CgroupRoot     string   `mapstructure:"cgroupRoot" toml:"cgroupRoot" default:"/sys/fs/cgroup/cds-hatchery-local" comment:"cgroup v2 directory in which a cgroup is created for each worker" json:"cgroupRoot"`
+	DefaultMemory  int64    `mapstructure:"defaultMemory" toml:"defaultMemory" default:"0" comment:"Memory limit (in MB) of a worker when the job has no memory requirement. 0 for no limit" json:"defaultMemory"`
+	DefaultCPUs    float64  `mapstructure:"defaultCPUs" toml:"defaultCPUs" default:"0" comment:"CPU limit of a worker when the job has no cpu requirement. 0 for no limit" json:"defaultCPUs"`
+	DisableNetwork bool     `mapstructure:"disableNetwork" toml:"disableNetwork" default:"false" comment:"Run each worker in its own network namespace without network access. CDS API is reachable through a proxy started by the hatchery" json:"disableNetwork"`
+	UID            int      `mapstructure:"uid" toml:"uid" default:"65534" comment:"Id of the unprivileged user that runs the workers" json:"uid"`
+	GID            int      `mapstructure:"gid" toml:"gid" default:"65534" comment:"Id of the group of the unprivileged user that runs the workers" json:"gid"`
+	Env            []string `mapstructure:"env" toml:"env" comment:"Names of the environment variables of the hatchery given to the workers, in addition to PATH and LANG" json:"env"`
 }
 
 // HatcheryLocal implements HatcheryMode interface for local usage
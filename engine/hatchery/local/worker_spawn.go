@@ -52,7 +52,7 @@ const workerCmdTmpl = "{{.WorkerBinary}} --api={{.API}} --token={{.Token}} --log
 func (h *HatcheryLocal) SpawnWorker(ctx context.Context, spawnArgs hatchery.SpawnArguments) error {
 	log.Debug("HatcheryLocal.SpawnWorker> %s want to spawn a worker named %s (jobID = %d)", spawnArgs.HatcheryName, spawnArgs.WorkerName, spawnArgs.JobID)
 
-	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly {
+	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly && !spawnArgs.Warm {
 		return sdk.WithStack(fmt.Errorf("no job ID and no register"))
 	}
 
@@ -86,6 +86,11 @@ func (h *HatcheryLocal) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 		WorkflowJobID:     spawnArgs.JobID,
 	}
 
+	// Without network, an isolated worker reaches CDS API through the proxy started by the hatchery in its namespace
+	if h.Config.Isolation.Enabled && h.Config.Isolation.DisableNetwork {
+		udataParam.API = "http://" + isolatedAPIProxyAddr
+	}
+
 	tmpl, errt := template.New("cmd").Parse(workerCmdTmpl)
 	if errt != nil {
 		return errt
@@ -103,9 +108,21 @@ func (h *HatcheryLocal) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 	// Prefix the command with the directory where the worker binary has been downloaded
 	log.Debug("Command exec: %v", cmdSplitted)
 	var cmd *exec.Cmd
+	var isolated *isolatedWorker
 	if spawnArgs.RegisterOnly {
 		cmdSplitted[0] = "register"
 		cmd = h.LocalWorkerRunner.NewCmd(ctx, cmdSplitted[0], cmdSplitted...)
+	} else if h.Config.Isolation.Enabled {
+		var err error
+		isolated, err = h.newIsolatedWorker(spawnArgs.WorkerName, basedir, spawnArgs.Requirements)
+		if err != nil {
+			return err
+		}
+		cmd, err = isolated.command(ctx, h.LocalWorkerRunner, cmdSplitted)
+		if err != nil {
+			isolated.release(ctx)
+			return err
+		}
 	} else {
 		cmd = h.LocalWorkerRunner.NewCmd(ctx, cmdSplitted[0], cmdSplitted[1:]...)
 	}
@@ -113,17 +130,21 @@ func (h *HatcheryLocal) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 	cmd.Dir = udataParam.BaseDir
 
 	// Clearenv
-	env := os.Environ()
-	for _, e := range env {
-		if !strings.HasPrefix(e, "CDS") && !strings.HasPrefix(e, "HATCHERY") {
-			cmd.Env = append(cmd.Env, e)
+	if isolated != nil {
+		cmd.Env = isolatedWorkerEnv(h.Config.Isolation, basedir)
+	} else {
+		env := os.Environ()
+		for _, e := range env {
+			if !strings.HasPrefix(e, "CDS") && !strings.HasPrefix(e, "HATCHERY") {
+				cmd.Env = append(cmd.Env, e)
+			}
 		}
 	}
 
 	// Wait in a goroutine so that when process exits, Wait() update cmd.ProcessState
 	go func() {
 		log.Debug("hatchery> local> starting worker: %s", spawnArgs.WorkerName)
-		if err := h.startCmd(spawnArgs.WorkerName, cmd, localWorkerLogger{spawnArgs.WorkerName}); err != nil {
+		if err := h.startCmd(ctx, spawnArgs.WorkerName, cmd, isolated, localWorkerLogger{spawnArgs.WorkerName}); err != nil {
 			log.Error(ctx, "hatchery> local> %v", err)
 		}
 	}()
@@ -131,7 +152,11 @@ func (h *HatcheryLocal) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 	return nil
 }
 
-func (h *HatcheryLocal) startCmd(name string, cmd *exec.Cmd, logger log.Logger) error {
+func (h *HatcheryLocal) startCmd(ctx context.Context, name string, cmd *exec.Cmd, isolated *isolatedWorker, logger log.Logger) error {
+	if isolated != nil {
+		defer isolated.release(ctx)
+	}
+
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
 		return fmt.Errorf("Failure due to internal error: unable to capture stdout: %v", err)
@@ -179,6 +204,16 @@ func (h *HatcheryLocal) startCmd(name string, cmd *exec.Cmd, logger log.Logger)
 		return fmt.Errorf("unable to start command: %v", err)
 	}
 
+	if isolated != nil {
+		if err := isolated.setup(cmd.Process.Pid); err != nil {
+			cmd.Process.Kill() // nolint
+			<-outchan
+			<-errchan
+			cmd.Wait() // nolint
+			return fmt.Errorf("unable to isolate worker: %v", err)
+		}
+	}
+
 	h.Lock()
 	h.workers[name] = workerCmd{cmd: cmd, created: time.Now()}
 	h.Unlock()
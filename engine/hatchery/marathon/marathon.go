@@ -202,6 +202,23 @@ func (h *HatcheryMarathon) CanSpawn(ctx context.Context, model *sdk.Model, jobID
 		return false
 	}
 
+	cpus, err := hatchery.RequiredCPUs(requirements)
+	if err != nil {
+		log.Debug("CanSpawn> Job %d has an invalid cpu requirement: %v", jobID, err)
+		return false
+	}
+	if cpus > 0 && h.Config.MaxCPUs > 0 {
+		usedCPUs, err := h.usedCPUs()
+		if err != nil {
+			log.Info(ctx, "CanSpawn> Error on m.usedCPUs() : %s", err)
+			return false
+		}
+		if usedCPUs+cpus > h.Config.MaxCPUs {
+			log.Info(ctx, "CanSpawn> Job %d requires %v cpus, %v cpus are already used by workers. Max: %v", jobID, cpus, usedCPUs, h.Config.MaxCPUs)
+			return false
+		}
+	}
+
 	return true
 }
 
@@ -217,7 +234,7 @@ func (h *HatcheryMarathon) SpawnWorker(ctx context.Context, spawnArgs hatchery.S
 		log.Debug("spawnWorker> spawning worker %s (%s)", spawnArgs.Model.Name, spawnArgs.Model.ModelDocker.Image)
 	}
 
-	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly {
+	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly && !spawnArgs.Warm {
 		return sdk.WithStack(fmt.Errorf("no job ID and no register"))
 	}
 
@@ -259,6 +276,15 @@ func (h *HatcheryMarathon) SpawnWorker(ctx context.Context, spawnArgs hatchery.S
 
 	cmd += "; sleep 120" // sleep 2min, to let marathon hatchery remove the container
 
+	cpus := h.Config.DefaultCPUs
+	requiredCPUs, err := hatchery.RequiredCPUs(spawnArgs.Requirements)
+	if err != nil {
+		return err
+	}
+	if requiredCPUs > 0 {
+		cpus = requiredCPUs
+	}
+
 	//Check if there is a memory requirement
 	//if there is a service requirement: exit
 	if spawnArgs.JobID > 0 {
@@ -293,6 +319,9 @@ func (h *HatcheryMarathon) SpawnWorker(ctx context.Context, spawnArgs hatchery.S
 	if spawnArgs.JobID > 0 {
 		envsWm["CDS_BOOKED_WORKFLOW_JOB_ID"] = fmt.Sprintf("%d", spawnArgs.JobID)
 	}
+	if requiredCPUs > 0 {
+		envsWm["CDS_MODEL_CPU"] = strconv.FormatFloat(requiredCPUs, 'f', -1, 64)
+	}
 
 	envTemplated, errEnv := sdk.TemplateEnvs(udataParam, spawnArgs.Model.ModelDocker.Envs)
 	if errEnv != nil {
@@ -315,7 +344,7 @@ func (h *HatcheryMarathon) SpawnWorker(ctx context.Context, spawnArgs hatchery.S
 			Type: "DOCKER",
 		},
 		Env:       &envsWm,
-		CPUs:      h.Config.DefaultCPUs,
+		CPUs:      cpus,
 		Instances: &instance,
 		Mem:       &mem,
 		Labels:    &h.marathonLabels,
@@ -426,6 +455,25 @@ func (h *HatcheryMarathon) listApplications(idPrefix string) ([]string, error) {
 	return h.marathonClient.ListApplications(values)
 }
 
+// usedCPUs returns the number of CPUs used by the workers of the hatchery.
+func (h *HatcheryMarathon) usedCPUs() (float64, error) {
+	values := url.Values{}
+	values.Set("id", h.Config.MarathonIDPrefix)
+	apps, err := h.marathonClient.Applications(values)
+	if err != nil {
+		return 0, err
+	}
+	var cpus float64
+	for _, app := range apps.Apps {
+		instances := 1
+		if app.Instances != nil {
+			instances = *app.Instances
+		}
+		cpus += app.CPUs * float64(instances)
+	}
+	return cpus, nil
+}
+
 // WorkersStarted returns the number of instances started but
 // not necessarily register on CDS yet
 func (h *HatcheryMarathon) WorkersStarted(ctx context.Context) []string {
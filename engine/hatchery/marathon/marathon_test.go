@@ -398,3 +398,27 @@ func TestCanSpawnWithService(t *testing.T) {
 	canSpawn := h.CanSpawn(context.TODO(), m, int64(1), []sdk.Requirement{{Name: "pg", Type: sdk.ServiceRequirement, Value: "postgres:9.5.4"}})
 	assert.False(t, canSpawn)
 }
+
+func TestCanSpawnCPURequirement(t *testing.T) {
+	defer gock.Off()
+	h := InitMarathonMarathonTest(marathonJDD{
+		MaxWorker:    10,
+		MaxProvision: 1,
+	})
+	h.Config.MaxCPUs = 4
+
+	two := 2
+	apps := marathon.Applications{
+		Apps: []marathon.Application{
+			{ID: "app1", CPUs: 1, Instances: &two},
+			{ID: "app2", CPUs: 0.5},
+		},
+	}
+	gock.New("http://mara.thon").Get("/v2/deployments").Times(2).Reply(200).JSON([]*marathon.DeploymentID{})
+	gock.New("http://mara.thon").Get("/v2/apps").Times(4).Reply(200).JSON(apps)
+
+	m := &sdk.Model{Name: "fake"}
+	assert.True(t, h.CanSpawn(context.TODO(), m, int64(1), []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "1.5"}}))
+	assert.False(t, h.CanSpawn(context.TODO(), m, int64(1), []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "2"}}))
+	assert.True(t, gock.IsDone())
+}
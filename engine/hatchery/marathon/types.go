@@ -29,6 +29,9 @@ type HatcheryConfiguration struct {
 	// DefaultCPUs
 	DefaultCPUs float64 `mapstructure:"defaultCPUs" toml:"defaultCPUs" default:"1" commented:"false" comment:"Worker default CPUs count" json:"defaultCPUs"`
 
+	// MaxCPUs is the maximum number of CPUs used by the workers of the hatchery
+	MaxCPUs float64 `mapstructure:"maxCPUs" toml:"maxCPUs" default:"0" commented:"true" comment:"Maximum number of CPUs used by the workers of this hatchery, checked for jobs with a cpu requirement. 0 for no limit" json:"maxCPUs"`
+
 	// DefaultMemory Worker default memory
 	DefaultMemory int `mapstructure:"defaultMemory" toml:"defaultMemory" default:"1024" commented:"false" comment:"Worker default memory in Mo" json:"defaultMemory"`
 
@@ -0,0 +1,219 @@
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ovh/cds/sdk"
+)
+
+const (
+	nomadJobTypeBatch      = "batch"
+	nomadJobStatusDead     = "dead"
+	nomadDriverDocker      = "docker"
+	nomadNetworkBridge     = "bridge"
+	nomadLifecyclePrestart = "prestart"
+)
+
+// nomadJob is the subset of a Nomad job used by the hatchery, see https://www.nomadproject.io/api-docs/json-jobs
+type nomadJob struct {
+	ID          string            `json:"ID"`
+	Name        string            `json:"Name"`
+	Type        string            `json:"Type,omitempty"`
+	Region      string            `json:"Region,omitempty"`
+	Namespace   string            `json:"Namespace,omitempty"`
+	Datacenters []string          `json:"Datacenters,omitempty"`
+	Meta        map[string]string `json:"Meta,omitempty"`
+	TaskGroups  []nomadTaskGroup  `json:"TaskGroups,omitempty"`
+	Status      string            `json:"Status,omitempty"`
+	Stop        bool              `json:"Stop,omitempty"`
+	SubmitTime  int64             `json:"SubmitTime,omitempty"`
+}
+
+type nomadTaskGroup struct {
+	Name             string                 `json:"Name"`
+	Count            int                    `json:"Count"`
+	Networks         []nomadNetwork         `json:"Networks,omitempty"`
+	RestartPolicy    *nomadRestartPolicy    `json:"RestartPolicy,omitempty"`
+	ReschedulePolicy *nomadReschedulePolicy `json:"ReschedulePolicy,omitempty"`
+	Tasks            []nomadTask            `json:"Tasks"`
+}
+
+type nomadNetwork struct {
+	Mode string `json:"Mode"`
+}
+
+type nomadRestartPolicy struct {
+	Attempts int    `json:"Attempts"`
+	Mode     string `json:"Mode"`
+}
+
+type nomadReschedulePolicy struct {
+	Attempts  int  `json:"Attempts"`
+	Unlimited bool `json:"Unlimited"`
+}
+
+type nomadTask struct {
+	Name      string                 `json:"Name"`
+	Driver    string                 `json:"Driver"`
+	Leader    bool                   `json:"Leader,omitempty"`
+	Config    map[string]interface{} `json:"Config"`
+	Env       map[string]string      `json:"Env,omitempty"`
+	Resources *nomadResources        `json:"Resources,omitempty"`
+	Lifecycle *nomadLifecycle        `json:"Lifecycle,omitempty"`
+}
+
+type nomadResources struct {
+	CPU      int `json:"CPU,omitempty"`
+	MemoryMB int `json:"MemoryMB"`
+}
+
+type nomadLifecycle struct {
+	Hook    string `json:"Hook"`
+	Sidecar bool   `json:"Sidecar"`
+}
+
+func (j nomadJob) submitted() time.Time {
+	return time.Unix(0, j.SubmitTime)
+}
+
+// nomadClient calls the Nomad HTTP API, see https://www.nomadproject.io/api-docs
+type nomadClient struct {
+	address    string
+	token      string
+	namespace  string
+	region     string
+	httpClient *http.Client
+}
+
+func newNomadClient(address, token, namespace, region string) *nomadClient {
+	return &nomadClient{
+		address:    strings.TrimSuffix(address, "/"),
+		token:      token,
+		namespace:  namespace,
+		region:     region,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (c *nomadClient) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) (int, error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if c.namespace != "" {
+		query.Set("namespace", c.namespace)
+	}
+	if c.region != "" {
+		query.Set("region", c.region)
+	}
+	u := c.address + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return 0, sdk.WithStack(err)
+		}
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return 0, sdk.WithStack(err)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("X-Nomad-Token", c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, sdk.WrapError(err, "cannot call nomad %s %s", method, path)
+	}
+	defer resp.Body.Close() // nolint
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, sdk.WithStack(err)
+	}
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode, sdk.WithStack(fmt.Errorf("nomad %s %s returned %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(respBody))))
+	}
+	if out != nil && len(respBody) > 0 {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return resp.StatusCode, sdk.WrapError(err, "cannot unmarshal nomad response of %s %s", method, path)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// leader returns the address of the Nomad cluster leader, it is used to check the connection to Nomad.
+func (c *nomadClient) leader(ctx context.Context) (string, error) {
+	var leader string
+	_, err := c.do(ctx, http.MethodGet, "/v1/status/leader", nil, nil, &leader)
+	return leader, err
+}
+
+func (c *nomadClient) registerJob(ctx context.Context, job nomadJob) error {
+	_, err := c.do(ctx, http.MethodPost, "/v1/jobs", nil, map[string]interface{}{"Job": job}, nil)
+	return err
+}
+
+// listJobs returns the jobs with given ID prefix. Meta of the jobs are returned by Nomad >= 1.6 only,
+// for older versions the jobs are loaded one by one to get their meta.
+func (c *nomadClient) listJobs(ctx context.Context, prefix string) ([]nomadJob, error) {
+	query := url.Values{}
+	query.Set("prefix", prefix)
+	query.Set("meta", "true")
+	var jobs []nomadJob
+	if _, err := c.do(ctx, http.MethodGet, "/v1/jobs", query, nil, &jobs); err != nil {
+		return nil, err
+	}
+	for i := range jobs {
+		if jobs[i].Meta != nil {
+			continue
+		}
+		job, err := c.getJob(ctx, jobs[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		if job != nil {
+			jobs[i].Meta = job.Meta
+		}
+	}
+	return jobs, nil
+}
+
+// getJob returns the job with given ID, nil if not found
+func (c *nomadClient) getJob(ctx context.Context, id string) (*nomadJob, error) {
+	var job nomadJob
+	code, err := c.do(ctx, http.MethodGet, "/v1/job/"+url.PathEscape(id), nil, nil, &job)
+	if code == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// deleteJob stops the job and purges it from Nomad
+func (c *nomadClient) deleteJob(ctx context.Context, id string) error {
+	query := url.Values{}
+	query.Set("purge", "true")
+	code, err := c.do(ctx, http.MethodDelete, "/v1/job/"+url.PathEscape(id), query, nil, nil)
+	if code == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,23 @@
+package nomad
+
+import (
+	"testing"
+
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/ovh/cds/sdk/cdsclient"
+)
+
+func NewHatcheryNomadTest(t *testing.T) *HatcheryNomad {
+	h := new(HatcheryNomad)
+	h.Client = cdsclient.New(cdsclient.Config{Host: "http://lolcat.api", InsecureSkipVerifyTLS: false})
+	gock.InterceptClient(h.Client.(cdsclient.Raw).HTTPClient())
+
+	h.Config.Name = "kyubi"
+	h.Config.JobPrefix = "cds-"
+	h.Config.DefaultMemory = 1024
+	h.Common.Common.ServiceName = "kyubi"
+	h.nomadClient = newNomadClient("http://lolcat.nomad", "", "hachibi", "")
+	gock.InterceptClient(h.nomadClient.httpClient)
+	return h
+}
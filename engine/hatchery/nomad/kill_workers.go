@@ -0,0 +1,69 @@
+package nomad
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/hatchery"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// killAwolWorkers deletes the jobs of the workers that are over, and the jobs of the workers that
+// are not known by the API (or disabled) 3 minutes after their submission.
+func (h *HatcheryNomad) killAwolWorkers(ctx context.Context) error {
+	jobs, err := h.workerJobs(ctx)
+	if err != nil {
+		return err
+	}
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	ctxList, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	apiWorkers, err := h.CDSClient().WorkerList(ctxList)
+	if err != nil {
+		return sdk.WrapError(err, "cannot get workers")
+	}
+	apiWorkersStatus := make(map[string]string, len(apiWorkers))
+	for _, w := range apiWorkers {
+		apiWorkersStatus[w.Name] = w.Status
+	}
+
+	var globalErr error
+	for _, j := range jobs {
+		workerName := j.Meta[META_WORKER_NAME]
+		if !j.Stop && j.Status != nomadJobStatusDead {
+			if time.Since(j.submitted()) < 3*time.Minute {
+				log.Debug("hatchery> nomad> killAwolWorkers> job %s (status=%s) is too young", j.ID, j.Status)
+				continue
+			}
+			if status, has := apiWorkersStatus[workerName]; has && status != sdk.StatusDisabled {
+				continue
+			}
+		}
+
+		// If its a worker "register", check registration before deleting it
+		if j.Meta[META_WORKER] == "register" {
+			modelPath := j.Meta[META_MODEL_PATH]
+			if err := hatchery.CheckWorkerModelRegister(h, modelPath); err != nil {
+				var spawnErr = sdk.SpawnErrorForm{
+					Error: err.Error(),
+				}
+				tuple := strings.SplitN(modelPath, "/", 2)
+				if err := h.CDSClient().WorkerModelSpawnError(tuple[0], tuple[1], spawnErr); err != nil {
+					log.Error(ctx, "hatchery> nomad> killAwolWorkers> error on call client.WorkerModelSpawnError on worker model %s for register: %s", modelPath, err)
+				}
+			}
+		}
+
+		log.Debug("hatchery> nomad> killAwolWorkers> delete job %s (status=%s)", j.ID, j.Status)
+		if err := h.nomadClient.deleteJob(ctx, j.ID); err != nil {
+			globalErr = err
+			log.Error(ctx, "hatchery> nomad> killAwolWorkers> cannot delete job %s: %v", j.ID, err)
+		}
+	}
+	return globalErr
+}
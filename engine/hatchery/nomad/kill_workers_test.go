@@ -0,0 +1,45 @@
+package nomad
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestHatcheryNomad_KillAwolWorkers(t *testing.T) {
+	defer gock.Off()
+	h := NewHatcheryNomadTest(t)
+
+	old := time.Now().Add(-10 * time.Minute).UnixNano()
+	meta := func(name string) map[string]string {
+		return map[string]string{META_HATCHERY_NAME: "kyubi", META_WORKER_NAME: name}
+	}
+	jobs := []nomadJob{
+		{ID: "cds-dead", Status: "dead", SubmitTime: time.Now().UnixNano(), Meta: meta("dead")},
+		{ID: "cds-young", Status: "running", SubmitTime: time.Now().UnixNano(), Meta: meta("young")},
+		{ID: "cds-registered", Status: "running", SubmitTime: old, Meta: meta("registered")},
+		{ID: "cds-disabled", Status: "running", SubmitTime: old, Meta: meta("disabled")},
+		{ID: "cds-unknown", Status: "pending", SubmitTime: old, Meta: meta("unknown")},
+		{ID: "cds-other", Status: "dead", SubmitTime: old, Meta: map[string]string{META_HATCHERY_NAME: "jubi", META_WORKER_NAME: "other"}},
+	}
+	gock.New("http://lolcat.nomad").Get("/v1/jobs").Reply(http.StatusOK).JSON(jobs)
+
+	workers := []sdk.Worker{
+		{Name: "registered", Status: sdk.StatusBuilding},
+		{Name: "disabled", Status: sdk.StatusDisabled},
+	}
+	gock.New("http://lolcat.api").Get("/worker").Reply(http.StatusOK).JSON(workers)
+
+	gock.New("http://lolcat.nomad").Delete("/v1/job/cds-dead").MatchParam("purge", "true").Reply(http.StatusOK).JSON(nil)
+	gock.New("http://lolcat.nomad").Delete("/v1/job/cds-disabled").MatchParam("purge", "true").Reply(http.StatusOK).JSON(nil)
+	gock.New("http://lolcat.nomad").Delete("/v1/job/cds-unknown").MatchParam("purge", "true").Reply(http.StatusOK).JSON(nil)
+
+	require.NoError(t, h.killAwolWorkers(context.TODO()))
+	require.True(t, gock.IsDone())
+}
@@ -0,0 +1,436 @@
+package nomad
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/mux"
+
+	"github.com/ovh/cds/engine/api"
+	"github.com/ovh/cds/engine/api/services"
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/cdsclient"
+	"github.com/ovh/cds/sdk/hatchery"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// New instanciates a new hatchery nomad
+func New() *HatcheryNomad {
+	s := new(HatcheryNomad)
+	s.Router = &api.Router{
+		Mux: mux.NewRouter(),
+	}
+	return s
+}
+
+var _ hatchery.InterfaceWithModels = new(HatcheryNomad)
+
+// InitHatchery register nomad hatchery with its worker model
+func (h *HatcheryNomad) InitHatchery(ctx context.Context) error {
+	if err := h.Common.InitServiceLogger(); err != nil {
+		return err
+	}
+	sdk.GoRoutine(context.Background(), "hatchery nomad routines", func(ctx context.Context) {
+		h.routines(ctx)
+	})
+	return nil
+}
+
+// Init cdsclient config.
+func (h *HatcheryNomad) Init(config interface{}) (cdsclient.ServiceConfig, error) {
+	var cfg cdsclient.ServiceConfig
+	sConfig, ok := config.(HatcheryConfiguration)
+	if !ok {
+		return cfg, sdk.WithStack(fmt.Errorf("invalid nomad hatchery configuration"))
+	}
+
+	cfg.Host = sConfig.API.HTTP.URL
+	cfg.Token = sConfig.API.Token
+	cfg.InsecureSkipVerifyTLS = sConfig.API.HTTP.Insecure
+	cfg.RequestSecondsTimeout = sConfig.API.RequestTimeout
+	return cfg, nil
+}
+
+// ApplyConfiguration apply an object of type HatcheryConfiguration after checking it
+func (h *HatcheryNomad) ApplyConfiguration(cfg interface{}) error {
+	if err := h.CheckConfiguration(cfg); err != nil {
+		return err
+	}
+
+	var ok bool
+	h.Config, ok = cfg.(HatcheryConfiguration)
+	if !ok {
+		return fmt.Errorf("Invalid configuration")
+	}
+
+	h.nomadClient = newNomadClient(h.Config.NomadAddress, h.Config.NomadToken, h.Config.Namespace, h.Config.Region)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if _, err := h.nomadClient.leader(ctx); err != nil {
+		return sdk.WrapError(err, "cannot connect to nomad at %s", h.Config.NomadAddress)
+	}
+
+	h.Common.Common.ServiceName = h.Config.Name
+	h.Common.Common.ServiceType = services.TypeHatchery
+	h.HTTPURL = h.Config.URL
+	h.MaxHeartbeatFailures = h.Config.API.MaxHeartbeatFailures
+	var err error
+	h.Common.Common.PrivateKey, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(h.Config.RSAPrivateKey))
+	if err != nil {
+		return fmt.Errorf("unable to parse RSA private Key: %v", err)
+	}
+
+	return nil
+}
+
+// Status returns sdk.MonitoringStatus, implements interface service.Service
+func (h *HatcheryNomad) Status(ctx context.Context) sdk.MonitoringStatus {
+	m := h.CommonMonitoring()
+	m.Lines = append(m.Lines, sdk.MonitoringStatusLine{Component: "Workers", Value: fmt.Sprintf("%d/%d", len(h.WorkersStarted(ctx)), h.Config.Provision.MaxWorker), Status: sdk.MonitoringStatusOK})
+
+	return m
+}
+
+// CheckConfiguration checks the validity of the configuration object
+func (h *HatcheryNomad) CheckConfiguration(cfg interface{}) error {
+	hconfig, ok := cfg.(HatcheryConfiguration)
+	if !ok {
+		return fmt.Errorf("Invalid hatchery nomad configuration")
+	}
+
+	if err := hconfig.Check(); err != nil {
+		return fmt.Errorf("Invalid hatchery nomad configuration: %v", err)
+	}
+
+	if hconfig.NomadAddress == "" {
+		return fmt.Errorf("please enter a valid nomad address")
+	}
+
+	if hconfig.JobPrefix == "" {
+		return fmt.Errorf("please enter a nomad job prefix")
+	}
+
+	return nil
+}
+
+// Serve start the hatchery server
+func (h *HatcheryNomad) Serve(ctx context.Context) error {
+	return h.CommonServe(ctx, h)
+}
+
+// Configuration returns Hatchery CommonConfiguration
+func (h *HatcheryNomad) Configuration() service.HatcheryCommonConfiguration {
+	return h.Config.HatcheryCommonConfiguration
+}
+
+// ModelType returns type of hatchery
+func (*HatcheryNomad) ModelType() string {
+	return sdk.Docker
+}
+
+// WorkerModelsEnabled returns Worker model enabled.
+func (h *HatcheryNomad) WorkerModelsEnabled() ([]sdk.Model, error) {
+	return h.CDSClient().WorkerModelEnabledList()
+}
+
+// WorkerModelSecretList returns secret for given model.
+func (h *HatcheryNomad) WorkerModelSecretList(m sdk.Model) (sdk.WorkerModelSecrets, error) {
+	return h.CDSClient().WorkerModelSecretList(m.Group.Name, m.Name)
+}
+
+// CanSpawn return wether or not hatchery can spawn model.
+func (h *HatcheryNomad) CanSpawn(ctx context.Context, model *sdk.Model, jobID int64, requirements []sdk.Requirement) bool {
+	// Hostname requirement is not supported
+	for _, r := range requirements {
+		if r.Type == sdk.HostnameRequirement {
+			log.Debug("CanSpawn> Job %d has a hostname requirement. Nomad can't spawn a worker for this job", jobID)
+			return false
+		}
+	}
+	if _, err := hatchery.RequiredCPUs(requirements); err != nil {
+		log.Debug("CanSpawn> Job %d has an invalid cpu requirement: %v", jobID, err)
+		return false
+	}
+	return true
+}
+
+// SpawnWorker starts a new worker as a Nomad batch job
+func (h *HatcheryNomad) SpawnWorker(ctx context.Context, spawnArgs hatchery.SpawnArguments) error {
+	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly && !spawnArgs.Warm {
+		return sdk.WithStack(fmt.Errorf("no job ID and no register"))
+	}
+
+	label := "execution"
+	if spawnArgs.RegisterOnly {
+		label = "register"
+	}
+
+	memory := int64(h.Config.DefaultMemory)
+	for _, r := range spawnArgs.Requirements {
+		if r.Type == sdk.MemoryRequirement {
+			var err error
+			memory, err = strconv.ParseInt(r.Value, 10, 64)
+			if err != nil {
+				log.Warning(ctx, "hatchery> nomad> SpawnWorker> %s unable to parse memory requirement %s: %v", spawnArgs.WorkerName, r.Value, err)
+				return err
+			}
+		}
+	}
+
+	// Nomad reserves CPU in MHz, the Nomad default is used if there is no cpu requirement
+	cpus, err := hatchery.RequiredCPUs(spawnArgs.Requirements)
+	if err != nil {
+		return err
+	}
+	cpu := int(math.Ceil(cpus * float64(h.Config.CPUMHz)))
+
+	udataParam := sdk.WorkerArgs{
+		API:               h.Configuration().API.HTTP.URL,
+		Token:             spawnArgs.WorkerToken,
+		HTTPInsecure:      h.Config.API.HTTP.Insecure,
+		Name:              spawnArgs.WorkerName,
+		Model:             spawnArgs.Model.Group.Name + "/" + spawnArgs.Model.Name,
+		HatcheryName:      h.Name(),
+		TTL:               h.Config.WorkerTTL,
+		GraylogHost:       h.Configuration().Provision.WorkerLogsOptions.Graylog.Host,
+		GraylogPort:       h.Configuration().Provision.WorkerLogsOptions.Graylog.Port,
+		GraylogExtraKey:   h.Configuration().Provision.WorkerLogsOptions.Graylog.ExtraKey,
+		GraylogExtraValue: h.Configuration().Provision.WorkerLogsOptions.Graylog.ExtraValue,
+	}
+
+	udataParam.WorkflowJobID = spawnArgs.JobID
+
+	tmpl, err := template.New("cmd").Parse(spawnArgs.Model.ModelDocker.Cmd)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	if err := tmpl.Execute(&buffer, udataParam); err != nil {
+		return err
+	}
+
+	cmd := buffer.String()
+	if spawnArgs.RegisterOnly {
+		cmd += " register"
+		memory = hatchery.MemoryRegisterContainer
+	}
+
+	envsWm := map[string]string{}
+	envsWm["CDS_MODEL_MEMORY"] = fmt.Sprintf("%d", memory)
+	envsWm["CDS_API"] = udataParam.API
+	envsWm["CDS_TOKEN"] = udataParam.Token
+	envsWm["CDS_NAME"] = udataParam.Name
+	envsWm["CDS_MODEL_PATH"] = udataParam.Model
+	envsWm["CDS_HATCHERY_NAME"] = udataParam.HatcheryName
+	envsWm["CDS_FROM_WORKER_IMAGE"] = fmt.Sprintf("%v", udataParam.FromWorkerImage)
+	envsWm["CDS_INSECURE"] = fmt.Sprintf("%v", udataParam.HTTPInsecure)
+
+	if spawnArgs.JobID > 0 {
+		envsWm["CDS_BOOKED_WORKFLOW_JOB_ID"] = fmt.Sprintf("%d", spawnArgs.JobID)
+	}
+
+	envTemplated, err := sdk.TemplateEnvs(udataParam, spawnArgs.Model.ModelDocker.Envs)
+	if err != nil {
+		return err
+	}
+	for envName, envValue := range envTemplated {
+		envsWm[envName] = envValue
+	}
+
+	shell := strings.Fields(spawnArgs.Model.ModelDocker.Shell)
+	if len(shell) == 0 {
+		shell = []string{"sh", "-c"}
+	}
+	workerConfig := map[string]interface{}{
+		"image":   spawnArgs.Model.ModelDocker.Image,
+		"command": shell[0],
+		"args":    append(shell[1:], cmd),
+	}
+	if spawnArgs.Model.ModelDocker.Private {
+		registry := "https://index.docker.io/v1/"
+		if spawnArgs.Model.ModelDocker.Registry != "" {
+			registry = spawnArgs.Model.ModelDocker.Registry
+		}
+		workerConfig["auth"] = []map[string]string{{
+			"username":       spawnArgs.Model.ModelDocker.Username,
+			"password":       spawnArgs.Model.ModelDocker.Password,
+			"server_address": registry,
+		}}
+	}
+
+	group := nomadTaskGroup{
+		Name:  workerTaskName,
+		Count: 1,
+		// A worker is never restarted nor rescheduled, a new worker is spawned by the hatchery if needed
+		RestartPolicy:    &nomadRestartPolicy{Attempts: 0, Mode: "fail"},
+		ReschedulePolicy: &nomadReschedulePolicy{Attempts: 0, Unlimited: false},
+		Tasks: []nomadTask{{
+			Name:      workerTaskName,
+			Driver:    nomadDriverDocker,
+			Leader:    true,
+			Config:    workerConfig,
+			Env:       envsWm,
+			Resources: &nomadResources{CPU: cpu, MemoryMB: int(memory)},
+		}},
+	}
+
+	job := nomadJob{
+		ID:          h.Config.JobPrefix + spawnArgs.WorkerName,
+		Name:        spawnArgs.WorkerName,
+		Type:        nomadJobTypeBatch,
+		Region:      h.Config.Region,
+		Namespace:   h.Config.Namespace,
+		Datacenters: h.Config.Datacenters,
+		Meta: map[string]string{
+			META_HATCHERY_NAME: h.Name(),
+			META_WORKER:        label,
+			META_WORKER_NAME:   spawnArgs.WorkerName,
+			META_WORKER_MODEL:  spawnArgs.Model.Name,
+			META_MODEL_PATH:    udataParam.Model,
+		},
+	}
+	if len(job.Datacenters) == 0 {
+		job.Datacenters = []string{"dc1"}
+	}
+
+	// Services are sidecar tasks of the worker task, they share the network namespace of the worker
+	// and are reachable with the name of the requirement.
+	var extraHosts []string
+	for _, r := range spawnArgs.Requirements {
+		if r.Type != sdk.ServiceRequirement {
+			continue
+		}
+		//name= <alias> => the name of the host put in /etc/hosts of the worker
+		//value= "postgres:latest env_1=blabla env_2=blabla"" => we can add env variables in requirement name
+		img, envm := hatchery.ParseRequirementModel(r.Value)
+
+		serviceConfig := map[string]interface{}{"image": img}
+		serviceMemory := int64(h.Config.DefaultMemory)
+		if sm, ok := envm["CDS_SERVICE_MEMORY"]; ok {
+			i, err := strconv.ParseInt(sm, 10, 64)
+			if err != nil {
+				log.Warning(ctx, "hatchery> nomad> SpawnWorker> Unable to parse CDS_SERVICE_MEMORY value '%s': %s", sm, err)
+				continue
+			}
+			serviceMemory = i
+			delete(envm, "CDS_SERVICE_MEMORY")
+		}
+		if sa, ok := envm["CDS_SERVICE_ARGS"]; ok {
+			serviceConfig["args"] = hatchery.ParseArgs(sa)
+			delete(envm, "CDS_SERVICE_ARGS")
+		}
+
+		group.Tasks = append(group.Tasks, nomadTask{
+			Name:      fmt.Sprintf("service-%d-%s", r.ID, strings.ToLower(r.Name)),
+			Driver:    nomadDriverDocker,
+			Config:    serviceConfig,
+			Env:       envm,
+			Resources: &nomadResources{MemoryMB: int(serviceMemory)},
+			Lifecycle: &nomadLifecycle{Hook: nomadLifecyclePrestart, Sidecar: true},
+		})
+		extraHosts = append(extraHosts, strings.ToLower(r.Name)+":127.0.0.1")
+	}
+	if len(extraHosts) > 0 {
+		group.Networks = []nomadNetwork{{Mode: nomadNetworkBridge}}
+		workerConfig["extra_hosts"] = append([]string{"worker:127.0.0.1"}, extraHosts...)
+		job.Meta[META_SERVICE_JOB_ID] = fmt.Sprintf("%d", spawnArgs.JobID)
+	}
+	job.TaskGroups = []nomadTaskGroup{group}
+
+	if err := h.nomadClient.registerJob(ctx, job); err != nil {
+		return sdk.WrapError(err, "cannot register nomad job %s", job.ID)
+	}
+
+	log.Debug("hatchery> nomad> SpawnWorker> %s > Job registered", spawnArgs.WorkerName)
+	return nil
+}
+
+// workerJobs returns the jobs of the workers spawned by the hatchery
+func (h *HatcheryNomad) workerJobs(ctx context.Context) ([]nomadJob, error) {
+	jobs, err := h.nomadClient.listJobs(ctx, h.Config.JobPrefix)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]nomadJob, 0, len(jobs))
+	for _, j := range jobs {
+		if j.Meta[META_HATCHERY_NAME] == h.Name() {
+			res = append(res, j)
+		}
+	}
+	return res, nil
+}
+
+// WorkersStarted returns the number of instances started but
+// not necessarily register on CDS yet
+func (h *HatcheryNomad) WorkersStarted(ctx context.Context) []string {
+	jobs, err := h.workerJobs(ctx)
+	if err != nil {
+		log.Warning(ctx, "WorkersStarted> unable to list nomad jobs: %v", err)
+		return nil
+	}
+	workerNames := make([]string, 0, len(jobs))
+	for _, j := range jobs {
+		if j.Stop || j.Status == nomadJobStatusDead {
+			continue
+		}
+		workerNames = append(workerNames, j.Meta[META_WORKER_NAME])
+	}
+	return workerNames
+}
+
+// WorkersStartedByModel returns the number of instances of given model started but
+// not necessarily register on CDS yet
+func (h *HatcheryNomad) WorkersStartedByModel(ctx context.Context, model *sdk.Model) int {
+	jobs, err := h.workerJobs(ctx)
+	if err != nil {
+		log.Error(ctx, "WorkersStartedByModel> Cannot get list of workers started (%s)", err)
+		return 0
+	}
+	workersLen := 0
+	for _, j := range jobs {
+		if j.Stop || j.Status == nomadJobStatusDead {
+			continue
+		}
+		if j.Meta[META_WORKER_MODEL] == model.Name {
+			workersLen++
+		}
+	}
+	return workersLen
+}
+
+// NeedRegistration return true if worker model need regsitration
+func (h *HatcheryNomad) NeedRegistration(ctx context.Context, m *sdk.Model) bool {
+	if m.NeedRegistration || m.LastRegistration.Unix() < m.UserLastModified.Unix() {
+		return true
+	}
+	return false
+}
+
+func (h *HatcheryNomad) routines(ctx context.Context) {
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			sdk.GoRoutine(ctx, "killAwolWorkers", func(ctx context.Context) {
+				if err := h.killAwolWorkers(ctx); err != nil {
+					log.Error(ctx, "hatchery> nomad> cannot kill awol workers: %v", err)
+				}
+			})
+		case <-ctx.Done():
+			if ctx.Err() != nil {
+				log.Error(ctx, "Hatchery> Nomad> Exiting routines")
+			}
+			return
+		}
+	}
+}
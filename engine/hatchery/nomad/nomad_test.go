@@ -0,0 +1,129 @@
+package nomad
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/h2non/gock.v1"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/hatchery"
+)
+
+func TestHatcheryNomad_WorkersStarted(t *testing.T) {
+	defer gock.Off()
+	h := NewHatcheryNomadTest(t)
+
+	jobs := []nomadJob{
+		{ID: "cds-w1", Status: "running", Meta: map[string]string{META_HATCHERY_NAME: "kyubi", META_WORKER_NAME: "w1"}},
+		{ID: "cds-wrong", Status: "running", Meta: map[string]string{META_HATCHERY_NAME: "jubi", META_WORKER_NAME: "wrong"}},
+		{ID: "cds-dead", Status: "dead", Meta: map[string]string{META_HATCHERY_NAME: "kyubi", META_WORKER_NAME: "dead"}},
+		{ID: "cds-w2", Status: "pending"},
+	}
+	gock.New("http://lolcat.nomad").Get("/v1/jobs").MatchParam("prefix", "cds-").MatchParam("namespace", "hachibi").Reply(http.StatusOK).JSON(jobs)
+	// Meta are not returned in the list by Nomad < 1.6
+	gock.New("http://lolcat.nomad").Get("/v1/job/cds-w2").Reply(http.StatusOK).JSON(nomadJob{
+		ID: "cds-w2", Meta: map[string]string{META_HATCHERY_NAME: "kyubi", META_WORKER_NAME: "w2"},
+	})
+
+	ws := h.WorkersStarted(context.TODO())
+	require.Equal(t, []string{"w1", "w2"}, ws)
+	require.True(t, gock.IsDone())
+}
+
+func TestHatcheryNomad_SpawnWorker(t *testing.T) {
+	defer gock.Off()
+	h := NewHatcheryNomadTest(t)
+	h.Config.Datacenters = []string{"gra"}
+	h.Config.CPUMHz = 1000
+
+	m := &sdk.Model{
+		Name: "model1",
+		Group: &sdk.Group{
+			Name: "group",
+		},
+		ModelDocker: sdk.ModelDocker{
+			Image: "my-worker:latest",
+			Shell: "sh -c",
+			Cmd:   "worker --api={{.API}}",
+		},
+	}
+
+	var jobRequest struct {
+		Job nomadJob
+	}
+	gock.New("http://lolcat.nomad").Post("/v1/jobs").
+		AddMatcher(func(r *http.Request, _ *gock.Request) (bool, error) {
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil {
+				return false, err
+			}
+			return true, json.Unmarshal(body, &jobRequest)
+		}).
+		Reply(http.StatusOK).JSON(map[string]string{"EvalID": "123"})
+
+	err := h.SpawnWorker(context.TODO(), hatchery.SpawnArguments{
+		JobID:      666,
+		Model:      m,
+		WorkerName: "nomad-toto",
+		Requirements: []sdk.Requirement{
+			{
+				Name:  "mem",
+				Type:  sdk.MemoryRequirement,
+				Value: "4096",
+			}, {
+				Name:  "cpu",
+				Type:  sdk.CPURequirement,
+				Value: "1.5",
+			}, {
+				ID:    1,
+				Name:  "pg",
+				Type:  sdk.ServiceRequirement,
+				Value: "postgresql:5.6.7 PG_USERNAME=toto CDS_SERVICE_MEMORY=512",
+			},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, gock.IsDone())
+
+	job := jobRequest.Job
+	assert.Equal(t, "cds-nomad-toto", job.ID)
+	assert.Equal(t, "batch", job.Type)
+	assert.Equal(t, []string{"gra"}, job.Datacenters)
+	assert.Equal(t, "kyubi", job.Meta[META_HATCHERY_NAME])
+	assert.Equal(t, "execution", job.Meta[META_WORKER])
+	assert.Equal(t, "nomad-toto", job.Meta[META_WORKER_NAME])
+	assert.Equal(t, "model1", job.Meta[META_WORKER_MODEL])
+	assert.Equal(t, "666", job.Meta[META_SERVICE_JOB_ID])
+
+	require.Len(t, job.TaskGroups, 1)
+	group := job.TaskGroups[0]
+	require.Len(t, group.Networks, 1)
+	assert.Equal(t, "bridge", group.Networks[0].Mode)
+	require.Len(t, group.Tasks, 2)
+
+	worker := group.Tasks[0]
+	assert.True(t, worker.Leader)
+	assert.Equal(t, 4096, worker.Resources.MemoryMB)
+	assert.Equal(t, 1500, worker.Resources.CPU)
+	assert.Equal(t, "my-worker:latest", worker.Config["image"])
+	assert.Equal(t, "sh", worker.Config["command"])
+	assert.Equal(t, []interface{}{"-c", "worker --api="}, worker.Config["args"])
+	assert.Equal(t, []interface{}{"worker:127.0.0.1", "pg:127.0.0.1"}, worker.Config["extra_hosts"])
+	assert.Equal(t, "666", worker.Env["CDS_BOOKED_WORKFLOW_JOB_ID"])
+	assert.Equal(t, "group/model1", worker.Env["CDS_MODEL_PATH"])
+
+	service := group.Tasks[1]
+	assert.Equal(t, "service-1-pg", service.Name)
+	assert.Equal(t, "postgresql:5.6.7", service.Config["image"])
+	assert.Equal(t, 512, service.Resources.MemoryMB)
+	assert.Equal(t, 0, service.Resources.CPU)
+	assert.Equal(t, map[string]string{"PG_USERNAME": "toto"}, service.Env)
+	require.NotNil(t, service.Lifecycle)
+	assert.True(t, service.Lifecycle.Sidecar)
+}
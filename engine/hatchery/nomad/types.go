@@ -0,0 +1,48 @@
+package nomad
+
+import (
+	"github.com/ovh/cds/engine/service"
+
+	hatcheryCommon "github.com/ovh/cds/engine/hatchery"
+)
+
+const (
+	META_HATCHERY_NAME  = "CDS_HATCHERY_NAME"
+	META_WORKER         = "CDS_WORKER"
+	META_WORKER_NAME    = "CDS_WORKER_NAME"
+	META_WORKER_MODEL   = "CDS_WORKER_MODEL"
+	META_MODEL_PATH     = "CDS_MODEL_PATH"
+	META_SERVICE_JOB_ID = "CDS_SERVICE_JOB_ID"
+
+	workerTaskName = "worker"
+)
+
+// HatcheryConfiguration is the configuration for nomad hatchery
+type HatcheryConfiguration struct {
+	service.HatcheryCommonConfiguration `mapstructure:"commonConfiguration" toml:"commonConfiguration" json:"commonConfiguration"`
+	// WorkerTTL Worker TTL (minutes)
+	WorkerTTL int `mapstructure:"workerTTL" toml:"workerTTL" default:"10" commented:"false" comment:"Worker TTL (minutes)" json:"workerTTL"`
+	// DefaultMemory Worker default memory
+	DefaultMemory int `mapstructure:"defaultMemory" toml:"defaultMemory" default:"1024" commented:"false" comment:"Worker default memory in Mo" json:"defaultMemory"`
+	// CPUMHz is the CPU in MHz given to a worker for each CPU of its cpu requirement
+	CPUMHz int `mapstructure:"cpuMHz" toml:"cpuMHz" default:"1000" commented:"false" comment:"CPU in MHz given to a worker for each CPU of its cpu requirement, the Nomad default CPU is used if there is no cpu requirement" json:"cpuMHz"`
+	// NomadAddress is the address of the Nomad HTTP API
+	NomadAddress string `mapstructure:"address" toml:"address" default:"http://127.0.0.1:4646" commented:"false" comment:"Address of the Nomad HTTP API" json:"address"`
+	// NomadToken is the ACL token used to call the Nomad API
+	NomadToken string `mapstructure:"token" toml:"token" default:"" commented:"true" comment:"Nomad ACL token (optional if ACLs are not enabled)" json:"-"`
+	// Namespace is the Nomad namespace in which workers are spawned
+	Namespace string `mapstructure:"namespace" toml:"namespace" default:"" commented:"true" comment:"Nomad namespace in which workers are spawned (Nomad Enterprise or Nomad >= 1.0)" json:"namespace"`
+	// Region is the Nomad region in which workers are spawned
+	Region string `mapstructure:"region" toml:"region" default:"" commented:"true" comment:"Nomad region in which workers are spawned, the region of the Nomad agent if empty" json:"region"`
+	// Datacenters are the Nomad datacenters in which workers are spawned
+	Datacenters []string `mapstructure:"datacenters" toml:"datacenters" default:"" commented:"true" comment:"Nomad datacenters in which workers are spawned, dc1 if empty" json:"datacenters"`
+	// JobPrefix is the prefix of the Nomad jobs ID
+	JobPrefix string `mapstructure:"jobPrefix" toml:"jobPrefix" default:"cds-" commented:"false" comment:"Prefix of the Nomad jobs spawned by this hatchery, the job ID is the prefix followed by the worker name" json:"jobPrefix"`
+}
+
+// HatcheryNomad implements HatcheryMode interface for nomad usage
+type HatcheryNomad struct {
+	hatcheryCommon.Common
+	Config      HatcheryConfiguration
+	nomadClient *nomadClient
+}
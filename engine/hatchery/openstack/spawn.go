@@ -27,7 +27,7 @@ func (h *HatcheryOpenstack) SpawnWorker(ctx context.Context, spawnArgs hatchery.
 		log.Debug("spawnWorker> spawning worker %s model:%s", spawnArgs.WorkerName, spawnArgs.Model.Name)
 	}
 
-	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly {
+	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly && !spawnArgs.Warm {
 		return sdk.WithStack(fmt.Errorf("no job ID and no register"))
 	}
 
@@ -190,13 +190,19 @@ func (h *HatcherySwarm) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 	ctx, end := observability.Span(ctx, "swarm.SpawnWorker")
 	defer end()
 
-	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly {
+	if spawnArgs.JobID == 0 && !spawnArgs.RegisterOnly && !spawnArgs.Warm {
 		return sdk.WithStack(fmt.Errorf("unable to spawn worker, no Job ID and no Register."))
 	}
 
 	observability.Current(ctx, observability.Tag(observability.TagWorker, spawnArgs.WorkerName))
 	log.Debug("hatchery> swarm> SpawnWorker> Spawning worker %s", spawnArgs.WorkerName)
 
+	// CPUs reserved for the worker
+	cpus, err := hatchery.RequiredCPUs(spawnArgs.Requirements)
+	if err != nil {
+		return err
+	}
+
 	// Choose a dockerEngine
 	var dockerClient *dockerClient
 	var foundDockerClient bool
@@ -215,6 +221,18 @@ func (h *HatcherySwarm) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 			continue
 		}
 
+		if cpus > 0 {
+			availableCPUs, err := h.availableCPUs(ctxList, dclient, containers)
+			if err != nil {
+				log.Error(ctx, "hatchery> swarm> SpawnWorker> %v", err)
+				continue
+			}
+			if availableCPUs < cpus {
+				log.Debug("hatchery> swarm> SpawnWorker> not enough cpus on %s. available:%v required:%v", dname, availableCPUs, cpus)
+				continue
+			}
+		}
+
 		if len(containers) == 0 {
 			dockerClient = h.dockerClients[dname]
 			foundDockerClient = true
@@ -354,6 +372,9 @@ func (h *HatcherySwarm) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 		"worker_requirements": strings.Join(services, ","),
 		"hatchery":            h.Config.Name,
 	}
+	if cpus > 0 {
+		labels[labelWorkerCPUs] = strconv.FormatFloat(cpus, 'f', -1, 64)
+	}
 
 	// Add new options on hatchery swarm to allow advanced docker option such as addHost, priviledge, port mapping and so one: #4594
 	dockerOpts, errDockerOpts := h.computeDockerOpts(spawnArgs.Requirements)
@@ -407,6 +428,9 @@ func (h *HatcherySwarm) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 	if spawnArgs.JobID > 0 {
 		envsWm["CDS_BOOKED_WORKFLOW_JOB_ID"] = fmt.Sprintf("%d", spawnArgs.JobID)
 	}
+	if cpus > 0 {
+		envsWm["CDS_MODEL_CPU"] = strconv.FormatFloat(cpus, 'f', -1, 64)
+	}
 
 	envTemplated, errEnv := sdk.TemplateEnvs(udataParam, modelEnvs)
 	if errEnv != nil {
@@ -432,6 +456,7 @@ func (h *HatcherySwarm) SpawnWorker(ctx context.Context, spawnArgs hatchery.Spaw
 		cmd:          cmds,
 		labels:       labels,
 		memory:       memory,
+		cpus:         cpus,
 		dockerOpts:   *dockerOpts,
 		entryPoint:   []string{},
 		env:          envs,
@@ -465,6 +490,11 @@ func (h *HatcherySwarm) CanSpawn(ctx context.Context, model *sdk.Model, jobID in
 			return false
 		}
 	}
+	cpus, err := hatchery.RequiredCPUs(requirements)
+	if err != nil {
+		log.Debug("CanSpawn> Job %d has an invalid cpu requirement: %v", jobID, err)
+		return false
+	}
 	for dockerName, dockerClient := range h.dockerClients {
 		//List all containers to check if we can spawn a new one
 		cs, errList := h.getContainers(dockerClient, types.ContainerListOptions{All: true})
@@ -493,6 +523,19 @@ func (h *HatcherySwarm) CanSpawn(ctx context.Context, model *sdk.Model, jobID in
 			continue
 		}
 
+		//Checking the cpus not reserved on the docker engine
+		if cpus > 0 {
+			availableCPUs, err := h.availableCPUs(ctx, dockerClient, cs)
+			if err != nil {
+				log.Error(ctx, "hatchery> swarm> CanSpawn> %v", err)
+				continue
+			}
+			if availableCPUs < cpus {
+				log.Debug("hatchery> swarm> CanSpawn> not enough cpus on %s. available:%v required:%v", dockerName, availableCPUs, cpus)
+				continue
+			}
+		}
+
 		//Get links from requirements
 		links := map[string]string{}
 		for _, r := range requirements {
@@ -522,6 +565,25 @@ func (h *HatcherySwarm) CanSpawn(ctx context.Context, model *sdk.Model, jobID in
 	return false
 }
 
+// availableCPUs returns the number of CPUs of the docker engine that are not reserved by workers.
+func (h *HatcherySwarm) availableCPUs(ctx context.Context, dockerClient *dockerClient, containers []types.Container) (float64, error) {
+	info, err := dockerClient.Info(ctx)
+	if err != nil {
+		return 0, sdk.WrapError(err, "unable to get info of docker engine %s", dockerClient.name)
+	}
+	available := float64(info.NCPU)
+	for _, cont := range containers {
+		v, ok := cont.Labels[labelWorkerCPUs]
+		if !ok || cont.State == "exited" || cont.State == "dead" {
+			continue
+		}
+		if cpus, err := strconv.ParseFloat(v, 64); err == nil {
+			available -= cpus
+		}
+	}
+	return available, nil
+}
+
 func (h *HatcherySwarm) getWorkerContainers(containers []types.Container, option types.ContainerListOptions) ([]types.Container, error) {
 	res := []types.Container{}
 	//We only count worker
@@ -1,7 +1,9 @@
 package swarm
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/network"
@@ -10,7 +12,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/h2non/gock.v1"
+	"io/ioutil"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 )
@@ -568,3 +572,103 @@ func TestHatcherySwarm_CanSpawnNoDockerClient(t *testing.T) {
 	assert.False(t, b)
 	assert.True(t, gock.IsDone())
 }
+
+func TestHatcherySwarm_CanSpawnCPURequirement(t *testing.T) {
+	defer gock.Off()
+	h := InitTestHatcherySwarm(t)
+	h.dockerClients["default"].MaxContainers = 10
+
+	m := sdk.Model{
+		ID:   1,
+		Name: "my-model",
+		Group: &sdk.Group{
+			ID:   1,
+			Name: "mygroup",
+		},
+	}
+	jobID := int64(1)
+
+	containers := []types.Container{
+		{
+			Names:  []string{"swarmy-worker1"},
+			State:  "running",
+			Labels: map[string]string{"hatchery": "swarmy", "worker_name": "swarmy-worker1", labelWorkerCPUs: "2"},
+		},
+		{
+			Names:  []string{"swarmy-worker2"},
+			State:  "exited",
+			Labels: map[string]string{"hatchery": "swarmy", "worker_name": "swarmy-worker2", labelWorkerCPUs: "2"},
+		},
+	}
+	gock.New("https://lolcat.host").Get("/v6.66/containers/json").Times(2).Reply(http.StatusOK).JSON(containers)
+	gock.New("https://lolcat.host").Get("/v6.66/info").Times(2).Reply(http.StatusOK).JSON(types.Info{NCPU: 4})
+
+	assert.True(t, h.CanSpawn(context.TODO(), &m, jobID, []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "1.5"}}))
+	assert.False(t, h.CanSpawn(context.TODO(), &m, jobID, []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "3"}}))
+	assert.False(t, h.CanSpawn(context.TODO(), &m, jobID, []sdk.Requirement{{Name: "cpu", Type: sdk.CPURequirement, Value: "two"}}))
+	assert.True(t, gock.IsDone())
+}
+
+func TestHatcherySwarm_SpawnCPURequirement(t *testing.T) {
+	defer gock.Off()
+	h := InitTestHatcherySwarm(t)
+	h.Config.Name = "swarmy"
+	h.dockerClients["default"].MaxContainers = 2
+
+	m := sdk.Model{
+		ID:   1,
+		Name: "my-model",
+		Group: &sdk.Group{
+			ID:   1,
+			Name: "mygroup",
+		},
+		ModelDocker: sdk.ModelDocker{
+			Image: "model:9",
+		},
+	}
+
+	containers := []types.Container{
+		{
+			Names:  []string{"postgresql"},
+			Labels: map[string]string{"hatchery": "swarmy"},
+		},
+	}
+	gock.New("https://lolcat.host").Get("/v6.66/containers/json").Reply(http.StatusOK).JSON(containers)
+	gock.New("https://lolcat.host").Get("/v6.66/info").Reply(http.StatusOK).JSON(types.Info{NCPU: 4})
+	gock.New("https://lolcat.host").Post("/v6.66/images/create").MatchParam("fromImage", "model").MatchParam("tag", "9").Reply(http.StatusOK).JSON(nil)
+	gock.New("https://lolcat.host").Post("/v6.66/containers/create").MatchParam("name", "swarmy-*").Reply(http.StatusOK).JSON(container.ContainerCreateCreatedBody{ID: "workerIDContainer"})
+	gock.New("https://lolcat.host").Post("/v6.66/containers/workerIDContainer/start").Reply(http.StatusOK).JSON(nil)
+
+	var created bool
+	gock.Observe(func(request *http.Request, mock gock.Mock) {
+		if mock == nil || request.Body == nil || request.Method != http.MethodPost || !strings.HasPrefix(request.URL.Path, "/v6.66/containers/create") {
+			return
+		}
+		bodyContent, err := ioutil.ReadAll(request.Body)
+		require.NoError(t, err)
+		request.Body = ioutil.NopCloser(bytes.NewReader(bodyContent))
+		var body struct {
+			Env        []string
+			Labels     map[string]string
+			HostConfig container.HostConfig
+		}
+		require.NoError(t, json.Unmarshal(bodyContent, &body))
+		assert.Equal(t, int64(1500000000), body.HostConfig.NanoCPUs)
+		assert.Equal(t, "1.5", body.Labels[labelWorkerCPUs])
+		assert.Contains(t, body.Env, "CDS_MODEL_CPU=1.5")
+		created = true
+	})
+	defer gock.Observe(nil)
+
+	err := h.SpawnWorker(context.TODO(), hatchery.SpawnArguments{
+		JobID:      1,
+		Model:      &m,
+		WorkerName: "swarmy-worker1",
+		Requirements: []sdk.Requirement{
+			{Name: "cpu", Type: sdk.CPURequirement, Value: "1.5"},
+		},
+	})
+	assert.NoError(t, err)
+	assert.True(t, created)
+	require.True(t, gock.IsDone())
+}
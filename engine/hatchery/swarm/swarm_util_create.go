@@ -44,6 +44,7 @@ type containerArgs struct {
 	cmd, env                           []string
 	labels                             map[string]string
 	memory                             int64
+	cpus                               float64
 	dockerOpts                         dockerOpts
 	entryPoint                         strslice.StrSlice
 }
@@ -88,6 +89,9 @@ func (h *HatcherySwarm) createAndStartContainer(ctx context.Context, dockerClien
 		Memory:     cArgs.memory * 1024 * 1024, //from MB to B
 		MemorySwap: -1,
 	}
+	if cArgs.cpus > 0 {
+		hostConfig.Resources.NanoCPUs = int64(cArgs.cpus * 1e9)
+	}
 
 	networkingConfig := &network.NetworkingConfig{
 		EndpointsConfig: map[string]*network.EndpointSettings{},
@@ -7,6 +7,9 @@ import (
 	hatcheryCommon "github.com/ovh/cds/engine/hatchery"
 )
 
+// labelWorkerCPUs is the label of a worker container that contains the number of CPUs reserved for the worker
+const labelWorkerCPUs = "worker_cpus"
+
 // HatcheryConfiguration is the configuration for hatchery
 type HatcheryConfiguration struct {
 	service.HatcheryCommonConfiguration `mapstructure:"commonConfiguration" toml:"commonConfiguration"`
@@ -113,7 +113,7 @@ func (h *HatcheryVSphere) CheckConfiguration(cfg interface{}) error {
 // requirements are not supported
 func (h *HatcheryVSphere) CanSpawn(ctx context.Context, model *sdk.Model, jobID int64, requirements []sdk.Requirement) bool {
 	for _, r := range requirements {
-		if r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement || r.Type == sdk.HostnameRequirement {
+		if r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement || r.Type == sdk.CPURequirement || r.Type == sdk.HostnameRequirement {
 			return false
 		}
 	}
@@ -63,7 +63,7 @@ func (s *Service) nodeRunToTask(nr sdk.WorkflowNodeRun) (sdk.Task, error) {
 			Type:   TypeOutgoingWebHook,
 			Config: config,
 		}, nil
-	case sdk.WorkflowModelName:
+	case sdk.WorkflowModelName, sdk.SubWorkflowModelName:
 		return sdk.Task{
 			UUID:   uuid,
 			Type:   TypeOutgoingWorkflow,
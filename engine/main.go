@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 	_ "github.com/spf13/viper/remote"
 
+	"github.com/ovh/cds/engine/hatchery/local"
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/doc"
 )
@@ -19,7 +20,8 @@ func init() {
 	mainCmd.AddCommand(startCmd)
 	mainCmd.AddCommand(configCmd)
 	mainCmd.AddCommand(downloadCmd)
-	mainCmd.AddCommand(docCmd) // hidden command
+	mainCmd.AddCommand(docCmd)             // hidden command
+	mainCmd.AddCommand(localWorkerInitCmd) // hidden command
 }
 
 func main() {
@@ -66,3 +68,15 @@ var docCmd = &cobra.Command{
 		}
 	},
 }
+
+var localWorkerInitCmd = &cobra.Command{
+	Use:                local.IsolatedWorkerInitCommand + " <spec> <worker-binary> [args...]",
+	Short:              "start an isolated worker of a local hatchery, used internally by the hatchery",
+	Hidden:             true,
+	DisableFlagParsing: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := local.InitIsolatedWorker(args); err != nil {
+			sdk.Exit(err.Error())
+		}
+	},
+}
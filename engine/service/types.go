@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/rsa"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -43,13 +44,14 @@ type HatcheryCommonConfiguration struct {
 		MaxHeartbeatFailures int    `toml:"maxHeartbeatFailures" default:"10" comment:"Maximum allowed consecutives failures on heatbeat routine" json:"maxHeartbeatFailures"`
 	} `toml:"api" json:"api"`
 	Provision struct {
-		RatioService              *int   `toml:"ratioService" default:"50" commented:"true" comment:"Percent reserved for spawning worker with service requirement" json:"ratioService,omitempty" mapstructure:"ratioService"`
-		MaxWorker                 int    `toml:"maxWorker" default:"10" comment:"Maximum allowed simultaneous workers" json:"maxWorker"`
-		MaxConcurrentProvisioning int    `toml:"maxConcurrentProvisioning" default:"10" comment:"Maximum allowed simultaneous workers provisioning" json:"maxConcurrentProvisioning"`
-		MaxConcurrentRegistering  int    `toml:"maxConcurrentRegistering" default:"2" comment:"Maximum allowed simultaneous workers registering. -1 to disable registering on this hatchery" json:"maxConcurrentRegistering"`
-		RegisterFrequency         int    `toml:"registerFrequency" default:"60" comment:"Check if some worker model have to be registered each n Seconds" json:"registerFrequency"`
-		Region                    string `toml:"region" default:"" comment:"region of this hatchery - optional. With a free text as 'myregion', user can set a prerequisite 'region' with value 'myregion' on CDS Job" json:"region"`
-		IgnoreJobWithNoRegion     bool   `toml:"ignoreJobWithNoRegion" default:"false" comment:"Ignore job without a region prerequisite if ignoreJobWithNoRegion=true"`
+		RatioService              *int                    `toml:"ratioService" default:"50" commented:"true" comment:"Percent reserved for spawning worker with service requirement" json:"ratioService,omitempty" mapstructure:"ratioService"`
+		MaxWorker                 int                     `toml:"maxWorker" default:"10" comment:"Maximum allowed simultaneous workers" json:"maxWorker"`
+		MaxConcurrentProvisioning int                     `toml:"maxConcurrentProvisioning" default:"10" comment:"Maximum allowed simultaneous workers provisioning" json:"maxConcurrentProvisioning"`
+		MaxConcurrentRegistering  int                     `toml:"maxConcurrentRegistering" default:"2" comment:"Maximum allowed simultaneous workers registering. -1 to disable registering on this hatchery" json:"maxConcurrentRegistering"`
+		RegisterFrequency         int                     `toml:"registerFrequency" default:"60" comment:"Check if some worker model have to be registered each n Seconds" json:"registerFrequency"`
+		Region                    string                  `toml:"region" default:"" comment:"region of this hatchery - optional. With a free text as 'myregion', user can set a prerequisite 'region' with value 'myregion' on CDS Job" json:"region"`
+		IgnoreJobWithNoRegion     bool                    `toml:"ignoreJobWithNoRegion" default:"false" comment:"Ignore job without a region prerequisite if ignoreJobWithNoRegion=true"`
+		WarmPools                 []WarmPoolConfiguration `toml:"warmPools" comment:"Warm worker pools: pre-started and registered idle workers kept for a worker model, a job is assigned to a warm worker without waiting for a spawn" json:"warmPools,omitempty" mapstructure:"warmPools"`
 		WorkerLogsOptions         struct {
 			Graylog struct {
 				Host       string `toml:"host" comment:"Example: thot.ovh.com" json:"host"`
@@ -68,6 +70,82 @@ type HatcheryCommonConfiguration struct {
 	} `toml:"logOptions" comment:"Hatchery Log Configuration" json:"logOptions"`
 }
 
+// WarmPoolConfiguration is the configuration of a warm worker pool of an hatchery.
+type WarmPoolConfiguration struct {
+	Model     string                          `toml:"model" comment:"Worker model path: group/name" json:"model"`
+	Size      int                             `toml:"size" default:"0" comment:"Number of idle workers to keep when no schedule matches" json:"size"`
+	Schedules []WarmPoolScheduleConfiguration `toml:"schedules" comment:"Pool size for a time of day, the first matching schedule is used" json:"schedules,omitempty" mapstructure:"schedules"`
+}
+
+// WarmPoolScheduleConfiguration overrides the size of a warm worker pool for a time of day.
+type WarmPoolScheduleConfiguration struct {
+	From string `toml:"from" comment:"Start of the schedule, format: 15:04" json:"from"`
+	To   string `toml:"to" comment:"End of the schedule (excluded), format: 15:04. Can be before 'from' for a schedule over midnight" json:"to"`
+	Size int    `toml:"size" comment:"Number of idle workers to keep during the schedule" json:"size"`
+}
+
+const warmPoolScheduleLayout = "15:04"
+
+// Check returns an error if the schedule is invalid.
+func (s WarmPoolScheduleConfiguration) Check() error {
+	if _, err := time.Parse(warmPoolScheduleLayout, s.From); err != nil {
+		return fmt.Errorf("invalid 'from' value %q, expected format is %s", s.From, warmPoolScheduleLayout)
+	}
+	if _, err := time.Parse(warmPoolScheduleLayout, s.To); err != nil {
+		return fmt.Errorf("invalid 'to' value %q, expected format is %s", s.To, warmPoolScheduleLayout)
+	}
+	if s.Size < 0 {
+		return fmt.Errorf("size cannot be negative")
+	}
+	return nil
+}
+
+// Match returns true if given time of day is in the schedule.
+func (s WarmPoolScheduleConfiguration) Match(t time.Time) bool {
+	from, err := time.Parse(warmPoolScheduleLayout, s.From)
+	if err != nil {
+		return false
+	}
+	to, err := time.Parse(warmPoolScheduleLayout, s.To)
+	if err != nil {
+		return false
+	}
+	minutes := t.Hour()*60 + t.Minute()
+	fromMinutes := from.Hour()*60 + from.Minute()
+	toMinutes := to.Hour()*60 + to.Minute()
+	if fromMinutes <= toMinutes {
+		return minutes >= fromMinutes && minutes < toMinutes
+	}
+	// The schedule is over midnight
+	return minutes >= fromMinutes || minutes < toMinutes
+}
+
+// SizeAt returns the size of the pool at given time.
+func (c WarmPoolConfiguration) SizeAt(t time.Time) int {
+	for _, s := range c.Schedules {
+		if s.Match(t) {
+			return s.Size
+		}
+	}
+	return c.Size
+}
+
+// Check returns an error if the pool configuration is invalid.
+func (c WarmPoolConfiguration) Check() error {
+	if len(strings.SplitN(c.Model, "/", 2)) != 2 {
+		return fmt.Errorf("invalid model %q, expected format is group/name", c.Model)
+	}
+	if c.Size < 0 {
+		return fmt.Errorf("size cannot be negative")
+	}
+	for i := range c.Schedules {
+		if err := c.Schedules[i].Check(); err != nil {
+			return fmt.Errorf("invalid schedule %d: %v", i, err)
+		}
+	}
+	return nil
+}
+
 func (hcc HatcheryCommonConfiguration) Check() error {
 	if hcc.Provision.MaxConcurrentProvisioning > hcc.Provision.MaxWorker {
 		return fmt.Errorf("maxConcurrentProvisioning (value: %d) cannot be less than maxWorker (value: %d) ",
@@ -91,6 +169,12 @@ func (hcc HatcheryCommonConfiguration) Check() error {
 		return fmt.Errorf("please enter a name in your hatchery configuration")
 	}
 
+	for _, p := range hcc.Provision.WarmPools {
+		if err := p.Check(); err != nil {
+			return fmt.Errorf("invalid warm pool for model %s: %v", p.Model, err)
+		}
+	}
+
 	return nil
 }
 
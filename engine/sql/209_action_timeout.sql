@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE "action" ADD COLUMN IF NOT EXISTS timeout BIGINT DEFAULT 0;
+
+-- +migrate Down
+
+ALTER TABLE "action" DROP COLUMN IF EXISTS timeout;
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE "action" ADD COLUMN IF NOT EXISTS matrix JSONB;
+
+-- +migrate Down
+
+ALTER TABLE "action" DROP COLUMN IF EXISTS matrix;
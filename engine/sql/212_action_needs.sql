@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE "action" ADD COLUMN IF NOT EXISTS needs JSONB DEFAULT '[]'::jsonb;
+
+-- +migrate Down
+
+ALTER TABLE "action" DROP COLUMN IF EXISTS needs;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE "w_node_context" ADD COLUMN IF NOT EXISTS concurrency JSONB;
+ALTER TABLE "workflow_node_run" ADD COLUMN IF NOT EXISTS concurrency_group VARCHAR(256);
+SELECT create_index('workflow_node_run', 'IDX_WORKFLOW_NODE_RUN_CONCURRENCY_GROUP', 'concurrency_group,status');
+
+-- +migrate Down
+
+DROP INDEX IF EXISTS IDX_WORKFLOW_NODE_RUN_CONCURRENCY_GROUP;
+ALTER TABLE "workflow_node_run" DROP COLUMN IF EXISTS concurrency_group;
+ALTER TABLE "w_node_context" DROP COLUMN IF EXISTS concurrency;
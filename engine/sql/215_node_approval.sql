@@ -0,0 +1,9 @@
+-- +migrate Up
+
+ALTER TABLE "w_node_context" ADD COLUMN IF NOT EXISTS approval JSONB;
+ALTER TABLE "workflow_node_run" ADD COLUMN IF NOT EXISTS approvals JSONB;
+
+-- +migrate Down
+
+ALTER TABLE "workflow_node_run" DROP COLUMN IF EXISTS approvals;
+ALTER TABLE "w_node_context" DROP COLUMN IF EXISTS approval;
@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE "environment" ADD COLUMN IF NOT EXISTS freezes JSONB;
+
+-- +migrate Down
+
+ALTER TABLE "environment" DROP COLUMN IF EXISTS freezes;
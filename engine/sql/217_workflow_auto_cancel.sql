@@ -0,0 +1,7 @@
+-- +migrate Up
+
+ALTER TABLE "workflow" ADD COLUMN IF NOT EXISTS auto_cancel BOOLEAN NOT NULL DEFAULT false;
+
+-- +migrate Down
+
+ALTER TABLE "workflow" DROP COLUMN IF EXISTS auto_cancel;
@@ -0,0 +1,11 @@
+-- +migrate Up
+
+ALTER TABLE "action" ADD COLUMN IF NOT EXISTS priority INT DEFAULT 0;
+ALTER TABLE "workflow" ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+ALTER TABLE "workflow_node_run_job" ADD COLUMN IF NOT EXISTS priority INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+
+ALTER TABLE "action" DROP COLUMN IF EXISTS priority;
+ALTER TABLE "workflow" DROP COLUMN IF EXISTS priority;
+ALTER TABLE "workflow_node_run_job" DROP COLUMN IF EXISTS priority;
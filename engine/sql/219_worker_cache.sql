@@ -0,0 +1,18 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS "worker_cache" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "project_id" BIGINT NOT NULL,
+    "integration_name" TEXT NOT NULL,
+    "tag" TEXT NOT NULL,
+    "size" BIGINT NOT NULL DEFAULT 0,
+    "created" TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP,
+    "last_access" TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+
+SELECT create_unique_index('worker_cache','IDX_WORKER_CACHE_TAG','project_id,integration_name,tag');
+SELECT create_foreign_key_idx_cascade('FK_WORKER_CACHE_PROJECT', 'worker_cache', 'project', 'project_id', 'id');
+
+-- +migrate Down
+
+DROP TABLE worker_cache;
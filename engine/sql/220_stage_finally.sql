@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "pipeline_stage" ADD COLUMN IF NOT EXISTS finally BOOLEAN DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE "pipeline_stage" DROP COLUMN IF EXISTS finally;
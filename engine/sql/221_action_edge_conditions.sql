@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "action_edge" ADD COLUMN IF NOT EXISTS conditions JSONB;
+
+-- +migrate Down
+ALTER TABLE "action_edge" DROP COLUMN IF EXISTS conditions;
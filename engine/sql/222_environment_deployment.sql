@@ -0,0 +1,36 @@
+-- +migrate Up
+
+CREATE TABLE IF NOT EXISTS "environment_deployment" (
+    "id" BIGSERIAL PRIMARY KEY,
+    "project_id" BIGINT NOT NULL,
+    "environment_id" BIGINT NOT NULL,
+    "environment_name" TEXT NOT NULL,
+    "application_id" BIGINT NOT NULL,
+    "application_name" TEXT NOT NULL,
+    "integration_name" TEXT NOT NULL DEFAULT '',
+    "workflow_id" BIGINT NOT NULL,
+    "workflow_name" TEXT NOT NULL,
+    "workflow_node_id" BIGINT NOT NULL,
+    "workflow_node_name" TEXT NOT NULL,
+    "workflow_run_id" BIGINT NOT NULL,
+    "workflow_node_run_id" BIGINT NOT NULL,
+    "num" BIGINT NOT NULL,
+    "subnum" BIGINT NOT NULL DEFAULT 0,
+    "version" TEXT NOT NULL DEFAULT '',
+    "vcs_repository" TEXT NOT NULL DEFAULT '',
+    "vcs_branch" TEXT NOT NULL DEFAULT '',
+    "vcs_tag" TEXT NOT NULL DEFAULT '',
+    "vcs_hash" TEXT NOT NULL DEFAULT '',
+    "artifacts" JSONB,
+    "deployed" TIMESTAMP WITH TIME ZONE DEFAULT LOCALTIMESTAMP
+);
+
+SELECT create_index('environment_deployment','IDX_ENVIRONMENT_DEPLOYMENT_DEPLOYED','environment_id,deployed');
+SELECT create_foreign_key_idx_cascade('FK_ENVIRONMENT_DEPLOYMENT_PROJECT', 'environment_deployment', 'project', 'project_id', 'id');
+SELECT create_foreign_key_idx_cascade('FK_ENVIRONMENT_DEPLOYMENT_ENVIRONMENT', 'environment_deployment', 'environment', 'environment_id', 'id');
+SELECT create_foreign_key_idx_cascade('FK_ENVIRONMENT_DEPLOYMENT_APPLICATION', 'environment_deployment', 'application', 'application_id', 'id');
+SELECT create_foreign_key_idx_cascade('FK_ENVIRONMENT_DEPLOYMENT_WORKFLOW', 'environment_deployment', 'workflow', 'workflow_id', 'id');
+
+-- +migrate Down
+
+DROP TABLE environment_deployment;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "worker" ADD COLUMN IF NOT EXISTS "warm" BOOLEAN NOT NULL DEFAULT false;
+
+-- +migrate Down
+ALTER TABLE "worker" DROP COLUMN IF EXISTS "warm";
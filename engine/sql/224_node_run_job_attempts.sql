@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE "workflow_node_run_job" ADD COLUMN IF NOT EXISTS "attempts" INT NOT NULL DEFAULT 0;
+
+-- +migrate Down
+ALTER TABLE "workflow_node_run_job" DROP COLUMN IF EXISTS "attempts";
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/engine/worker/internal"
+	"github.com/ovh/cds/sdk"
+)
+
+var cmdAppendStages = &cobra.Command{
+	Use:   "append-stages",
+	Short: "worker append-stages <file>",
+	Long: `
+Inside a step script (https://ovh.github.io/cds/docs/actions/builtin-script/), you can generate stages and jobs that will be added to the current pipeline:
+
+	worker append-stages pipeline.yml
+
+Use - to read the pipeline on the standard input:
+
+	./compute-jobs.sh | worker append-stages -
+
+The file uses the pipeline format (https://ovh.github.io/cds/docs/concepts/files/pipeline-syntax/), the name of the pipeline is not used.
+The stages of the file are run after the last stage of the current pipeline, when the job succeeded. The stage names must not already exist in the pipeline.
+
+If the command is called several times in a job, the last file is kept. If the generated pipeline is not valid, the job fails.
+
+	`,
+	Run: appendStagesCmd,
+}
+
+func appendStagesCmd(cmd *cobra.Command, args []string) {
+	portS := os.Getenv(internal.WorkerServerPort)
+	if portS == "" {
+		sdk.Exit("%s not found, are you running inside a CDS worker job?\n", internal.WorkerServerPort)
+	}
+
+	port, err := strconv.Atoi(portS)
+	if err != nil {
+		sdk.Exit("cannot parse '%s' as a port number", portS)
+	}
+
+	if len(args) != 1 {
+		sdk.Exit("Wrong usage: See '%s'\n", cmd.Short)
+	}
+
+	var data []byte
+	if args[0] == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(args[0])
+	}
+	if err != nil {
+		sdk.Exit("cannot read pipeline: %s\n", err)
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/stages", port), bytes.NewReader(data))
+	if err != nil {
+		sdk.Exit("cannot append stages: %s\n", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		sdk.Exit("cannot append stages: %s\n", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		if cdsError := sdk.DecodeError(body); cdsError != nil {
+			sdk.Exit("cannot append stages: %v\n", cdsError)
+		}
+		sdk.Exit("cannot append stages: HTTP %d\n", resp.StatusCode)
+	}
+}
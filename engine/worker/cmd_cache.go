@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 
 	"github.com/ovh/cds/engine/worker/internal"
@@ -56,6 +57,17 @@ Here, an example of a script inside a CDS Job using the cache feature:
 	# put in cache the updated .m2/ directory
 	worker cache push $tag .m2/
 
+## Cache keys
+
+Instead of a tag, the cache can be identified by a key computed from the content of files with hashFiles(pattern, ...).
+When pulling a cache, restore keys are prefixes used in the given order to find the most recent cache if there is no cache with the key:
+
+	worker cache pull --key 'go-hashFiles(go.sum)' --restore-key go-
+	go build ./...
+	worker cache push --key 'go-hashFiles(go.sum)' $(go env GOMODCACHE)
+
+The caches of a project that were not used for the longest time are deleted when the project exceeds the maximum cache size.
+
     `,
 	}
 	cmdCacheRoot.AddCommand(cmdCachePush(), cmdCachePull())
@@ -63,7 +75,24 @@ Here, an example of a script inside a CDS Job using the cache feature:
 	return cmdCacheRoot
 }
 
-var cmdStorageIntegrationName string
+var (
+	cmdStorageIntegrationName string
+	cmdCacheKey               string
+	cmdCacheRestoreKeys       []string
+)
+
+// cacheKey returns the cache key computed in the current directory
+func cacheKey(cmdName string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		sdk.Exit("worker cache %s > Cannot find working directory : %s", cmdName, err)
+	}
+	key, err := sdk.ComputeCacheKey(afero.NewOsFs(), cwd, cmdCacheKey)
+	if err != nil {
+		sdk.Exit("worker cache %s > Cannot compute cache key : %s", cmdName, err)
+	}
+	return key
+}
 
 func cmdCachePush() *cobra.Command {
 	c := &cobra.Command{
@@ -76,11 +105,15 @@ Inside a project, you can create a cache from your worker with a tag (useful for
 
 You can use you storage integration:
 	worker cache push --destination=MyStorageIntegration  <tagValue> dir/file
+
+With a key computed from the content of files, all the arguments are the files to upload:
+	worker cache push --key 'npm-hashFiles(**/package-lock.json)' node_modules
 		`,
 		Example: "worker cache push {{.cds.workflow}}-{{.cds.version}} ./pathToUpload",
 		Run:     cachePushCmd(),
 	}
 	c.Flags().StringVar(&cmdStorageIntegrationName, "destination", "", "optional. Your storage integration name")
+	c.Flags().StringVar(&cmdCacheKey, "key", "", "optional. The key of the cache, hashFiles(pattern, ...) is replaced by the hash of the matching files")
 	return c
 }
 
@@ -96,13 +129,22 @@ func cachePushCmd() func(cmd *cobra.Command, args []string) {
 			sdk.Exit("worker cache push > Cannot parse '%s' as a port number : %s", portS, errPort)
 		}
 
-		if len(args) < 2 {
-			sdk.Exit("worker cache push > Wrong usage: Example : worker cache push myTagValue filea fileb filec")
-		}
-
-		files := make([]string, len(args)-1)
-		for i, arg := range args[1:] {
-			files[i] = arg
+		var tag, encodedTag string
+		var files []string
+		if cmdCacheKey != "" {
+			if len(args) < 1 {
+				sdk.Exit("worker cache push > Wrong usage: Example : worker cache push --key 'go-hashFiles(go.sum)' filea fileb filec")
+			}
+			tag = cacheKey("push")
+			encodedTag = tag
+			files = args
+		} else {
+			if len(args) < 2 {
+				sdk.Exit("worker cache push > Wrong usage: Example : worker cache push myTagValue filea fileb filec")
+			}
+			tag = args[0]
+			encodedTag = base64.RawURLEncoding.EncodeToString([]byte(args[0]))
+			files = args[1:]
 		}
 
 		cwd, err := os.Getwd()
@@ -111,7 +153,7 @@ func cachePushCmd() func(cmd *cobra.Command, args []string) {
 		}
 
 		c := sdk.Cache{
-			Tag:              base64.RawURLEncoding.EncodeToString([]byte(args[0])),
+			Tag:              encodedTag,
 			Files:            files,
 			WorkingDirectory: cwd,
 			IntegrationName:  cmdStorageIntegrationName,
@@ -122,7 +164,7 @@ func cachePushCmd() func(cmd *cobra.Command, args []string) {
 			sdk.Exit("worker cache push > internal error (%s)", errMarshal)
 		}
 
-		fmt.Printf("Worker cache push in progress... (tag: %s)\n", args[0])
+		fmt.Printf("Worker cache push in progress... (tag: %s)\n", tag)
 		req, errRequest := http.NewRequest(
 			"POST",
 			fmt.Sprintf("http://127.0.0.1:%d/cache/push", port),
@@ -150,7 +192,7 @@ func cachePushCmd() func(cmd *cobra.Command, args []string) {
 			sdk.Exit("Error: http code %d : %v", resp.StatusCode, cdsError)
 		}
 
-		fmt.Printf("Worker cache push with success (tag: %s)\n", args[0])
+		fmt.Printf("Worker cache push with success (tag: %s)\n", tag)
 	}
 }
 
@@ -178,10 +220,16 @@ If you want to push a cache into a storage integration:
 
 	worker cache push latest --from=MyStorageIntegration {{.cds.workspace}}/pathToUpload
 
+With a key computed from the content of files and restore keys used if there is no cache with this key:
+
+	worker cache pull --key 'npm-hashFiles(**/package-lock.json)' --restore-key npm-
+
 		`,
 		Run: cachePullCmd(),
 	}
 	c.Flags().StringVar(&cmdStorageIntegrationName, "from", "", "optional. Your storage integration name")
+	c.Flags().StringVar(&cmdCacheKey, "key", "", "optional. The key of the cache, hashFiles(pattern, ...) is replaced by the hash of the matching files")
+	c.Flags().StringArrayVar(&cmdCacheRestoreKeys, "restore-key", nil, "optional. A prefix used to find the most recent cache if there is no cache with the key, can be repeated")
 	return c
 }
 
@@ -197,8 +245,16 @@ func cachePullCmd() func(cmd *cobra.Command, args []string) {
 			sdk.Exit("worker cache pull > cannot parse '%s' as a port number: %s", portS, errPort)
 		}
 
-		if len(args) < 1 {
-			sdk.Exit("worker cache pull > Wrong usage: Example : worker cache pull myTagValue")
+		var tag, encodedTag string
+		if cmdCacheKey != "" {
+			tag = cacheKey("pull")
+			encodedTag = tag
+		} else {
+			if len(args) < 1 {
+				sdk.Exit("worker cache pull > Wrong usage: Example : worker cache pull myTagValue")
+			}
+			tag = args[0]
+			encodedTag = base64.RawURLEncoding.EncodeToString([]byte(args[0]))
 		}
 
 		dir, err := filepath.Abs(filepath.Dir(os.Args[0]))
@@ -206,17 +262,21 @@ func cachePullCmd() func(cmd *cobra.Command, args []string) {
 			sdk.Exit("worker cache pull > cannot get current path: %s", err)
 		}
 
-		fmt.Printf("Worker cache pull in progress... (tag: %s)\n", args[0])
+		q := url.Values{}
+		q.Set("path", dir)
+		q.Set("integration", cmdStorageIntegrationName)
+		for _, k := range cmdCacheRestoreKeys {
+			q.Add("restoreKey", k)
+		}
+
+		fmt.Printf("Worker cache pull in progress... (tag: %s)\n", tag)
 		req, errRequest := http.NewRequest(
 			"GET",
-			fmt.Sprintf("http://127.0.0.1:%d/cache/%s/pull?path=%s&integration=%s", port,
-				base64.RawURLEncoding.EncodeToString([]byte(args[0])),
-				url.QueryEscape(dir),
-				url.QueryEscape(cmdStorageIntegrationName)),
+			fmt.Sprintf("http://127.0.0.1:%d/cache/%s/pull?%s", port, encodedTag, q.Encode()),
 			nil,
 		)
 		if errRequest != nil {
-			sdk.Exit("worker cache pull > cannot post worker cache pull with tag %s (Request): %s", args[0], errRequest)
+			sdk.Exit("worker cache pull > cannot post worker cache pull with tag %s (Request): %s", tag, errRequest)
 		}
 
 		client := http.DefaultClient
@@ -236,6 +296,11 @@ func cachePullCmd() func(cmd *cobra.Command, args []string) {
 			sdk.Exit("Error: %v", cdsError)
 		}
 
-		fmt.Printf("Worker cache pull with success (tag: %s)\n", args[0])
+		var pulled sdk.Cache
+		if err := json.NewDecoder(resp.Body).Decode(&pulled); err == nil && pulled.Tag != encodedTag {
+			fmt.Printf("Worker cache pull with success (tag: %s, restored from: %s)\n", tag, pulled.Tag)
+			return
+		}
+		fmt.Printf("Worker cache pull with success (tag: %s)\n", tag)
 	}
 }
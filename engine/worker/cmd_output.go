@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ovh/cds/engine/worker/internal"
+	"github.com/ovh/cds/sdk"
+)
+
+var cmdOutput = &cobra.Command{
+	Use:   "output",
+	Short: "worker output <name> <value>",
+	Long: `
+Inside a step script (https://ovh.github.io/cds/docs/actions/builtin-script/), you can set the value of an output declared by the job with the worker command:
+
+	worker output version 1.2.3
+
+
+The value must match the type of the output (string, number, boolean or json). All the outputs declared by the job must be set before the end of the job, else the job fails.
+
+## Scope
+
+You can use the output in :
+
+* the next jobs of the pipeline with ` + "`{{.cds.outputs.jobName.outputName}}`" + `
+* the next pipelines ` + "`{{.cds.outputs.jobName.outputName}}`" + ` or ` + "`{{.workflow.pipelineName.outputs.jobName.outputName}}`" + ` with ` + "`pipelineName`" + ` the name of the pipeline in your workflow
+
+Characters of the job name other than letters, digits, '-' and '_' are replaced by '_'.
+
+	`,
+	Run: outputCmd,
+}
+
+func outputCmd(cmd *cobra.Command, args []string) {
+	portS := os.Getenv(internal.WorkerServerPort)
+	if portS == "" {
+		sdk.Exit("%s not found, are you running inside a CDS worker job?\n", internal.WorkerServerPort)
+	}
+
+	port, err := strconv.Atoi(portS)
+	if err != nil {
+		sdk.Exit("cannot parse '%s' as a port number", portS)
+	}
+
+	if len(args) != 2 {
+		sdk.Exit("Wrong usage: See '%s'\n", cmd.Short)
+	}
+
+	v := sdk.Variable{
+		Name:  args[0],
+		Type:  sdk.StringVariable,
+		Value: args[1],
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		sdk.Exit("internal error (%s)\n", err)
+	}
+
+	req, err := http.NewRequest("POST", fmt.Sprintf("http://127.0.0.1:%d/output", port), bytes.NewReader(data))
+	if err != nil {
+		sdk.Exit("cannot set output: %s\n", err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		sdk.Exit("cannot set output: %s\n", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		if cdsError := sdk.DecodeError(body); cdsError != nil {
+			sdk.Exit("cannot set output: %v\n", cdsError)
+		}
+		sdk.Exit("cannot set output: HTTP %d\n", resp.StatusCode)
+	}
+}
@@ -0,0 +1,11 @@
+package internal
+
+import (
+	"context"
+	"time"
+)
+
+// SetJobTimeoutFunc replaces the function that creates the context of the steps of a job with its timeout.
+func (w *CurrentWorker) SetJobTimeoutFunc(f func(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc)) {
+	w.withJobTimeout = f
+}
@@ -130,7 +130,23 @@ func cachePullHandler(ctx context.Context, wk *CurrentWorker) http.HandlerFunc {
 		integrationName := sdk.DefaultIfEmptyStorage(req.FormValue("integration"))
 		params := wk.currentJob.wJob.Parameters
 		projectKey := sdk.ParameterValue(params, "cds.project")
-		r, err := wk.client.WorkflowCachePull(projectKey, integrationName, vars["ref"])
+
+		// With restore keys, the most recent cache matching the key or one of the restore keys is pulled
+		ref := vars["ref"]
+		if restoreKeys := req.Form["restoreKey"]; len(restoreKeys) > 0 {
+			entry, err := wk.client.WorkflowCacheEntry(projectKey, integrationName, ref, restoreKeys)
+			if err != nil {
+				err = sdk.Error{
+					Message: "worker cache pull > Cannot find cache: " + err.Error(),
+					Status:  http.StatusNotFound,
+				}
+				writeError(w, req, err)
+				return
+			}
+			ref = entry.Tag
+		}
+
+		r, err := wk.client.WorkflowCachePull(projectKey, integrationName, ref)
 		if err != nil {
 			err = sdk.Error{
 				Message: "worker cache pull > Cannot pull cache: " + err.Error(),
@@ -231,5 +247,7 @@ func cachePullHandler(ctx context.Context, wk *CurrentWorker) http.HandlerFunc {
 				_ = f.Close()
 			}
 		}
+
+		writeJSON(w, sdk.Cache{Tag: ref}, http.StatusOK)
 	}
 }
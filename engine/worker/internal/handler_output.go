@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+func addOutputHandler(ctx context.Context, wk *CurrentWorker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, sdk.NewError(sdk.ErrWrongRequest, err))
+			return
+		}
+
+		var v sdk.Variable
+		if err := json.Unmarshal(data, &v); err != nil {
+			writeError(w, r, sdk.NewError(sdk.ErrWrongRequest, err))
+			return
+		}
+
+		if wk.currentJob.wJob == nil {
+			writeError(w, r, sdk.NewErrorFrom(sdk.ErrWrongRequest, "no job is running"))
+			return
+		}
+
+		output := wk.currentJob.wJob.Job.Action.Outputs.Find(v.Name)
+		if output == nil {
+			writeError(w, r, sdk.NewErrorFrom(sdk.ErrWrongRequest, "output %s is not declared by job %s", v.Name, wk.currentJob.wJob.Job.Action.Name))
+			return
+		}
+		if err := output.CheckValue(v.Value); err != nil {
+			writeError(w, r, err)
+			return
+		}
+
+		wk.setOutput(v.Name, v.Value)
+		log.Debug("Output %s set for job %d", v.Name, wk.currentJob.wJob.ID)
+	}
+}
+
+func (wk *CurrentWorker) setOutput(name, value string) {
+	for i := range wk.currentJob.outputs {
+		if wk.currentJob.outputs[i].Name == name {
+			wk.currentJob.outputs[i].Value = value
+			return
+		}
+	}
+	wk.currentJob.outputs = append(wk.currentJob.outputs, sdk.Variable{
+		Name:  name,
+		Type:  sdk.StringVariable,
+		Value: value,
+	})
+}
+
+// checkOutputs returns an error if one of the declared outputs was not set by the job steps
+func (wk *CurrentWorker) checkOutputs(declared sdk.JobOutputs) error {
+	var missing []string
+	for _, o := range declared {
+		if sdk.VariableFind(wk.currentJob.outputs, o.Name) == nil {
+			missing = append(missing, o.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return sdk.NewErrorFrom(sdk.ErrWrongRequest, "missing declared outputs: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
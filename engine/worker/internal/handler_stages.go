@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/exportentities"
+	"github.com/ovh/cds/sdk/log"
+)
+
+func addStagesHandler(ctx context.Context, wk *CurrentWorker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, r, sdk.NewError(sdk.ErrWrongRequest, err))
+			return
+		}
+
+		if wk.currentJob.wJob == nil {
+			writeError(w, r, sdk.NewErrorFrom(sdk.ErrWrongRequest, "no job is running"))
+			return
+		}
+
+		// The pipeline is checked by the API at the end of the job, only its syntax is checked here
+		nbJobs, err := countPipelineJobs(data)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		if nbJobs == 0 {
+			writeError(w, r, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid pipeline: no job found"))
+			return
+		}
+
+		wk.currentJob.dynamicPipeline = data
+		log.Debug("Pipeline with %d jobs set for job %d", nbJobs, wk.currentJob.wJob.ID)
+	}
+}
+
+func countPipelineJobs(data []byte) (int, error) {
+	pipeliner, err := exportentities.ParsePipeline(exportentities.FormatYAML, data)
+	if err != nil {
+		return 0, err
+	}
+	pip, err := pipeliner.Pipeline()
+	if err != nil {
+		return 0, err
+	}
+	var nbJobs int
+	for _, s := range pip.Stages {
+		nbJobs += len(s.Jobs)
+	}
+	return nbJobs, nil
+}
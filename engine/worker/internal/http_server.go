@@ -52,7 +52,9 @@ func (w *CurrentWorker) Serve(c context.Context) error {
 	r.HandleFunc("/download", LogMiddleware(downloadHandler(c, w)))
 	r.HandleFunc("/exit", LogMiddleware(exitHandler(c, w)))
 	r.HandleFunc("/key/{key}/install", LogMiddleware(keyInstallHandler(c, w)))
+	r.HandleFunc("/output", LogMiddleware(addOutputHandler(c, w)))
 	r.HandleFunc("/services/{type}", LogMiddleware(serviceHandler(c, w)))
+	r.HandleFunc("/stages", LogMiddleware(addStagesHandler(c, w)))
 	r.HandleFunc("/tag", LogMiddleware(tagHandler(c, w)))
 	r.HandleFunc("/tmpl", LogMiddleware(tmplHandler(c, w)))
 	r.HandleFunc("/upload", LogMiddleware(uploadHandler(c, w)))
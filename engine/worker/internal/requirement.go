@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +24,7 @@ var requirementCheckFuncs = map[string]func(w *CurrentWorker, r sdk.Requirement)
 	sdk.PluginRequirement:   checkPluginRequirement,
 	sdk.ServiceRequirement:  checkServiceRequirement,
 	sdk.MemoryRequirement:   checkMemoryRequirement,
+	sdk.CPURequirement:      checkCPURequirement,
 	sdk.VolumeRequirement:   checkVolumeRequirement,
 	sdk.OSArchRequirement:   checkOSArchRequirement,
 	sdk.RegionRequirement:   checkRegionRequirement,
@@ -178,6 +180,23 @@ func checkMemoryRequirement(w *CurrentWorker, r sdk.Requirement) (bool, error) {
 	return totalMemory >= (neededMemory*1024*1024)*90/100, nil
 }
 
+func checkCPURequirement(w *CurrentWorker, r sdk.Requirement) (bool, error) {
+	neededCPUs, err := sdk.ParseCPURequirement(r.Value)
+	if err != nil {
+		return false, err
+	}
+
+	// Container hatcheries give the number of CPUs reserved for the worker
+	if cpuEnv := os.Getenv("CDS_MODEL_CPU"); cpuEnv != "" {
+		cpus, err := strconv.ParseFloat(cpuEnv, 64)
+		if err != nil {
+			return false, err
+		}
+		return cpus >= neededCPUs, nil
+	}
+	return float64(runtime.NumCPU()) >= neededCPUs, nil
+}
+
 func checkVolumeRequirement(w *CurrentWorker, r sdk.Requirement) (bool, error) {
 	// volume are supported only for Model Docker
 	if w.model.Type != sdk.Docker {
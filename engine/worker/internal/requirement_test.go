@@ -63,3 +63,39 @@ func TestCheckHostnameRequirement(t *testing.T) {
 		t.Fatalf("Requirement should not be ok")
 	}
 }
+
+func TestCheckCPURequirement(t *testing.T) {
+	r := sdk.Requirement{
+		Type:  sdk.CPURequirement,
+		Value: "1",
+	}
+
+	ok, err := checkRequirement(nil, r)
+	if err != nil {
+		t.Fatalf("checkRequirement should not fail: %s", err)
+	}
+	if !ok {
+		t.Fatalf("Requirement should be ok")
+	}
+
+	os.Setenv("CDS_MODEL_CPU", "1.5")
+	defer os.Unsetenv("CDS_MODEL_CPU")
+
+	r.Value = "1.5"
+	ok, err = checkRequirement(nil, r)
+	if err != nil {
+		t.Fatalf("checkRequirement should not fail: %s", err)
+	}
+	if !ok {
+		t.Fatalf("Requirement should be ok")
+	}
+
+	r.Value = "2"
+	ok, err = checkRequirement(nil, r)
+	if err != nil {
+		t.Fatalf("checkRequirement should not fail: %s", err)
+	}
+	if ok {
+		t.Fatalf("Requirement should not be ok")
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/interpolate"
 	"github.com/ovh/cds/sdk/log"
+	"github.com/ovh/cds/sdk/luascript"
 )
 
 func processVariablesAndParameters(action *sdk.Action, jobParameters []sdk.Parameter, jobSecrets []sdk.Variable) error {
@@ -118,9 +119,25 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 		BuildID: jobID,
 	}
 
+	// Steps are executed with a context that expires with the job timeout, step status
+	// updates still use the job context so they can be sent after the timeout
+	runCtx := ctx
+	timeout := time.Duration(a.Timeout) * time.Second
+	if timeout > 0 {
+		withTimeout := context.WithTimeout
+		if w.withJobTimeout != nil {
+			withTimeout = w.withJobTimeout
+		}
+		var cancel context.CancelFunc
+		runCtx, cancel = withTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	var nDisabled, nCriticalFailed int
+	var timedOut bool
 	for jobStepIndex, step := range a.Actions {
 		ctx = workerruntime.SetStepOrder(ctx, jobStepIndex)
+		runCtx = workerruntime.SetStepOrder(runCtx, jobStepIndex)
 		if err := w.updateStepStatus(ctx, jobID, jobStepIndex, sdk.StatusBuilding); err != nil {
 			jobResult.Status = sdk.StatusFail
 			jobResult.Reason = fmt.Sprintf("Cannot update step (%d) status (%s): %v", jobStepIndex, sdk.StatusBuilding, err)
@@ -130,8 +147,15 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 			Status:  sdk.StatusNeverBuilt,
 			BuildID: jobID,
 		}
-		if nCriticalFailed == 0 || step.AlwaysExecuted {
-			stepResult = w.runAction(ctx, step, jobID, secrets, step.Name)
+		if !timedOut && (nCriticalFailed == 0 || step.AlwaysExecuted) {
+			stepResult = w.runAction(runCtx, step, jobID, secrets, step.Name)
+
+			// The job context is still valid if the step context ended because of the timeout
+			if runCtx.Err() != nil && ctx.Err() == nil {
+				timedOut = true
+				stepResult.Status = sdk.StatusFail
+				w.SendLog(ctx, workerruntime.LevelError, fmt.Sprintf("Job timed out after %s", timeout))
+			}
 
 			// Check if all newVariables are in currentJob.params
 			// variable can be add in w.currentJob.newVariables by worker command export
@@ -152,7 +176,7 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 			}
 
 			switch stepResult.Status {
-			case sdk.StatusDisabled:
+			case sdk.StatusDisabled, sdk.StatusSkipped:
 				nDisabled++
 			case sdk.StatusFail:
 				if !step.Optional {
@@ -178,9 +202,37 @@ func (w *CurrentWorker) runJob(ctx context.Context, a *sdk.Action, jobID int64,
 	if nCriticalFailed > 0 {
 		jobResult.Status = sdk.StatusFail
 	}
+	if timedOut {
+		jobResult.Status = sdk.StatusFail
+		w.sendJobTimeoutSpawnInfo(ctx, jobID, timeout)
+	}
+
+	// Declared outputs are sent with the job result, the job fails if one of them is missing
+	if jobResult.Status == sdk.StatusSuccess {
+		if err := w.checkOutputs(a.Outputs); err != nil {
+			jobResult.Status = sdk.StatusFail
+			jobResult.Reason = sdk.ExtractHTTPError(err, "").Message
+			w.SendLog(ctx, workerruntime.LevelError, jobResult.Reason)
+		}
+	}
+	jobResult.Outputs = w.currentJob.outputs
+	jobResult.DynamicPipeline = string(w.currentJob.dynamicPipeline)
+
 	return jobResult
 }
 
+func (w *CurrentWorker) sendJobTimeoutSpawnInfo(ctx context.Context, jobID int64, timeout time.Duration) {
+	sp := sdk.SpawnMsg{ID: sdk.MsgSpawnInfoJobTimeout.ID, Args: []interface{}{timeout.String()}}
+	infos := []sdk.SpawnInfo{{
+		RemoteTime:  time.Now(),
+		Message:     sp,
+		UserMessage: sp.DefaultUserMessage(),
+	}}
+	if err := w.Client().QueueJobSendSpawnInfo(ctx, jobID, infos); err != nil {
+		log.Error(ctx, "runJob> Unable to send spawn info: %v", err)
+	}
+}
+
 func (w *CurrentWorker) runAction(ctx context.Context, a sdk.Action, jobID int64, secrets []sdk.Variable, actionName string) sdk.Result {
 	log.Info(ctx, "runAction> start action %s %s %d", a.StepName, actionName, jobID)
 	defer func() { log.Info(ctx, "runAction> end action %s %s run %d", a.StepName, actionName, jobID) }()
@@ -202,6 +254,25 @@ func (w *CurrentWorker) runAction(ctx context.Context, a sdk.Action, jobID int64
 		}
 	}
 
+	// If the conditions of the step are not satisfied; skip it
+	if a.Conditions != nil {
+		ok, err := checkStepConditions(*a.Conditions, w.currentJob.params)
+		if err != nil {
+			return sdk.Result{
+				Status:  sdk.StatusFail,
+				BuildID: jobID,
+				Reason:  fmt.Sprintf("Unable to check step conditions: %v", err),
+			}
+		}
+		if !ok {
+			w.SendLog(ctx, workerruntime.LevelInfo, fmt.Sprintf("Step \"%s\" skipped: conditions not satisfied", actionName))
+			return sdk.Result{
+				Status:  sdk.StatusSkipped,
+				BuildID: jobID,
+			}
+		}
+	}
+
 	// Replace variable placeholder that may have been added by last step
 	if err := w.replaceVariablesPlaceholder(&a, w.currentJob.params); err != nil {
 		return sdk.Result{
@@ -277,7 +348,9 @@ func (w *CurrentWorker) runSteps(ctx context.Context, steps []sdk.Action, a sdk.
 
 		if !criticalStepFailed || child.AlwaysExecuted {
 			r = w.runAction(ctx, child, jobID, secrets, childName)
-			if r.Status != sdk.StatusSuccess && !child.Optional {
+			if r.Status == sdk.StatusSkipped {
+				nbDisabledChildren++
+			} else if r.Status != sdk.StatusSuccess && !child.Optional {
 				criticalStepFailed = true
 			}
 		} else if criticalStepFailed && !child.AlwaysExecuted {
@@ -310,6 +383,25 @@ func (w *CurrentWorker) runSteps(ctx context.Context, steps []sdk.Action, a sdk.
 	return r, nbDisabledChildren
 }
 
+// checkStepConditions evaluates the conditions of a step on the job parameters, like the conditions of a stage.
+func checkStepConditions(conditions sdk.WorkflowNodeConditions, params []sdk.Parameter) (bool, error) {
+	if conditions.Expression != "" {
+		return sdk.WorkflowCheckConditionsExpression(conditions.Expression, params)
+	}
+	if conditions.LuaScript == "" {
+		return sdk.WorkflowCheckConditions(conditions.PlainConditions, params)
+	}
+	luacheck, err := luascript.NewCheck()
+	if err != nil {
+		return false, err
+	}
+	luacheck.SetVariables(sdk.ParametersToMap(params))
+	if err := luacheck.Perform(conditions.LuaScript); err != nil {
+		return false, err
+	}
+	return luacheck.Result, nil
+}
+
 func (w *CurrentWorker) updateStepStatus(ctx context.Context, buildID int64, stepOrder int, status string) error {
 	step := sdk.StepStatus{
 		StepOrder: stepOrder,
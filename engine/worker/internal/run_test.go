@@ -31,3 +31,31 @@ func Test_processVariablesAndParameters(t *testing.T) {
 	assert.Equal(t, expectedJobParameters, string(actualJobParameters))
 
 }
+
+func Test_checkStepConditions(t *testing.T) {
+	params := []sdk.Parameter{
+		{Name: "git.branch", Type: sdk.StringParameter, Value: "master"},
+		{Name: "cds.build.debug", Type: sdk.StringParameter, Value: "true"},
+	}
+
+	ok, err := checkStepConditions(sdk.WorkflowNodeConditions{
+		PlainConditions: []sdk.WorkflowNodeCondition{{Variable: "git.branch", Operator: sdk.WorkflowConditionsOperatorEquals, Value: "master"}},
+	}, params)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = checkStepConditions(sdk.WorkflowNodeConditions{Expression: `git.branch == "develop"`}, params)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = checkStepConditions(sdk.WorkflowNodeConditions{Expression: `cds.build.debug == "true"`}, params)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = checkStepConditions(sdk.WorkflowNodeConditions{LuaScript: `return git_branch == "master"`}, params)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = checkStepConditions(sdk.WorkflowNodeConditions{Expression: `git.branch ==`}, params)
+	assert.Error(t, err)
+}
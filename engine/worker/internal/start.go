@@ -10,11 +10,13 @@ import (
 	"github.com/ovh/cds/sdk/log"
 )
 
+// StartWorker registers the worker and runs the booked job. If bookedJobID is 0, the worker is a warm worker
+// that waits for a job assigned by its hatchery.
 func StartWorker(ctx context.Context, w *CurrentWorker, bookedJobID int64) (mainError error) {
-	log.Info(ctx, "Starting worker %s on job %d", w.Name(), bookedJobID)
-
 	if bookedJobID == 0 {
-		return fmt.Errorf("startWorker: bookedJobID is mandatory. val:%d", bookedJobID)
+		log.Info(ctx, "Starting warm worker %s", w.Name())
+	} else {
+		log.Info(ctx, "Starting worker %s on job %d", w.Name(), bookedJobID)
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
@@ -55,6 +57,16 @@ func StartWorker(ctx context.Context, w *CurrentWorker, bookedJobID int64) (main
 		}
 	}
 
+	if bookedJobID == 0 {
+		var err error
+		bookedJobID, err = waitForJobAssignment(ctx, w, refreshTick)
+		if err != nil {
+			endFunc()
+			return err
+		}
+		log.Info(ctx, "Job %d assigned to warm worker %s", bookedJobID, w.Name())
+	}
+
 	if err := processBookedWJob(ctx, w, jobsChan, bookedJobID); err != nil {
 		// Unbook job
 		if errR := w.Client().QueueJobRelease(ctx, bookedJobID); errR != nil {
@@ -139,6 +151,38 @@ func StartWorker(ctx context.Context, w *CurrentWorker, bookedJobID int64) (main
 	}
 }
 
+// waitForJobAssignment waits until a job is assigned to the warm worker by its hatchery.
+// The worker keeps sending its heartbeat to CDS API while it's waiting.
+func waitForJobAssignment(ctx context.Context, w *CurrentWorker, refreshTick *time.Ticker) (int64, error) {
+	tick := time.NewTicker(2 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-refreshTick.C:
+			if err := w.Client().WorkerRefresh(ctx); err != nil {
+				log.Error(ctx, "Heartbeat failed: %v", err)
+			}
+		case <-tick.C:
+			wk, err := w.Client().WorkerMe(ctx)
+			if err != nil {
+				if strings.Contains(err.Error(), "not authenticated") {
+					return 0, sdk.WrapError(err, "unable to get worker")
+				}
+				log.Error(ctx, "waitForJobAssignment> unable to get worker: %v", err)
+				continue
+			}
+			if wk.Status == sdk.StatusDisabled {
+				return 0, fmt.Errorf("worker %s has been disabled", wk.Name)
+			}
+			if wk.JobRunID != nil && *wk.JobRunID > 0 {
+				return *wk.JobRunID, nil
+			}
+		}
+	}
+}
+
 func processBookedWJob(ctx context.Context, w *CurrentWorker, wjobs chan<- sdk.WorkflowNodeJobRun, bookedWJobID int64) error {
 	log.Debug("Try to take the workflow node job %d", bookedWJobID)
 	wjob, err := w.Client().QueueJobInfo(ctx, bookedWJobID)
@@ -32,8 +32,10 @@ func (w *CurrentWorker) Take(ctx context.Context, job sdk.WorkflowNodeJobRun) er
 	// Set build variables
 	w.currentJob.wJob = &info.NodeJobRun
 	w.currentJob.secrets = info.Secrets
-	// Reset build variables
+	// Reset build variables, outputs and generated pipeline
 	w.currentJob.newVariables = nil
+	w.currentJob.outputs = nil
+	w.currentJob.dynamicPipeline = nil
 
 	if info.SigningKey != "" {
 		secretKey := make([]byte, 32)
@@ -51,18 +51,22 @@ type CurrentWorker struct {
 		model       string
 	}
 	currentJob struct {
-		wJob         *sdk.WorkflowNodeJobRun
-		newVariables []sdk.Variable
-		params       []sdk.Parameter
-		secrets      []sdk.Variable
-		context      context.Context
-		signer       jose.Signer
+		wJob            *sdk.WorkflowNodeJobRun
+		newVariables    []sdk.Variable
+		outputs         []sdk.Variable
+		dynamicPipeline []byte
+		params          []sdk.Parameter
+		secrets         []sdk.Variable
+		context         context.Context
+		signer          jose.Signer
 	}
 	status struct {
 		Name   string `json:"name"`
 		Status string `json:"status"`
 	}
 	client cdsclient.WorkerInterface
+	// withJobTimeout creates the context of the steps of a job with a timeout, context.WithTimeout if nil
+	withJobTimeout func(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc)
 }
 
 // BuiltInAction defines builtin action signature
@@ -3,6 +3,8 @@ package main
 func main() {
 	cmd := cmdMain()
 	cmd.AddCommand(cmdExport)
+	cmd.AddCommand(cmdOutput)
+	cmd.AddCommand(cmdAppendStages)
 	cmd.AddCommand(cmdUpload())
 	cmd.AddCommand(cmdArtifacts())
 	cmd.AddCommand(cmdDownload())
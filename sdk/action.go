@@ -39,17 +39,25 @@ func NewAction(name string) *Action {
 
 // Action is the base element of CDS pipeline
 type Action struct {
-	ID          int64  `json:"id" yaml:"-" db:"id"`
-	GroupID     *int64 `json:"group_id,omitempty" yaml:"-" db:"group_id"`
-	Name        string `json:"name" db:"name"`
-	Type        string `json:"type" yaml:"-" db:"type"`
-	Description string `json:"description" yaml:"desc,omitempty" db:"description"`
-	Enabled     bool   `json:"enabled" yaml:"-" db:"enabled"`
-	Deprecated  bool   `json:"deprecated" yaml:"-" db:"deprecated"`
+	ID          int64       `json:"id" yaml:"-" db:"id"`
+	GroupID     *int64      `json:"group_id,omitempty" yaml:"-" db:"group_id"`
+	Name        string      `json:"name" db:"name"`
+	Type        string      `json:"type" yaml:"-" db:"type"`
+	Description string      `json:"description" yaml:"desc,omitempty" db:"description"`
+	Enabled     bool        `json:"enabled" yaml:"-" db:"enabled"`
+	Deprecated  bool        `json:"deprecated" yaml:"-" db:"deprecated"`
+	Timeout     int64       `json:"timeout,omitempty" yaml:"-" db:"timeout"`   // in seconds, only used for joined actions
+	Retry       *JobRetry   `json:"retry,omitempty" yaml:"-" db:"retry"`       // only used for joined actions
+	Matrix      JobMatrix   `json:"matrix,omitempty" yaml:"-" db:"matrix"`     // only used for joined actions
+	Needs       StringSlice `json:"needs,omitempty" yaml:"-" db:"needs"`       // names of the jobs of the stage that should succeed before, only used for joined actions
+	Outputs     JobOutputs  `json:"outputs,omitempty" yaml:"-" db:"outputs"`   // only used for joined actions
+	Priority    int         `json:"priority,omitempty" yaml:"-" db:"priority"` // higher priority jobs are taken first in the queue, only used for joined actions
 	// aggregates from action_edge
 	StepName       string `json:"step_name,omitempty" yaml:"step_name,omitempty" db:"-"`
 	Optional       bool   `json:"optional" yaml:"-" db:"-"`
 	AlwaysExecuted bool   `json:"always_executed" yaml:"-" db:"-"`
+	// conditions evaluated by the worker before running the step
+	Conditions *WorkflowNodeConditions `json:"conditions,omitempty" yaml:"-" db:"-"`
 	// aggregates
 	Requirements RequirementList `json:"requirements" db:"-"`
 	Parameters   []Parameter     `json:"parameters" db:"-"`
@@ -126,6 +134,28 @@ func (a Action) IsValid() error {
 		return NewErrorFrom(ErrWrongRequest, "invalid name for action")
 	}
 
+	if a.Timeout < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid timeout for action")
+	}
+
+	if err := CheckPriority(a.Priority); err != nil {
+		return err
+	}
+
+	if a.Retry != nil {
+		if err := a.Retry.IsValid(); err != nil {
+			return err
+		}
+	}
+
+	if err := a.Matrix.IsValid(); err != nil {
+		return err
+	}
+
+	if err := a.Outputs.IsValid(); err != nil {
+		return err
+	}
+
 	for i := range a.Parameters {
 		if err := a.Parameters[i].IsValid(); err != nil {
 			return err
@@ -36,6 +36,8 @@ type ExecutedJob struct {
 	Reason     string       `json:"reason" db:"-"`
 	WorkerName string       `json:"worker_name" db:"-"`
 	WorkerID   string       `json:"worker_id" db:"-"`
+	// Matrix contains the values of the matrix variables for this execution of the job
+	Matrix JobMatrixCombination `json:"matrix,omitempty" db:"-"`
 }
 
 // ExecutedJobSummary is a light representation of ExecutedJob for CDS event
@@ -48,6 +50,7 @@ type ExecutedJobSummary struct {
 	PipelineActionID  int64               `json:"pipeline_action_id"`
 	PipelineStageID   int64               `json:"pipeline_stage_id"`
 	Steps             []ActionSummary     `json:"steps"`
+	Matrix            map[string]string   `json:"matrix,omitempty"`
 }
 
 // ToSummary transforms an ExecutedJob to an ExecutedJobSummary
@@ -58,6 +61,7 @@ func (j ExecutedJob) ToSummary() ExecutedJobSummary {
 		WorkerName:       j.WorkerName,
 		PipelineActionID: j.PipelineActionID,
 		PipelineStageID:  j.PipelineStageID,
+		Matrix:           j.Matrix,
 	}
 	sum.StepStatusSummary = make([]StepStatusSummary, len(j.StepStatus))
 	for i := range j.StepStatus {
@@ -110,6 +114,8 @@ type BuildState struct {
 const (
 	StatusPending           = "Pending"
 	StatusWaiting           = "Waiting"
+	StatusWaitingApproval   = "WaitingApproval"
+	StatusFrozen            = "Frozen"
 	StatusChecking          = "Checking" // DEPRECATED, to remove when removing pipelineBuild
 	StatusBuilding          = "Building"
 	StatusSuccess           = "Success"
@@ -126,7 +132,7 @@ const (
 // StatusIsTerminated returns if status is terminated (nothing related to building or waiting, ...)
 func StatusIsTerminated(status string) bool {
 	switch status {
-	case StatusPending, StatusBuilding, StatusWaiting, "": // A stage does not have status when he's waiting a previous stage
+	case StatusPending, StatusBuilding, StatusWaiting, StatusWaitingApproval, StatusFrozen, "": // A stage does not have status when he's waiting a previous stage
 		return false
 	default:
 		return true
@@ -2,12 +2,18 @@ package sdk
 
 import (
 	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/afero"
 )
@@ -24,6 +30,155 @@ type Cache struct {
 
 	Files            []string `json:"files"`
 	WorkingDirectory string   `json:"working_directory"`
+	RestoreKeys      []string `json:"restore_keys,omitempty"`
+}
+
+// CacheEntry is a cache stored for a project, identified by its tag
+type CacheEntry struct {
+	ID              int64     `json:"id" db:"id"`
+	ProjectID       int64     `json:"project_id" db:"project_id"`
+	IntegrationName string    `json:"integration_name" db:"integration_name"`
+	Tag             string    `json:"tag" db:"tag"`
+	Size            int64     `json:"size" db:"size"`
+	Created         time.Time `json:"created" db:"created"`
+	LastAccess      time.Time `json:"last_access" db:"last_access"`
+}
+
+// SelectCacheEntry returns the entry with the given key, or the most recent entry
+// starting with the first matching restore key prefix.
+func SelectCacheEntry(entries []CacheEntry, key string, restoreKeys []string) *CacheEntry {
+	for i := range entries {
+		if entries[i].Tag == key {
+			return &entries[i]
+		}
+	}
+	for _, prefix := range restoreKeys {
+		var found *CacheEntry
+		for i := range entries {
+			if strings.HasPrefix(entries[i].Tag, prefix) && (found == nil || entries[i].Created.After(found.Created)) {
+				found = &entries[i]
+			}
+		}
+		if found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// CacheEntriesToEvict returns the entries to delete to keep the total size under maxSize,
+// the entries that were not accessed for the longest time are deleted first.
+func CacheEntriesToEvict(entries []CacheEntry, maxSize int64) []CacheEntry {
+	sorted := make([]CacheEntry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].LastAccess.After(sorted[j].LastAccess)
+	})
+
+	var size int64
+	var evicted []CacheEntry
+	for _, e := range sorted {
+		if size+e.Size > maxSize {
+			evicted = append(evicted, e)
+			continue
+		}
+		size += e.Size
+	}
+	return evicted
+}
+
+var cacheKeyHashFilesRegex = regexp.MustCompile(`hashFiles\(([^)]*)\)`)
+
+// ComputeCacheKey replaces each hashFiles(pattern, ...) in the key by the sha256 of the files of the directory
+// matching one of the patterns. Patterns use the syntax of path.Match on the relative path of the files,
+// a pattern starting with **/ matches files in any sub directory.
+func ComputeCacheKey(fs afero.Fs, dir, key string) (string, error) {
+	var errHash error
+	computed := cacheKeyHashFilesRegex.ReplaceAllStringFunc(key, func(s string) string {
+		patterns := strings.Split(cacheKeyHashFilesRegex.FindStringSubmatch(s)[1], ",")
+		for i := range patterns {
+			patterns[i] = strings.TrimSpace(patterns[i])
+		}
+		hash, err := hashFiles(fs, dir, patterns)
+		if err != nil {
+			errHash = err
+		}
+		return hash
+	})
+	if errHash != nil {
+		return "", errHash
+	}
+	if !NamePatternRegex.MatchString(computed) {
+		return "", NewErrorFrom(ErrWrongRequest, "invalid cache key %q, it should match %s", computed, NamePattern)
+	}
+	return computed, nil
+}
+
+func hashFiles(fs afero.Fs, dir string, patterns []string) (string, error) {
+	var files []string
+	err := afero.Walk(fs, dir, func(file string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			if fi.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		for _, p := range patterns {
+			if matchCachePattern(p, rel) {
+				files = append(files, file)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", WrapError(err, "cannot walk directory %s", dir)
+	}
+	if len(files) == 0 {
+		return "", NewErrorFrom(ErrNotFound, "no file matches hashFiles(%s)", strings.Join(patterns, ", "))
+	}
+
+	sort.Strings(files)
+	h := sha256.New()
+	for _, file := range files {
+		f, err := fs.Open(file)
+		if err != nil {
+			return "", WithStack(err)
+		}
+		fh := sha256.New()
+		_, err = io.Copy(fh, f)
+		_ = f.Close()
+		if err != nil {
+			return "", WithStack(err)
+		}
+		_, _ = h.Write(fh.Sum(nil))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func matchCachePattern(pattern, file string) bool {
+	if ok, _ := path.Match(pattern, file); ok {
+		return true
+	}
+	if !strings.HasPrefix(pattern, "**/") {
+		return false
+	}
+	pattern = strings.TrimPrefix(pattern, "**/")
+	parts := strings.Split(file, "/")
+	for i := range parts {
+		if ok, _ := path.Match(pattern, strings.Join(parts[i:], "/")); ok {
+			return true
+		}
+	}
+	return false
 }
 
 //GetName returns the name the artifact
@@ -0,0 +1,78 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeCacheKey(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "/src/go.sum", []byte("sum"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/src/ui/package-lock.json", []byte("lock"), 0644))
+	require.NoError(t, afero.WriteFile(fs, "/src/api/package-lock.json", []byte("lock"), 0644))
+
+	key, err := ComputeCacheKey(fs, "/src", "go-hashFiles(go.sum)")
+	require.NoError(t, err)
+	assert.Regexp(t, "^go-[0-9a-f]{64}$", key)
+
+	same, err := ComputeCacheKey(fs, "/src", "go-hashFiles( go.sum )")
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	npm, err := ComputeCacheKey(fs, "/src", "npm-hashFiles(**/package-lock.json)")
+	require.NoError(t, err)
+	require.NoError(t, afero.WriteFile(fs, "/src/api/package-lock.json", []byte("updated"), 0644))
+	npmUpdated, err := ComputeCacheKey(fs, "/src", "npm-hashFiles(**/package-lock.json)")
+	require.NoError(t, err)
+	assert.NotEqual(t, npm, npmUpdated)
+
+	key, err = ComputeCacheKey(fs, "/src", "static-key")
+	require.NoError(t, err)
+	assert.Equal(t, "static-key", key)
+
+	_, err = ComputeCacheKey(fs, "/src", "go-hashFiles(missing.sum)")
+	assert.Error(t, err)
+
+	_, err = ComputeCacheKey(fs, "/src", "invalid/key")
+	assert.Error(t, err)
+}
+
+func TestSelectCacheEntry(t *testing.T) {
+	now := time.Now()
+	entries := []CacheEntry{
+		{ID: 1, Tag: "go-linux-aaa", Created: now.Add(-2 * time.Hour)},
+		{ID: 2, Tag: "go-linux-bbb", Created: now.Add(-time.Hour)},
+		{ID: 3, Tag: "go-darwin-ccc", Created: now},
+	}
+
+	assert.Equal(t, int64(1), SelectCacheEntry(entries, "go-linux-aaa", []string{"go-"}).ID)
+	assert.Equal(t, int64(2), SelectCacheEntry(entries, "go-linux-ddd", []string{"go-linux-", "go-"}).ID)
+	assert.Equal(t, int64(3), SelectCacheEntry(entries, "go-windows-eee", []string{"go-windows-", "go-"}).ID)
+	assert.Nil(t, SelectCacheEntry(entries, "npm-fff", []string{"npm-"}))
+}
+
+func TestCacheEntriesToEvict(t *testing.T) {
+	now := time.Now()
+	entries := []CacheEntry{
+		{ID: 1, Size: 40, LastAccess: now.Add(-3 * time.Hour)},
+		{ID: 2, Size: 50, LastAccess: now},
+		{ID: 3, Size: 30, LastAccess: now.Add(-time.Hour)},
+		{ID: 4, Size: 10, LastAccess: now.Add(-2 * time.Hour)},
+	}
+
+	evicted := CacheEntriesToEvict(entries, 85)
+	require.Len(t, evicted, 2)
+	assert.Equal(t, int64(4), evicted[0].ID)
+	assert.Equal(t, int64(1), evicted[1].ID)
+
+	// A smaller entry is kept if it fits
+	evicted = CacheEntriesToEvict(entries, 90)
+	require.Len(t, evicted, 1)
+	assert.Equal(t, int64(1), evicted[0].ID)
+
+	assert.Empty(t, CacheEntriesToEvict(entries, 200))
+}
@@ -0,0 +1,27 @@
+package cdsclient
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func (c *client) EnvironmentDeploymentList(projectKey string, envName string, mods ...RequestModifier) ([]sdk.EnvironmentDeployment, error) {
+	deployments := []sdk.EnvironmentDeployment{}
+	path := fmt.Sprintf("/project/%s/environment/%s/deployment", projectKey, url.QueryEscape(envName))
+	if _, err := c.GetJSON(context.Background(), path, &deployments, mods...); err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+func (c *client) EnvironmentDeploymentGet(projectKey string, envName string, deploymentID int64) (*sdk.EnvironmentDeployment, error) {
+	deployment := &sdk.EnvironmentDeployment{}
+	path := fmt.Sprintf("/project/%s/environment/%s/deployment/%d", projectKey, url.QueryEscape(envName), deploymentID)
+	if _, err := c.GetJSON(context.Background(), path, deployment); err != nil {
+		return nil, err
+	}
+	return deployment, nil
+}
@@ -97,3 +97,23 @@ func (c *client) WorkerSetStatus(ctx context.Context, status string) error {
 
 	return nil
 }
+
+func (c *client) WorkerMe(ctx context.Context) (*sdk.Worker, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	var w sdk.Worker
+	if _, err := c.GetJSON(ctx, "/worker/me", &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+func (c *client) WorkerAssignJob(ctx context.Context, id string, jobID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	url := fmt.Sprintf("/worker/%s/assign/%d", id, jobID)
+	if _, err := c.PostJSON(ctx, url, nil, nil); err != nil {
+		return err
+	}
+	return nil
+}
@@ -322,6 +322,49 @@ func (c *client) WorkflowNodeStop(projectKey string, workflowName string, number
 	return nodeRun, nil
 }
 
+func (c *client) WorkflowNodeRunApprove(projectKey string, workflowName string, number, nodeRunID int64, req sdk.WorkflowNodeRunApprovalRequest) (*sdk.WorkflowNodeRun, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/approval", projectKey, workflowName, number, nodeRunID)
+
+	nodeRun := &sdk.WorkflowNodeRun{}
+	if _, err := c.PostJSON(context.Background(), url, req, nodeRun); err != nil {
+		return nil, err
+	}
+	return nodeRun, nil
+}
+
+// WorkflowNodeRunFailedJobs runs the failed and stopped jobs of a node run again in a new sub number
+func (c *client) WorkflowNodeRunFailedJobs(projectKey string, workflowName string, number, nodeRunID int64) (*sdk.WorkflowRun, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/nodes/%d/failed-jobs", projectKey, workflowName, number, nodeRunID)
+
+	run := &sdk.WorkflowRun{}
+	if _, err := c.PostJSON(context.Background(), url, nil, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+// WorkflowRunDiff compares two runs of a workflow
+func (c *client) WorkflowRunDiff(projectKey string, workflowName string, number, otherNumber int64) (*sdk.WorkflowRunDiff, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/runs/%d/diff/%d", projectKey, workflowName, number, otherNumber)
+
+	diff := &sdk.WorkflowRunDiff{}
+	if _, err := c.GetJSON(context.Background(), url, diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// WorkflowDeploymentRollback runs again the node of a deployment with the payload and the pipeline parameters of the deployment
+func (c *client) WorkflowDeploymentRollback(projectKey string, workflowName string, deploymentID int64) (*sdk.WorkflowRun, error) {
+	url := fmt.Sprintf("/project/%s/workflows/%s/deployments/%d/rollback", projectKey, workflowName, deploymentID)
+
+	run := &sdk.WorkflowRun{}
+	if _, err := c.PostJSON(context.Background(), url, nil, run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
 func (c *client) WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error {
 	store := new(sdk.ArtifactsStore)
 	uri := fmt.Sprintf("/project/%s/storage/%s", projectKey, integrationName)
@@ -364,7 +407,19 @@ func (c *client) workflowCachePushIndirectUpload(projectKey, integrationName, re
 		return fmt.Errorf("HTTP Code %d", code)
 	}
 
-	return c.workflowCachePushIndirectUploadPost(cacheObj.TmpURL, tarContent, size)
+	if err := c.workflowCachePushIndirectUploadPost(cacheObj.TmpURL, tarContent, size); err != nil {
+		return err
+	}
+
+	// The cache is registered by CDS once uploaded
+	code, err = c.PostJSON(context.Background(), uri+"/callback", nil, nil)
+	if err != nil {
+		return err
+	}
+	if code >= 400 {
+		return fmt.Errorf("HTTP Code %d", code)
+	}
+	return nil
 }
 
 func (c *client) workflowCachePushIndirectUploadPost(url string, tarContent io.Reader, size int) error {
@@ -408,6 +463,20 @@ func (c *client) workflowCachePushIndirectUploadPost(url string, tarContent io.R
 	return globalErr
 }
 
+func (c *client) WorkflowCacheEntry(projectKey, integrationName, key string, restoreKeys []string) (*sdk.CacheEntry, error) {
+	q := url.Values{}
+	q.Set("key", key)
+	for _, k := range restoreKeys {
+		q.Add("restoreKey", k)
+	}
+	var entry sdk.CacheEntry
+	uri := fmt.Sprintf("/project/%s/storage/%s/cache?%s", projectKey, integrationName, q.Encode())
+	if _, err := c.GetJSON(context.Background(), uri, &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
 func (c *client) WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error) {
 	uri := fmt.Sprintf("/project/%s/storage/%s", projectKey, integrationName)
 	store := new(sdk.ArtifactsStore)
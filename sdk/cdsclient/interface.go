@@ -123,6 +123,13 @@ type EnvironmentClient interface {
 	EnvironmentImport(projectKey string, content io.Reader, mods ...RequestModifier) ([]string, error)
 	EnvironmentVariableClient
 	EnvironmentKeysClient
+	EnvironmentDeploymentClient
+}
+
+// EnvironmentDeploymentClient exposes environment deployments related functions
+type EnvironmentDeploymentClient interface {
+	EnvironmentDeploymentList(projectKey string, envName string, mods ...RequestModifier) ([]sdk.EnvironmentDeployment, error)
+	EnvironmentDeploymentGet(projectKey string, envName string, deploymentID int64) (*sdk.EnvironmentDeployment, error)
 }
 
 // EnvironmentKeysClient exposes environment keys related functions
@@ -284,6 +291,8 @@ type WorkerClient interface {
 	WorkerModelSecretList(groupName, name string) (sdk.WorkerModelSecrets, error)
 	WorkerRegister(ctx context.Context, authToken string, form sdk.WorkerRegistrationForm) (*sdk.Worker, bool, error)
 	WorkerSetStatus(ctx context.Context, status string) error
+	WorkerMe(ctx context.Context) (*sdk.Worker, error)
+	WorkerAssignJob(ctx context.Context, id string, jobID int64) error
 }
 
 // HookClient exposes functions used for hooks services
@@ -315,6 +324,10 @@ type WorkflowClient interface {
 	WorkflowRunNumberSet(projectKey string, workflowName string, number int64) error
 	WorkflowStop(projectKey string, workflowName string, number int64) (*sdk.WorkflowRun, error)
 	WorkflowNodeStop(projectKey string, workflowName string, number, fromNodeID int64) (*sdk.WorkflowNodeRun, error)
+	WorkflowNodeRunApprove(projectKey string, workflowName string, number, nodeRunID int64, req sdk.WorkflowNodeRunApprovalRequest) (*sdk.WorkflowNodeRun, error)
+	WorkflowNodeRunFailedJobs(projectKey string, workflowName string, number, nodeRunID int64) (*sdk.WorkflowRun, error)
+	WorkflowRunDiff(projectKey string, workflowName string, number, otherNumber int64) (*sdk.WorkflowRunDiff, error)
+	WorkflowDeploymentRollback(projectKey string, workflowName string, deploymentID int64) (*sdk.WorkflowRun, error)
 	WorkflowNodeRun(projectKey string, name string, number int64, nodeRunID int64) (*sdk.WorkflowNodeRun, error)
 	WorkflowNodeRunArtifactDownload(projectKey string, name string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error
 	WorkflowNodeRunJobStep(projectKey string, workflowName string, number int64, nodeRunID, job int64, step int) (*sdk.BuildState, error)
@@ -322,6 +335,7 @@ type WorkflowClient interface {
 	WorkflowAllHooksList() ([]sdk.NodeHook, error)
 	WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error
 	WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error)
+	WorkflowCacheEntry(projectKey, integrationName, key string, restoreKeys []string) (*sdk.CacheEntry, error)
 	WorkflowTransformAsCode(projectKey, workflowName, branch, message string) (*sdk.Operation, error)
 	WorkflowTransformAsCodeFollow(projectKey, workflowName, opeUUID string) (*sdk.Operation, error)
 }
@@ -389,6 +403,7 @@ type WorkerInterface interface {
 	WorkflowRunArtifacts(projectKey string, name string, number int64) ([]sdk.WorkflowNodeRunArtifact, error)
 	WorkflowCachePush(projectKey, integrationName, ref string, tarContent io.Reader, size int) error
 	WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error)
+	WorkflowCacheEntry(projectKey, integrationName, key string, restoreKeys []string) (*sdk.CacheEntry, error)
 	WorkflowRunSearch(projectKey string, offset, limit int64, filter ...Filter) ([]sdk.WorkflowRun, error)
 	WorkflowNodeRunArtifactDownload(projectKey string, name string, a sdk.WorkflowNodeRunArtifact, w io.Writer) error
 	WorkflowNodeRunRelease(projectKey string, workflowName string, runNumber int64, nodeRunID int64, release sdk.WorkflowNodeRunRelease) error
@@ -1525,6 +1525,41 @@ func (mr *MockEnvironmentClientMockRecorder) EnvironmentKeysDelete(projectKey, e
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentKeysDelete", reflect.TypeOf((*MockEnvironmentClient)(nil).EnvironmentKeysDelete), projectKey, envName, keyEnvName)
 }
 
+// EnvironmentDeploymentList mocks base method
+func (m *MockEnvironmentClient) EnvironmentDeploymentList(projectKey, envName string, mods ...cdsclient.RequestModifier) ([]sdk.EnvironmentDeployment, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{projectKey, envName}
+	for _, a := range mods {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnvironmentDeploymentList", varargs...)
+	ret0, _ := ret[0].([]sdk.EnvironmentDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnvironmentDeploymentList indicates an expected call of EnvironmentDeploymentList
+func (mr *MockEnvironmentClientMockRecorder) EnvironmentDeploymentList(projectKey, envName interface{}, mods ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{projectKey, envName}, mods...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentDeploymentList", reflect.TypeOf((*MockEnvironmentClient)(nil).EnvironmentDeploymentList), varargs...)
+}
+
+// EnvironmentDeploymentGet mocks base method
+func (m *MockEnvironmentClient) EnvironmentDeploymentGet(projectKey, envName string, deploymentID int64) (*sdk.EnvironmentDeployment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnvironmentDeploymentGet", projectKey, envName, deploymentID)
+	ret0, _ := ret[0].(*sdk.EnvironmentDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnvironmentDeploymentGet indicates an expected call of EnvironmentDeploymentGet
+func (mr *MockEnvironmentClientMockRecorder) EnvironmentDeploymentGet(projectKey, envName, deploymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentDeploymentGet", reflect.TypeOf((*MockEnvironmentClient)(nil).EnvironmentDeploymentGet), projectKey, envName, deploymentID)
+}
+
 // MockEnvironmentKeysClient is a mock of EnvironmentKeysClient interface
 type MockEnvironmentKeysClient struct {
 	ctrl     *gomock.Controller
@@ -1686,6 +1721,64 @@ func (mr *MockEnvironmentVariableClientMockRecorder) EnvironmentVariableUpdate(p
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentVariableUpdate", reflect.TypeOf((*MockEnvironmentVariableClient)(nil).EnvironmentVariableUpdate), projectKey, envName, variable)
 }
 
+// MockEnvironmentDeploymentClient is a mock of EnvironmentDeploymentClient interface
+type MockEnvironmentDeploymentClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockEnvironmentDeploymentClientMockRecorder
+}
+
+// MockEnvironmentDeploymentClientMockRecorder is the mock recorder for MockEnvironmentDeploymentClient
+type MockEnvironmentDeploymentClientMockRecorder struct {
+	mock *MockEnvironmentDeploymentClient
+}
+
+// NewMockEnvironmentDeploymentClient creates a new mock instance
+func NewMockEnvironmentDeploymentClient(ctrl *gomock.Controller) *MockEnvironmentDeploymentClient {
+	mock := &MockEnvironmentDeploymentClient{ctrl: ctrl}
+	mock.recorder = &MockEnvironmentDeploymentClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use
+func (m *MockEnvironmentDeploymentClient) EXPECT() *MockEnvironmentDeploymentClientMockRecorder {
+	return m.recorder
+}
+
+// EnvironmentDeploymentList mocks base method
+func (m *MockEnvironmentDeploymentClient) EnvironmentDeploymentList(projectKey, envName string, mods ...cdsclient.RequestModifier) ([]sdk.EnvironmentDeployment, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{projectKey, envName}
+	for _, a := range mods {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnvironmentDeploymentList", varargs...)
+	ret0, _ := ret[0].([]sdk.EnvironmentDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnvironmentDeploymentList indicates an expected call of EnvironmentDeploymentList
+func (mr *MockEnvironmentDeploymentClientMockRecorder) EnvironmentDeploymentList(projectKey, envName interface{}, mods ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{projectKey, envName}, mods...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentDeploymentList", reflect.TypeOf((*MockEnvironmentDeploymentClient)(nil).EnvironmentDeploymentList), varargs...)
+}
+
+// EnvironmentDeploymentGet mocks base method
+func (m *MockEnvironmentDeploymentClient) EnvironmentDeploymentGet(projectKey, envName string, deploymentID int64) (*sdk.EnvironmentDeployment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnvironmentDeploymentGet", projectKey, envName, deploymentID)
+	ret0, _ := ret[0].(*sdk.EnvironmentDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnvironmentDeploymentGet indicates an expected call of EnvironmentDeploymentGet
+func (mr *MockEnvironmentDeploymentClientMockRecorder) EnvironmentDeploymentGet(projectKey, envName, deploymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentDeploymentGet", reflect.TypeOf((*MockEnvironmentDeploymentClient)(nil).EnvironmentDeploymentGet), projectKey, envName, deploymentID)
+}
+
 // MockEventsClient is a mock of EventsClient interface
 type MockEventsClient struct {
 	ctrl     *gomock.Controller
@@ -3482,6 +3575,35 @@ func (mr *MockWorkerClientMockRecorder) WorkerSetStatus(ctx, status interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerSetStatus", reflect.TypeOf((*MockWorkerClient)(nil).WorkerSetStatus), ctx, status)
 }
 
+// WorkerMe mocks base method
+func (m *MockWorkerClient) WorkerMe(ctx context.Context) (*sdk.Worker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkerMe", ctx)
+	ret0, _ := ret[0].(*sdk.Worker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkerMe indicates an expected call of WorkerMe
+func (mr *MockWorkerClientMockRecorder) WorkerMe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerMe", reflect.TypeOf((*MockWorkerClient)(nil).WorkerMe), ctx)
+}
+
+// WorkerAssignJob mocks base method
+func (m *MockWorkerClient) WorkerAssignJob(ctx context.Context, id string, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkerAssignJob", ctx, id, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WorkerAssignJob indicates an expected call of WorkerAssignJob
+func (mr *MockWorkerClientMockRecorder) WorkerAssignJob(ctx, id, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerAssignJob", reflect.TypeOf((*MockWorkerClient)(nil).WorkerAssignJob), ctx, id, jobID)
+}
+
 // MockHookClient is a mock of HookClient interface
 type MockHookClient struct {
 	ctrl     *gomock.Controller
@@ -3885,6 +4007,66 @@ func (mr *MockWorkflowClientMockRecorder) WorkflowNodeStop(projectKey, workflowN
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowNodeStop", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowNodeStop), projectKey, workflowName, number, fromNodeID)
 }
 
+// WorkflowNodeRunApprove mocks base method
+func (m *MockWorkflowClient) WorkflowNodeRunApprove(projectKey, workflowName string, number, nodeRunID int64, req sdk.WorkflowNodeRunApprovalRequest) (*sdk.WorkflowNodeRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowNodeRunApprove", projectKey, workflowName, number, nodeRunID, req)
+	ret0, _ := ret[0].(*sdk.WorkflowNodeRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowNodeRunApprove indicates an expected call of WorkflowNodeRunApprove
+func (mr *MockWorkflowClientMockRecorder) WorkflowNodeRunApprove(projectKey, workflowName, number, nodeRunID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowNodeRunApprove", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowNodeRunApprove), projectKey, workflowName, number, nodeRunID, req)
+}
+
+// WorkflowNodeRunFailedJobs mocks base method
+func (m *MockWorkflowClient) WorkflowNodeRunFailedJobs(projectKey, workflowName string, number, nodeRunID int64) (*sdk.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowNodeRunFailedJobs", projectKey, workflowName, number, nodeRunID)
+	ret0, _ := ret[0].(*sdk.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowNodeRunFailedJobs indicates an expected call of WorkflowNodeRunFailedJobs
+func (mr *MockWorkflowClientMockRecorder) WorkflowNodeRunFailedJobs(projectKey, workflowName, number, nodeRunID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowNodeRunFailedJobs", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowNodeRunFailedJobs), projectKey, workflowName, number, nodeRunID)
+}
+
+// WorkflowRunDiff mocks base method
+func (m *MockWorkflowClient) WorkflowRunDiff(projectKey, workflowName string, number, otherNumber int64) (*sdk.WorkflowRunDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowRunDiff", projectKey, workflowName, number, otherNumber)
+	ret0, _ := ret[0].(*sdk.WorkflowRunDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowRunDiff indicates an expected call of WorkflowRunDiff
+func (mr *MockWorkflowClientMockRecorder) WorkflowRunDiff(projectKey, workflowName, number, otherNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowRunDiff", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowRunDiff), projectKey, workflowName, number, otherNumber)
+}
+
+// WorkflowDeploymentRollback mocks base method
+func (m *MockWorkflowClient) WorkflowDeploymentRollback(projectKey, workflowName string, deploymentID int64) (*sdk.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowDeploymentRollback", projectKey, workflowName, deploymentID)
+	ret0, _ := ret[0].(*sdk.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowDeploymentRollback indicates an expected call of WorkflowDeploymentRollback
+func (mr *MockWorkflowClientMockRecorder) WorkflowDeploymentRollback(projectKey, workflowName, deploymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowDeploymentRollback", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowDeploymentRollback), projectKey, workflowName, deploymentID)
+}
+
 // WorkflowNodeRun mocks base method
 func (m *MockWorkflowClient) WorkflowNodeRun(projectKey, name string, number, nodeRunID int64) (*sdk.WorkflowNodeRun, error) {
 	m.ctrl.T.Helper()
@@ -3972,6 +4154,21 @@ func (mr *MockWorkflowClientMockRecorder) WorkflowCachePush(projectKey, integrat
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowCachePush", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowCachePush), projectKey, integrationName, ref, tarContent, size)
 }
 
+// WorkflowCacheEntry mocks base method
+func (m *MockWorkflowClient) WorkflowCacheEntry(projectKey, integrationName, key string, restoreKeys []string) (*sdk.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowCacheEntry", projectKey, integrationName, key, restoreKeys)
+	ret0, _ := ret[0].(*sdk.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowCacheEntry indicates an expected call of WorkflowCacheEntry
+func (mr *MockWorkflowClientMockRecorder) WorkflowCacheEntry(projectKey, integrationName, key, restoreKeys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowCacheEntry", reflect.TypeOf((*MockWorkflowClient)(nil).WorkflowCacheEntry), projectKey, integrationName, key, restoreKeys)
+}
+
 // WorkflowCachePull mocks base method
 func (m *MockWorkflowClient) WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error) {
 	m.ctrl.T.Helper()
@@ -5534,6 +5731,41 @@ func (mr *MockInterfaceMockRecorder) EnvironmentKeysDelete(projectKey, envName,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentKeysDelete", reflect.TypeOf((*MockInterface)(nil).EnvironmentKeysDelete), projectKey, envName, keyEnvName)
 }
 
+// EnvironmentDeploymentList mocks base method
+func (m *MockInterface) EnvironmentDeploymentList(projectKey, envName string, mods ...cdsclient.RequestModifier) ([]sdk.EnvironmentDeployment, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{projectKey, envName}
+	for _, a := range mods {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "EnvironmentDeploymentList", varargs...)
+	ret0, _ := ret[0].([]sdk.EnvironmentDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnvironmentDeploymentList indicates an expected call of EnvironmentDeploymentList
+func (mr *MockInterfaceMockRecorder) EnvironmentDeploymentList(projectKey, envName interface{}, mods ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{projectKey, envName}, mods...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentDeploymentList", reflect.TypeOf((*MockInterface)(nil).EnvironmentDeploymentList), varargs...)
+}
+
+// EnvironmentDeploymentGet mocks base method
+func (m *MockInterface) EnvironmentDeploymentGet(projectKey, envName string, deploymentID int64) (*sdk.EnvironmentDeployment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnvironmentDeploymentGet", projectKey, envName, deploymentID)
+	ret0, _ := ret[0].(*sdk.EnvironmentDeployment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnvironmentDeploymentGet indicates an expected call of EnvironmentDeploymentGet
+func (mr *MockInterfaceMockRecorder) EnvironmentDeploymentGet(projectKey, envName, deploymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnvironmentDeploymentGet", reflect.TypeOf((*MockInterface)(nil).EnvironmentDeploymentGet), projectKey, envName, deploymentID)
+}
+
 // WebsocketEventsListen mocks base method
 func (m *MockInterface) WebsocketEventsListen(ctx context.Context, chanMsgToSend <-chan sdk.WebsocketFilter, chanMsgReceived chan<- sdk.WebsocketEvent) {
 	m.ctrl.T.Helper()
@@ -7235,6 +7467,35 @@ func (mr *MockInterfaceMockRecorder) WorkerSetStatus(ctx, status interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerSetStatus", reflect.TypeOf((*MockInterface)(nil).WorkerSetStatus), ctx, status)
 }
 
+// WorkerMe mocks base method
+func (m *MockInterface) WorkerMe(ctx context.Context) (*sdk.Worker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkerMe", ctx)
+	ret0, _ := ret[0].(*sdk.Worker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkerMe indicates an expected call of WorkerMe
+func (mr *MockInterfaceMockRecorder) WorkerMe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerMe", reflect.TypeOf((*MockInterface)(nil).WorkerMe), ctx)
+}
+
+// WorkerAssignJob mocks base method
+func (m *MockInterface) WorkerAssignJob(ctx context.Context, id string, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkerAssignJob", ctx, id, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WorkerAssignJob indicates an expected call of WorkerAssignJob
+func (mr *MockInterfaceMockRecorder) WorkerAssignJob(ctx, id, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerAssignJob", reflect.TypeOf((*MockInterface)(nil).WorkerAssignJob), ctx, id, jobID)
+}
+
 // WorkflowSearch mocks base method
 func (m *MockInterface) WorkflowSearch(opts ...cdsclient.RequestModifier) ([]sdk.Workflow, error) {
 	m.ctrl.T.Helper()
@@ -7561,6 +7822,66 @@ func (mr *MockInterfaceMockRecorder) WorkflowNodeStop(projectKey, workflowName,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowNodeStop", reflect.TypeOf((*MockInterface)(nil).WorkflowNodeStop), projectKey, workflowName, number, fromNodeID)
 }
 
+// WorkflowNodeRunApprove mocks base method
+func (m *MockInterface) WorkflowNodeRunApprove(projectKey, workflowName string, number, nodeRunID int64, req sdk.WorkflowNodeRunApprovalRequest) (*sdk.WorkflowNodeRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowNodeRunApprove", projectKey, workflowName, number, nodeRunID, req)
+	ret0, _ := ret[0].(*sdk.WorkflowNodeRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowNodeRunApprove indicates an expected call of WorkflowNodeRunApprove
+func (mr *MockInterfaceMockRecorder) WorkflowNodeRunApprove(projectKey, workflowName, number, nodeRunID, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowNodeRunApprove", reflect.TypeOf((*MockInterface)(nil).WorkflowNodeRunApprove), projectKey, workflowName, number, nodeRunID, req)
+}
+
+// WorkflowNodeRunFailedJobs mocks base method
+func (m *MockInterface) WorkflowNodeRunFailedJobs(projectKey, workflowName string, number, nodeRunID int64) (*sdk.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowNodeRunFailedJobs", projectKey, workflowName, number, nodeRunID)
+	ret0, _ := ret[0].(*sdk.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowNodeRunFailedJobs indicates an expected call of WorkflowNodeRunFailedJobs
+func (mr *MockInterfaceMockRecorder) WorkflowNodeRunFailedJobs(projectKey, workflowName, number, nodeRunID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowNodeRunFailedJobs", reflect.TypeOf((*MockInterface)(nil).WorkflowNodeRunFailedJobs), projectKey, workflowName, number, nodeRunID)
+}
+
+// WorkflowRunDiff mocks base method
+func (m *MockInterface) WorkflowRunDiff(projectKey, workflowName string, number, otherNumber int64) (*sdk.WorkflowRunDiff, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowRunDiff", projectKey, workflowName, number, otherNumber)
+	ret0, _ := ret[0].(*sdk.WorkflowRunDiff)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowRunDiff indicates an expected call of WorkflowRunDiff
+func (mr *MockInterfaceMockRecorder) WorkflowRunDiff(projectKey, workflowName, number, otherNumber interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowRunDiff", reflect.TypeOf((*MockInterface)(nil).WorkflowRunDiff), projectKey, workflowName, number, otherNumber)
+}
+
+// WorkflowDeploymentRollback mocks base method
+func (m *MockInterface) WorkflowDeploymentRollback(projectKey, workflowName string, deploymentID int64) (*sdk.WorkflowRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowDeploymentRollback", projectKey, workflowName, deploymentID)
+	ret0, _ := ret[0].(*sdk.WorkflowRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowDeploymentRollback indicates an expected call of WorkflowDeploymentRollback
+func (mr *MockInterfaceMockRecorder) WorkflowDeploymentRollback(projectKey, workflowName, deploymentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowDeploymentRollback", reflect.TypeOf((*MockInterface)(nil).WorkflowDeploymentRollback), projectKey, workflowName, deploymentID)
+}
+
 // WorkflowNodeRun mocks base method
 func (m *MockInterface) WorkflowNodeRun(projectKey, name string, number, nodeRunID int64) (*sdk.WorkflowNodeRun, error) {
 	m.ctrl.T.Helper()
@@ -7648,6 +7969,21 @@ func (mr *MockInterfaceMockRecorder) WorkflowCachePush(projectKey, integrationNa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowCachePush", reflect.TypeOf((*MockInterface)(nil).WorkflowCachePush), projectKey, integrationName, ref, tarContent, size)
 }
 
+// WorkflowCacheEntry mocks base method
+func (m *MockInterface) WorkflowCacheEntry(projectKey, integrationName, key string, restoreKeys []string) (*sdk.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowCacheEntry", projectKey, integrationName, key, restoreKeys)
+	ret0, _ := ret[0].(*sdk.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowCacheEntry indicates an expected call of WorkflowCacheEntry
+func (mr *MockInterfaceMockRecorder) WorkflowCacheEntry(projectKey, integrationName, key, restoreKeys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowCacheEntry", reflect.TypeOf((*MockInterface)(nil).WorkflowCacheEntry), projectKey, integrationName, key, restoreKeys)
+}
+
 // WorkflowCachePull mocks base method
 func (m *MockInterface) WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error) {
 	m.ctrl.T.Helper()
@@ -8614,6 +8950,35 @@ func (mr *MockWorkerInterfaceMockRecorder) WorkerSetStatus(ctx, status interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerSetStatus", reflect.TypeOf((*MockWorkerInterface)(nil).WorkerSetStatus), ctx, status)
 }
 
+// WorkerMe mocks base method
+func (m *MockWorkerInterface) WorkerMe(ctx context.Context) (*sdk.Worker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkerMe", ctx)
+	ret0, _ := ret[0].(*sdk.Worker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkerMe indicates an expected call of WorkerMe
+func (mr *MockWorkerInterfaceMockRecorder) WorkerMe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerMe", reflect.TypeOf((*MockWorkerInterface)(nil).WorkerMe), ctx)
+}
+
+// WorkerAssignJob mocks base method
+func (m *MockWorkerInterface) WorkerAssignJob(ctx context.Context, id string, jobID int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkerAssignJob", ctx, id, jobID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WorkerAssignJob indicates an expected call of WorkerAssignJob
+func (mr *MockWorkerInterfaceMockRecorder) WorkerAssignJob(ctx, id, jobID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkerAssignJob", reflect.TypeOf((*MockWorkerInterface)(nil).WorkerAssignJob), ctx, id, jobID)
+}
+
 // WorkflowRunArtifacts mocks base method
 func (m *MockWorkerInterface) WorkflowRunArtifacts(projectKey, name string, number int64) ([]sdk.WorkflowNodeRunArtifact, error) {
 	m.ctrl.T.Helper()
@@ -8643,6 +9008,21 @@ func (mr *MockWorkerInterfaceMockRecorder) WorkflowCachePush(projectKey, integra
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowCachePush", reflect.TypeOf((*MockWorkerInterface)(nil).WorkflowCachePush), projectKey, integrationName, ref, tarContent, size)
 }
 
+// WorkflowCacheEntry mocks base method
+func (m *MockWorkerInterface) WorkflowCacheEntry(projectKey, integrationName, key string, restoreKeys []string) (*sdk.CacheEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkflowCacheEntry", projectKey, integrationName, key, restoreKeys)
+	ret0, _ := ret[0].(*sdk.CacheEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkflowCacheEntry indicates an expected call of WorkflowCacheEntry
+func (mr *MockWorkerInterfaceMockRecorder) WorkflowCacheEntry(projectKey, integrationName, key, restoreKeys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkflowCacheEntry", reflect.TypeOf((*MockWorkerInterface)(nil).WorkflowCacheEntry), projectKey, integrationName, key, restoreKeys)
+}
+
 // WorkflowCachePull mocks base method
 func (m *MockWorkerInterface) WorkflowCachePull(projectKey, integrationName, ref string) (io.Reader, error) {
 	m.ctrl.T.Helper()
@@ -1,8 +1,12 @@
 package sdk
 
 import (
+	"database/sql/driver"
 	json "encoding/json"
 	"time"
+
+	"github.com/gorhill/cronexpr"
+	"github.com/pkg/errors"
 )
 
 // Environment represent a deployment environment
@@ -17,6 +21,7 @@ type Environment struct {
 	Keys                 []EnvironmentKey      `json:"keys"`
 	Usage                *Usage                `json:"usage,omitempty"`
 	FromRepository       string                `json:"from_repository,omitempty"`
+	Freezes              EnvironmentFreezes    `json:"freezes,omitempty" yaml:"freezes,omitempty"`
 	WorkflowAscodeHolder *Workflow             `json:"workflow_ascode_holder,omitempty" cli:"-"`
 }
 
@@ -31,6 +36,7 @@ func (e *Environment) UnmarshalJSON(data []byte) error {
 		Keys           []EnvironmentKey      `json:"keys"`
 		Usage          *Usage                `json:"usage"`
 		FromRepository string                `json:"from_repository"`
+		Freezes        EnvironmentFreezes    `json:"freezes"`
 	}
 
 	if err := json.Unmarshal(data, &tmp); err != nil {
@@ -44,6 +50,7 @@ func (e *Environment) UnmarshalJSON(data []byte) error {
 	e.Keys = tmp.Keys
 	e.Usage = tmp.Usage
 	e.FromRepository = tmp.FromRepository
+	e.Freezes = tmp.Freezes
 
 	var v map[string]interface{}
 	if err := json.Unmarshal(data, &v); err != nil {
@@ -127,3 +134,168 @@ var DefaultEnv = Environment{
 	ID:   1,
 	Name: "NoEnv",
 }
+
+// Environment freeze policies
+const (
+	EnvironmentFreezePolicyHold   = "hold"
+	EnvironmentFreezePolicyRefuse = "refuse"
+)
+
+// EnvironmentFreezeDateLayout is the layout of the start and end dates of a calendar freeze window.
+const EnvironmentFreezeDateLayout = "2006-01-02 15:04"
+
+// EnvironmentFreeze is a window during which pipelines can't run on an environment.
+// A window is either recurring, from each occurrence of a cron expression and for the given duration,
+// or a calendar window between two dates. Both are evaluated in the timezone of the freeze, UTC by default.
+// During the window, node runs are held until the end of the freeze or refused, depending on the policy.
+// Node runs manually started by a member of the override group are not frozen.
+type EnvironmentFreeze struct {
+	Name          string `json:"name" yaml:"name"`
+	Cron          string `json:"cron,omitempty" yaml:"cron,omitempty"`
+	Duration      string `json:"duration,omitempty" yaml:"duration,omitempty"`
+	Start         string `json:"start,omitempty" yaml:"start,omitempty"`
+	End           string `json:"end,omitempty" yaml:"end,omitempty"`
+	Timezone      string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+	Policy        string `json:"policy,omitempty" yaml:"policy,omitempty"`
+	OverrideGroup string `json:"override_group,omitempty" yaml:"override_group,omitempty"`
+}
+
+// IsValid returns an error if the freeze window is invalid.
+func (f EnvironmentFreeze) IsValid() error {
+	if f.Name == "" {
+		return NewErrorFrom(ErrWrongRequest, "invalid freeze window, name is required")
+	}
+	if _, err := time.LoadLocation(f.Timezone); err != nil {
+		return NewErrorFrom(ErrWrongRequest, "invalid timezone %s for freeze window %s", f.Timezone, f.Name)
+	}
+	switch f.Policy {
+	case "", EnvironmentFreezePolicyHold, EnvironmentFreezePolicyRefuse:
+	default:
+		return NewErrorFrom(ErrWrongRequest, "invalid policy %s for freeze window %s, should be %s or %s", f.Policy, f.Name, EnvironmentFreezePolicyHold, EnvironmentFreezePolicyRefuse)
+	}
+
+	if f.Cron != "" {
+		if f.Start != "" || f.End != "" {
+			return NewErrorFrom(ErrWrongRequest, "invalid freeze window %s, cron can't be used with start and end dates", f.Name)
+		}
+		if _, err := cronexpr.Parse(f.Cron); err != nil {
+			return NewErrorFrom(ErrWrongRequest, "invalid cron expression %s for freeze window %s", f.Cron, f.Name)
+		}
+		d, err := time.ParseDuration(f.Duration)
+		if err != nil || d <= 0 {
+			return NewErrorFrom(ErrWrongRequest, "invalid duration %s for freeze window %s", f.Duration, f.Name)
+		}
+		return nil
+	}
+
+	start, end, err := f.calendarWindow()
+	if err != nil {
+		return err
+	}
+	if !end.After(start) {
+		return NewErrorFrom(ErrWrongRequest, "invalid freeze window %s, end should be after start", f.Name)
+	}
+	return nil
+}
+
+// GetPolicy returns the policy of the freeze window, hold by default.
+func (f EnvironmentFreeze) GetPolicy() string {
+	if f.Policy == "" {
+		return EnvironmentFreezePolicyHold
+	}
+	return f.Policy
+}
+
+func (f EnvironmentFreeze) calendarWindow() (time.Time, time.Time, error) {
+	loc, err := time.LoadLocation(f.Timezone)
+	if err != nil {
+		return time.Time{}, time.Time{}, NewErrorFrom(ErrWrongRequest, "invalid timezone %s for freeze window %s", f.Timezone, f.Name)
+	}
+	start, err := time.ParseInLocation(EnvironmentFreezeDateLayout, f.Start, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, NewErrorFrom(ErrWrongRequest, "invalid start date %s for freeze window %s, expected format is %s", f.Start, f.Name, EnvironmentFreezeDateLayout)
+	}
+	end, err := time.ParseInLocation(EnvironmentFreezeDateLayout, f.End, loc)
+	if err != nil {
+		return time.Time{}, time.Time{}, NewErrorFrom(ErrWrongRequest, "invalid end date %s for freeze window %s, expected format is %s", f.End, f.Name, EnvironmentFreezeDateLayout)
+	}
+	return start, end, nil
+}
+
+// ActiveUntil returns the end of the freeze window if it is active at the given time.
+func (f EnvironmentFreeze) ActiveUntil(t time.Time) (time.Time, bool) {
+	if f.Cron == "" {
+		start, end, err := f.calendarWindow()
+		if err != nil || t.Before(start) || !t.Before(end) {
+			return time.Time{}, false
+		}
+		return end, true
+	}
+
+	loc, err := time.LoadLocation(f.Timezone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	expr, err := cronexpr.Parse(f.Cron)
+	if err != nil {
+		return time.Time{}, false
+	}
+	d, err := time.ParseDuration(f.Duration)
+	if err != nil || d <= 0 {
+		return time.Time{}, false
+	}
+
+	// Look for the occurrences of the cron that started less than one duration ago
+	var until time.Time
+	t = t.In(loc)
+	for occurrence := expr.Next(t.Add(-d)); !occurrence.IsZero() && !occurrence.After(t); occurrence = expr.Next(occurrence) {
+		until = occurrence.Add(d)
+	}
+	return until, !until.IsZero()
+}
+
+// EnvironmentFreezes type used for database json storage.
+type EnvironmentFreezes []EnvironmentFreeze
+
+// IsValid returns an error if one of the freeze windows is invalid.
+func (fs EnvironmentFreezes) IsValid() error {
+	names := make(map[string]struct{}, len(fs))
+	for _, f := range fs {
+		if err := f.IsValid(); err != nil {
+			return err
+		}
+		if _, ok := names[f.Name]; ok {
+			return NewErrorFrom(ErrWrongRequest, "freeze window %s is defined twice", f.Name)
+		}
+		names[f.Name] = struct{}{}
+	}
+	return nil
+}
+
+// Active returns the first freeze window active at the given time and its end.
+func (fs EnvironmentFreezes) Active(t time.Time) (*EnvironmentFreeze, time.Time) {
+	for i := range fs {
+		if until, ok := fs[i].ActiveUntil(t); ok {
+			return &fs[i], until
+		}
+	}
+	return nil, time.Time{}
+}
+
+// Value returns driver.Value from environment freezes.
+func (fs EnvironmentFreezes) Value() (driver.Value, error) {
+	j, err := json.Marshal(fs)
+	return j, WrapError(err, "cannot marshal EnvironmentFreezes")
+}
+
+// Scan environment freezes.
+func (fs *EnvironmentFreezes) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(errors.New("type assertion .([]byte) failed"))
+	}
+	return WrapError(json.Unmarshal(source, fs), "cannot unmarshal EnvironmentFreezes")
+}
@@ -0,0 +1,124 @@
+package sdk
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// EnvironmentDeployment is an entry of the deployment registry of an environment. It is recorded
+// for each successful node run that deployed an application on the environment.
+type EnvironmentDeployment struct {
+	ID                int64                          `json:"id" db:"id" cli:"id,key"`
+	ProjectID         int64                          `json:"project_id" db:"project_id" cli:"-"`
+	EnvironmentID     int64                          `json:"environment_id" db:"environment_id" cli:"-"`
+	EnvironmentName   string                         `json:"environment_name" db:"environment_name" cli:"environment"`
+	ApplicationID     int64                          `json:"application_id" db:"application_id" cli:"-"`
+	ApplicationName   string                         `json:"application_name" db:"application_name" cli:"application"`
+	IntegrationName   string                         `json:"integration_name,omitempty" db:"integration_name" cli:"integration"`
+	WorkflowID        int64                          `json:"workflow_id" db:"workflow_id" cli:"-"`
+	WorkflowName      string                         `json:"workflow_name" db:"workflow_name" cli:"workflow"`
+	WorkflowNodeID    int64                          `json:"workflow_node_id" db:"workflow_node_id" cli:"-"`
+	WorkflowNodeName  string                         `json:"workflow_node_name" db:"workflow_node_name" cli:"node"`
+	WorkflowRunID     int64                          `json:"workflow_run_id" db:"workflow_run_id" cli:"-"`
+	WorkflowNodeRunID int64                          `json:"workflow_node_run_id" db:"workflow_node_run_id" cli:"-"`
+	Number            int64                          `json:"num" db:"num" cli:"run"`
+	SubNumber         int64                          `json:"subnumber" db:"subnum" cli:"subnumber"`
+	Version           string                         `json:"version" db:"version" cli:"version"`
+	VCSRepository     string                         `json:"vcs_repository,omitempty" db:"vcs_repository" cli:"-"`
+	VCSBranch         string                         `json:"vcs_branch,omitempty" db:"vcs_branch" cli:"branch"`
+	VCSTag            string                         `json:"vcs_tag,omitempty" db:"vcs_tag" cli:"tag"`
+	VCSHash           string                         `json:"vcs_hash,omitempty" db:"vcs_hash" cli:"commit"`
+	Artifacts         EnvironmentDeploymentArtifacts `json:"artifacts,omitempty" db:"artifacts" cli:"-"`
+	Deployed          time.Time                      `json:"deployed" db:"deployed" cli:"deployed"`
+}
+
+// EnvironmentDeploymentArtifact is an artifact available for a deployment.
+type EnvironmentDeploymentArtifact struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Tag    string `json:"tag"`
+	Size   int64  `json:"size,omitempty"`
+	MD5sum string `json:"md5sum,omitempty"`
+}
+
+// EnvironmentDeploymentArtifacts type used for database json storage.
+type EnvironmentDeploymentArtifacts []EnvironmentDeploymentArtifact
+
+// Value returns driver.Value from deployment artifacts.
+func (a EnvironmentDeploymentArtifacts) Value() (driver.Value, error) {
+	j, err := json.Marshal(a)
+	return j, WrapError(err, "cannot marshal EnvironmentDeploymentArtifacts")
+}
+
+// Scan deployment artifacts.
+func (a *EnvironmentDeploymentArtifacts) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, a), "cannot unmarshal EnvironmentDeploymentArtifacts")
+}
+
+// NewEnvironmentDeployment returns the deployment made by given node run. The node context must reference an application
+// and an environment. Given artifacts are the artifacts of the workflow run, only the last upload of each artifact is kept.
+func NewEnvironmentDeployment(wr WorkflowRun, nr WorkflowNodeRun, nodeCtx NodeContext, artifacts []WorkflowNodeRunArtifact) EnvironmentDeployment {
+	d := EnvironmentDeployment{
+		ProjectID:         wr.ProjectID,
+		EnvironmentID:     nodeCtx.EnvironmentID,
+		ApplicationID:     nodeCtx.ApplicationID,
+		WorkflowID:        wr.WorkflowID,
+		WorkflowName:      wr.Workflow.Name,
+		WorkflowNodeID:    nr.WorkflowNodeID,
+		WorkflowNodeName:  nr.WorkflowNodeName,
+		WorkflowRunID:     wr.ID,
+		WorkflowNodeRunID: nr.ID,
+		Number:            nr.Number,
+		SubNumber:         nr.SubNumber,
+		VCSRepository:     nr.VCSRepository,
+		VCSBranch:         nr.VCSBranch,
+		VCSTag:            nr.VCSTag,
+		VCSHash:           nr.VCSHash,
+		Deployed:          nr.Done,
+	}
+	if d.Deployed.IsZero() {
+		d.Deployed = time.Now()
+	}
+	if env, has := wr.Workflow.Environments[nodeCtx.EnvironmentID]; has {
+		d.EnvironmentName = env.Name
+	}
+	if app, has := wr.Workflow.Applications[nodeCtx.ApplicationID]; has {
+		d.ApplicationName = app.Name
+	}
+	if integ, has := wr.Workflow.ProjectIntegrations[nodeCtx.ProjectIntegrationID]; has {
+		d.IntegrationName = integ.Name
+	}
+	if v := ParameterFind(nr.BuildParameters, "cds.version"); v != nil {
+		d.Version = v.Value
+	}
+
+	lastArtifacts := make(map[string]WorkflowNodeRunArtifact, len(artifacts))
+	for _, a := range artifacts {
+		if last, has := lastArtifacts[a.Name]; has && last.ID > a.ID {
+			continue
+		}
+		lastArtifacts[a.Name] = a
+	}
+	for _, a := range lastArtifacts {
+		d.Artifacts = append(d.Artifacts, EnvironmentDeploymentArtifact{
+			ID:     a.ID,
+			Name:   a.Name,
+			Tag:    a.Tag,
+			Size:   a.Size,
+			MD5sum: a.MD5sum,
+		})
+	}
+	sort.Slice(d.Artifacts, func(i, j int) bool { return d.Artifacts[i].Name < d.Artifacts[j].Name })
+
+	return d
+}
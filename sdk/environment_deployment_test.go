@@ -0,0 +1,66 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEnvironmentDeployment(t *testing.T) {
+	now := time.Now()
+	wr := WorkflowRun{
+		ID:         10,
+		ProjectID:  1,
+		WorkflowID: 2,
+		Workflow: Workflow{
+			Name:                "my-workflow",
+			Applications:        map[int64]Application{3: {ID: 3, Name: "my-app"}},
+			Environments:        map[int64]Environment{4: {ID: 4, Name: "production"}},
+			ProjectIntegrations: map[int64]ProjectIntegration{5: {ID: 5, Name: "my-kube"}},
+		},
+	}
+	nr := WorkflowNodeRun{
+		ID:               20,
+		WorkflowNodeID:   30,
+		WorkflowNodeName: "deploy",
+		Number:           12,
+		SubNumber:        1,
+		VCSBranch:        "master",
+		VCSHash:          "abcdef",
+		Done:             now,
+		BuildParameters:  []Parameter{{Name: "cds.version", Type: StringParameter, Value: "12"}},
+	}
+	artifacts := []WorkflowNodeRunArtifact{
+		{ID: 2, Name: "app.tar.gz", Tag: "12", MD5sum: "new"},
+		{ID: 1, Name: "app.tar.gz", Tag: "12", MD5sum: "old"},
+		{ID: 3, Name: "api.tar.gz", Tag: "12", Size: 10},
+	}
+
+	d := NewEnvironmentDeployment(wr, nr, NodeContext{ApplicationID: 3, EnvironmentID: 4, ProjectIntegrationID: 5}, artifacts)
+
+	assert.Equal(t, EnvironmentDeployment{
+		ProjectID:         1,
+		EnvironmentID:     4,
+		EnvironmentName:   "production",
+		ApplicationID:     3,
+		ApplicationName:   "my-app",
+		IntegrationName:   "my-kube",
+		WorkflowID:        2,
+		WorkflowName:      "my-workflow",
+		WorkflowNodeID:    30,
+		WorkflowNodeName:  "deploy",
+		WorkflowRunID:     10,
+		WorkflowNodeRunID: 20,
+		Number:            12,
+		SubNumber:         1,
+		Version:           "12",
+		VCSBranch:         "master",
+		VCSHash:           "abcdef",
+		Artifacts: EnvironmentDeploymentArtifacts{
+			{ID: 3, Name: "api.tar.gz", Tag: "12", Size: 10},
+			{ID: 2, Name: "app.tar.gz", Tag: "12", MD5sum: "new"},
+		},
+		Deployed: now,
+	}, d)
+}
@@ -30,3 +30,48 @@ func TestEnvironmentUnmarshal(t *testing.T) {
 	assert.Equal(t, "two", two.Name)
 	assert.True(t, now.Equal(two.LastModified))
 }
+
+func TestEnvironmentFreeze(t *testing.T) {
+	assert.Error(t, sdk.EnvironmentFreeze{Name: "xmas", Start: "2020-12-20", End: "2021-01-03"}.IsValid())
+	assert.Error(t, sdk.EnvironmentFreeze{Name: "xmas", Start: "2020-12-20 00:00", End: "2020-12-19 00:00"}.IsValid())
+	assert.Error(t, sdk.EnvironmentFreeze{Name: "weekend", Cron: "0 18 * * 5"}.IsValid())
+	assert.Error(t, sdk.EnvironmentFreeze{Name: "weekend", Cron: "0 18 * * 5", Duration: "62h", Timezone: "Mars/Olympus"}.IsValid())
+	assert.Error(t, sdk.EnvironmentFreeze{Name: "weekend", Cron: "0 18 * * 5", Duration: "62h", Policy: "ignore"}.IsValid())
+	assert.Error(t, sdk.EnvironmentFreezes{
+		{Name: "weekend", Cron: "0 18 * * 5", Duration: "62h"},
+		{Name: "weekend", Cron: "0 18 * * 5", Duration: "62h"},
+	}.IsValid())
+
+	xmas := sdk.EnvironmentFreeze{Name: "xmas", Start: "2020-12-20 00:00", End: "2021-01-03 00:00", Timezone: "Europe/Paris"}
+	require.NoError(t, xmas.IsValid())
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	until, active := xmas.ActiveUntil(time.Date(2020, 12, 24, 10, 0, 0, 0, paris))
+	assert.True(t, active)
+	assert.True(t, time.Date(2021, 1, 3, 0, 0, 0, 0, paris).Equal(until))
+	_, active = xmas.ActiveUntil(time.Date(2020, 12, 19, 23, 30, 0, 0, time.UTC)) // 00:30 in Paris
+	assert.True(t, active)
+	_, active = xmas.ActiveUntil(time.Date(2021, 1, 3, 0, 0, 0, 0, paris))
+	assert.False(t, active)
+
+	// From friday 18:00 to monday 08:00
+	weekend := sdk.EnvironmentFreeze{Name: "weekend", Cron: "0 18 * * 5", Duration: "62h", Timezone: "Europe/Paris", Policy: sdk.EnvironmentFreezePolicyRefuse}
+	require.NoError(t, weekend.IsValid())
+	assert.Equal(t, sdk.EnvironmentFreezePolicyRefuse, weekend.GetPolicy())
+
+	until, active = weekend.ActiveUntil(time.Date(2020, 6, 6, 12, 0, 0, 0, paris)) // saturday
+	assert.True(t, active)
+	assert.True(t, time.Date(2020, 6, 8, 8, 0, 0, 0, paris).Equal(until))
+	_, active = weekend.ActiveUntil(time.Date(2020, 6, 5, 17, 59, 0, 0, paris)) // friday
+	assert.False(t, active)
+	_, active = weekend.ActiveUntil(time.Date(2020, 6, 8, 8, 0, 0, 0, paris)) // monday
+	assert.False(t, active)
+
+	freezes := sdk.EnvironmentFreezes{xmas, weekend}
+	freeze, _ := freezes.Active(time.Date(2020, 6, 7, 12, 0, 0, 0, paris))
+	require.NotNil(t, freeze)
+	assert.Equal(t, "weekend", freeze.Name)
+	freeze, _ = freezes.Active(time.Date(2020, 6, 9, 12, 0, 0, 0, paris))
+	assert.Nil(t, freeze)
+}
@@ -198,6 +198,8 @@ var (
 	ErrUnsupportedMediaType                          = Error{ID: 188, Status: http.StatusUnsupportedMediaType}
 	ErrNothingToPush                                 = Error{ID: 189, Status: http.StatusBadRequest}
 	ErrWorkerErrorCommand                            = Error{ID: 190, Status: http.StatusBadRequest}
+	ErrQueueQuotaExceeded                            = Error{ID: 191, Status: http.StatusConflict}
+	ErrWorkerNotAvailable                            = Error{ID: 192, Status: http.StatusConflict}
 )
 
 var errorsAmericanEnglish = map[int]string{
@@ -378,6 +380,8 @@ var errorsAmericanEnglish = map[int]string{
 	ErrUnsupportedMediaType.ID:                          "Request format invalid",
 	ErrNothingToPush.ID:                                 "No diff to push",
 	ErrWorkerErrorCommand.ID:                            "Worker command in error",
+	ErrQueueQuotaExceeded.ID:                            "queue quota exceeded",
+	ErrWorkerNotAvailable.ID:                            "worker is not available",
 }
 
 var errorsFrench = map[int]string{
@@ -558,6 +562,8 @@ var errorsFrench = map[int]string{
 	ErrUnsupportedMediaType.ID:                          "Le format de la requête est invalide",
 	ErrNothingToPush.ID:                                 "Aucune modification à pousser",
 	ErrWorkerErrorCommand.ID:                            "Commande du worker en erreur",
+	ErrQueueQuotaExceeded.ID:                            "quota de la file d'attente dépassé",
+	ErrWorkerNotAvailable.ID:                            "le worker n'est pas disponible",
 }
 
 var errorsLanguages = []map[int]string{
@@ -6,16 +6,18 @@ import (
 
 // Environment is a struct to export sdk.Environment
 type Environment struct {
-	Name   string                   `json:"name" yaml:"name" jsonschema_description:"The name of the environment."`
-	Values map[string]VariableValue `json:"values,omitempty" yaml:"values,omitempty"`
-	Keys   map[string]KeyValue      `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Name    string                   `json:"name" yaml:"name" jsonschema_description:"The name of the environment."`
+	Values  map[string]VariableValue `json:"values,omitempty" yaml:"values,omitempty"`
+	Keys    map[string]KeyValue      `json:"keys,omitempty" yaml:"keys,omitempty"`
+	Freezes []sdk.EnvironmentFreeze  `json:"freezes,omitempty" yaml:"freezes,omitempty" jsonschema_description:"Windows during which pipelines can't run on the environment."`
 }
 
 //NewEnvironment returns an Environment from an sdk.Environment pointer
 func NewEnvironment(e sdk.Environment, keys []EncryptedKey) Environment {
 	env := Environment{
-		Name:   e.Name,
-		Values: make(map[string]VariableValue, len(e.Variables)),
+		Name:    e.Name,
+		Values:  make(map[string]VariableValue, len(e.Variables)),
+		Freezes: e.Freezes,
 	}
 	for _, v := range e.Variables {
 		env.Values[v.Name] = VariableValue{
@@ -50,6 +52,7 @@ func (e *Environment) Environment() (env *sdk.Environment) {
 		}
 		i++
 	}
+	env.Freezes = e.Freezes
 
 	return
 }
@@ -2,6 +2,7 @@ package exportentities
 
 import (
 	"sort"
+	"time"
 
 	"github.com/ovh/cds/sdk"
 )
@@ -20,6 +21,7 @@ type PipelineV1 struct {
 	Stages       []string                  `json:"stages,omitempty" yaml:"stages,omitempty" jsonschema_description:"The list of stage's names for the pipeline."`
 	StageOptions map[string]Stage          `json:"options,omitempty" yaml:"options,omitempty" jsonschema_description:"The options for stages of the pipeline."` //Here Stage.Jobs will NEVER be set
 	Jobs         []Job                     `json:"jobs,omitempty" yaml:"jobs,omitempty" jsonschema_description:"The list of jobs for the pipeline."`
+	Finally      string                    `json:"finally,omitempty" yaml:"finally,omitempty" jsonschema_description:"The name of the stage that runs after all the other stages, whatever their status. The pipeline status is available in the cds.pipeline.status variable."`
 }
 
 // PipelineVersion is a version
@@ -39,14 +41,32 @@ type Stage struct {
 
 // Job represents exported sdk.Job
 type Job struct {
-	Name           string        `json:"job,omitempty" yaml:"job,omitempty" jsonschema_description:"The name of the job."`
-	Stage          string        `json:"stage,omitempty" yaml:"stage,omitempty" jsonschema_description:"The name of the stage for the job."`
-	Description    string        `json:"description,omitempty" yaml:"description,omitempty" jsonschema_description:"The description of the job."`
-	Enabled        *bool         `json:"enabled,omitempty" yaml:"enabled,omitempty" jsonschema_description:"Job is enabled by default, you can set this option to disable a job."`
-	Steps          []Step        `json:"steps,omitempty" yaml:"steps,omitempty" jsonschema_description:"The list of steps for the job."`
-	Requirements   []Requirement `json:"requirements,omitempty" yaml:"requirements,omitempty" jsonschema_description:"The list of requirements for the jobs."`
-	Optional       *bool         `json:"optional,omitempty" yaml:"optional,omitempty" jsonschema_description:"Set this option to ignore job's errors."`
-	AlwaysExecuted *bool         `json:"always_executed,omitempty" yaml:"always_executed,omitempty" jsonschema_description:"Set this option to execute the job even if a previous step failed."`
+	Name           string               `json:"job,omitempty" yaml:"job,omitempty" jsonschema_description:"The name of the job."`
+	Stage          string               `json:"stage,omitempty" yaml:"stage,omitempty" jsonschema_description:"The name of the stage for the job."`
+	Description    string               `json:"description,omitempty" yaml:"description,omitempty" jsonschema_description:"The description of the job."`
+	Enabled        *bool                `json:"enabled,omitempty" yaml:"enabled,omitempty" jsonschema_description:"Job is enabled by default, you can set this option to disable a job."`
+	Steps          []Step               `json:"steps,omitempty" yaml:"steps,omitempty" jsonschema_description:"The list of steps for the job."`
+	Requirements   []Requirement        `json:"requirements,omitempty" yaml:"requirements,omitempty" jsonschema_description:"The list of requirements for the jobs."`
+	Optional       *bool                `json:"optional,omitempty" yaml:"optional,omitempty" jsonschema_description:"Set this option to ignore job's errors."`
+	AlwaysExecuted *bool                `json:"always_executed,omitempty" yaml:"always_executed,omitempty" jsonschema_description:"Set this option to execute the job even if a previous step failed."`
+	Timeout        string               `json:"timeout,omitempty" yaml:"timeout,omitempty" jsonschema_description:"Maximum execution duration of the job (ex: 30m, 1h30m), the job fails when it is reached."`
+	Retry          *JobRetry            `json:"retry,omitempty" yaml:"retry,omitempty" jsonschema_description:"Retry policy of the job, the job is replaced in queue if it failed for one of the given reasons."`
+	Needs          []string             `json:"needs,omitempty" yaml:"needs,omitempty" jsonschema_description:"The list of jobs of the same stage that should succeed before this job is started."`
+	Outputs        map[string]JobOutput `json:"outputs,omitempty" yaml:"outputs,omitempty" jsonschema_description:"The outputs set by the job steps with the worker output command, available as cds.outputs.<job>.<name> variables."`
+	Matrix         sdk.JobMatrix        `json:"matrix,omitempty" yaml:"matrix,omitempty" jsonschema_description:"The job is run for each combination of the given values, available as cds.matrix.* variables.\nEx: go: [1.13, 1.14]"`
+	Priority       int                  `json:"priority,omitempty" yaml:"priority,omitempty" jsonschema_description:"Priority of the job in the queue, between -100 and 100 (default 0). Jobs with a higher priority are taken first."`
+}
+
+// JobOutput represents an exported sdk.JobOutput
+type JobOutput struct {
+	Type        string `json:"type,omitempty" yaml:"type,omitempty" jsonschema_description:"Type of the output: string (default), number, boolean or json."`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+}
+
+// JobRetry represents an exported sdk.JobRetry
+type JobRetry struct {
+	MaxAttempts int      `json:"max_attempts,omitempty" yaml:"max_attempts,omitempty" jsonschema_description:"Maximum number of attempts for the job, including the first one."`
+	When        []string `json:"when,omitempty" yaml:"when,omitempty" jsonschema_description:"Failure reasons that trigger a new attempt: worker_lost, spawn_error or failure."`
 }
 
 // Requirement represents an exported sdk.Requirement
@@ -57,6 +77,7 @@ type Requirement struct {
 	Plugin            string             `json:"plugin,omitempty" yaml:"plugin,omitempty"`
 	Service           ServiceRequirement `json:"service,omitempty" yaml:"service,omitempty"`
 	Memory            string             `json:"memory,omitempty" yaml:"memory,omitempty"`
+	CPU               string             `json:"cpu,omitempty" yaml:"cpu,omitempty"`
 	OSArchRequirement string             `json:"os-architecture,omitempty" yaml:"os-architecture,omitempty"`
 	RegionRequirement string             `json:"region,omitempty" yaml:"region,omitempty"`
 }
@@ -67,7 +88,7 @@ type ServiceRequirement struct {
 	Value string `json:"value,omitempty" yaml:"value,omitempty"`
 }
 
-//NewPipelineV1 creates an exportable pipeline from a sdk.Pipeline
+// NewPipelineV1 creates an exportable pipeline from a sdk.Pipeline
 func NewPipelineV1(pip sdk.Pipeline) (p PipelineV1) {
 	p.Name = pip.Name
 	p.Description = pip.Description
@@ -83,9 +104,12 @@ func NewPipelineV1(pip sdk.Pipeline) (p PipelineV1) {
 	}
 
 	p.Stages, p.StageOptions = newStagesForPipelineV1(pip.Stages)
+	if s := pip.FinallyStage(); s != nil {
+		p.Finally = s.Name
+	}
 
 	//If there is one stages and no options
-	if len(p.Stages) == 1 && len(p.StageOptions) == 0 {
+	if len(p.Stages) == 1 && len(p.StageOptions) == 0 && p.Finally == "" {
 		p.Stages = nil
 	}
 
@@ -117,7 +141,7 @@ func newStagesForPipelineV1(stages []sdk.Stage) ([]string, map[string]Stage) {
 			st.Enabled = &s.Enabled
 			hasOptions = true
 		}
-		if len(s.Conditions.PlainConditions) > 0 || s.Conditions.LuaScript != "" {
+		if len(s.Conditions.PlainConditions) > 0 || s.Conditions.LuaScript != "" || s.Conditions.Expression != "" {
 			st.Conditions = &s.Conditions
 			hasOptions = true
 		}
@@ -152,6 +176,8 @@ func newRequirements(req []sdk.Requirement) []Requirement {
 			res = append(res, Requirement{RegionRequirement: r.Value})
 		case sdk.MemoryRequirement:
 			res = append(res, Requirement{Memory: r.Value})
+		case sdk.CPURequirement:
+			res = append(res, Requirement{CPU: r.Value})
 		}
 	}
 	return res
@@ -165,6 +191,28 @@ func newJob(j sdk.Job) Job {
 	jo.Steps = newSteps(j.Action)
 	jo.Description = j.Action.Description
 	jo.Requirements = newRequirements(j.Action.Requirements)
+	if j.Action.Timeout > 0 {
+		jo.Timeout = j.Timeout().String()
+	}
+	if len(j.Action.Matrix) > 0 {
+		jo.Matrix = j.Action.Matrix
+	}
+	if len(j.Action.Needs) > 0 {
+		jo.Needs = j.Action.Needs
+	}
+	if len(j.Action.Outputs) > 0 {
+		jo.Outputs = make(map[string]JobOutput, len(j.Action.Outputs))
+		for _, o := range j.Action.Outputs {
+			jo.Outputs[o.Name] = JobOutput{Type: o.Type, Description: o.Description}
+		}
+	}
+	jo.Priority = j.Action.Priority
+	if j.Action.Retry != nil {
+		jo.Retry = &JobRetry{
+			MaxAttempts: j.Action.Retry.MaxAttempts,
+			When:        j.Action.Retry.On,
+		}
+	}
 	return jo
 }
 
@@ -209,6 +257,10 @@ func computeJobRequirements(req []Requirement) []sdk.Requirement {
 			name = "memory"
 			val = r.Memory
 			tpe = sdk.MemoryRequirement
+		} else if r.CPU != "" {
+			name = "cpu"
+			val = r.CPU
+			tpe = sdk.CPURequirement
 		} else if r.Model != "" {
 			name = "model"
 			val = r.Model
@@ -255,6 +307,55 @@ func computeJob(name string, j Job) (*sdk.Job, error) {
 	job.Action.Enabled = job.Enabled
 	job.Action.Requirements = computeJobRequirements(j.Requirements)
 
+	if j.Timeout != "" {
+		timeout, err := time.ParseDuration(j.Timeout)
+		if err != nil || timeout < time.Second {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid timeout %q for job %s", j.Timeout, name)
+		}
+		job.Action.Timeout = int64(timeout / time.Second)
+	}
+
+	if j.Retry != nil {
+		job.Action.Retry = &sdk.JobRetry{
+			MaxAttempts: j.Retry.MaxAttempts,
+			On:          j.Retry.When,
+		}
+		if err := job.Action.Retry.IsValid(); err != nil {
+			return nil, sdk.WrapError(err, "invalid retry for job %s", name)
+		}
+	}
+
+	if len(j.Needs) > 0 {
+		job.Action.Needs = j.Needs
+	}
+
+	if err := sdk.CheckPriority(j.Priority); err != nil {
+		return nil, sdk.WrapError(err, "invalid priority for job %s", name)
+	}
+	job.Action.Priority = j.Priority
+
+	if len(j.Outputs) > 0 {
+		job.Action.Outputs = make(sdk.JobOutputs, 0, len(j.Outputs))
+		for name, o := range j.Outputs {
+			output := sdk.JobOutput{Name: name, Type: o.Type, Description: o.Description}
+			if output.Type == "" {
+				output.Type = sdk.JobOutputTypeString
+			}
+			job.Action.Outputs = append(job.Action.Outputs, output)
+		}
+		sort.Slice(job.Action.Outputs, func(i, j int) bool { return job.Action.Outputs[i].Name < job.Action.Outputs[j].Name })
+		if err := job.Action.Outputs.IsValid(); err != nil {
+			return nil, sdk.WrapError(err, "invalid outputs for job %s", name)
+		}
+	}
+
+	if len(j.Matrix) > 0 {
+		if err := j.Matrix.IsValid(); err != nil {
+			return nil, sdk.WrapError(err, "invalid matrix for job %s", name)
+		}
+		job.Action.Matrix = j.Matrix
+	}
+
 	//Compute steps for the jobs
 	children, err := computeSteps(j.Steps)
 	if err != nil {
@@ -265,7 +366,7 @@ func computeJob(name string, j Job) (*sdk.Job, error) {
 	return &job, nil
 }
 
-//Pipeline returns a sdk.Pipeline entity
+// Pipeline returns a sdk.Pipeline entity
 func (p PipelineV1) Pipeline() (pip *sdk.Pipeline, err error) {
 	pip = new(sdk.Pipeline)
 	pip.Name = p.Name
@@ -338,6 +439,16 @@ func (p PipelineV1) Pipeline() (pip *sdk.Pipeline, err error) {
 		s.Jobs = append(s.Jobs, *job)
 	}
 
+	// The finally stage is always the last one
+	if p.Finally != "" {
+		s := mapStages[p.Finally]
+		if s == nil {
+			return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid finally stage, stage %s not found", p.Finally)
+		}
+		s.Finally = true
+		s.BuildOrder = len(mapStages) + 1
+	}
+
 	pip.Stages = make([]sdk.Stage, len(mapStages))
 	iS := 0
 	for _, s := range mapStages {
@@ -348,6 +459,15 @@ func (p PipelineV1) Pipeline() (pip *sdk.Pipeline, err error) {
 	sort.Slice(pip.Stages, func(i, j int) bool {
 		return pip.Stages[i].BuildOrder < pip.Stages[j].BuildOrder
 	})
+	for i := range pip.Stages {
+		pip.Stages[i].BuildOrder = i + 1
+	}
+
+	for _, s := range pip.Stages {
+		if err := s.CheckJobsNeeds(); err != nil {
+			return nil, err
+		}
+	}
 
 	return pip, nil
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/ovh/cds/sdk/exportentities"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v2"
 
 	"github.com/ovh/cds/engine/api/test"
@@ -540,6 +541,31 @@ jobs:
 	assert.Len(t, p.Stages[0].Jobs[0].Action.Requirements, 2)
 }
 
+func Test_ImportPipelineWithCPURequirement(t *testing.T) {
+	in := `name: build-all-images
+jobs:
+- job: build
+  requirements:
+  - cpu: "2"
+  - memory: "4096"
+  steps:
+  - script: make -j2
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	require.Len(t, p.Stages[0].Jobs[0].Action.Requirements, 2)
+	assert.Equal(t, sdk.Requirement{Name: "cpu", Type: sdk.CPURequirement, Value: "2"}, p.Stages[0].Jobs[0].Action.Requirements[0])
+	assert.NoError(t, p.Stages[0].Jobs[0].Action.Requirements.IsValid())
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, "2", exported.Jobs[0].Requirements[0].CPU)
+}
+
 func Test_ImportPipelineWithGitClone(t *testing.T) {
 	in := `name: build-all-images
 jobs:
@@ -682,6 +708,212 @@ jobs:
 	assert.Equal(t, "Stage 1", p.Stages[1].Name)
 }
 
+func Test_ImportPipelineWithJobTimeout(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: New Job
+  timeout: 1h30m
+  steps:
+  - script:
+    - echo "coucou"
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	assert.Equal(t, int64(5400), p.Stages[0].Jobs[0].Action.Timeout)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, "1h30m0s", exported.Jobs[0].Timeout)
+
+	payload.Jobs[0].Timeout = "soon"
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithJobPriority(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: New Job
+  priority: 50
+  steps:
+  - script:
+    - echo "coucou"
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	assert.Equal(t, 50, p.Stages[0].Jobs[0].Action.Priority)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, 50, exported.Jobs[0].Priority)
+
+	payload.Jobs[0].Priority = 1000
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithJobRetry(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: New Job
+  retry:
+    max_attempts: 3
+    when:
+    - worker_lost
+    - failure
+  steps:
+  - script:
+    - echo "coucou"
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	retry := p.Stages[0].Jobs[0].Action.Retry
+	require.NotNil(t, retry)
+	assert.Equal(t, 3, retry.MaxAttempts)
+	assert.Equal(t, []string{sdk.JobRetryOnWorkerLost, sdk.JobRetryOnFailure}, retry.On)
+
+	exported := exportentities.NewPipelineV1(*p)
+	require.NotNil(t, exported.Jobs[0].Retry)
+	assert.Equal(t, 3, exported.Jobs[0].Retry.MaxAttempts)
+	assert.Equal(t, []string{"worker_lost", "failure"}, exported.Jobs[0].Retry.When)
+
+	payload.Jobs[0].Retry.When = []string{"always"}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+
+	payload.Jobs[0].Retry = &exportentities.JobRetry{When: []string{"failure"}}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithJobMatrix(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: New Job
+  matrix:
+    go: [1.13, 1.14]
+    os: [linux/amd64, linux/arm64]
+  requirements:
+  - os-architecture: "{{.cds.matrix.os}}"
+  steps:
+  - script:
+    - echo "{{.cds.matrix.go}}"
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	matrix := p.Stages[0].Jobs[0].Action.Matrix
+	assert.Equal(t, sdk.JobMatrix{"go": {"1.13", "1.14"}, "os": {"linux/amd64", "linux/arm64"}}, matrix)
+	assert.Equal(t, 4, matrix.Len())
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, matrix, exported.Jobs[0].Matrix)
+
+	payload.Jobs[0].Matrix = sdk.JobMatrix{"go": nil}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithJobNeeds(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: compile
+  steps:
+  - script:
+    - echo "compile"
+- job: test
+  needs: [compile]
+  steps:
+  - script:
+    - echo "test"
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	require.Len(t, p.Stages, 1)
+	require.Len(t, p.Stages[0].Jobs, 2)
+	assert.Equal(t, sdk.StringSlice{"compile"}, p.Stages[0].Jobs[1].Action.Needs)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, []string{"compile"}, exported.Jobs[1].Needs)
+
+	payload.Jobs[0].Needs = []string{"test"}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+
+	payload.Jobs[0].Needs = []string{"unknown"}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithJobOutputs(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: compile
+  outputs:
+    version:
+      type: string
+      description: the built version
+    coverage:
+      type: number
+  steps:
+  - script:
+    - worker output version 1.0.0
+    - worker output coverage 87.5
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	require.Len(t, p.Stages, 1)
+	require.Len(t, p.Stages[0].Jobs, 1)
+	outs := p.Stages[0].Jobs[0].Action.Outputs
+	require.Len(t, outs, 2)
+	assert.Equal(t, "coverage", outs[0].Name)
+	assert.Equal(t, sdk.JobOutputTypeNumber, outs[0].Type)
+	assert.Equal(t, "version", outs[1].Name)
+	assert.Equal(t, sdk.JobOutputTypeString, outs[1].Type)
+	assert.Equal(t, "the built version", outs[1].Description)
+
+	exported := exportentities.NewPipelineV1(*p)
+	require.Len(t, exported.Jobs[0].Outputs, 2)
+	assert.Equal(t, sdk.JobOutputTypeNumber, exported.Jobs[0].Outputs["coverage"].Type)
+
+	payload.Jobs[0].Outputs["coverage"] = exportentities.JobOutput{Type: "float"}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
 func TestExportPipelineV1_YAML(t *testing.T) {
 	for _, tc := range testcases {
 		p := exportentities.NewPipelineV1(tc.arg)
@@ -777,3 +1009,100 @@ func TestExportAndImportPipelineV1_YAML(t *testing.T) {
 		}
 	}
 }
+
+func Test_ImportPipelineWithFinallyStage(t *testing.T) {
+	in := `version: v1.0
+name: integration
+stages:
+- cleanup
+- setup
+- tests
+finally: cleanup
+jobs:
+- job: Create environment
+  stage: setup
+  steps:
+  - script:
+    - echo "create"
+- job: Run tests
+  stage: tests
+  steps:
+  - script:
+    - echo "test"
+- job: Delete environment
+  stage: cleanup
+  steps:
+  - script:
+    - echo "delete {{.cds.pipeline.status}}"
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	require.Len(t, p.Stages, 3)
+	assert.Equal(t, "setup", p.Stages[0].Name)
+	assert.Equal(t, "tests", p.Stages[1].Name)
+	assert.Equal(t, "cleanup", p.Stages[2].Name)
+	assert.Equal(t, 3, p.Stages[2].BuildOrder)
+	assert.True(t, p.Stages[2].Finally)
+	assert.False(t, p.Stages[0].Finally)
+	test.NoError(t, p.CheckFinallyStage())
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Equal(t, "cleanup", exported.Finally)
+	assert.Equal(t, []string{"setup", "tests", "cleanup"}, exported.Stages)
+
+	payload.Finally = "unknown"
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
+
+func Test_ImportPipelineWithStepConditions(t *testing.T) {
+	in := `version: v1.0
+name: echo
+jobs:
+- job: New Job
+  steps:
+  - script:
+    - make build
+  - name: publish
+    conditions:
+      check:
+      - variable: git.branch
+        operator: eq
+        value: master
+    script:
+    - make publish
+  - name: debug
+    conditions:
+      expression: cds.build.debug == "true"
+    script:
+    - env
+`
+
+	payload := &exportentities.PipelineV1{}
+	test.NoError(t, yaml.Unmarshal([]byte(in), payload))
+
+	p, err := payload.Pipeline()
+	test.NoError(t, err)
+
+	steps := p.Stages[0].Jobs[0].Action.Actions
+	require.Len(t, steps, 3)
+	assert.Nil(t, steps[0].Conditions)
+	require.NotNil(t, steps[1].Conditions)
+	assert.Equal(t, "git.branch", steps[1].Conditions.PlainConditions[0].Variable)
+	require.NotNil(t, steps[2].Conditions)
+	assert.Equal(t, `cds.build.debug == "true"`, steps[2].Conditions.Expression)
+
+	exported := exportentities.NewPipelineV1(*p)
+	assert.Nil(t, exported.Jobs[0].Steps[0].Conditions)
+	assert.Equal(t, steps[1].Conditions, exported.Jobs[0].Steps[1].Conditions)
+	assert.Equal(t, steps[2].Conditions, exported.Jobs[0].Steps[2].Conditions)
+
+	payload.Jobs[0].Steps[2].Conditions = &sdk.WorkflowNodeConditions{Expression: "cds.build.debug =="}
+	_, err = payload.Pipeline()
+	assert.Error(t, err)
+}
@@ -26,6 +26,9 @@ func newStep(act sdk.Action) Step {
 	if act.AlwaysExecuted {
 		s.AlwaysExecuted = &sdk.True
 	}
+	if act.Conditions != nil && !act.Conditions.IsEmpty() {
+		s.Conditions = act.Conditions
+	}
 
 	switch act.Type {
 	case sdk.BuiltinAction:
@@ -322,10 +325,11 @@ type StepDeploy string
 // Step represents exported step used in a job.
 type Step struct {
 	// common step data
-	Name           string `json:"name,omitempty" yaml:"name,omitempty" jsonschema_description:"The name for this step."`
-	Enabled        *bool  `json:"enabled,omitempty" yaml:"enabled,omitempty"`
-	Optional       *bool  `json:"optional,omitempty" yaml:"optional,omitempty"`
-	AlwaysExecuted *bool  `json:"always_executed,omitempty" yaml:"always_executed,omitempty"`
+	Name           string                      `json:"name,omitempty" yaml:"name,omitempty" jsonschema_description:"The name for this step."`
+	Enabled        *bool                       `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Optional       *bool                       `json:"optional,omitempty" yaml:"optional,omitempty"`
+	AlwaysExecuted *bool                       `json:"always_executed,omitempty" yaml:"always_executed,omitempty"`
+	Conditions     *sdk.WorkflowNodeConditions `json:"conditions,omitempty" yaml:"conditions,omitempty" jsonschema_description:"Conditions evaluated by the worker on the job variables, the step is skipped if they are not satisfied."`
 	// step specific data, only one option should be set
 	StepCustom       `json:"-" yaml:",inline"`
 	Script           interface{}           `json:"script,omitempty" yaml:"script,omitempty" jsonschema:"oneof_type=string;array,oneof_required=actionScript" jsonschema_description:"Script.\nhttps://ovh.github.io/cds/docs/actions/builtin-script"`
@@ -506,6 +510,12 @@ func (s Step) toAction() (*sdk.Action, error) {
 	a.Enabled = s.Enabled == nil || *s.Enabled == sdk.True // enabled is true by default
 	a.Optional = s.Optional != nil && *s.Optional == sdk.True
 	a.AlwaysExecuted = s.AlwaysExecuted != nil && *s.AlwaysExecuted == sdk.True
+	if s.Conditions != nil && !s.Conditions.IsEmpty() {
+		if err := s.Conditions.IsValid(); err != nil {
+			return nil, sdk.NewErrorWithStack(err, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid step conditions: %v", err))
+		}
+		a.Conditions = s.Conditions
+	}
 
 	return &a, nil
 }
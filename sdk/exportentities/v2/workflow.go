@@ -28,6 +28,8 @@ type Workflow struct {
 	PurgeTags     []string            `json:"purge_tags,omitempty" yaml:"purge_tags,omitempty"`
 	Notifications []NotificationEntry `json:"notifications,omitempty" yaml:"notifications,omitempty"` // This is used when the workflow have only one pipeline
 	HistoryLength *int64              `json:"history_length,omitempty" yaml:"history_length,omitempty"`
+	AutoCancel    bool                `json:"auto_cancel,omitempty" yaml:"auto_cancel,omitempty" jsonschema_description:"Set to true to stop the previous runs on a branch when a new run is started by a hook on this branch."`
+	Priority      int                 `json:"priority,omitempty" yaml:"priority,omitempty" jsonschema_description:"Priority of the jobs of the workflow in the queue, between -100 and 100 (default 0). Jobs with a higher priority are taken first."`
 }
 
 // NodeEntry represents a node as code
@@ -41,6 +43,8 @@ type NodeEntry struct {
 	EnvironmentName        string                 `json:"environment,omitempty" yaml:"environment,omitempty" jsonschema_description:"The environment to use in the context of the node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
 	ProjectIntegrationName string                 `json:"integration,omitempty" yaml:"integration,omitempty" jsonschema_description:"The integration to use in the context of the node.\nhttps://ovh.github.io/cds/docs/concepts/workflow/pipeline-context"`
 	OneAtATime             *bool                  `json:"one_at_a_time,omitempty" yaml:"one_at_a_time,omitempty" jsonschema_description:"Set to true if you want to limit the execution of this node to one at a time."`
+	Concurrency            *ConcurrencyEntry      `json:"concurrency,omitempty" yaml:"concurrency,omitempty" jsonschema_description:"Named concurrency group shared with nodes of other workflows (ex: deploy-prod-{{.cds.env.name}})."`
+	Approval               *ApprovalEntry         `json:"approval,omitempty" yaml:"approval,omitempty" jsonschema_description:"Manual approval required before running the node."`
 	Payload                map[string]interface{} `json:"payload,omitempty" yaml:"payload,omitempty"`
 	Parameters             map[string]string      `json:"parameters,omitempty" yaml:"parameters,omitempty" jsonschema_description:"List of parameters for the workflow."`
 	OutgoingHookModelName  string                 `json:"trigger,omitempty" yaml:"trigger,omitempty"`
@@ -48,9 +52,23 @@ type NodeEntry struct {
 	Permissions            map[string]int         `json:"permissions,omitempty" yaml:"permissions,omitempty" jsonschema_description:"The permissions for the node (ex: myGroup: 7).\nhttps://ovh.github.io/cds/docs/concepts/permissions"`
 }
 
+// ConcurrencyEntry represents a node concurrency group as code
+type ConcurrencyEntry struct {
+	Group  string `json:"group" yaml:"group" jsonschema_description:"The name of the concurrency group, can use the build parameters of the node."`
+	Policy string `json:"policy,omitempty" yaml:"policy,omitempty" jsonschema_description:"What to do with the older runs of the group: queue (default), cancel-pending or cancel-in-progress."`
+}
+
+// ApprovalEntry represents a node approval gate as code
+type ApprovalEntry struct {
+	Groups       []string `json:"groups" yaml:"groups" jsonschema_description:"Names of the groups whose members can approve the node."`
+	MinApprovals int      `json:"min_approvals,omitempty" yaml:"min_approvals,omitempty" jsonschema_description:"Number of approvals required to run the node, 1 by default."`
+	Expiry       string   `json:"expiry,omitempty" yaml:"expiry,omitempty" jsonschema_description:"Duration after which the node run is stopped if not approved (ex: 24h)."`
+}
+
 type ConditionEntry struct {
 	PlainConditions []PlainConditionEntry `json:"check,omitempty" yaml:"check,omitempty"`
 	LuaScript       string                `json:"script,omitempty" yaml:"script,omitempty"`
+	Expression      string                `json:"expression,omitempty" yaml:"expression,omitempty" jsonschema_description:"Typed condition expression.\nhttps://ovh.github.io/cds/docs/concepts/workflow/run-conditions"`
 }
 
 //WorkflowNodeCondition represents a condition to trigger ot not a pipeline in a workflow. Operator can be =, !=, regex
@@ -69,7 +87,7 @@ type HookEntry struct {
 
 func (h HookEntry) IsDefault(model sdk.WorkflowHookModel) bool {
 	if h.Conditions != nil {
-		if h.Conditions.LuaScript != "" || len(h.Conditions.PlainConditions) > 0 {
+		if h.Conditions.LuaScript != "" || h.Conditions.Expression != "" || len(h.Conditions.PlainConditions) > 0 {
 			return false
 		}
 	}
@@ -118,6 +136,8 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, version string, opts ...Ex
 	}
 
 	exportedWorkflow.PurgeTags = w.PurgeTags
+	exportedWorkflow.AutoCancel = w.AutoCancel
+	exportedWorkflow.Priority = w.Priority
 
 	nodes := w.WorkflowData.Array()
 
@@ -147,7 +167,7 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, version string, opts ...Ex
 				Conditions: &h.Conditions,
 			}
 
-			if h.Conditions.LuaScript == "" && len(h.Conditions.PlainConditions) == 0 {
+			if h.Conditions.LuaScript == "" && h.Conditions.Expression == "" && len(h.Conditions.PlainConditions) == 0 {
 				pipHook.Conditions = nil
 			}
 
@@ -170,7 +190,7 @@ func NewWorkflow(ctx context.Context, w sdk.Workflow, version string, opts ...Ex
 }
 
 func joinAsNode(n *sdk.Node) bool {
-	return n.Context != nil && (n.Context.Conditions.LuaScript != "" || len(n.Context.Conditions.PlainConditions) > 0)
+	return n.Context != nil && (n.Context.Conditions.LuaScript != "" || n.Context.Conditions.Expression != "" || len(n.Context.Conditions.PlainConditions) > 0)
 }
 
 func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
@@ -229,10 +249,11 @@ func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
 			}
 		}
 
-		if len(conditions) > 0 || n.Context.Conditions.LuaScript != "" {
+		if len(conditions) > 0 || n.Context.Conditions.LuaScript != "" || n.Context.Conditions.Expression != "" {
 			entry.Conditions = &ConditionEntry{
 				PlainConditions: make([]PlainConditionEntry, 0, len(conditions)),
 				LuaScript:       n.Context.Conditions.LuaScript,
+				Expression:      n.Context.Conditions.Expression,
 			}
 			for _, c := range conditions {
 				entry.Conditions.PlainConditions = append(entry.Conditions.PlainConditions, PlainConditionEntry{
@@ -257,6 +278,23 @@ func craftNodeEntry(w sdk.Workflow, n sdk.Node) (NodeEntry, error) {
 			entry.OneAtATime = &n.Context.Mutex
 		}
 
+		if n.Context.Concurrency != nil {
+			entry.Concurrency = &ConcurrencyEntry{
+				Group:  n.Context.Concurrency.Name,
+				Policy: n.Context.Concurrency.Policy,
+			}
+		}
+
+		if n.Context.Approval != nil {
+			entry.Approval = &ApprovalEntry{
+				Groups:       n.Context.Approval.Groups,
+				MinApprovals: n.Context.Approval.MinApprovals,
+			}
+			if n.Context.Approval.Expiry > 0 {
+				entry.Approval.Expiry = n.Context.Approval.GetExpiry().String()
+			}
+		}
+
 		if n.Context.HasDefaultPayload() {
 			enc := dump.NewDefaultEncoder()
 			enc.ExtraFields.DetailedMap = false
@@ -377,6 +415,11 @@ func (w Workflow) GetWorkflow() (*sdk.Workflow, error) {
 		return nil, sdk.WrapError(err, "unable to check dependencies")
 	}
 	wf.PurgeTags = w.PurgeTags
+	wf.AutoCancel = w.AutoCancel
+	if err := sdk.CheckPriority(w.Priority); err != nil {
+		return nil, err
+	}
+	wf.Priority = w.Priority
 	if len(w.Metadata) > 0 {
 		wf.Metadata = make(map[string]string, len(w.Metadata))
 		for k, v := range w.Metadata {
@@ -648,6 +691,7 @@ func (e *NodeEntry) getNode(name string) (*sdk.Node, error) {
 		node.Context.Conditions = sdk.WorkflowNodeConditions{
 			PlainConditions: make([]sdk.WorkflowNodeCondition, 0, len(e.Conditions.PlainConditions)),
 			LuaScript:       e.Conditions.LuaScript,
+			Expression:      e.Conditions.Expression,
 		}
 		for _, c := range e.Conditions.PlainConditions {
 			node.Context.Conditions.PlainConditions = append(node.Context.Conditions.PlainConditions, sdk.WorkflowNodeCondition{
@@ -691,6 +735,33 @@ func (e *NodeEntry) getNode(name string) (*sdk.Node, error) {
 		node.Context.Mutex = *e.OneAtATime
 	}
 
+	if e.Concurrency != nil {
+		node.Context.Concurrency = &sdk.NodeConcurrency{
+			Name:   e.Concurrency.Group,
+			Policy: e.Concurrency.Policy,
+		}
+		if err := node.Context.Concurrency.IsValid(); err != nil {
+			return nil, sdk.WrapError(err, "invalid concurrency for node %s", name)
+		}
+	}
+
+	if e.Approval != nil {
+		node.Context.Approval = &sdk.NodeApproval{
+			Groups:       e.Approval.Groups,
+			MinApprovals: e.Approval.MinApprovals,
+		}
+		if e.Approval.Expiry != "" {
+			expiry, err := time.ParseDuration(e.Approval.Expiry)
+			if err != nil {
+				return nil, sdk.NewErrorFrom(sdk.ErrWrongRequest, "invalid approval expiry %s for node %s", e.Approval.Expiry, name)
+			}
+			node.Context.Approval.Expiry = int64(expiry.Seconds())
+		}
+		if err := node.Context.Approval.IsValid(); err != nil {
+			return nil, sdk.WrapError(err, "invalid approval for node %s", name)
+		}
+	}
+
 	if e.OutgoingHookModelName != "" {
 		node.Type = sdk.NodeTypeOutGoingHook
 		config := sdk.WorkflowNodeHookConfig{}
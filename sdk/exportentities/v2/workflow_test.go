@@ -693,6 +693,59 @@ func TestFromYAMLToYAML(t *testing.T) {
 		wantErr bool
 	}{
 		{
+			name: "test with concurrency group",
+			yaml: `name: deploy
+version: v2.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    when:
+    - success
+    pipeline: deploy
+    concurrency:
+      group: deploy-prod-{{.cds.env.name}}
+      policy: cancel-in-progress
+`,
+		}, {
+			name: "test with condition expression",
+			yaml: `name: deploy
+version: v2.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    conditions:
+      expression: git.branch startsWith "release/" && cds.version > 100
+    when:
+    - success
+    pipeline: deploy
+`,
+		}, {
+			name: "test with approval gate",
+			yaml: `name: deploy
+version: v2.0
+workflow:
+  build:
+    pipeline: build
+  deploy:
+    depends_on:
+    - build
+    when:
+    - success
+    pipeline: deploy
+    approval:
+      groups:
+      - ops
+      - release-managers
+      min_approvals: 2
+      expiry: 24h0m0s
+`,
+		}, {
 			name: "1_start -> 2_webhook -> 3_after_webhook -> 4_fork_before_end -> 5_end",
 			yaml: `name: test1
 version: v2.0
@@ -767,6 +820,51 @@ workflow:
     pipeline: DDOS-me
 metadata:
   default_tags: git.branch,git.author
+`,
+		}, {
+			name: "test with auto cancel",
+			yaml: `name: build
+version: v2.0
+workflow:
+  build:
+    pipeline: build
+hooks:
+  build:
+  - type: RepositoryWebHook
+auto_cancel: true
+`,
+		}, {
+			name: "test with priority",
+			yaml: `name: release
+version: v2.0
+workflow:
+  release:
+    pipeline: release
+priority: 50
+`,
+		}, {
+			name: "test with sub workflow",
+			yaml: `name: release
+version: v2.0
+workflow:
+  1_build:
+    pipeline: build
+  2_SubWorkflow:
+    depends_on:
+    - 1_build
+    trigger: SubWorkflow
+    config:
+      artifacts: '*.tar.gz'
+      outputs: cds.outputs.*,cds.build.version
+      target_hook: bd9ca90e-02e8-4559-9eca-9c56f1518945
+      target_project: SHARED
+      target_workflow: release
+  3_deploy:
+    depends_on:
+    - 2_SubWorkflow
+    when:
+    - success
+    pipeline: deploy
 `,
 		}, {
 			name: "tests with outgoing hooks with a join",
@@ -23,6 +23,8 @@ type WorkerModel struct {
 	PostCmd      string            `json:"post_cmd,omitempty" yaml:"post_cmd,omitempty"`
 	Restricted   bool              `json:"restricted,omitempty" yaml:"restricted,omitempty"`
 	IsDeprecated bool              `json:"is_deprecated,omitempty" yaml:"is_deprecated,omitempty"`
+	// Kubernetes contains the pod overrides of a docker model used by the Kubernetes hatchery
+	Kubernetes *sdk.ModelDockerKubernetes `json:"kubernetes,omitempty" yaml:"kubernetes,omitempty"`
 }
 
 type WorkerModelOption func(sdk.Model, *WorkerModel) error
@@ -39,6 +41,7 @@ func loadWorkerModelWithoutAdminFields(_ sdk.Model, wm *WorkerModel) error {
 	wm.Cmd = ""
 	wm.PostCmd = ""
 	wm.Envs = nil
+	wm.Kubernetes = nil
 	return nil
 }
 
@@ -60,6 +63,7 @@ func NewWorkerModel(wm sdk.Model, opts ...WorkerModelOption) WorkerModel {
 		model.Image = wm.ModelDocker.Image
 		model.Cmd = wm.ModelDocker.Cmd
 		model.Envs = wm.ModelDocker.Envs
+		model.Kubernetes = wm.ModelDocker.Kubernetes
 		if wm.ModelDocker.Private {
 			model.Registry = wm.ModelDocker.Registry
 			model.Username = wm.ModelDocker.Username
@@ -98,10 +102,11 @@ func (wm WorkerModel) GetWorkerModel() sdk.Model {
 	switch wm.Type {
 	case sdk.Docker:
 		model.ModelDocker = sdk.ModelDocker{
-			Shell: wm.Shell,
-			Image: wm.Image,
-			Cmd:   wm.Cmd,
-			Envs:  wm.Envs,
+			Shell:      wm.Shell,
+			Image:      wm.Image,
+			Cmd:        wm.Cmd,
+			Envs:       wm.Envs,
+			Kubernetes: wm.Kubernetes,
 		}
 		if wm.Username != "" || wm.Registry != "" || wm.Password != "" {
 			model.ModelDocker.Registry = wm.Registry
@@ -49,3 +49,53 @@ func TestNewWorkerModelAndGetWorkerModel(t *testing.T) {
 	test.NoError(t, err)
 	assert.Equal(t, string(sdkWmYaml), string(importedYaml))
 }
+
+func TestWorkerModelKubernetesOverrides(t *testing.T) {
+	content := `name: myITModel
+group: shared.infra
+type: docker
+image: foo/model/go:latest
+shell: sh -c
+cmd: worker --api={{.API}}
+kubernetes:
+  node_selector:
+    disktype: ssd
+  tolerations:
+  - key: dedicated
+    operator: Equal
+    value: cds
+    effect: NoSchedule
+  cpu_request: 500m
+  cpu_limit: "2"
+  service_account: cds-worker
+  volumes:
+  - name: cache
+    mount_path: /cache
+  init_container:
+    image: busybox
+    command: [sh, -c, "echo init"]
+`
+	var wm exportentities.WorkerModel
+	test.NoError(t, yaml.Unmarshal([]byte(content), &wm))
+
+	model := wm.GetWorkerModel()
+	assert.NoError(t, model.IsValidType())
+	k := model.ModelDocker.Kubernetes
+	if assert.NotNil(t, k) {
+		assert.Equal(t, map[string]string{"disktype": "ssd"}, k.NodeSelector)
+		assert.Equal(t, []sdk.ModelKubernetesToleration{{Key: "dedicated", Operator: "Equal", Value: "cds", Effect: "NoSchedule"}}, k.Tolerations)
+		assert.Equal(t, "500m", k.CPURequest)
+		assert.Equal(t, "2", k.CPULimit)
+		assert.Equal(t, "cds-worker", k.ServiceAccount)
+		assert.Equal(t, []sdk.ModelKubernetesVolume{{Name: "cache", MountPath: "/cache"}}, k.Volumes)
+		assert.Equal(t, &sdk.ModelKubernetesInitContainer{Image: "busybox", Command: []string{"sh", "-c", "echo init"}}, k.InitContainer)
+	}
+
+	exported := exportentities.NewWorkerModel(model)
+	assert.Equal(t, wm.Kubernetes, exported.Kubernetes)
+	exported = exportentities.NewWorkerModel(model, exportentities.WorkerModelLoadOptions.HideAdminFields)
+	assert.Nil(t, exported.Kubernetes)
+
+	wm.Kubernetes.Volumes[0].MountPath = "cache"
+	assert.Error(t, wm.GetWorkerModel().IsValidType())
+}
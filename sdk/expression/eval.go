@@ -0,0 +1,328 @@
+package expression
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/blang/semver"
+)
+
+var numberRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// functions available in expressions, they take already evaluated arguments
+var functions = map[string]func(args []interface{}) (interface{}, error){
+	"number": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("number() takes one argument")
+		}
+		if f, ok := toNumber(args[0]); ok {
+			return f, nil
+		}
+		return nil, fmt.Errorf("%q is not a number", toString(args[0]))
+	},
+	"semver": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("semver() takes one argument")
+		}
+		if v, ok := toSemver(args[0], true); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("%q is not a semantic version", toString(args[0]))
+	},
+	"string": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("string() takes one argument")
+		}
+		return toString(args[0]), nil
+	},
+	"len": func(args []interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("len() takes one argument")
+		}
+		switch v := args[0].(type) {
+		case []interface{}:
+			return float64(len(v)), nil
+		case map[string]interface{}:
+			return float64(len(v)), nil
+		default:
+			return float64(len(toString(v))), nil
+		}
+	},
+}
+
+func (n literalNode) eval(vars map[string]string) (interface{}, error) {
+	return n.value, nil
+}
+
+func (n listNode) eval(vars map[string]string) (interface{}, error) {
+	res := make([]interface{}, 0, len(n.items))
+	for _, i := range n.items {
+		v, err := i.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+// eval looks for the longest variable name matching the beginning of the path,
+// the rest of the path is read in the value of the variable parsed as JSON (ex: payload.commits[0].author).
+// Unknown variables are null.
+func (n variableNode) eval(vars map[string]string) (interface{}, error) {
+	var names []string
+	for _, p := range n.path {
+		s, ok := p.(string)
+		if !ok {
+			break
+		}
+		names = append(names, s)
+	}
+
+	for i := len(names); i > 0; i-- {
+		value, ok := vars[strings.Join(names[:i], ".")]
+		if !ok {
+			continue
+		}
+		if i == len(n.path) {
+			return value, nil
+		}
+		var current interface{}
+		if err := json.Unmarshal([]byte(value), &current); err != nil {
+			return nil, nil
+		}
+		for _, p := range n.path[i:] {
+			switch k := p.(type) {
+			case string:
+				m, ok := current.(map[string]interface{})
+				if !ok {
+					return nil, nil
+				}
+				current = m[k]
+			case int:
+				l, ok := current.([]interface{})
+				if !ok || k >= len(l) {
+					return nil, nil
+				}
+				current = l[k]
+			}
+		}
+		return current, nil
+	}
+	return nil, nil
+}
+
+func (n callNode) eval(vars map[string]string) (interface{}, error) {
+	args := make([]interface{}, 0, len(n.args))
+	for _, a := range n.args {
+		v, err := a.eval(vars)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	return functions[n.name](args)
+}
+
+func (n notNode) eval(vars map[string]string) (interface{}, error) {
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	b, err := toBool(v)
+	if err != nil {
+		return nil, err
+	}
+	return !b, nil
+}
+
+func (n logicalNode) eval(vars map[string]string) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	l, err := toBool(left)
+	if err != nil {
+		return nil, err
+	}
+	if n.operator == "and" && !l {
+		return false, nil
+	}
+	if n.operator == "or" && l {
+		return true, nil
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	return toBool(right)
+}
+
+func (n comparisonNode) eval(vars map[string]string) (interface{}, error) {
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.operator {
+	case "==":
+		return compare(left, right) == 0, nil
+	case "!=":
+		return compare(left, right) != 0, nil
+	case "<":
+		return compare(left, right) < 0, nil
+	case "<=":
+		return compare(left, right) <= 0, nil
+	case ">":
+		return compare(left, right) > 0, nil
+	case ">=":
+		return compare(left, right) >= 0, nil
+	case "in":
+		return contains(right, left), nil
+	case "contains":
+		return contains(left, right), nil
+	case "startsWith":
+		return strings.HasPrefix(toString(left), toString(right)), nil
+	case "endsWith":
+		return strings.HasSuffix(toString(left), toString(right)), nil
+	case "matches":
+		match, err := regexp.MatchString(toString(right), toString(left))
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", toString(right), err)
+		}
+		return match, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.operator)
+}
+
+// compare compares two values as booleans, numbers, semantic versions or strings, in this order,
+// depending on what both values can be converted to.
+func compare(a, b interface{}) int {
+	_, aIsBool := a.(bool)
+	_, bIsBool := b.(bool)
+	if aIsBool || bIsBool {
+		if toString(a) == toString(b) {
+			return 0
+		}
+		return strings.Compare(toString(a), toString(b))
+	}
+
+	if fa, ok := toNumber(a); ok {
+		if fb, ok := toNumber(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	_, aIsSemver := a.(semver.Version)
+	_, bIsSemver := b.(semver.Version)
+	if va, ok := toSemver(a, bIsSemver); ok {
+		if vb, ok := toSemver(b, aIsSemver); ok {
+			return va.Compare(vb)
+		}
+	}
+
+	return strings.Compare(toString(a), toString(b))
+}
+
+// contains returns true if the list contains the value or if the string contains the value as substring
+func contains(container, value interface{}) bool {
+	if l, ok := container.([]interface{}); ok {
+		for _, i := range l {
+			if compare(i, value) == 0 {
+				return true
+			}
+		}
+		return false
+	}
+	if m, ok := container.(map[string]interface{}); ok {
+		_, has := m[toString(value)]
+		return has
+	}
+	return strings.Contains(toString(container), toString(value))
+}
+
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case string:
+		if !numberRegex.MatchString(t) {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(t, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toSemver converts the value to a semantic version, a string is converted only if it is a complete version
+// like 1.2.3 or v1.2.3, unless tolerant is set where 1.2 and v1 are also accepted
+func toSemver(v interface{}, tolerant bool) (semver.Version, bool) {
+	switch t := v.(type) {
+	case semver.Version:
+		return t, true
+	case float64:
+		if !tolerant {
+			return semver.Version{}, false
+		}
+		return toSemver(strconv.FormatFloat(t, 'f', -1, 64), true)
+	case string:
+		s := strings.TrimPrefix(t, "v")
+		if tolerant {
+			sv, err := semver.ParseTolerant(s)
+			return sv, err == nil
+		}
+		sv, err := semver.Parse(s)
+		return sv, err == nil
+	}
+	return semver.Version{}, false
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch t := v.(type) {
+	case bool:
+		return t, nil
+	case string:
+		switch strings.ToLower(t) {
+		case "true":
+			return true, nil
+		case "false", "":
+			return false, nil
+		}
+	case nil:
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not a boolean", toString(v))
+}
+
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(t)
+	case semver.Version:
+		return t.String()
+	default:
+		btes, _ := json.Marshal(t)
+		return string(btes)
+	}
+}
@@ -0,0 +1,59 @@
+// Package expression implements the typed condition expressions used to run or skip nodes, stages and notifications.
+//
+// An expression returns a boolean, ex:
+//
+//	git.branch startsWith "release/" && semver(cds.version) >= "1.2.0"
+//	cds.status in ["Success", "Stopped"] and not (payload.pull_request.draft == true)
+//
+// Values are compared as numbers or semantic versions when both sides can be converted, and as strings otherwise.
+// The JSON value of a variable, like the git payload, can be read with a path: payload.commits[0].author.name
+package expression
+
+import (
+	"fmt"
+)
+
+// Expression is a parsed expression that can be evaluated with several sets of variables.
+type Expression struct {
+	raw  string
+	root node
+}
+
+// Parse parses an expression, it returns an error if the syntax is invalid.
+func Parse(s string) (*Expression, error) {
+	tokens, err := lex(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression: %v", err)
+	}
+	if t := p.peek(); t.typ != tokenEOF {
+		return nil, fmt.Errorf("invalid expression: unexpected %s", t)
+	}
+	return &Expression{raw: s, root: root}, nil
+}
+
+// Eval evaluates the expression with the given variables.
+func (e *Expression) Eval(vars map[string]string) (bool, error) {
+	v, err := e.root.eval(vars)
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate expression %q: %v", e.raw, err)
+	}
+	b, err := toBool(v)
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate expression %q: %v", e.raw, err)
+	}
+	return b, nil
+}
+
+// Eval parses and evaluates the expression with the given variables.
+func Eval(s string, vars map[string]string) (bool, error) {
+	e, err := Parse(s)
+	if err != nil {
+		return false, err
+	}
+	return e.Eval(vars)
+}
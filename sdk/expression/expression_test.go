@@ -0,0 +1,92 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEval(t *testing.T) {
+	vars := map[string]string{
+		"cds.version":     "10",
+		"cds.status":      "Success",
+		"cds.manual":      "true",
+		"git.branch":      "release/1.2",
+		"git.tag":         "v1.10.0",
+		"cds.env.name":    "prod",
+		"cds.env.timeout": "9.5",
+		"payload":         `{"ref":"refs/heads/master","commits":[{"id":"abc","author":{"name":"john"}}],"labels":["deploy","urgent"],"pull_request":{"draft":false}}`,
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{`cds.version > 9`, true},
+		{`cds.version > "9"`, true},
+		{`cds.version gt 9 and cds.version le 10`, true},
+		{`cds.env.timeout < 10`, true},
+		{`number(cds.version) == 10.0`, true},
+		{`git.tag > "v1.9.0"`, true},
+		{`semver(git.tag) >= "1.10"`, true},
+		{`semver(git.tag) < semver("2")`, true},
+		{`git.branch startsWith "release/"`, true},
+		{`git.branch endsWith "1.3"`, false},
+		{`git.branch contains "ease"`, true},
+		{`git.branch matches "^release/[0-9.]+$"`, true},
+		{`cds.status in ["Success", "Stopped"]`, true},
+		{`cds.env.name in ['preprod', 'staging']`, false},
+		{`cds.manual`, true},
+		{`!cds.manual || cds.status == "Success"`, true},
+		{`not (cds.manual and cds.status != "Success")`, true},
+		{`cds.manual && (cds.env.name == "dev" || cds.env.name == "prod")`, true},
+		{`payload.ref == "refs/heads/master"`, true},
+		{`payload.commits[0].author.name == "john"`, true},
+		{`payload.commits[0]["id"] == "abc"`, true},
+		{`len(payload.commits) == 1`, true},
+		{`payload.labels contains "urgent"`, true},
+		{`"deploy" in payload.labels`, true},
+		{`payload.pull_request.draft == false`, true},
+		{`payload.unknown.field == null`, true},
+		{`unknown.variable == ""`, true},
+		{`payload.commits[3].id == "abc"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			got, err := Eval(tt.expr, vars)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`cds.version >`,
+		`(cds.version > 1`,
+		`git.branch == "master`,
+		`cds.status in ["Success"`,
+		`unknownfunc(cds.version)`,
+		`cds.version > 1 cds.status`,
+		`git..branch == "master"`,
+		`cds.version @ 1`,
+	} {
+		_, err := Parse(expr)
+		assert.Error(t, err, "expression %q should be invalid", expr)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	vars := map[string]string{"git.branch": "master"}
+	for _, expr := range []string{
+		`git.branch`,
+		`semver(git.branch) > "1.0.0"`,
+		`git.branch matches "["`,
+	} {
+		_, err := Eval(expr, vars)
+		assert.Error(t, err, "expression %q should fail", expr)
+	}
+}
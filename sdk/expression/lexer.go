@@ -0,0 +1,152 @@
+package expression
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type tokenType int
+
+const (
+	tokenEOF tokenType = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenDot
+	tokenComma
+	tokenLeftParen
+	tokenRightParen
+	tokenLeftBracket
+	tokenRightBracket
+)
+
+type token struct {
+	typ   tokenType
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	if t.typ == tokenEOF {
+		return "end of expression"
+	}
+	return fmt.Sprintf("%q at position %d", t.value, t.pos)
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!"}
+
+func isIdentStart(r byte) bool {
+	return r == '_' || unicode.IsLetter(rune(r))
+}
+
+func isIdentPart(r byte) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsLetter(rune(r)) || unicode.IsDigit(rune(r))
+}
+
+func isDigit(r byte) bool {
+	return r >= '0' && r <= '9'
+}
+
+// lex splits the expression into tokens.
+// Identifiers contain dots so that variables like git.branch are a single token.
+func lex(s string) ([]token, error) {
+	var tokens []token
+	i := 0
+loop:
+	for i < len(s) {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{tokenLeftParen, "(", i})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{tokenRightParen, ")", i})
+			i++
+		case c == '[':
+			tokens = append(tokens, token{tokenLeftBracket, "[", i})
+			i++
+		case c == ']':
+			tokens = append(tokens, token{tokenRightBracket, "]", i})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{tokenComma, ",", i})
+			i++
+		case c == '.' && (i+1 >= len(s) || !isDigit(s[i+1])):
+			tokens = append(tokens, token{tokenDot, ".", i})
+			i++
+		case c == '\'' || c == '"':
+			value, n, err := lexString(s[i:])
+			if err != nil {
+				return nil, fmt.Errorf("%v at position %d", err, i)
+			}
+			tokens = append(tokens, token{tokenString, value, i})
+			i += n
+		case isDigit(c) || c == '.' || (c == '-' && i+1 < len(s) && isDigit(s[i+1])):
+			start := i
+			i++
+			for i < len(s) && (isDigit(s[i]) || s[i] == '.') {
+				i++
+			}
+			value := s[start:i]
+			// A literal with several dots is a version, not a number
+			if strings.Count(value, ".") > 1 {
+				tokens = append(tokens, token{tokenString, value, start})
+			} else {
+				tokens = append(tokens, token{tokenNumber, value, start})
+			}
+		case isIdentStart(c):
+			start := i
+			for i < len(s) && isIdentPart(s[i]) {
+				i++
+			}
+			value := s[start:i]
+			if strings.HasPrefix(value, ".") || strings.HasSuffix(value, ".") || strings.Contains(value, "..") {
+				return nil, fmt.Errorf("invalid identifier %q at position %d", value, start)
+			}
+			tokens = append(tokens, token{tokenIdent, value, start})
+		default:
+			for _, op := range operators {
+				if strings.HasPrefix(s[i:], op) {
+					tokens = append(tokens, token{tokenOperator, op, i})
+					i += len(op)
+					continue loop
+				}
+			}
+			return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+		}
+	}
+	tokens = append(tokens, token{tokenEOF, "", len(s)})
+	return tokens, nil
+}
+
+// lexString reads a quoted string and returns its unescaped value and its length in the expression
+func lexString(s string) (string, int, error) {
+	quote := s[0]
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			if i+1 >= len(s) {
+				return "", 0, fmt.Errorf("unterminated string")
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				b.WriteByte(s[i])
+			}
+		case quote:
+			return b.String(), i + 1, nil
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return "", 0, fmt.Errorf("unterminated string")
+}
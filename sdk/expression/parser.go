@@ -0,0 +1,301 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Comparison operators, the symbols and keywords are equivalent
+var comparisonOperators = map[string]string{
+	"==":         "==",
+	"!=":         "!=",
+	"<":          "<",
+	"<=":         "<=",
+	">":          ">",
+	">=":         ">=",
+	"eq":         "==",
+	"ne":         "!=",
+	"lt":         "<",
+	"le":         "<=",
+	"gt":         ">",
+	"ge":         ">=",
+	"in":         "in",
+	"contains":   "contains",
+	"startsWith": "startsWith",
+	"endsWith":   "endsWith",
+	"matches":    "matches",
+}
+
+type node interface {
+	eval(vars map[string]string) (interface{}, error)
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+type listNode struct {
+	items []node
+}
+
+// variableNode is a path in the variables, ex: git.branch or payload.commits[0].author
+type variableNode struct {
+	path []interface{}
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+type notNode struct {
+	operand node
+}
+
+type logicalNode struct {
+	operator    string
+	left, right node
+}
+
+type comparisonNode struct {
+	operator    string
+	left, right node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.typ != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(typ tokenType, value string) error {
+	t := p.next()
+	if t.typ != typ {
+		return fmt.Errorf("expected %q but got %s", value, t)
+	}
+	return nil
+}
+
+// isKeyword returns true if the token is the given operator or keyword
+func (p *parser) isKeyword(t token, values ...string) bool {
+	if t.typ != tokenOperator && t.typ != tokenIdent {
+		return false
+	}
+	for _, v := range values {
+		if t.value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// expression := or
+// or         := and (("||" | "or") and)*
+// and        := not (("&&" | "and") not)*
+// not        := ("!" | "not") not | comparison
+// comparison := operand (comparison-operator operand)?
+// operand    := literal | list | variable | function "(" args ")" | "(" expression ")"
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "||", "or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{operator: "or", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), "&&", "and") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalNode{operator: "and", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.isKeyword(p.peek(), "!", "not") {
+		p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{operand: operand}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	t := p.peek()
+	if t.typ != tokenOperator && t.typ != tokenIdent {
+		return left, nil
+	}
+	op, ok := comparisonOperators[t.value]
+	if !ok {
+		return left, nil
+	}
+	p.next()
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparisonNode{operator: op, left: left, right: right}, nil
+}
+
+func (p *parser) parseOperand() (node, error) {
+	t := p.next()
+	switch t.typ {
+	case tokenString:
+		return literalNode{value: t.value}, nil
+	case tokenNumber:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", t)
+		}
+		return literalNode{value: f}, nil
+	case tokenLeftParen:
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return n, nil
+	case tokenLeftBracket:
+		var l listNode
+		if p.peek().typ == tokenRightBracket {
+			p.next()
+			return l, nil
+		}
+		for {
+			item, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			l.items = append(l.items, item)
+			if p.peek().typ == tokenComma {
+				p.next()
+				continue
+			}
+			if err := p.expect(tokenRightBracket, "]"); err != nil {
+				return nil, err
+			}
+			return l, nil
+		}
+	case tokenIdent:
+		switch t.value {
+		case "true":
+			return literalNode{value: true}, nil
+		case "false":
+			return literalNode{value: false}, nil
+		case "null":
+			return literalNode{value: nil}, nil
+		}
+		if _, ok := comparisonOperators[t.value]; ok {
+			return nil, fmt.Errorf("unexpected operator %s", t)
+		}
+		if p.peek().typ == tokenLeftParen {
+			return p.parseCall(t)
+		}
+		return p.parseVariable(t)
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	if _, ok := functions[name.value]; !ok {
+		return nil, fmt.Errorf("unknown function %s", name)
+	}
+	p.next()
+	c := callNode{name: name.value}
+	if p.peek().typ == tokenRightParen {
+		p.next()
+		return c, nil
+	}
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		c.args = append(c.args, arg)
+		if p.peek().typ == tokenComma {
+			p.next()
+			continue
+		}
+		if err := p.expect(tokenRightParen, ")"); err != nil {
+			return nil, err
+		}
+		return c, nil
+	}
+}
+
+func (p *parser) parseVariable(ident token) (node, error) {
+	var v variableNode
+	for _, s := range strings.Split(ident.value, ".") {
+		v.path = append(v.path, s)
+	}
+	for {
+		switch p.peek().typ {
+		case tokenDot:
+			p.next()
+			t := p.next()
+			if t.typ != tokenIdent {
+				return nil, fmt.Errorf("expected a field name but got %s", t)
+			}
+			for _, s := range strings.Split(t.value, ".") {
+				v.path = append(v.path, s)
+			}
+		case tokenLeftBracket:
+			p.next()
+			t := p.next()
+			switch t.typ {
+			case tokenNumber:
+				i, err := strconv.Atoi(t.value)
+				if err != nil || i < 0 {
+					return nil, fmt.Errorf("invalid index %s", t)
+				}
+				v.path = append(v.path, i)
+			case tokenString:
+				v.path = append(v.path, t.value)
+			default:
+				return nil, fmt.Errorf("expected an index but got %s", t)
+			}
+			if err := p.expect(tokenRightBracket, "]"); err != nil {
+				return nil, err
+			}
+		default:
+			return v, nil
+		}
+	}
+}
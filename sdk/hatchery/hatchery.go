@@ -65,7 +65,7 @@ func Create(ctx context.Context, h Interface) error {
 		return fmt.Errorf("Create> Init error: %v", err)
 	}
 
-	var chanRegister, chanGetModels <-chan time.Time
+	var chanRegister, chanGetModels, chanWarmPools <-chan time.Time
 	var modelType string
 
 	hWithModels, isWithModels := h.(InterfaceWithModels)
@@ -81,6 +81,9 @@ func Create(ctx context.Context, h Interface) error {
 		// using time.Tick leaks the underlying ticker but we don't care about it because it is an endless function
 		chanRegister = time.Tick(time.Duration(h.Configuration().Provision.RegisterFrequency) * time.Second) // nolint
 		chanGetModels = time.Tick(10 * time.Second)                                                          // nolint
+		if len(h.Configuration().Provision.WarmPools) > 0 {
+			chanWarmPools = time.Tick(10 * time.Second) // nolint
+		}
 
 		modelType = hWithModels.ModelType()
 	}
@@ -199,8 +202,9 @@ func Create(ctx context.Context, h Interface) error {
 				continue
 			}
 
-			//Check if hatchery if able to start a new worker
-			if !checkCapacities(ctx, h) {
+			//Check if hatchery if able to start a new worker, if not the job can still be assigned to an idle warm worker
+			hasCapacities := checkCapacities(ctx, h)
+			if !hasCapacities && !warmWorkers.hasIdleWorkers() {
 				log.Info(ctx, "hatchery %s is not able to provision new worker", h.Service().Name)
 				endTrace("no capacities")
 				continue
@@ -261,6 +265,15 @@ func Create(ctx context.Context, h Interface) error {
 				continue
 			}
 
+			if !hasCapacities {
+				if chosenModel == nil || !warmWorkers.hasIdleWorker(chosenModel.ID) || !canUseWarmWorker(workerRequest.requirements) {
+					log.Info(ctx, "hatchery %s is not able to provision new worker", h.Service().Name)
+					endTrace("no capacities")
+					continue
+				}
+				workerRequest.onlyWarmWorker = true
+			}
+
 			if chosenModel != nil {
 				// We got a model, let's start a worker
 				workerRequest.model = chosenModel
@@ -279,6 +292,11 @@ func Create(ctx context.Context, h Interface) error {
 			if err := workerRegister(ctx, hWithModels, workersStartChan); err != nil {
 				log.Warning(ctx, "Error on workerRegister: %s", err)
 			}
+
+		case <-chanWarmPools:
+			if err := warmPoolsRefill(ctx, hWithModels, workersStartChan); err != nil {
+				log.Warning(ctx, "Error on warmPoolsRefill: %v", err)
+			}
 		}
 	}
 }
@@ -297,8 +315,8 @@ func canRunJob(ctx context.Context, h Interface, j workerStarterRequest) bool {
 		}
 
 		// Skip others requirement as we can't check it
-		if r.Type == sdk.PluginRequirement || r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement {
-			log.Debug("canRunJob> %d - job %d - job with service, plugin or memory requirement. Skip these check as we can't check it on hatchery routine", j.timestamp, j.id)
+		if r.Type == sdk.PluginRequirement || r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement || r.Type == sdk.CPURequirement {
+			log.Debug("canRunJob> %d - job %d - job with service, plugin, memory or cpu requirement. Skip these check as we can't check it on hatchery routine", j.timestamp, j.id)
 			continue
 		}
 
@@ -372,15 +390,15 @@ func canRunJobWithModel(ctx context.Context, h InterfaceWithModels, j workerStar
 			}
 		}
 
-		// service and memory requirements are only supported by docker model
-		if model.Type != sdk.Docker && (r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement) {
-			log.Debug("canRunJobWithModel> %d - job %d - job with service requirement or memory requirement: only for model docker. current model:%s", j.timestamp, j.id, model.Type)
+		// service, memory and cpu requirements are only supported by docker model
+		if model.Type != sdk.Docker && (r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement || r.Type == sdk.CPURequirement) {
+			log.Debug("canRunJobWithModel> %d - job %d - job with service, memory or cpu requirement: only for model docker. current model:%s", j.timestamp, j.id, model.Type)
 			return false
 		}
 
 		// Skip other requirement as we can't check it
-		if r.Type == sdk.PluginRequirement || r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement {
-			log.Debug("canRunJobWithModel> %d - job %d - job with service, plugin, network or memory requirement. Skip these check as we can't check it on hatchery routine", j.timestamp, j.id)
+		if r.Type == sdk.PluginRequirement || r.Type == sdk.ServiceRequirement || r.Type == sdk.MemoryRequirement || r.Type == sdk.CPURequirement {
+			log.Debug("canRunJobWithModel> %d - job %d - job with service, plugin, network, memory or cpu requirement. Skip these check as we can't check it on hatchery routine", j.timestamp, j.id)
 			continue
 		}
 
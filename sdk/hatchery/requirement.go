@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"github.com/ovh/cds/sdk"
 )
 
 var (
@@ -137,3 +139,13 @@ func ParseArgs(str string) []string {
 	}
 	return append(ret, cur.String())
 }
+
+// RequiredCPUs returns the number of CPUs of the cpu requirement, 0 if there is no cpu requirement.
+func RequiredCPUs(requirements []sdk.Requirement) (float64, error) {
+	for _, r := range requirements {
+		if r.Type == sdk.CPURequirement {
+			return sdk.ParseCPURequirement(r.Value)
+		}
+	}
+	return 0, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/ovh/cds/sdk"
 	"github.com/ovh/cds/sdk/hatchery"
 )
 
@@ -129,3 +130,19 @@ func TestParseArgs(t *testing.T) {
 		assert.Equal(t, test.expectedArgs, args, "ParseArgs("+test.in+")")
 	}
 }
+
+func TestRequiredCPUs(t *testing.T) {
+	cpus, err := hatchery.RequiredCPUs(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), cpus)
+
+	cpus, err = hatchery.RequiredCPUs([]sdk.Requirement{
+		{Type: sdk.MemoryRequirement, Value: "4096"},
+		{Type: sdk.CPURequirement, Value: "1.5"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, cpus)
+
+	_, err = hatchery.RequiredCPUs([]sdk.Requirement{{Type: sdk.CPURequirement, Value: "-1"}})
+	assert.Error(t, err)
+}
@@ -27,6 +27,9 @@ type workerStarterRequest struct {
 	timestamp           int64
 	workflowNodeRunID   int64
 	registerWorkerModel *sdk.Model
+	warmWorkerModel     *sdk.Model
+	warmWorkerName      string
+	onlyWarmWorker      bool
 }
 
 func PanicDump(h Interface) func(s string) (io.WriteCloser, error) {
@@ -58,6 +61,12 @@ func startWorkerStarters(ctx context.Context, h Interface) chan<- workerStarterR
 
 func workerStarter(ctx context.Context, h Interface, workerNum string, jobs <-chan workerStarterRequest) {
 	for j := range jobs {
+		// Start a warm worker for a pool
+		if j.warmWorkerModel != nil {
+			spawnWarmWorker(ctx, h, j)
+			continue
+		}
+
 		// Start a worker for a job
 		if m := j.registerWorkerModel; m == nil {
 			_ = spawnWorkerForJob(ctx, h, j)
@@ -114,11 +123,28 @@ func spawnWorkerForJob(ctx context.Context, h Interface, j workerStarterRequest)
 		observability.TagServiceName, h.Name(),
 		observability.TagServiceType, h.Type(),
 	)
-	observability.Record(ctxJob, GetMetrics().SpawnedWorkers, 1)
 
 	log.Debug("hatchery> spawnWorkerForJob> %d", j.id)
 	defer log.Debug("hatchery> spawnWorkerForJob> %d (%.3f seconds elapsed)", j.id, time.Since(time.Unix(j.timestamp, 0)).Seconds())
 
+	// Assign the job to an idle warm worker if there is one, the job is booked by the API
+	if h.Service() != nil {
+		if workerName, ok := assignWarmWorker(ctxJob, h, j); ok {
+			log.Info(ctx, "hatchery> spawnWorkerForJob> job %d assigned to warm worker %s", j.id, workerName)
+			SendSpawnInfo(ctxJob, h, j.id, sdk.SpawnMsg{
+				ID:   sdk.MsgSpawnInfoHatcheryWarmWorkerAssigned.ID,
+				Args: []interface{}{h.Service().Name, workerName},
+			})
+			return true
+		}
+	}
+	if j.onlyWarmWorker {
+		log.Debug("hatchery> spawnWorkerForJob> no warm worker available for job %d", j.id)
+		return false
+	}
+
+	observability.Record(ctxJob, GetMetrics().SpawnedWorkers, 1)
+
 	maxProv := h.Configuration().Provision.MaxConcurrentProvisioning
 	if maxProv < 1 {
 		maxProv = defaultMaxProvisioning
@@ -26,6 +26,7 @@ type SpawnArguments struct {
 	JobID        int64             `json:"job_id"`
 	Requirements []sdk.Requirement `json:"requirements"`
 	RegisterOnly bool              `json:"register_only"`
+	Warm         bool              `json:"warm"`
 	HatcheryName string            `json:"hatchery_name"`
 }
 
@@ -0,0 +1,283 @@
+package hatchery
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ovh/cds/sdk"
+	"github.com/ovh/cds/sdk/log"
+)
+
+// warmWorkerRegisterTimeout is the delay after which a started warm worker that is not registered
+// is not considered as part of its pool anymore.
+const warmWorkerRegisterTimeout = 10 * time.Minute
+
+// warmWorkers contains the warm workers of the hatchery.
+var warmWorkers = newWarmPool()
+
+type warmWorkerStarting struct {
+	modelID int64
+	since   time.Time
+}
+
+// warmPool keeps track of the warm workers of an hatchery: the idle warm workers registered
+// on CDS API and the warm workers started but not registered yet.
+type warmPool struct {
+	mutex    sync.Mutex
+	idle     map[int64][]sdk.Worker
+	starting map[string]warmWorkerStarting
+}
+
+func newWarmPool() *warmPool {
+	return &warmPool{
+		idle:     make(map[int64][]sdk.Worker),
+		starting: make(map[string]warmWorkerStarting),
+	}
+}
+
+// update refreshes the pool from the workers of the hatchery known by CDS API.
+func (p *warmPool) update(workers []sdk.Worker, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.idle = make(map[int64][]sdk.Worker)
+	for _, w := range workers {
+		// Workers started but not registered yet have no ID
+		if w.ID != "" {
+			delete(p.starting, w.Name)
+		}
+		if w.Warm && w.ModelID != nil && w.JobRunID == nil && w.Status == sdk.StatusWaiting {
+			p.idle[*w.ModelID] = append(p.idle[*w.ModelID], w)
+		}
+	}
+	for name, s := range p.starting {
+		if now.Sub(s.since) > warmWorkerRegisterTimeout {
+			delete(p.starting, name)
+		}
+	}
+}
+
+// count returns the number of idle and starting warm workers for given model.
+func (p *warmPool) count(modelID int64) int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	n := len(p.idle[modelID])
+	for _, s := range p.starting {
+		if s.modelID == modelID {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *warmPool) hasIdleWorkers() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for _, ws := range p.idle {
+		if len(ws) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *warmPool) hasIdleWorker(modelID int64) bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return len(p.idle[modelID]) > 0
+}
+
+// pop removes an idle worker of given model from the pool.
+func (p *warmPool) pop(modelID int64) (sdk.Worker, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ws := p.idle[modelID]
+	if len(ws) == 0 {
+		return sdk.Worker{}, false
+	}
+	p.idle[modelID] = ws[1:]
+	return ws[0], true
+}
+
+func (p *warmPool) addStarting(workerName string, modelID int64, now time.Time) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.starting[workerName] = warmWorkerStarting{modelID: modelID, since: now}
+}
+
+func (p *warmPool) removeStarting(workerName string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	delete(p.starting, workerName)
+}
+
+// canUseWarmWorker returns false if the job has requirements that have to be given to the hatchery
+// when the worker is spawned.
+func canUseWarmWorker(requirements []sdk.Requirement) bool {
+	for _, r := range requirements {
+		switch r.Type {
+		case sdk.ServiceRequirement, sdk.MemoryRequirement, sdk.CPURequirement:
+			return false
+		case sdk.ModelRequirement:
+			// The model requirement can contain options for the spawn, ex: myModel --port=8888:9999
+			if len(strings.Split(r.Value, " ")) > 1 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// assignWarmWorker assigns the job to an idle warm worker of the job's model. It returns the name of
+// the worker, or false if there is no idle warm worker available.
+func assignWarmWorker(ctx context.Context, h Interface, j workerStarterRequest) (string, bool) {
+	if j.model == nil || !canUseWarmWorker(j.requirements) {
+		return "", false
+	}
+	for {
+		w, ok := warmWorkers.pop(j.model.ID)
+		if !ok {
+			return "", false
+		}
+		ctxAssign, cancel := context.WithTimeout(ctx, 10*time.Second)
+		err := h.CDSClient().WorkerAssignJob(ctxAssign, w.ID, j.id)
+		cancel()
+		if err == nil {
+			return w.Name, true
+		}
+		if sdk.ErrorIs(err, sdk.ErrWorkerNotAvailable) {
+			log.Debug("hatchery> assignWarmWorker> worker %s is not available anymore: %v", w.Name, err)
+			continue
+		}
+		// perhaps the job is already booked by another hatchery
+		log.Info(ctx, "hatchery> assignWarmWorker> cannot assign job %d to worker %s: %v", j.id, w.Name, err)
+		return "", false
+	}
+}
+
+// warmPoolsRefill starts the missing warm workers of the pools of the hatchery, and disables the idle
+// warm workers over the size of the pools.
+func warmPoolsRefill(ctx context.Context, h InterfaceWithModels, startWorkerChan chan<- workerStarterRequest) error {
+	pools := h.Configuration().Provision.WarmPools
+	if len(pools) == 0 {
+		return nil
+	}
+
+	workers, err := WorkerPool(ctx, h)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	warmWorkers.update(workers, now)
+
+	var nbWorkers int
+	for _, w := range workers {
+		if w.Status != sdk.StatusDisabled {
+			nbWorkers++
+		}
+	}
+	available := h.Configuration().Provision.MaxWorker - nbWorkers - int(atomic.LoadInt64(&nbWorkerToStart))
+
+	for _, p := range pools {
+		var model *sdk.Model
+		for i := range models {
+			if models[i].Group != nil && models[i].Path() == p.Model {
+				model = &models[i]
+				break
+			}
+		}
+		if model == nil {
+			log.Debug("hatchery> warmPoolsRefill> model %s not found", p.Model)
+			continue
+		}
+		if model.Type != h.ModelType() || h.NeedRegistration(ctx, model) || model.NbSpawnErr > 5 {
+			log.Debug("hatchery> warmPoolsRefill> cannot start warm workers for model %s", p.Model)
+			continue
+		}
+
+		size := p.SizeAt(now)
+		current := warmWorkers.count(model.ID)
+
+		// Too many warm workers, ex: the size of the pool is lower for this time of day
+		for ; current > size; current-- {
+			w, ok := warmWorkers.pop(model.ID)
+			if !ok {
+				break // the other warm workers are not registered yet
+			}
+			log.Info(ctx, "hatchery> warmPoolsRefill> disabling warm worker %s for model %s", w.Name, p.Model)
+			if err := h.CDSClient().WorkerDisable(ctx, w.ID); err != nil {
+				log.Error(ctx, "hatchery> warmPoolsRefill> unable to disable worker %s: %v", w.Name, err)
+			}
+		}
+
+		for ; current < size && available > 0; current++ {
+			m := *model
+			if err := ModelInterpolateSecrets(h, &m); err != nil {
+				log.Error(ctx, "hatchery> warmPoolsRefill> cannot interpolate secrets for model %s: %v", p.Model, err)
+				break
+			}
+			workerName := generateWorkerName(h.Service().Name, false, p.Model)
+			warmWorkers.addStarting(workerName, m.ID, now)
+			available--
+			startWorkerChan <- workerStarterRequest{
+				warmWorkerModel: &m,
+				warmWorkerName:  workerName,
+			}
+		}
+		if current < size {
+			log.Info(ctx, "hatchery> warmPoolsRefill> %s has reached the max worker, %d warm workers missing for model %s", h.Service().Name, size-current, p.Model)
+		}
+	}
+	return nil
+}
+
+// spawnWarmWorker starts a worker without a job, registered on CDS API as a warm worker.
+func spawnWarmWorker(ctx context.Context, h Interface, j workerStarterRequest) {
+	m := j.warmWorkerModel
+	maxProv := h.Configuration().Provision.MaxConcurrentProvisioning
+	if maxProv < 1 {
+		maxProv = defaultMaxProvisioning
+	}
+	if atomic.LoadInt64(&nbWorkerToStart) >= int64(maxProv) {
+		log.Debug("hatchery> spawnWarmWorker> max concurrent provisioning reached")
+		warmWorkers.removeStarting(j.warmWorkerName)
+		return
+	}
+
+	atomic.AddInt64(&nbWorkerToStart, 1)
+	defer func(i *int64) {
+		atomic.AddInt64(i, -1)
+	}(&nbWorkerToStart)
+
+	arg := SpawnArguments{
+		WorkerName:   j.warmWorkerName,
+		Model:        m,
+		Warm:         true,
+		HatcheryName: h.Service().Name,
+	}
+
+	log.Info(ctx, "hatchery> spawnWarmWorker> starting warm worker %s for model %s", arg.WorkerName, arg.ModelName())
+
+	// Get a JWT to authentified the worker
+	jwt, err := NewWorkerToken(h.Service().Name, h.GetPrivateKey(), time.Now().Add(1*time.Hour), arg)
+	if err != nil {
+		log.Error(ctx, "hatchery> spawnWarmWorker> cannot create token for worker %s: %v", arg.WorkerName, err)
+		warmWorkers.removeStarting(arg.WorkerName)
+		return
+	}
+	arg.WorkerToken = jwt
+
+	if err := h.SpawnWorker(ctx, arg); err != nil {
+		log.Warning(ctx, "hatchery> spawnWarmWorker> cannot spawn warm worker %s for model %s: %v", arg.WorkerName, arg.ModelName(), err)
+		warmWorkers.removeStarting(arg.WorkerName)
+	}
+}
@@ -0,0 +1,100 @@
+package hatchery
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/engine/service"
+	"github.com/ovh/cds/sdk"
+)
+
+func TestWarmPool(t *testing.T) {
+	now := time.Now()
+	modelID, otherModelID := int64(1), int64(2)
+	jobID := int64(42)
+
+	p := newWarmPool()
+	p.addStarting("worker-starting", modelID, now)
+	p.addStarting("worker-registered", modelID, now)
+	p.addStarting("worker-expired", otherModelID, now.Add(-2*warmWorkerRegisterTimeout))
+
+	p.update([]sdk.Worker{
+		{ID: "1", Name: "worker-registered", ModelID: &modelID, Warm: true, Status: sdk.StatusWaiting},
+		{ID: "2", Name: "worker-idle", ModelID: &modelID, Warm: true, Status: sdk.StatusWaiting},
+		{ID: "3", Name: "worker-busy", ModelID: &modelID, Warm: true, Status: sdk.StatusBuilding, JobRunID: &jobID},
+		{ID: "4", Name: "worker-not-warm", ModelID: &modelID, Status: sdk.StatusWaiting},
+		{Name: "worker-starting", ModelID: &modelID, Status: sdk.StatusWorkerPending},
+	}, now)
+
+	assert.Equal(t, 3, p.count(modelID))
+	assert.Equal(t, 0, p.count(otherModelID))
+	assert.True(t, p.hasIdleWorkers())
+	assert.True(t, p.hasIdleWorker(modelID))
+	assert.False(t, p.hasIdleWorker(otherModelID))
+
+	w, ok := p.pop(modelID)
+	require.True(t, ok)
+	assert.Equal(t, "worker-registered", w.Name)
+	w, ok = p.pop(modelID)
+	require.True(t, ok)
+	assert.Equal(t, "worker-idle", w.Name)
+	_, ok = p.pop(modelID)
+	assert.False(t, ok)
+	assert.False(t, p.hasIdleWorkers())
+
+	p.removeStarting("worker-starting")
+	assert.Equal(t, 0, p.count(modelID))
+}
+
+func TestCanUseWarmWorker(t *testing.T) {
+	assert.True(t, canUseWarmWorker(nil))
+	assert.True(t, canUseWarmWorker([]sdk.Requirement{
+		{Type: sdk.BinaryRequirement, Value: "git"},
+		{Type: sdk.ModelRequirement, Value: "shared.infra/go-official"},
+	}))
+	assert.False(t, canUseWarmWorker([]sdk.Requirement{
+		{Type: sdk.ModelRequirement, Value: "shared.infra/go-official --port=8888:9999"},
+	}))
+	assert.False(t, canUseWarmWorker([]sdk.Requirement{
+		{Type: sdk.ServiceRequirement, Name: "pg", Value: "postgres:9.5.3"},
+	}))
+	assert.False(t, canUseWarmWorker([]sdk.Requirement{
+		{Type: sdk.MemoryRequirement, Value: "4096"},
+	}))
+	assert.False(t, canUseWarmWorker([]sdk.Requirement{
+		{Type: sdk.CPURequirement, Value: "2"},
+	}))
+}
+
+func TestWarmPoolConfigurationSizeAt(t *testing.T) {
+	cfg := service.WarmPoolConfiguration{
+		Model: "shared.infra/go-official",
+		Size:  1,
+		Schedules: []service.WarmPoolScheduleConfiguration{
+			{From: "08:00", To: "19:00", Size: 5},
+			{From: "22:00", To: "02:00", Size: 0},
+		},
+	}
+	require.NoError(t, cfg.Check())
+
+	at := func(hour, min int) time.Time {
+		return time.Date(2020, 1, 1, hour, min, 0, 0, time.Local)
+	}
+	assert.Equal(t, 1, cfg.SizeAt(at(7, 59)))
+	assert.Equal(t, 5, cfg.SizeAt(at(8, 0)))
+	assert.Equal(t, 5, cfg.SizeAt(at(18, 59)))
+	assert.Equal(t, 1, cfg.SizeAt(at(19, 0)))
+	assert.Equal(t, 0, cfg.SizeAt(at(23, 30)))
+	assert.Equal(t, 0, cfg.SizeAt(at(1, 0)))
+	assert.Equal(t, 1, cfg.SizeAt(at(2, 0)))
+
+	assert.Error(t, service.WarmPoolConfiguration{Model: "go-official", Size: 1}.Check())
+	assert.Error(t, service.WarmPoolConfiguration{Model: "shared.infra/go-official", Size: -1}.Check())
+	assert.Error(t, service.WarmPoolConfiguration{
+		Model:     "shared.infra/go-official",
+		Schedules: []service.WarmPoolScheduleConfiguration{{From: "8h", To: "19:00", Size: 1}},
+	}.Check())
+}
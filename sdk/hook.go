@@ -10,11 +10,14 @@ const (
 	KafkaHookModelName            = "Kafka hook"
 	RabbitMQHookModelName         = "RabbitMQ hook"
 	WorkflowModelName             = "Workflow"
+	SubWorkflowModelName          = "SubWorkflow"
 	HookConfigProject             = "project"
 	HookConfigWorkflow            = "workflow"
 	HookConfigTargetProject       = "target_project"
 	HookConfigTargetWorkflow      = "target_workflow"
 	HookConfigTargetHook          = "target_hook"
+	HookConfigOutputs             = "outputs"
+	HookConfigArtifacts           = "artifacts"
 	HookConfigWorkflowID          = "workflow_id"
 	HookConfigWebHookID           = "webHookID"
 	HookConfigVCSServer           = "vcsServer"
@@ -56,6 +59,7 @@ var (
 	BuiltinOutgoingHookModels = []*WorkflowHookModel{
 		&OutgoingWebHookModel,
 		&OutgoingWorkflowModel,
+		&OutgoingSubWorkflowModel,
 	}
 
 	KafkaHookModel = WorkflowHookModel{
@@ -266,6 +270,46 @@ var (
 			},
 		},
 	}
+
+	// OutgoingSubWorkflowModel starts a workflow run and waits for it, the node run takes the status of the child workflow run.
+	// The child build parameters matching the outputs patterns and the artifacts matching the artifacts patterns
+	// are passed back to the next nodes.
+	OutgoingSubWorkflowModel = WorkflowHookModel{
+		Author:     "CDS",
+		Type:       WorkflowHookModelBuiltin,
+		Identifier: "github.com/ovh/cds/hook/builtin/subworkflow",
+		Name:       SubWorkflowModelName,
+		Icon:       "sitemap",
+		DefaultConfig: WorkflowNodeHookConfig{
+			HookConfigTargetProject: {
+				Configurable: true,
+				Type:         HookConfigTypeProject,
+			},
+			HookConfigTargetWorkflow: {
+				Configurable: true,
+				Type:         HookConfigTypeWorkflow,
+			},
+			HookConfigTargetHook: {
+				Configurable: true,
+				Type:         HookConfigTypeHook,
+			},
+			Payload: {
+				Value:        "{}",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			HookConfigOutputs: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+			HookConfigArtifacts: {
+				Value:        "",
+				Configurable: true,
+				Type:         HookConfigTypeString,
+			},
+		},
+	}
 )
 
 // GetDefaultHookModel return the workflow hook model by its name
@@ -1,5 +1,16 @@
 package sdk
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Job is the element of a stage
 type Job struct {
 	PipelineActionID int64                  `json:"pipeline_action_id"`
@@ -18,3 +29,302 @@ func (j Job) IsValid() error {
 
 	return j.Action.IsValid()
 }
+
+// Timeout returns the maximum execution duration of the job, 0 means no timeout.
+func (j Job) Timeout() time.Duration {
+	return time.Duration(j.Action.Timeout) * time.Second
+}
+
+// Job priority bounds, jobs with a higher priority are taken first in the queue.
+const (
+	PriorityMin = -100
+	PriorityMax = 100
+)
+
+// CheckPriority returns an error if the given job or workflow priority is out of bounds.
+func CheckPriority(priority int) error {
+	if priority < PriorityMin || priority > PriorityMax {
+		return NewErrorFrom(ErrWrongRequest, "invalid priority %d, should be between %d and %d", priority, PriorityMin, PriorityMax)
+	}
+	return nil
+}
+
+// Job retry conditions
+const (
+	JobRetryOnWorkerLost = "worker_lost"
+	JobRetryOnSpawnError = "spawn_error"
+	JobRetryOnFailure    = "failure"
+)
+
+// JobRetryConditions contains all the conditions that can be used in a job retry policy.
+var JobRetryConditions = []string{JobRetryOnWorkerLost, JobRetryOnSpawnError, JobRetryOnFailure}
+
+// JobRetry is the retry policy of a job, a job run is requeued until it reaches its max attempts
+// if it failed because of one of the given conditions.
+type JobRetry struct {
+	MaxAttempts int      `json:"max_attempts"`
+	On          []string `json:"on"`
+}
+
+// IsValid returns an error if the retry policy is not valid.
+func (r JobRetry) IsValid() error {
+	if r.MaxAttempts < 1 {
+		return NewErrorFrom(ErrWrongRequest, "invalid max attempts for job retry")
+	}
+	if len(r.On) == 0 {
+		return NewErrorFrom(ErrWrongRequest, "missing conditions for job retry")
+	}
+	for _, c := range r.On {
+		if !IsInArray(c, JobRetryConditions) {
+			return NewErrorFrom(ErrWrongRequest, "invalid job retry condition %q, should be one of %s", c, strings.Join(JobRetryConditions, ", "))
+		}
+	}
+	return nil
+}
+
+// Match returns true if the given condition is part of the retry policy.
+func (r JobRetry) Match(condition string) bool {
+	return IsInArray(condition, r.On)
+}
+
+// Value returns driver.Value from job retry.
+func (r JobRetry) Value() (driver.Value, error) {
+	j, err := json.Marshal(r)
+	return j, WrapError(err, "cannot marshal JobRetry")
+}
+
+// Scan job retry.
+func (r *JobRetry) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, r), "cannot unmarshal JobRetry")
+}
+
+// JobMatrixMaxCombinations is the maximum number of job runs that can be created from a job matrix.
+const JobMatrixMaxCombinations = 256
+
+var jobMatrixVariableRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// JobMatrix contains for each matrix variable the list of its values, the job will be run
+// for each combination of values.
+type JobMatrix map[string][]string
+
+// IsValid returns an error if the matrix is not valid.
+func (m JobMatrix) IsValid() error {
+	for k, vs := range m {
+		if !jobMatrixVariableRegexp.MatchString(k) {
+			return NewErrorFrom(ErrWrongRequest, "invalid matrix variable name %q", k)
+		}
+		if len(vs) == 0 {
+			return NewErrorFrom(ErrWrongRequest, "missing values for matrix variable %s", k)
+		}
+	}
+	if m.Len() > JobMatrixMaxCombinations {
+		return NewErrorFrom(ErrWrongRequest, "too many matrix combinations, maximum is %d", JobMatrixMaxCombinations)
+	}
+	return nil
+}
+
+// Len returns the number of combinations of the matrix. Counting stops as soon as it exceeds
+// JobMatrixMaxCombinations, so the returned value is only exact up to this maximum.
+func (m JobMatrix) Len() int {
+	if len(m) == 0 {
+		return 0
+	}
+	n := 1
+	for _, vs := range m {
+		n *= len(vs)
+		if n > JobMatrixMaxCombinations {
+			return n
+		}
+	}
+	return n
+}
+
+// Combinations returns all the combinations of the matrix values, sorted by variable names.
+func (m JobMatrix) Combinations() []JobMatrixCombination {
+	if len(m) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	combinations := []JobMatrixCombination{{}}
+	for _, k := range keys {
+		next := make([]JobMatrixCombination, 0, len(combinations)*len(m[k]))
+		for _, c := range combinations {
+			for _, v := range m[k] {
+				nc := make(JobMatrixCombination, len(c)+1)
+				for ck, cv := range c {
+					nc[ck] = cv
+				}
+				nc[k] = v
+				next = append(next, nc)
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// Value returns driver.Value from job matrix.
+func (m JobMatrix) Value() (driver.Value, error) {
+	j, err := json.Marshal(m)
+	return j, WrapError(err, "cannot marshal JobMatrix")
+}
+
+// Scan job matrix.
+func (m *JobMatrix) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, m), "cannot unmarshal JobMatrix")
+}
+
+// JobMatrixCombination contains a value for each matrix variable of a job.
+type JobMatrixCombination map[string]string
+
+// Parameters returns the combination values as cds.matrix.* parameters.
+func (c JobMatrixCombination) Parameters() []Parameter {
+	var params []Parameter
+	for _, k := range c.keys() {
+		AddParameter(&params, "cds.matrix."+k, StringParameter, c[k])
+	}
+	return params
+}
+
+// String returns a readable representation of the combination (ex: go=1.13, os=linux/amd64).
+func (c JobMatrixCombination) String() string {
+	values := make([]string, 0, len(c))
+	for _, k := range c.keys() {
+		values = append(values, k+"="+c[k])
+	}
+	return strings.Join(values, ", ")
+}
+
+func (c JobMatrixCombination) keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Job output types
+const (
+	JobOutputTypeString  = "string"
+	JobOutputTypeNumber  = "number"
+	JobOutputTypeBoolean = "boolean"
+	JobOutputTypeJSON    = "json"
+)
+
+// JobOutputTypes contains all the available job output types.
+var JobOutputTypes = []string{JobOutputTypeString, JobOutputTypeNumber, JobOutputTypeBoolean, JobOutputTypeJSON}
+
+// JobOutput is an output declared by a job, its value is set by a step of the job
+// and can be used by the next jobs and workflow nodes.
+type JobOutput struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+}
+
+// IsValid returns an error if the job output is not valid.
+func (o JobOutput) IsValid() error {
+	if !jobMatrixVariableRegexp.MatchString(o.Name) {
+		return NewErrorFrom(ErrWrongRequest, "invalid output name %q", o.Name)
+	}
+	if !IsInArray(o.Type, JobOutputTypes) {
+		return NewErrorFrom(ErrWrongRequest, "invalid type %q for output %s, should be one of %s", o.Type, o.Name, strings.Join(JobOutputTypes, ", "))
+	}
+	return nil
+}
+
+// CheckValue returns an error if the given value does not match the output type.
+func (o JobOutput) CheckValue(value string) error {
+	var valid bool
+	switch o.Type {
+	case JobOutputTypeNumber:
+		_, err := strconv.ParseFloat(value, 64)
+		valid = err == nil
+	case JobOutputTypeBoolean:
+		_, err := strconv.ParseBool(value)
+		valid = err == nil
+	case JobOutputTypeJSON:
+		valid = json.Valid([]byte(value))
+	default:
+		valid = true
+	}
+	if !valid {
+		return NewErrorFrom(ErrWrongRequest, "invalid value %q for output %s of type %s", value, o.Name, o.Type)
+	}
+	return nil
+}
+
+// JobOutputs is a list of job outputs.
+type JobOutputs []JobOutput
+
+// IsValid returns an error if one of the outputs is not valid or if an output is declared twice.
+func (outs JobOutputs) IsValid() error {
+	for i := range outs {
+		if err := outs[i].IsValid(); err != nil {
+			return err
+		}
+		for j := range outs[:i] {
+			if outs[j].Name == outs[i].Name {
+				return NewErrorFrom(ErrWrongRequest, "output %s is declared twice", outs[i].Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Find returns the output with given name.
+func (outs JobOutputs) Find(name string) *JobOutput {
+	for i := range outs {
+		if outs[i].Name == name {
+			return &outs[i]
+		}
+	}
+	return nil
+}
+
+// Value returns driver.Value from job outputs.
+func (outs JobOutputs) Value() (driver.Value, error) {
+	j, err := json.Marshal(outs)
+	return j, WrapError(err, "cannot marshal JobOutputs")
+}
+
+// Scan job outputs.
+func (outs *JobOutputs) Scan(src interface{}) error {
+	if src == nil {
+		return nil
+	}
+	source, ok := src.([]byte)
+	if !ok {
+		return WithStack(fmt.Errorf("type assertion .([]byte) failed (%T)", src))
+	}
+	return WrapError(json.Unmarshal(source, outs), "cannot unmarshal JobOutputs")
+}
+
+var jobOutputInvalidCharsRegexp = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// JobOutputParameterName returns the name of the parameter that contains the value of a job output (ex: cds.outputs.build.version).
+// Characters of the job name that can't be used in a variable name are replaced by '_'.
+func JobOutputParameterName(jobName, outputName string) string {
+	return "cds.outputs." + jobOutputInvalidCharsRegexp.ReplaceAllString(jobName, "_") + "." + outputName
+}
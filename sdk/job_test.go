@@ -0,0 +1,61 @@
+package sdk_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestJobMatrixCombinations(t *testing.T) {
+	m := sdk.JobMatrix{
+		"os": {"linux/amd64", "linux/arm64"},
+		"go": {"1.13", "1.14"},
+	}
+	require.NoError(t, m.IsValid())
+	assert.Equal(t, 4, m.Len())
+
+	cs := m.Combinations()
+	require.Len(t, cs, 4)
+	assert.Equal(t, "go=1.13, os=linux/amd64", cs[0].String())
+	assert.Equal(t, "go=1.13, os=linux/arm64", cs[1].String())
+	assert.Equal(t, "go=1.14, os=linux/amd64", cs[2].String())
+	assert.Equal(t, "go=1.14, os=linux/arm64", cs[3].String())
+
+	params := cs[3].Parameters()
+	require.Len(t, params, 2)
+	assert.Equal(t, "cds.matrix.go", params[0].Name)
+	assert.Equal(t, "1.14", params[0].Value)
+	assert.Equal(t, "cds.matrix.os", params[1].Name)
+	assert.Equal(t, "linux/arm64", params[1].Value)
+
+	assert.Nil(t, sdk.JobMatrix{}.Combinations())
+	assert.Error(t, sdk.JobMatrix{"go": {}}.IsValid())
+	assert.Error(t, sdk.JobMatrix{"go.version": {"1.13"}}.IsValid())
+
+	// the number of combinations of a huge matrix should not overflow
+	values := make([]string, 1<<16)
+	huge := sdk.JobMatrix{}
+	for i := 0; i < 8; i++ {
+		huge[fmt.Sprintf("v%d", i)] = values
+	}
+	assert.True(t, huge.Len() > sdk.JobMatrixMaxCombinations)
+	assert.Error(t, huge.IsValid())
+	assert.NoError(t, sdk.JobMatrix{"a": make([]string, 16), "b": make([]string, 16)}.IsValid())
+	assert.Error(t, sdk.JobMatrix{"a": make([]string, 16), "b": make([]string, 17)}.IsValid())
+}
+
+func TestJobOutputCheckValue(t *testing.T) {
+	assert.NoError(t, sdk.JobOutput{Name: "n", Type: sdk.JobOutputTypeNumber}.CheckValue("87.5"))
+	assert.Error(t, sdk.JobOutput{Name: "n", Type: sdk.JobOutputTypeNumber}.CheckValue("abc"))
+	assert.NoError(t, sdk.JobOutput{Name: "b", Type: sdk.JobOutputTypeBoolean}.CheckValue("true"))
+	assert.Error(t, sdk.JobOutput{Name: "b", Type: sdk.JobOutputTypeBoolean}.CheckValue("yes"))
+	assert.NoError(t, sdk.JobOutput{Name: "j", Type: sdk.JobOutputTypeJSON}.CheckValue(`{"a": 1}`))
+	assert.Error(t, sdk.JobOutput{Name: "j", Type: sdk.JobOutputTypeJSON}.CheckValue(`{a}`))
+
+	assert.Error(t, sdk.JobOutputs{{Name: "a", Type: sdk.JobOutputTypeString}, {Name: "a", Type: sdk.JobOutputTypeString}}.IsValid())
+	assert.Equal(t, "cds.outputs.Build_linux.version", sdk.JobOutputParameterName("Build linux", "version"))
+}
@@ -57,6 +57,7 @@ var (
 	MsgSpawnInfoHatcheryStarts              = &Message{"MsgSpawnInfoHatcheryStarts", trad{FR: "La Hatchery %s a démarré le lancement du worker avec le modèle %s", EN: "Hatchery %s starts spawn worker with model %s"}, nil, RunInfoTypInfo}
 	MsgSpawnInfoHatcheryErrorSpawn          = &Message{"MsgSpawnInfoHatcheryErrorSpawn", trad{FR: "Une erreur est survenue lorsque la Hatchery %s a démarré un worker avec le modèle %s après %s, err:%s", EN: "Error while Hatchery %s spawn worker with model %s after %s, err:%s"}, nil, RunInfoTypeError}
 	MsgSpawnInfoHatcheryStartsSuccessfully  = &Message{"MsgSpawnInfoHatcheryStartsSuccessfully", trad{FR: "La Hatchery %s a démarré le worker %s avec succès en %s", EN: "Hatchery %s spawn worker %s successfully in %s"}, nil, RunInfoTypInfo}
+	MsgSpawnInfoHatcheryWarmWorkerAssigned  = &Message{"MsgSpawnInfoHatcheryWarmWorkerAssigned", trad{FR: "La Hatchery %s a attribué le job au worker démarré %s", EN: "Hatchery %s assigned the job to the warm worker %s"}, nil, RunInfoTypInfo}
 	MsgSpawnInfoHatcheryStartDockerPull     = &Message{"MsgSpawnInfoHatcheryStartDockerPull", trad{FR: "La Hatchery %s a démarré le docker pull de l'image %s...", EN: "Hatchery %s starts docker pull %s..."}, nil, RunInfoTypInfo}
 	MsgSpawnInfoHatcheryEndDockerPull       = &Message{"MsgSpawnInfoHatcheryEndDockerPull", trad{FR: "La Hatchery %s a terminé le docker pull de l'image %s", EN: "Hatchery %s docker pull %s done"}, nil, RunInfoTypInfo}
 	MsgSpawnInfoHatcheryEndDockerPullErr    = &Message{"MsgSpawnInfoHatcheryEndDockerPullErr", trad{FR: "⚠ La Hatchery %s a terminé le docker pull de l'image %s en erreur: %s", EN: "⚠ Hatchery %s - docker pull %s done with error: %v"}, nil, RunInfoTypeError}
@@ -68,16 +69,35 @@ var (
 	MsgSpawnInfoWorkerForJob                = &Message{"MsgSpawnInfoWorkerForJob", trad{FR: "Ce worker %s a été créé pour lancer ce job", EN: "This worker %s was created to take this action"}, nil, RunInfoTypInfo}
 	MsgSpawnInfoWorkerForJobError           = &Message{"MsgSpawnInfoWorkerForJobError", trad{FR: "⚠ Ce worker %s a été créé pour lancer ce job, mais ne possède pas tous les pré-requis. Vérifiez que les prérequis suivants:%s", EN: "⚠ This worker %s was created to take this action, but does not have all prerequisites. Please verify the following prerequisites:%s"}, nil, RunInfoTypeError}
 	MsgSpawnInfoJobError                    = &Message{"MsgSpawnInfoJobError", trad{FR: "⚠ Impossible de lancer ce job : %s", EN: "⚠ Unable to run this job: %s"}, nil, RunInfoTypInfo}
+	MsgSpawnInfoJobTimeout                  = &Message{"MsgSpawnInfoJobTimeout", trad{FR: "⚠ Le job est en échec car il a dépassé son délai d'exécution de %s", EN: "⚠ Job has failed because it exceeded its timeout of %s"}, nil, RunInfoTypeError}
+	MsgSpawnInfoJobRetry                    = &Message{"MsgSpawnInfoJobRetry", trad{FR: "⚠ L'essai %d/%d du job a échoué (%s), le job a été replacé dans la file d'attente", EN: "⚠ Attempt %d/%d of the job failed (%s), job has been replaced in queue"}, nil, RunInfoTypeWarning}
+	MsgSpawnInfoJobMissingOutputs           = &Message{"MsgSpawnInfoJobMissingOutputs", trad{FR: "⚠ Le job n'a pas défini les outputs déclarés suivants : %s", EN: "⚠ Job did not set the following declared outputs: %s"}, nil, RunInfoTypeError}
+	MsgSpawnInfoJobInvalidOutput            = &Message{"MsgSpawnInfoJobInvalidOutput", trad{FR: "⚠ Le job a défini un output invalide : %s", EN: "⚠ Job set an invalid output: %s"}, nil, RunInfoTypeError}
+	MsgSpawnInfoJobDynamicStages            = &Message{"MsgSpawnInfoJobDynamicStages", trad{FR: "Le job a ajouté les stages suivants au pipeline : %s", EN: "Job added the following stages to the pipeline: %s"}, nil, RunInfoTypInfo}
+	MsgSpawnInfoJobDynamicStagesError       = &Message{"MsgSpawnInfoJobDynamicStagesError", trad{FR: "⚠ Le pipeline généré par le job est invalide : %s", EN: "⚠ Pipeline generated by the job is invalid: %s"}, nil, RunInfoTypeError}
+	MsgSpawnInfoJobQuotaExceeded            = &Message{"MsgSpawnInfoJobQuotaExceeded", trad{FR: "⚠ Le job reste en attente, quota dépassé : %s", EN: "⚠ Job is kept waiting, quota exceeded: %s"}, nil, RunInfoTypeWarning}
 	MsgWorkflowStarting                     = &Message{"MsgWorkflowStarting", trad{FR: "Le workflow %s#%s a été démarré", EN: "Workflow %s#%s has been started"}, nil, RunInfoTypInfo}
 	MsgWorkflowError                        = &Message{"MsgWorkflowError", trad{FR: "⚠ Une erreur est survenue: %v", EN: "⚠ An error has occurred: %v"}, nil, RunInfoTypeError}
 	MsgWorkflowConditionError               = &Message{"MsgWorkflowConditionError", trad{FR: "Les conditions de lancement ne sont pas respectées.", EN: "Run conditions aren't ok."}, nil, RunInfoTypInfo}
 	MsgWorkflowNodeStop                     = &Message{"MsgWorkflowNodeStop", trad{FR: "Le pipeline a été arrété par %s", EN: "The pipeline has been stopped by %s"}, nil, RunInfoTypInfo}
 	MsgWorkflowNodeMutex                    = &Message{"MsgWorkflowNodeMutex", trad{FR: "Le pipeline %s est mis en attente tant qu'il est en cours sur un autre run", EN: "The pipeline %s is waiting while it's running on another run"}, nil, RunInfoTypInfo}
 	MsgWorkflowNodeMutexRelease             = &Message{"MsgWorkflowNodeMutexRelease", trad{FR: "Lancement du pipeline %s", EN: "Triggering pipeline %s"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeConcurrency              = &Message{"MsgWorkflowNodeConcurrency", trad{FR: "Le pipeline %s est mis en attente tant qu'un autre run est en cours dans le groupe de concurrence %s", EN: "The pipeline %s is waiting while another run is in progress in concurrency group %s"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeConcurrencyRelease       = &Message{"MsgWorkflowNodeConcurrencyRelease", trad{FR: "Lancement du pipeline %s, le groupe de concurrence %s est libre", EN: "Triggering pipeline %s, concurrency group %s is free"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeConcurrencyCancel        = &Message{"MsgWorkflowNodeConcurrencyCancel", trad{FR: "Le pipeline %s a été annulé par le run %s du groupe de concurrence %s", EN: "The pipeline %s has been cancelled by run %s in concurrency group %s"}, nil, RunInfoTypeWarning}
+	MsgWorkflowNodeWaitingApproval          = &Message{"MsgWorkflowNodeWaitingApproval", trad{FR: "Le pipeline %s attend %d approbation(s) des groupes %s", EN: "The pipeline %s is waiting for %d approval(s) from groups %s"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeApproved                 = &Message{"MsgWorkflowNodeApproved", trad{FR: "Le pipeline %s a été approuvé par %s (%d/%d) : %s", EN: "The pipeline %s has been approved by %s (%d/%d): %s"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeRejected                 = &Message{"MsgWorkflowNodeRejected", trad{FR: "Le pipeline %s a été rejeté par %s : %s", EN: "The pipeline %s has been rejected by %s: %s"}, nil, RunInfoTypeWarning}
+	MsgWorkflowNodeApprovalExpired          = &Message{"MsgWorkflowNodeApprovalExpired", trad{FR: "Le pipeline %s a été arrêté car il n'a pas été approuvé après %s", EN: "The pipeline %s has been stopped because it was not approved after %s"}, nil, RunInfoTypeWarning}
+	MsgWorkflowNodeFrozen                   = &Message{"MsgWorkflowNodeFrozen", trad{FR: "Le pipeline %s est mis en attente par la période de gel %s de l'environnement %s jusqu'au %s", EN: "The pipeline %s is held by the freeze window %s of environment %s until %s"}, nil, RunInfoTypeWarning}
+	MsgWorkflowNodeFreezeRefused            = &Message{"MsgWorkflowNodeFreezeRefused", trad{FR: "Le pipeline %s a été refusé par la période de gel %s de l'environnement %s jusqu'au %s", EN: "The pipeline %s has been refused by the freeze window %s of environment %s until %s"}, nil, RunInfoTypeWarning}
+	MsgWorkflowNodeFreezeOverridden         = &Message{"MsgWorkflowNodeFreezeOverridden", trad{FR: "La période de gel %s de l'environnement %s a été outrepassée par %s pour le pipeline %s", EN: "The freeze window %s of environment %s has been overridden by %s for pipeline %s"}, nil, RunInfoTypInfo}
+	MsgWorkflowNodeFreezeEnded              = &Message{"MsgWorkflowNodeFreezeEnded", trad{FR: "Le pipeline %s démarre car la période de gel de l'environnement %s est terminée", EN: "The pipeline %s starts because the freeze window of environment %s is over"}, nil, RunInfoTypInfo}
 	MsgWorkflowImportedUpdated              = &Message{"MsgWorkflowImportedUpdated", trad{FR: "Le workflow %s a été mis à jour", EN: "Workflow %s has been updated"}, nil, RunInfoTypInfo}
 	MsgWorkflowImportedInserted             = &Message{"MsgWorkflowImportedInserted", trad{FR: "Le workflow %s a été créé", EN: "Workflow %s has been created"}, nil, RunInfoTypInfo}
 	MsgSpawnInfoHatcheryCannotStartJob      = &Message{"MsgSpawnInfoHatcheryCannotStart", trad{FR: "Aucune hatchery n'a pu démarrer de worker respectant vos pré-requis de job, merci de les vérifier.", EN: "No hatchery can spawn a worker corresponding your job's requirements. Please check your job's requirements."}, nil, RunInfoTypeWarning}
 	MsgWorkflowRunBranchDeleted             = &Message{"MsgWorkflowRunBranchDeleted", trad{FR: "La branche %s  a été supprimée", EN: "Branch %s has been deleted"}, nil, RunInfoTypInfo}
+	MsgWorkflowRunSuperseded                = &Message{"MsgWorkflowRunSuperseded", trad{FR: "Le run a été arrêté car le run %d a été lancé sur la branche %s", EN: "The workflow run has been stopped because run %d was started on branch %s"}, nil, RunInfoTypeWarning}
 	MsgWorkflowTemplateImportedInserted     = &Message{"MsgWorkflowTemplateImportedInserted", trad{FR: "Le template de workflow %s/%s a été créé", EN: "Workflow template %s/%s has been created"}, nil, RunInfoTypInfo}
 	MsgWorkflowTemplateImportedUpdated      = &Message{"MsgWorkflowTemplateImportedUpdated", trad{FR: "Le template de workflow %s/%s a été mis à jour", EN: "Workflow template %s/%s has been updated"}, nil, RunInfoTypInfo}
 	MsgWorkflowErrorBadPipelineName         = &Message{"MsgWorkflowErrorBadPipelineName", trad{FR: "Le pipeline %s indiqué dans votre fichier yaml de workflow n'existe pas", EN: "The pipeline %s mentioned in your workflow's yaml file doesn't exist"}, nil, RunInfoTypeError}
@@ -130,6 +150,7 @@ var Messages = map[string]*Message{
 	MsgSpawnInfoHatcheryStarts.ID:              MsgSpawnInfoHatcheryStarts,
 	MsgSpawnInfoHatcheryErrorSpawn.ID:          MsgSpawnInfoHatcheryErrorSpawn,
 	MsgSpawnInfoHatcheryStartsSuccessfully.ID:  MsgSpawnInfoHatcheryStartsSuccessfully,
+	MsgSpawnInfoHatcheryWarmWorkerAssigned.ID:  MsgSpawnInfoHatcheryWarmWorkerAssigned,
 	MsgSpawnInfoHatcheryStartDockerPull.ID:     MsgSpawnInfoHatcheryStartDockerPull,
 	MsgSpawnInfoHatcheryEndDockerPull.ID:       MsgSpawnInfoHatcheryEndDockerPull,
 	MsgSpawnInfoHatcheryEndDockerPullErr.ID:    MsgSpawnInfoHatcheryEndDockerPullErr,
@@ -141,16 +162,35 @@ var Messages = map[string]*Message{
 	MsgSpawnInfoWorkerForJob.ID:                MsgSpawnInfoWorkerForJob,
 	MsgSpawnInfoWorkerForJobError.ID:           MsgSpawnInfoWorkerForJobError,
 	MsgSpawnInfoJobError.ID:                    MsgSpawnInfoJobError,
+	MsgSpawnInfoJobTimeout.ID:                  MsgSpawnInfoJobTimeout,
+	MsgSpawnInfoJobRetry.ID:                    MsgSpawnInfoJobRetry,
+	MsgSpawnInfoJobMissingOutputs.ID:           MsgSpawnInfoJobMissingOutputs,
+	MsgSpawnInfoJobInvalidOutput.ID:            MsgSpawnInfoJobInvalidOutput,
+	MsgSpawnInfoJobDynamicStages.ID:            MsgSpawnInfoJobDynamicStages,
+	MsgSpawnInfoJobDynamicStagesError.ID:       MsgSpawnInfoJobDynamicStagesError,
+	MsgSpawnInfoJobQuotaExceeded.ID:            MsgSpawnInfoJobQuotaExceeded,
 	MsgWorkflowStarting.ID:                     MsgWorkflowStarting,
 	MsgWorkflowError.ID:                        MsgWorkflowError,
 	MsgWorkflowConditionError.ID:               MsgWorkflowConditionError,
 	MsgWorkflowNodeStop.ID:                     MsgWorkflowNodeStop,
 	MsgWorkflowNodeMutex.ID:                    MsgWorkflowNodeMutex,
 	MsgWorkflowNodeMutexRelease.ID:             MsgWorkflowNodeMutexRelease,
+	MsgWorkflowNodeConcurrency.ID:              MsgWorkflowNodeConcurrency,
+	MsgWorkflowNodeConcurrencyRelease.ID:       MsgWorkflowNodeConcurrencyRelease,
+	MsgWorkflowNodeConcurrencyCancel.ID:        MsgWorkflowNodeConcurrencyCancel,
+	MsgWorkflowNodeWaitingApproval.ID:          MsgWorkflowNodeWaitingApproval,
+	MsgWorkflowNodeApproved.ID:                 MsgWorkflowNodeApproved,
+	MsgWorkflowNodeRejected.ID:                 MsgWorkflowNodeRejected,
+	MsgWorkflowNodeApprovalExpired.ID:          MsgWorkflowNodeApprovalExpired,
+	MsgWorkflowNodeFrozen.ID:                   MsgWorkflowNodeFrozen,
+	MsgWorkflowNodeFreezeRefused.ID:            MsgWorkflowNodeFreezeRefused,
+	MsgWorkflowNodeFreezeOverridden.ID:         MsgWorkflowNodeFreezeOverridden,
+	MsgWorkflowNodeFreezeEnded.ID:              MsgWorkflowNodeFreezeEnded,
 	MsgWorkflowImportedUpdated.ID:              MsgWorkflowImportedUpdated,
 	MsgWorkflowImportedInserted.ID:             MsgWorkflowImportedInserted,
 	MsgSpawnInfoHatcheryCannotStartJob.ID:      MsgSpawnInfoHatcheryCannotStartJob,
 	MsgWorkflowRunBranchDeleted.ID:             MsgWorkflowRunBranchDeleted,
+	MsgWorkflowRunSuperseded.ID:                MsgWorkflowRunSuperseded,
 	MsgWorkflowTemplateImportedInserted.ID:     MsgWorkflowTemplateImportedInserted,
 	MsgWorkflowTemplateImportedUpdated.ID:      MsgWorkflowTemplateImportedUpdated,
 	MsgWorkflowErrorBadPipelineName.ID:         MsgWorkflowErrorBadPipelineName,
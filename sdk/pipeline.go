@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"sort"
 	"time"
 )
 
@@ -21,6 +22,33 @@ type Pipeline struct {
 	WorkflowAscodeHolder *Workflow `json:"workflow_ascode_holder,omitempty" cli:"-" db:"-"`
 }
 
+// FinallyStage returns the stage that runs after all the other stages of the pipeline, if any.
+func (p Pipeline) FinallyStage() *Stage {
+	for i := range p.Stages {
+		if p.Stages[i].Finally {
+			return &p.Stages[i]
+		}
+	}
+	return nil
+}
+
+// CheckFinallyStage returns an error if the pipeline contains more than one finally stage
+// or if the finally stage is not the last stage of the pipeline.
+func (p Pipeline) CheckFinallyStage() error {
+	stages := make([]Stage, len(p.Stages))
+	copy(stages, p.Stages)
+	sort.SliceStable(stages, func(i, j int) bool { return stages[i].BuildOrder < stages[j].BuildOrder })
+	for i, s := range stages {
+		if !s.Finally {
+			continue
+		}
+		if i != len(stages)-1 {
+			return NewErrorFrom(ErrWrongRequest, "finally stage %s must be the last stage of pipeline %s", s.Name, p.Name)
+		}
+	}
+	return nil
+}
+
 // PipelineAudit represents pipeline audit
 type PipelineAudit struct {
 	ID         int64     `json:"id" db:"id"`
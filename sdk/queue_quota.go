@@ -0,0 +1,8 @@
+package sdk
+
+// QueueQuota limits the jobs of a project or a group that are building at the same time, 0 means no limit.
+type QueueQuota struct {
+	MaxJobs   int   `toml:"maxJobs" comment:"Maximum count of building jobs" json:"max_jobs,omitempty"`
+	MaxMemory int64 `toml:"maxMemory" comment:"Maximum memory of the workers of the building jobs, in MB" json:"max_memory,omitempty"`
+	MaxModels int   `toml:"maxModels" comment:"Maximum count of workers spawned from a worker model" json:"max_models,omitempty"`
+}
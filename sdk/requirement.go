@@ -1,5 +1,10 @@
 package sdk
 
+import (
+	"math"
+	"strconv"
+)
+
 const (
 	//BinaryRequirement refers to the need to a specific binary on host running the action
 	BinaryRequirement = "binary"
@@ -13,6 +18,8 @@ const (
 	ServiceRequirement = "service"
 	//MemoryRequirement set memory limit on a container
 	MemoryRequirement = "memory"
+	// CPURequirement set the number of CPUs reserved for a container
+	CPURequirement = "cpu"
 	// VolumeRequirement set Volume limit on a container
 	VolumeRequirement = "volume"
 	// OSArchRequirement checks the 'dist' of a worker eg {GOOS}/{GOARCH}
@@ -67,6 +74,10 @@ func (l RequirementList) IsValid() error {
 			nbModel++
 		case HostnameRequirement:
 			nbHostname++
+		case CPURequirement:
+			if _, err := ParseCPURequirement(l[i].Value); err != nil {
+				return err
+			}
 		}
 	}
 	if nbModel > 1 {
@@ -79,10 +90,20 @@ func (l RequirementList) IsValid() error {
 	return nil
 }
 
+// ParseCPURequirement returns the number of CPUs of a cpu requirement value, ex: 2 or 0.5.
+func ParseCPURequirement(value string) (float64, error) {
+	cpus, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(cpus) || math.IsInf(cpus, 0) || cpus <= 0 {
+		return 0, NewErrorFrom(ErrInvalidJobRequirement, "invalid cpu requirement %q: it should be a positive number of CPUs", value)
+	}
+	return cpus, nil
+}
+
 var (
 	// AvailableRequirementsType List of all requirements
 	AvailableRequirementsType = []string{
 		BinaryRequirement,
+		CPURequirement,
 		HostnameRequirement,
 		MemoryRequirement,
 		ModelRequirement,
@@ -100,3 +100,20 @@ func TestRequirementListDeduplicate(t *testing.T) {
 		})
 	}
 }
+
+func TestRequirementListIsValidCPU(t *testing.T) {
+	l := RequirementList{
+		{Name: "cpu", Type: CPURequirement, Value: "0.5"},
+		{Name: "memory", Type: MemoryRequirement, Value: "4096"},
+	}
+	if err := l.IsValid(); err != nil {
+		t.Fatalf("requirement list should be valid: %v", err)
+	}
+
+	for _, v := range []string{"", "two", "0", "-1", "NaN", "nan", "Inf", "+Inf", "-Inf", "infinity", "1e400"} {
+		l[0].Value = v
+		if err := l.IsValid(); !ErrorIs(err, ErrInvalidJobRequirement) {
+			t.Fatalf("cpu requirement %q should be invalid, got: %v", v, err)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package sdk
 
 import (
+	"sort"
 	"strings"
 )
 
@@ -11,6 +12,7 @@ type Stage struct {
 	PipelineID    int64                  `json:"-" yaml:"-"`
 	BuildOrder    int                    `json:"build_order"`
 	Enabled       bool                   `json:"enabled"`
+	Finally       bool                   `json:"finally"`
 	RunJobs       []WorkflowNodeJobRun   `json:"run_jobs"`
 	Prerequisites []Prerequisite         `json:"prerequisites"` //TODO: to delete
 	Conditions    WorkflowNodeConditions `json:"conditions"`
@@ -26,6 +28,7 @@ type StageSummary struct {
 	Name           string                      `json:"name"`
 	BuildOrder     int                         `json:"build_order"`
 	Enabled        bool                        `json:"enabled"`
+	Finally        bool                        `json:"finally"`
 	Status         string                      `json:"status"`
 	Jobs           []Job                       `json:"jobs"`
 	RunJobsSummary []WorkflowNodeJobRunSummary `json:"run_jobs_summary"`
@@ -38,6 +41,7 @@ func (s Stage) ToSummary() StageSummary {
 		Name:           s.Name,
 		BuildOrder:     s.BuildOrder,
 		Enabled:        s.Enabled,
+		Finally:        s.Finally,
 		Status:         s.Status,
 		RunJobsSummary: make([]WorkflowNodeJobRunSummary, len(s.RunJobs)),
 		Jobs:           s.Jobs,
@@ -71,3 +75,58 @@ func NewStage(name string) *Stage {
 	}
 	return s
 }
+
+// CheckJobsNeeds returns an error if a job of the stage needs an unknown job or if jobs needs contain a cycle.
+func (s Stage) CheckJobsNeeds() error {
+	needs := make(map[string][]string, len(s.Jobs))
+	for _, j := range s.Jobs {
+		needs[j.Action.Name] = append(needs[j.Action.Name], j.Action.Needs...)
+	}
+	for name, ns := range needs {
+		for _, n := range ns {
+			if _, ok := needs[n]; !ok {
+				return NewErrorFrom(ErrWrongRequest, "job %s needs unknown job %s in stage %s", name, n, s.Name)
+			}
+		}
+	}
+
+	// Depth first search on jobs needs, a job found in current path means a cycle
+	const (
+		unvisited = iota
+		inPath
+		visited
+	)
+	states := make(map[string]int, len(needs))
+	var path []string
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch states[name] {
+		case visited:
+			return nil
+		case inPath:
+			return NewErrorFrom(ErrWrongRequest, "jobs needs contain a cycle in stage %s: %s", s.Name, strings.Join(append(path, name), " -> "))
+		}
+		states[name] = inPath
+		path = append(path, name)
+		for _, n := range needs[name] {
+			if err := visit(n); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		states[name] = visited
+		return nil
+	}
+
+	names := make([]string, 0, len(needs))
+	for name := range needs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package sdk_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestStageCheckJobsNeeds(t *testing.T) {
+	newJob := func(name string, needs ...string) sdk.Job {
+		return sdk.Job{Action: sdk.Action{Name: name, Needs: needs}}
+	}
+
+	s := sdk.Stage{Name: "build", Jobs: []sdk.Job{
+		newJob("compile"),
+		newJob("lint"),
+		newJob("test", "compile"),
+		newJob("package", "test", "lint"),
+	}}
+	assert.NoError(t, s.CheckJobsNeeds())
+
+	s.Jobs = append(s.Jobs, newJob("deploy", "unknown"))
+	err := s.CheckJobsNeeds()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "job deploy needs unknown job unknown in stage build")
+
+	s.Jobs[len(s.Jobs)-1] = newJob("deploy", "package")
+	s.Jobs[0] = newJob("compile", "deploy")
+	err = s.CheckJobsNeeds()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "compile -> deploy -> package -> test -> compile")
+
+	s.Jobs = []sdk.Job{newJob("compile", "compile")}
+	assert.Error(t, s.CheckJobsNeeds())
+}
@@ -143,6 +143,7 @@ var (
 		"cds.job",
 		"cds.manual",
 		"cds.pipeline",
+		"cds.pipeline.status",
 		"cds.project",
 		"cds.run",
 		"cds.run.number",
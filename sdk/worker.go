@@ -20,6 +20,7 @@ type Worker struct {
 	Version    string    `json:"version" cli:"version"  db:"version"`
 	OS         string    `json:"os" cli:"os"  db:"os"`
 	Arch       string    `json:"arch" cli:"arch"  db:"arch"`
+	Warm       bool      `json:"warm" cli:"warm" db:"warm"` // Warm worker spawned by an hatchery without a job
 	PrivateKey []byte    `json:"-" cli:"-" db:"cypher_private_key" gorpmapping:"encrypted,ID,Name,JobRunID"`
 }
 
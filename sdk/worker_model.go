@@ -154,6 +154,11 @@ func (m Model) IsValidType() error {
 		if m.PatternName == "" && (m.ModelDocker.Cmd == "" || m.ModelDocker.Shell == "") {
 			return WrapError(ErrWrongRequest, "invalid worker model command or shell command")
 		}
+		if m.ModelDocker.Kubernetes != nil {
+			if err := m.ModelDocker.Kubernetes.IsValid(); err != nil {
+				return err
+			}
+		}
 	case Openstack:
 		if m.ModelVirtualMachine.Image == "" {
 			return WrapError(ErrWrongRequest, "invalid worker model image")
@@ -228,6 +233,8 @@ type ModelDocker struct {
 	Envs     map[string]string `json:"envs,omitempty"`
 	Shell    string            `json:"shell,omitempty"`
 	Cmd      string            `json:"cmd,omitempty"`
+	// Kubernetes contains the pod overrides used by the Kubernetes hatchery
+	Kubernetes *ModelDockerKubernetes `json:"kubernetes,omitempty"`
 }
 
 // Value returns driver.Value from model docker.
@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Kubernetes toleration operators and effects, see https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration/
+const (
+	KubernetesTolerationOpExists = "Exists"
+	KubernetesTolerationOpEqual  = "Equal"
+
+	KubernetesTaintEffectNoSchedule       = "NoSchedule"
+	KubernetesTaintEffectPreferNoSchedule = "PreferNoSchedule"
+	KubernetesTaintEffectNoExecute        = "NoExecute"
+)
+
+var (
+	// kubernetesNameRegex is the format of kubernetes names (RFC 1123 label)
+	kubernetesNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+	// kubernetesCPURegex is the format of a CPU quantity, ex: 0.5, 2 or 500m
+	kubernetesCPURegex = regexp.MustCompile(`^([0-9]+(\.[0-9]+)?|[0-9]+m)$`)
+)
+
+// ModelDockerKubernetes contains the overrides applied by the Kubernetes hatchery on the pod
+// of a worker spawned for a docker worker model.
+type ModelDockerKubernetes struct {
+	NodeSelector    map[string]string               `json:"node_selector,omitempty" yaml:"node_selector,omitempty"`
+	Tolerations     []ModelKubernetesToleration     `json:"tolerations,omitempty" yaml:"tolerations,omitempty"`
+	CPURequest      string                          `json:"cpu_request,omitempty" yaml:"cpu_request,omitempty"`
+	CPULimit        string                          `json:"cpu_limit,omitempty" yaml:"cpu_limit,omitempty"`
+	SecurityContext *ModelKubernetesSecurityContext `json:"security_context,omitempty" yaml:"security_context,omitempty"`
+	ServiceAccount  string                          `json:"service_account,omitempty" yaml:"service_account,omitempty"`
+	Volumes         []ModelKubernetesVolume         `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	InitContainer   *ModelKubernetesInitContainer   `json:"init_container,omitempty" yaml:"init_container,omitempty"`
+}
+
+// ModelKubernetesToleration allows the worker pod to be scheduled on nodes with matching taints.
+type ModelKubernetesToleration struct {
+	Key               string `json:"key,omitempty" yaml:"key,omitempty"`
+	Operator          string `json:"operator,omitempty" yaml:"operator,omitempty"`
+	Value             string `json:"value,omitempty" yaml:"value,omitempty"`
+	Effect            string `json:"effect,omitempty" yaml:"effect,omitempty"`
+	TolerationSeconds *int64 `json:"toleration_seconds,omitempty" yaml:"toleration_seconds,omitempty"`
+}
+
+// ModelKubernetesSecurityContext is the security context of the worker pod.
+type ModelKubernetesSecurityContext struct {
+	RunAsUser    *int64 `json:"run_as_user,omitempty" yaml:"run_as_user,omitempty"`
+	RunAsGroup   *int64 `json:"run_as_group,omitempty" yaml:"run_as_group,omitempty"`
+	RunAsNonRoot *bool  `json:"run_as_non_root,omitempty" yaml:"run_as_non_root,omitempty"`
+	FSGroup      *int64 `json:"fs_group,omitempty" yaml:"fs_group,omitempty"`
+}
+
+// ModelKubernetesVolume is a volume mounted in the worker container and in the init container.
+// The volume is an empty dir if no config map or secret is given.
+type ModelKubernetesVolume struct {
+	Name      string `json:"name" yaml:"name"`
+	MountPath string `json:"mount_path" yaml:"mount_path"`
+	ReadOnly  bool   `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+	ConfigMap string `json:"config_map,omitempty" yaml:"config_map,omitempty"`
+	Secret    string `json:"secret,omitempty" yaml:"secret,omitempty"`
+}
+
+// ModelKubernetesInitContainer is a container started before the worker container.
+type ModelKubernetesInitContainer struct {
+	Image   string            `json:"image" yaml:"image"`
+	Command []string          `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Envs    map[string]string `json:"envs,omitempty" yaml:"envs,omitempty"`
+}
+
+// IsValid returns an error if the kubernetes overrides are invalid.
+func (k ModelDockerKubernetes) IsValid() error {
+	for key := range k.NodeSelector {
+		if key == "" {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes node selector: empty key")
+		}
+	}
+
+	for _, t := range k.Tolerations {
+		switch t.Operator {
+		case "", KubernetesTolerationOpEqual:
+			if t.Key == "" {
+				return NewErrorFrom(ErrWrongRequest, "invalid kubernetes toleration: key is mandatory with operator %s", KubernetesTolerationOpEqual)
+			}
+		case KubernetesTolerationOpExists:
+			if t.Value != "" {
+				return NewErrorFrom(ErrWrongRequest, "invalid kubernetes toleration %s: value should be empty with operator %s", t.Key, KubernetesTolerationOpExists)
+			}
+		default:
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes toleration %s: unknown operator %s", t.Key, t.Operator)
+		}
+		switch t.Effect {
+		case "", KubernetesTaintEffectNoSchedule, KubernetesTaintEffectPreferNoSchedule:
+			if t.TolerationSeconds != nil {
+				return NewErrorFrom(ErrWrongRequest, "invalid kubernetes toleration %s: toleration seconds can only be set with effect %s", t.Key, KubernetesTaintEffectNoExecute)
+			}
+		case KubernetesTaintEffectNoExecute:
+		default:
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes toleration %s: unknown effect %s", t.Key, t.Effect)
+		}
+	}
+
+	if k.CPURequest != "" && !kubernetesCPURegex.MatchString(k.CPURequest) {
+		return NewErrorFrom(ErrWrongRequest, "invalid kubernetes cpu request %s", k.CPURequest)
+	}
+	if k.CPULimit != "" && !kubernetesCPURegex.MatchString(k.CPULimit) {
+		return NewErrorFrom(ErrWrongRequest, "invalid kubernetes cpu limit %s", k.CPULimit)
+	}
+	if k.CPURequest != "" && k.CPULimit != "" && kubernetesMilliCPU(k.CPURequest) > kubernetesMilliCPU(k.CPULimit) {
+		return NewErrorFrom(ErrWrongRequest, "invalid kubernetes cpu request %s: greater than cpu limit %s", k.CPURequest, k.CPULimit)
+	}
+
+	if k.SecurityContext != nil {
+		sc := k.SecurityContext
+		if (sc.RunAsUser != nil && *sc.RunAsUser < 0) || (sc.RunAsGroup != nil && *sc.RunAsGroup < 0) || (sc.FSGroup != nil && *sc.FSGroup < 0) {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes security context: user and group ids cannot be negative")
+		}
+		if sc.RunAsNonRoot != nil && *sc.RunAsNonRoot && sc.RunAsUser != nil && *sc.RunAsUser == 0 {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes security context: cannot run as user 0 with run_as_non_root")
+		}
+	}
+
+	if k.ServiceAccount != "" && !kubernetesNameRegex.MatchString(k.ServiceAccount) {
+		return NewErrorFrom(ErrWrongRequest, "invalid kubernetes service account %s", k.ServiceAccount)
+	}
+
+	volumeNames := make(map[string]struct{}, len(k.Volumes))
+	for _, v := range k.Volumes {
+		if !kubernetesNameRegex.MatchString(v.Name) {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes volume name %s", v.Name)
+		}
+		if _, has := volumeNames[v.Name]; has {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes volume %s: duplicate name", v.Name)
+		}
+		volumeNames[v.Name] = struct{}{}
+		if !path.IsAbs(v.MountPath) {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes volume %s: mount path should be an absolute path", v.Name)
+		}
+		if v.ConfigMap != "" && v.Secret != "" {
+			return NewErrorFrom(ErrWrongRequest, "invalid kubernetes volume %s: config map and secret cannot be both set", v.Name)
+		}
+	}
+
+	if k.InitContainer != nil && k.InitContainer.Image == "" {
+		return NewErrorFrom(ErrWrongRequest, "invalid kubernetes init container: image is mandatory")
+	}
+
+	return nil
+}
+
+// kubernetesMilliCPU returns the value in milli CPU of a valid CPU quantity.
+func kubernetesMilliCPU(q string) float64 {
+	if strings.HasSuffix(q, "m") {
+		v, _ := strconv.ParseFloat(strings.TrimSuffix(q, "m"), 64)
+		return v
+	}
+	v, _ := strconv.ParseFloat(q, 64)
+	return v * 1000
+}
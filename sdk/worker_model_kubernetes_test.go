@@ -0,0 +1,106 @@
+package sdk_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ovh/cds/sdk"
+)
+
+func TestModelDockerKubernetesIsValid(t *testing.T) {
+	uid, nonRoot := int64(0), true
+	seconds := int64(60)
+	tests := []struct {
+		name    string
+		k       sdk.ModelDockerKubernetes
+		wantErr bool
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "valid",
+			k: sdk.ModelDockerKubernetes{
+				NodeSelector: map[string]string{"disktype": "ssd"},
+				Tolerations: []sdk.ModelKubernetesToleration{
+					{Key: "dedicated", Value: "cds"},
+					{Key: "node.kubernetes.io/unreachable", Operator: sdk.KubernetesTolerationOpExists, Effect: sdk.KubernetesTaintEffectNoExecute, TolerationSeconds: &seconds},
+				},
+				CPURequest:     "0.5",
+				CPULimit:       "1000m",
+				ServiceAccount: "cds-worker",
+				Volumes: []sdk.ModelKubernetesVolume{
+					{Name: "cache", MountPath: "/cache"},
+					{Name: "config", MountPath: "/etc/config", ConfigMap: "worker-config"},
+				},
+				InitContainer: &sdk.ModelKubernetesInitContainer{Image: "busybox"},
+			},
+		},
+		{
+			name:    "toleration with value and operator Exists",
+			k:       sdk.ModelDockerKubernetes{Tolerations: []sdk.ModelKubernetesToleration{{Key: "dedicated", Operator: sdk.KubernetesTolerationOpExists, Value: "cds"}}},
+			wantErr: true,
+		},
+		{
+			name:    "toleration with unknown effect",
+			k:       sdk.ModelDockerKubernetes{Tolerations: []sdk.ModelKubernetesToleration{{Key: "dedicated", Effect: "NoWay"}}},
+			wantErr: true,
+		},
+		{
+			name:    "toleration seconds without NoExecute",
+			k:       sdk.ModelDockerKubernetes{Tolerations: []sdk.ModelKubernetesToleration{{Key: "dedicated", TolerationSeconds: &seconds}}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid cpu",
+			k:       sdk.ModelDockerKubernetes{CPULimit: "1 cpu"},
+			wantErr: true,
+		},
+		{
+			name:    "cpu request greater than limit",
+			k:       sdk.ModelDockerKubernetes{CPURequest: "1.5", CPULimit: "1000m"},
+			wantErr: true,
+		},
+		{
+			name:    "root user with run as non root",
+			k:       sdk.ModelDockerKubernetes{SecurityContext: &sdk.ModelKubernetesSecurityContext{RunAsUser: &uid, RunAsNonRoot: &nonRoot}},
+			wantErr: true,
+		},
+		{
+			name:    "invalid service account",
+			k:       sdk.ModelDockerKubernetes{ServiceAccount: "CDS_Worker"},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate volume",
+			k:       sdk.ModelDockerKubernetes{Volumes: []sdk.ModelKubernetesVolume{{Name: "cache", MountPath: "/cache"}, {Name: "cache", MountPath: "/tmp/cache"}}},
+			wantErr: true,
+		},
+		{
+			name:    "relative mount path",
+			k:       sdk.ModelDockerKubernetes{Volumes: []sdk.ModelKubernetesVolume{{Name: "cache", MountPath: "cache"}}},
+			wantErr: true,
+		},
+		{
+			name:    "volume with config map and secret",
+			k:       sdk.ModelDockerKubernetes{Volumes: []sdk.ModelKubernetesVolume{{Name: "config", MountPath: "/config", ConfigMap: "config", Secret: "config"}}},
+			wantErr: true,
+		},
+		{
+			name:    "init container without image",
+			k:       sdk.ModelDockerKubernetes{InitContainer: &sdk.ModelKubernetesInitContainer{Command: []string{"true"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.k.IsValid()
+			if tt.wantErr {
+				assert.True(t, sdk.ErrorIs(err, sdk.ErrWrongRequest), "expected a wrong request error but got: %v", err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -41,6 +41,8 @@ type Workflow struct {
 	Usage                   *Usage                       `json:"usage,omitempty" db:"-" cli:"-"`
 	HistoryLength           int64                        `json:"history_length" db:"history_length" cli:"-"`
 	PurgeTags               PurgeTags                    `json:"purge_tags,omitempty" db:"purge_tags" cli:"-"`
+	AutoCancel              bool                         `json:"auto_cancel,omitempty" db:"auto_cancel" cli:"-"`
+	Priority                int                          `json:"priority,omitempty" db:"priority" cli:"-"`
 	Notifications           []WorkflowNotification       `json:"notifications,omitempty" db:"-" cli:"-"`
 	FromRepository          string                       `json:"from_repository,omitempty" db:"from_repository" cli:"from"`
 	DerivedFromWorkflowID   int64                        `json:"derived_from_workflow_id,omitempty" db:"derived_from_workflow_id" cli:"-"`
@@ -349,10 +351,11 @@ func (w *Workflow) ValidateType() error {
 	return nil
 }
 
-//WorkflowNodeConditions is either an array of WorkflowNodeCondition or a lua script
+//WorkflowNodeConditions is either an array of WorkflowNodeCondition, a lua script or an expression
 type WorkflowNodeConditions struct {
 	PlainConditions []WorkflowNodeCondition `json:"plain,omitempty" yaml:"check,omitempty"`
 	LuaScript       string                  `json:"lua_script,omitempty" yaml:"script,omitempty"`
+	Expression      string                  `json:"expression,omitempty" yaml:"expression,omitempty"`
 }
 
 // Value returns driver.Value from WorkflowNodeConditions request.
@@ -5,6 +5,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/ovh/cds/sdk/expression"
 	"github.com/ovh/cds/sdk/interpolate"
 )
 
@@ -84,3 +85,36 @@ func WorkflowCheckConditions(conditions []WorkflowNodeCondition, params []Parame
 
 	return conditionsOK, nil
 }
+
+//WorkflowCheckConditionsExpression checks a condition expression given a list of parameters
+func WorkflowCheckConditionsExpression(expr string, params []Parameter) (bool, error) {
+	mapParams := ParametersToMap(params)
+	for k, v := range mapParams {
+		var err error
+		mapParams[k], err = interpolate.Do(v, mapParams)
+		if err != nil {
+			return false, fmt.Errorf("Unable to interpolate %s (%v)", v, err)
+		}
+	}
+	return expression.Eval(expr, mapParams)
+}
+
+// IsEmpty returns true if no plain condition, lua script or expression is set.
+func (w WorkflowNodeConditions) IsEmpty() bool {
+	return len(w.PlainConditions) == 0 && w.LuaScript == "" && w.Expression == ""
+}
+
+// IsValid returns an error if the operators of the plain conditions or the expression are invalid.
+func (w WorkflowNodeConditions) IsValid() error {
+	for _, cond := range w.PlainConditions {
+		if _, ok := WorkflowConditionsOperators[cond.Operator]; !ok {
+			return WithStack(ErrWorkflowConditionBadOperator)
+		}
+	}
+	if w.Expression != "" {
+		if _, err := expression.Parse(w.Expression); err != nil {
+			return NewErrorFrom(ErrWrongRequest, "%v", err)
+		}
+	}
+	return nil
+}
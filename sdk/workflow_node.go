@@ -3,6 +3,8 @@ package sdk
 import (
 	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/fsamin/go-dump"
 )
@@ -55,6 +57,78 @@ type NodeContext struct {
 	DefaultPipelineParameters []Parameter            `json:"default_pipeline_parameters" db:"-"`
 	Conditions                WorkflowNodeConditions `json:"conditions" db:"-"`
 	Mutex                     bool                   `json:"mutex" db:"mutex"`
+	Concurrency               *NodeConcurrency       `json:"concurrency,omitempty" db:"-"`
+	Approval                  *NodeApproval          `json:"approval,omitempty" db:"-"`
+}
+
+// NodeApproval represents an approval gate on a node, the node run waits until enough members of the given groups approve it.
+type NodeApproval struct {
+	Groups       []string `json:"groups"`
+	MinApprovals int      `json:"min_approvals"`
+	Expiry       int64    `json:"expiry,omitempty"` // in seconds
+}
+
+// IsValid returns an error if the approval gate is invalid.
+func (a NodeApproval) IsValid() error {
+	if len(a.Groups) == 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid approval, at least one approvers group is required")
+	}
+	if a.MinApprovals < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid approval, min approvals should be positive")
+	}
+	if a.Expiry < 0 {
+		return NewErrorFrom(ErrWrongRequest, "invalid approval, expiry should be positive")
+	}
+	return nil
+}
+
+// GetMinApprovals returns the number of approvals required to run the node, 1 by default.
+func (a NodeApproval) GetMinApprovals() int {
+	if a.MinApprovals <= 0 {
+		return 1
+	}
+	return a.MinApprovals
+}
+
+// GetExpiry returns the duration after which the approval expires, 0 if it never expires.
+func (a NodeApproval) GetExpiry() time.Duration {
+	return time.Duration(a.Expiry) * time.Second
+}
+
+// Concurrency policies
+const (
+	ConcurrencyPolicyQueue            = "queue"
+	ConcurrencyPolicyCancelPending    = "cancel-pending"
+	ConcurrencyPolicyCancelInProgress = "cancel-in-progress"
+)
+
+// ConcurrencyPolicies contains all the available concurrency policies.
+var ConcurrencyPolicies = []string{ConcurrencyPolicyQueue, ConcurrencyPolicyCancelPending, ConcurrencyPolicyCancelInProgress}
+
+// NodeConcurrency represents a named concurrency group shared by nodes of any workflow.
+// The name can be templated with the build parameters of the node run (ex: deploy-prod-{{.cds.env.name}}).
+type NodeConcurrency struct {
+	Name   string `json:"name"`
+	Policy string `json:"policy"`
+}
+
+// IsValid returns an error if the concurrency group is invalid.
+func (c NodeConcurrency) IsValid() error {
+	if c.Name == "" {
+		return NewErrorFrom(ErrWrongRequest, "invalid empty concurrency group name")
+	}
+	if c.Policy != "" && !IsInArray(c.Policy, ConcurrencyPolicies) {
+		return NewErrorFrom(ErrWrongRequest, "invalid concurrency policy %q, should be one of %s", c.Policy, strings.Join(ConcurrencyPolicies, ", "))
+	}
+	return nil
+}
+
+// GetPolicy returns the policy of the concurrency group, queue by default.
+func (c NodeConcurrency) GetPolicy() string {
+	if c.Policy == "" {
+		return ConcurrencyPolicyQueue
+	}
+	return c.Policy
 }
 
 // FilterHooksConfig filter all hooks configuration and remove somme configuration key
@@ -138,3 +138,21 @@ func TestAncestorsSimple(t *testing.T) {
 
 	}
 }
+
+func TestNodeApproval(t *testing.T) {
+	assert.Error(t, NodeApproval{}.IsValid())
+	assert.Error(t, NodeApproval{Groups: []string{"ops"}, MinApprovals: -1}.IsValid())
+	assert.NoError(t, NodeApproval{Groups: []string{"ops"}}.IsValid())
+
+	assert.Equal(t, 1, NodeApproval{Groups: []string{"ops"}}.GetMinApprovals())
+	assert.Equal(t, 2, NodeApproval{Groups: []string{"ops"}, MinApprovals: 2}.GetMinApprovals())
+
+	nr := WorkflowNodeRun{
+		Approvals: []WorkflowNodeRunApproval{
+			{Username: "foo", Approved: true},
+			{Username: "bar", Approved: false},
+			{Username: "baz", Approved: true},
+		},
+	}
+	assert.Equal(t, 2, nr.CountApprovals())
+}
@@ -249,6 +249,33 @@ type WorkflowNodeRun struct {
 	HookExecutionID        string                               `json:"execution_id,omitempty"`
 	Callback               *WorkflowNodeOutgoingHookRunCallback `json:"callback,omitempty"`
 	VCSReport              string                               `json:"vcs_report,omitempty"`
+	ConcurrencyGroup       string                               `json:"concurrency_group,omitempty"`
+	Approvals              []WorkflowNodeRunApproval            `json:"approvals,omitempty"`
+}
+
+// WorkflowNodeRunApproval is a decision taken by a user on a node run waiting for approval
+type WorkflowNodeRunApproval struct {
+	Username string    `json:"username" cli:"username"`
+	Approved bool      `json:"approved" cli:"approved"`
+	Comment  string    `json:"comment,omitempty" cli:"comment"`
+	Date     time.Time `json:"date" cli:"date"`
+}
+
+// WorkflowNodeRunApprovalRequest is the decision sent to approve or reject a node run
+type WorkflowNodeRunApprovalRequest struct {
+	Approved bool   `json:"approved"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+// CountApprovals returns the number of users that approved the node run
+func (r WorkflowNodeRun) CountApprovals() int {
+	var n int
+	for _, a := range r.Approvals {
+		if a.Approved {
+			n++
+		}
+	}
+	return n
 }
 
 // WorkflowNodeOutgoingHookRunCallback is the callback coming from hooks uservice avec an outgoing hook execution
@@ -363,6 +390,7 @@ type WorkflowNodeJobRun struct {
 	Parameters                []Parameter        `json:"parameters,omitempty"`
 	Status                    string             `json:"status"`
 	Retry                     int                `json:"retry"`
+	Attempts                  int                `json:"attempts"`
 	Queued                    time.Time          `json:"queued,omitempty" cli:"queued"`
 	QueuedSeconds             int64              `json:"queued_seconds,omitempty"`
 	Start                     time.Time          `json:"start,omitempty"`
@@ -377,6 +405,8 @@ type WorkflowNodeJobRun struct {
 	ContainsService           bool               `json:"contains_service,omitempty"`
 	HatcheryName              string             `json:"hatchery_name,omitempty"`
 	WorkerName                string             `json:"worker_name,omitempty"`
+	Priority                  int                `json:"priority,omitempty"`
+	QueueRank                 int                `json:"queue_rank,omitempty"`
 }
 
 // WorkflowNodeJobRunSummary is a light representation of WorkflowNodeJobRun for CDS event
@@ -394,6 +424,18 @@ type WorkflowNodeJobRunSummary struct {
 	WorkerModelName   string             `json:"worker_model_name,omitempty"`
 }
 
+// Attempt returns the current attempt number of the job run for its retry policy, starting at 1.
+// Requeues after a dead worker are not counted.
+func (wnjr WorkflowNodeJobRun) Attempt() int {
+	return wnjr.Attempts + 1
+}
+
+// CanRetry returns true if the job run can be requeued after a failure caused by given condition.
+func (wnjr WorkflowNodeJobRun) CanRetry(condition string) bool {
+	r := wnjr.Job.Action.Retry
+	return r != nil && r.Match(condition) && wnjr.Attempt() < r.MaxAttempts
+}
+
 // ToSummary transforms a WorkflowNodeJobRun into a WorkflowNodeJobRunSummary
 func (wnjr WorkflowNodeJobRun) ToSummary() WorkflowNodeJobRunSummary {
 	sum := WorkflowNodeJobRunSummary{
@@ -471,6 +513,24 @@ func (w *WorkflowNodeRunArtifact) GetPath() string {
 type WorkflowQueue []WorkflowNodeJobRun
 
 func (q WorkflowQueue) Sort() {
+	// Keep the order computed by the API if the jobs are ranked
+	var ranked bool
+	for _, j := range q {
+		if j.QueueRank > 0 {
+			ranked = true
+			break
+		}
+	}
+	if ranked {
+		sort.SliceStable(q, func(i, j int) bool {
+			if q[i].QueueRank == 0 || q[j].QueueRank == 0 {
+				return q[j].QueueRank == 0 && q[i].QueueRank != 0
+			}
+			return q[i].QueueRank < q[j].QueueRank
+		})
+		return
+	}
+
 	//Count the number of WorkflowNodeJobRun per project_id
 	n := make(map[int64]int, len(q))
 	for _, j := range q {
@@ -0,0 +1,421 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ovh/venom"
+)
+
+// WorkflowRunDiff contains the differences between two runs of a workflow
+type WorkflowRunDiff struct {
+	From       WorkflowRunDiffRun        `json:"from"`
+	To         WorkflowRunDiffRun        `json:"to"`
+	Workflow   WorkflowRunDiffWorkflow   `json:"workflow"`
+	Parameters []WorkflowRunDiffValue    `json:"parameters,omitempty"`
+	Commits    []WorkflowRunDiffCommits  `json:"commits,omitempty"`
+	Jobs       []WorkflowRunDiffJob      `json:"jobs,omitempty"`
+	Tests      []WorkflowRunDiffTests    `json:"tests,omitempty"`
+	Artifacts  []WorkflowRunDiffArtifact `json:"artifacts,omitempty"`
+}
+
+// WorkflowRunDiffRun identifies a compared workflow run
+type WorkflowRunDiffRun struct {
+	Number int64     `json:"num" cli:"num"`
+	Status string    `json:"status" cli:"status"`
+	Start  time.Time `json:"start" cli:"start"`
+}
+
+// WorkflowRunDiffWorkflow contains the differences between the workflow definitions used by two runs
+type WorkflowRunDiffWorkflow struct {
+	FromLastModified time.Time `json:"from_last_modified"`
+	ToLastModified   time.Time `json:"to_last_modified"`
+	// Pipelines contains the names of the pipelines that were modified, added or removed
+	Pipelines []string `json:"pipelines,omitempty"`
+}
+
+// Changed returns true if the workflow was modified between the two runs
+func (w WorkflowRunDiffWorkflow) Changed() bool {
+	return !w.FromLastModified.Equal(w.ToLastModified) || len(w.Pipelines) > 0
+}
+
+// WorkflowRunDiffValue is a value that differs between two runs, From or To is empty if the value does not exist in the run
+type WorkflowRunDiffValue struct {
+	Node string `json:"node,omitempty" cli:"node"`
+	Name string `json:"name" cli:"name"`
+	From string `json:"from" cli:"from"`
+	To   string `json:"to" cli:"to"`
+}
+
+// WorkflowRunDiffCommits contains the commits of a repository between two runs
+type WorkflowRunDiffCommits struct {
+	Repository string      `json:"repository"`
+	From       string      `json:"from"`
+	To         string      `json:"to"`
+	Commits    []VCSCommit `json:"commits,omitempty"`
+}
+
+// WorkflowRunDiffJob compares the executions of a job in two runs, durations are in seconds
+type WorkflowRunDiffJob struct {
+	Node         string                 `json:"node" cli:"node"`
+	Job          string                 `json:"job" cli:"job"`
+	FromStatus   string                 `json:"from_status" cli:"from_status"`
+	ToStatus     string                 `json:"to_status" cli:"to_status"`
+	FromDuration int64                  `json:"from_duration" cli:"from_duration"`
+	ToDuration   int64                  `json:"to_duration" cli:"to_duration"`
+	FromModel    string                 `json:"from_model,omitempty" cli:"from_model"`
+	ToModel      string                 `json:"to_model,omitempty" cli:"to_model"`
+	Requirements []WorkflowRunDiffValue `json:"requirements,omitempty"`
+}
+
+// WorkflowRunDiffTests compares the test results of a node in two runs
+type WorkflowRunDiffTests struct {
+	Node        string   `json:"node" cli:"node"`
+	FromTotal   int      `json:"from_total" cli:"from_total"`
+	FromKO      int      `json:"from_ko" cli:"from_ko"`
+	ToTotal     int      `json:"to_total" cli:"to_total"`
+	ToKO        int      `json:"to_ko" cli:"to_ko"`
+	NewFailures []string `json:"new_failures,omitempty"`
+	Fixed       []string `json:"fixed,omitempty"`
+}
+
+// WorkflowRunDiffArtifact is an artifact that was added, removed or modified between two runs
+type WorkflowRunDiffArtifact struct {
+	Node     string `json:"node" cli:"node"`
+	Name     string `json:"name" cli:"name"`
+	FromSize int64  `json:"from_size" cli:"from_size"`
+	ToSize   int64  `json:"to_size" cli:"to_size"`
+	FromMD5  string `json:"from_md5sum,omitempty" cli:"from_md5sum"`
+	ToMD5    string `json:"to_md5sum,omitempty" cli:"to_md5sum"`
+}
+
+// workflowRunDiffIgnoredParameters change for each run and are not compared
+var workflowRunDiffIgnoredParameters = []string{
+	"cds.run", "cds.run.number", "cds.run.subnumber", "cds.version", "cds.buildNumber",
+	"cds.node.id", "cds.ui.pipeline.run", "cds.triggered_by.username", "cds.triggered_by.fullname", "cds.triggered_by.email",
+}
+
+// LastNodeRunsByName returns the last execution of each node of the run, by node name
+func (r WorkflowRun) LastNodeRunsByName() map[string]WorkflowNodeRun {
+	res := make(map[string]WorkflowNodeRun, len(r.WorkflowNodeRuns))
+	for _, nrs := range r.WorkflowNodeRuns {
+		for _, nr := range nrs {
+			if last, has := res[nr.WorkflowNodeName]; !has || nr.SubNumber > last.SubNumber {
+				res[nr.WorkflowNodeName] = nr
+			}
+		}
+	}
+	return res
+}
+
+// DiffWorkflowRuns compares two runs of a workflow. Commits are not compared as they are loaded from the repositories.
+func DiffWorkflowRuns(from, to WorkflowRun) WorkflowRunDiff {
+	diff := WorkflowRunDiff{
+		From: WorkflowRunDiffRun{Number: from.Number, Status: from.Status, Start: from.Start},
+		To:   WorkflowRunDiffRun{Number: to.Number, Status: to.Status, Start: to.Start},
+		Workflow: WorkflowRunDiffWorkflow{
+			FromLastModified: from.Workflow.LastModified,
+			ToLastModified:   to.Workflow.LastModified,
+			Pipelines:        diffWorkflowRunPipelines(from.Workflow.Pipelines, to.Workflow.Pipelines),
+		},
+	}
+
+	fromNodes, toNodes := from.LastNodeRunsByName(), to.LastNodeRunsByName()
+	for _, name := range workflowRunDiffNodeNames(fromNodes, toNodes) {
+		fromNode, toNode := fromNodes[name], toNodes[name]
+		diff.Parameters = append(diff.Parameters, diffWorkflowRunParameters(name, fromNode.BuildParameters, toNode.BuildParameters)...)
+		diff.Jobs = append(diff.Jobs, diffWorkflowRunJobs(name, fromNode, toNode)...)
+		if tests := diffWorkflowRunTests(name, fromNode.Tests, toNode.Tests); tests != nil {
+			diff.Tests = append(diff.Tests, *tests)
+		}
+		diff.Artifacts = append(diff.Artifacts, diffWorkflowRunArtifacts(name, fromNode.Artifacts, toNode.Artifacts)...)
+	}
+	return diff
+}
+
+func workflowRunDiffNodeNames(from, to map[string]WorkflowNodeRun) []string {
+	names := make([]string, 0, len(from))
+	for name := range from {
+		names = append(names, name)
+	}
+	for name := range to {
+		if _, has := from[name]; !has {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// workflowRunDiffStage is the definition of a stage compared between two runs, without ids, timestamps and run data
+type workflowRunDiffStage struct {
+	Name       string                  `json:"name"`
+	BuildOrder int                     `json:"build_order"`
+	Enabled    bool                    `json:"enabled"`
+	Finally    bool                    `json:"finally"`
+	Conditions WorkflowNodeConditions  `json:"conditions"`
+	Jobs       []workflowRunDiffAction `json:"jobs"`
+}
+
+// workflowRunDiffAction is the definition of a job or a step compared between two runs
+type workflowRunDiffAction struct {
+	Name           string                  `json:"name"`
+	Type           string                  `json:"type"`
+	StepName       string                  `json:"step_name"`
+	Enabled        bool                    `json:"enabled"`
+	Optional       bool                    `json:"optional"`
+	AlwaysExecuted bool                    `json:"always_executed"`
+	Timeout        int64                   `json:"timeout"`
+	Retry          *JobRetry               `json:"retry"`
+	Matrix         JobMatrix               `json:"matrix"`
+	Needs          StringSlice             `json:"needs"`
+	Outputs        JobOutputs              `json:"outputs"`
+	Priority       int                     `json:"priority"`
+	Conditions     *WorkflowNodeConditions `json:"conditions"`
+	Requirements   map[string]string       `json:"requirements"`
+	Parameters     map[string]string       `json:"parameters"`
+	Steps          []workflowRunDiffAction `json:"steps"`
+}
+
+func workflowRunDiffParameters(params []Parameter) map[string]string {
+	res := make(map[string]string, len(params))
+	for _, p := range params {
+		res[p.Name] = p.Type + " " + p.Value
+	}
+	return res
+}
+
+func newWorkflowRunDiffAction(a Action, enabled bool) workflowRunDiffAction {
+	res := workflowRunDiffAction{
+		Name:           a.Name,
+		Type:           a.Type,
+		StepName:       a.StepName,
+		Enabled:        enabled,
+		Optional:       a.Optional,
+		AlwaysExecuted: a.AlwaysExecuted,
+		Timeout:        a.Timeout,
+		Retry:          a.Retry,
+		Matrix:         a.Matrix,
+		Needs:          a.Needs,
+		Outputs:        a.Outputs,
+		Priority:       a.Priority,
+		Conditions:     a.Conditions,
+		Requirements:   make(map[string]string, len(a.Requirements)),
+		Parameters:     workflowRunDiffParameters(a.Parameters),
+	}
+	for _, r := range a.Requirements {
+		res.Requirements[r.Type+" "+r.Name] = r.Value
+	}
+	for _, step := range a.Actions {
+		res.Steps = append(res.Steps, newWorkflowRunDiffAction(step, step.Enabled))
+	}
+	return res
+}
+
+func diffWorkflowRunPipelines(from, to map[int64]Pipeline) []string {
+	definitions := func(pips map[int64]Pipeline) map[string]string {
+		res := make(map[string]string, len(pips))
+		for _, p := range pips {
+			stages := make([]workflowRunDiffStage, 0, len(p.Stages))
+			for _, s := range p.Stages {
+				stage := workflowRunDiffStage{
+					Name:       s.Name,
+					BuildOrder: s.BuildOrder,
+					Enabled:    s.Enabled,
+					Finally:    s.Finally,
+					Conditions: s.Conditions,
+				}
+				for _, j := range s.Jobs {
+					stage.Jobs = append(stage.Jobs, newWorkflowRunDiffAction(j.Action, j.Enabled))
+				}
+				stages = append(stages, stage)
+			}
+			btes, _ := json.Marshal(struct {
+				Parameters map[string]string      `json:"parameters"`
+				Stages     []workflowRunDiffStage `json:"stages"`
+			}{workflowRunDiffParameters(p.Parameter), stages})
+			res[p.Name] = string(btes)
+		}
+		return res
+	}
+	fromDefs, toDefs := definitions(from), definitions(to)
+
+	var names []string
+	for name, def := range fromDefs {
+		if toDef, has := toDefs[name]; !has || toDef != def {
+			names = append(names, name)
+		}
+	}
+	for name := range toDefs {
+		if _, has := fromDefs[name]; !has {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func diffWorkflowRunParameters(node string, from, to []Parameter) []WorkflowRunDiffValue {
+	values := func(params []Parameter) map[string]string {
+		res := make(map[string]string, len(params))
+		for _, p := range params {
+			if IsInArray(p.Name, workflowRunDiffIgnoredParameters) {
+				continue
+			}
+			if p.Type == SecretVariable {
+				p.Value = PasswordPlaceholder
+			}
+			res[p.Name] = p.Value
+		}
+		return res
+	}
+	return diffWorkflowRunValues(node, values(from), values(to))
+}
+
+// diffWorkflowRunValues returns the values that differ between the two maps, sorted by name
+func diffWorkflowRunValues(node string, from, to map[string]string) []WorkflowRunDiffValue {
+	var res []WorkflowRunDiffValue
+	for name, v := range from {
+		if toV, has := to[name]; !has || toV != v {
+			res = append(res, WorkflowRunDiffValue{Node: node, Name: name, From: v, To: toV})
+		}
+	}
+	for name, v := range to {
+		if _, has := from[name]; !has {
+			res = append(res, WorkflowRunDiffValue{Node: node, Name: name, To: v})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
+
+func workflowRunDiffJobName(rj WorkflowNodeJobRun) string {
+	if len(rj.Job.Matrix) == 0 {
+		return rj.Job.Action.Name
+	}
+	return fmt.Sprintf("%s %s", rj.Job.Action.Name, rj.Job.Matrix.String())
+}
+
+func diffWorkflowRunJobs(node string, from, to WorkflowNodeRun) []WorkflowRunDiffJob {
+	runJobs := func(nr WorkflowNodeRun) (map[string]WorkflowNodeJobRun, []string) {
+		res := make(map[string]WorkflowNodeJobRun)
+		var names []string
+		for _, s := range nr.Stages {
+			for _, rj := range s.RunJobs {
+				name := workflowRunDiffJobName(rj)
+				if _, has := res[name]; !has {
+					names = append(names, name)
+				}
+				res[name] = rj
+			}
+		}
+		return res, names
+	}
+	fromJobs, fromNames := runJobs(from)
+	toJobs, toNames := runJobs(to)
+
+	// Jobs are kept in the order of the stages of the last run
+	names := toNames
+	for _, name := range fromNames {
+		if _, has := toJobs[name]; !has {
+			names = append(names, name)
+		}
+	}
+
+	requirements := func(rj WorkflowNodeJobRun) map[string]string {
+		res := make(map[string]string)
+		for _, r := range rj.Job.Action.Requirements {
+			res[r.Type+" "+r.Name] = r.Value
+		}
+		return res
+	}
+	duration := func(rj WorkflowNodeJobRun) int64 {
+		if rj.Start.IsZero() || rj.Done.Before(rj.Start) {
+			return 0
+		}
+		return int64(rj.Done.Sub(rj.Start).Seconds())
+	}
+
+	res := make([]WorkflowRunDiffJob, 0, len(names))
+	for _, name := range names {
+		fromJob, toJob := fromJobs[name], toJobs[name]
+		res = append(res, WorkflowRunDiffJob{
+			Node:         node,
+			Job:          name,
+			FromStatus:   fromJob.Status,
+			ToStatus:     toJob.Status,
+			FromDuration: duration(fromJob),
+			ToDuration:   duration(toJob),
+			FromModel:    fromJob.Model,
+			ToModel:      toJob.Model,
+			Requirements: diffWorkflowRunValues("", requirements(fromJob), requirements(toJob)),
+		})
+	}
+	return res
+}
+
+func diffWorkflowRunTests(node string, from, to *venom.Tests) *WorkflowRunDiffTests {
+	if from == nil && to == nil {
+		return nil
+	}
+	failures := func(tests *venom.Tests) (map[string]bool, int, int) {
+		res := make(map[string]bool)
+		if tests == nil {
+			return res, 0, 0
+		}
+		for _, ts := range tests.TestSuites {
+			for _, tc := range ts.TestCases {
+				res[ts.Name+" / "+tc.Name] = len(tc.Errors)+len(tc.Failures) > 0
+			}
+		}
+		return res, tests.Total, tests.TotalKO
+	}
+	fromFailures, fromTotal, fromKO := failures(from)
+	toFailures, toTotal, toKO := failures(to)
+
+	diff := WorkflowRunDiffTests{Node: node, FromTotal: fromTotal, FromKO: fromKO, ToTotal: toTotal, ToKO: toKO}
+	for name, failed := range toFailures {
+		if failed && !fromFailures[name] {
+			diff.NewFailures = append(diff.NewFailures, name)
+		}
+	}
+	for name, failed := range fromFailures {
+		if failed {
+			if toFailed, has := toFailures[name]; has && !toFailed {
+				diff.Fixed = append(diff.Fixed, name)
+			}
+		}
+	}
+	sort.Strings(diff.NewFailures)
+	sort.Strings(diff.Fixed)
+	return &diff
+}
+
+func diffWorkflowRunArtifacts(node string, from, to []WorkflowNodeRunArtifact) []WorkflowRunDiffArtifact {
+	artifacts := func(arts []WorkflowNodeRunArtifact) map[string]WorkflowNodeRunArtifact {
+		res := make(map[string]WorkflowNodeRunArtifact, len(arts))
+		for _, a := range arts {
+			res[a.Name] = a
+		}
+		return res
+	}
+	fromArts, toArts := artifacts(from), artifacts(to)
+
+	var res []WorkflowRunDiffArtifact
+	for name, a := range fromArts {
+		toA, has := toArts[name]
+		if has && toA.MD5sum == a.MD5sum && toA.Size == a.Size {
+			continue
+		}
+		res = append(res, WorkflowRunDiffArtifact{Node: node, Name: name, FromSize: a.Size, FromMD5: a.MD5sum, ToSize: toA.Size, ToMD5: toA.MD5sum})
+	}
+	for name, a := range toArts {
+		if _, has := fromArts[name]; !has {
+			res = append(res, WorkflowRunDiffArtifact{Node: node, Name: name, ToSize: a.Size, ToMD5: a.MD5sum})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+	return res
+}
@@ -0,0 +1,171 @@
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ovh/venom"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffWorkflowRuns(t *testing.T) {
+	now := time.Now()
+	runJob := func(name, status string, duration time.Duration, model string, reqs ...Requirement) WorkflowNodeJobRun {
+		rj := WorkflowNodeJobRun{Status: status, Start: now, Done: now.Add(duration), Model: model}
+		rj.Job.Action.Name = name
+		rj.Job.Action.Requirements = reqs
+		return rj
+	}
+	tests := func(failed ...string) *venom.Tests {
+		ts := venom.TestSuite{Name: "api"}
+		for _, name := range []string{"TestA", "TestB"} {
+			tc := venom.TestCase{Name: name}
+			if IsInArray(name, failed) {
+				tc.Failures = []venom.Failure{{Value: "fail"}}
+			}
+			ts.TestCases = append(ts.TestCases, tc)
+		}
+		return &venom.Tests{Total: 2, TotalKO: len(failed), TestSuites: []venom.TestSuite{ts}}
+	}
+
+	from := WorkflowRun{
+		Number: 1,
+		Status: StatusSuccess,
+		Workflow: Workflow{
+			LastModified: now,
+			Pipelines: map[int64]Pipeline{
+				1: {Name: "build", Stages: []Stage{{Name: "Build"}}},
+				2: {Name: "deploy", Stages: []Stage{{Name: "Deploy"}}},
+			},
+		},
+		WorkflowNodeRuns: map[int64][]WorkflowNodeRun{
+			10: {{
+				WorkflowNodeName: "build",
+				BuildParameters: []Parameter{
+					{Name: "cds.version", Type: StringParameter, Value: "1"},
+					{Name: "git.hash", Type: StringParameter, Value: "aaa"},
+					{Name: "cds.proj.token", Type: SecretVariable, Value: "secret"},
+				},
+				Stages: []Stage{{RunJobs: []WorkflowNodeJobRun{
+					runJob("compile", StatusSuccess, 10*time.Second, "go-1.14", Requirement{Name: "go-1.14", Type: ModelRequirement, Value: "go-1.14"}),
+					runJob("lint", StatusSuccess, 5*time.Second, ""),
+				}}},
+				Tests: tests(),
+				Artifacts: []WorkflowNodeRunArtifact{
+					{Name: "api", Size: 100, MD5sum: "md5-1"},
+					{Name: "ui", Size: 50, MD5sum: "md5-ui"},
+				},
+			}},
+		},
+	}
+	to := WorkflowRun{
+		Number: 2,
+		Status: StatusFail,
+		Workflow: Workflow{
+			LastModified: now.Add(time.Hour),
+			Pipelines: map[int64]Pipeline{
+				1: {Name: "build", Stages: []Stage{{Name: "Build"}, {Name: "Check"}}},
+				2: {Name: "deploy", Stages: []Stage{{Name: "Deploy"}}},
+			},
+		},
+		WorkflowNodeRuns: map[int64][]WorkflowNodeRun{
+			10: {
+				{
+					WorkflowNodeName: "build",
+					SubNumber:        1,
+					BuildParameters: []Parameter{
+						{Name: "cds.version", Type: StringParameter, Value: "2"},
+						{Name: "git.hash", Type: StringParameter, Value: "bbb"},
+						{Name: "cds.proj.token", Type: SecretVariable, Value: "other"},
+					},
+					Stages: []Stage{{RunJobs: []WorkflowNodeJobRun{
+						runJob("compile", StatusFail, 20*time.Second, "go-1.15", Requirement{Name: "go-1.15", Type: ModelRequirement, Value: "go-1.15"}),
+					}}},
+					Tests: tests("TestB"),
+					Artifacts: []WorkflowNodeRunArtifact{
+						{Name: "api", Size: 120, MD5sum: "md5-2"},
+						{Name: "ui", Size: 50, MD5sum: "md5-ui"},
+						{Name: "doc", Size: 10, MD5sum: "md5-doc"},
+					},
+				},
+				// Previous sub number is ignored
+				{WorkflowNodeName: "build", SubNumber: 0},
+			},
+		},
+	}
+
+	diff := DiffWorkflowRuns(from, to)
+
+	assert.Equal(t, int64(1), diff.From.Number)
+	assert.Equal(t, int64(2), diff.To.Number)
+	assert.True(t, diff.Workflow.Changed())
+	assert.Equal(t, []string{"build"}, diff.Workflow.Pipelines)
+
+	assert.Equal(t, []WorkflowRunDiffValue{{Node: "build", Name: "git.hash", From: "aaa", To: "bbb"}}, diff.Parameters)
+
+	require.Len(t, diff.Jobs, 2)
+	assert.Equal(t, WorkflowRunDiffJob{
+		Node: "build", Job: "compile",
+		FromStatus: StatusSuccess, ToStatus: StatusFail,
+		FromDuration: 10, ToDuration: 20,
+		FromModel: "go-1.14", ToModel: "go-1.15",
+		Requirements: []WorkflowRunDiffValue{
+			{Name: ModelRequirement + " go-1.14", From: "go-1.14"},
+			{Name: ModelRequirement + " go-1.15", To: "go-1.15"},
+		},
+	}, diff.Jobs[0])
+	assert.Equal(t, "lint", diff.Jobs[1].Job)
+	assert.Equal(t, "", diff.Jobs[1].ToStatus)
+
+	require.Len(t, diff.Tests, 1)
+	assert.Equal(t, 0, diff.Tests[0].FromKO)
+	assert.Equal(t, 1, diff.Tests[0].ToKO)
+	assert.Equal(t, []string{"api / TestB"}, diff.Tests[0].NewFailures)
+	assert.Empty(t, diff.Tests[0].Fixed)
+
+	assert.Equal(t, []WorkflowRunDiffArtifact{
+		{Node: "build", Name: "api", FromSize: 100, FromMD5: "md5-1", ToSize: 120, ToMD5: "md5-2"},
+		{Node: "build", Name: "doc", ToSize: 10, ToMD5: "md5-doc"},
+	}, diff.Artifacts)
+}
+
+func TestDiffWorkflowRunPipelinesIgnoresIDsAndTimestamps(t *testing.T) {
+	pipeline := func(id, lastModified int64, status string, script string) Pipeline {
+		step := Action{ID: id + 100, Name: ScriptAction, Type: BuiltinAction, Enabled: true,
+			Parameters: []Parameter{{ID: id + 200, Name: "script", Type: TextParameter, Value: script}}}
+		job := Job{
+			PipelineActionID: id + 10,
+			PipelineStageID:  id,
+			Enabled:          true,
+			LastModified:     lastModified,
+			Action: Action{
+				ID:           id + 20,
+				Name:         "compile",
+				Requirements: RequirementList{{ID: id + 30, ActionID: id + 20, Name: "go", Type: BinaryRequirement, Value: "go"}},
+				Actions:      []Action{step},
+			},
+		}
+		return Pipeline{
+			ID:        id,
+			Name:      "build",
+			Parameter: []Parameter{{ID: id + 40, Name: "env", Type: StringParameter, Value: "prod"}},
+			Stages: []Stage{{
+				ID:           id,
+				Name:         "Build",
+				BuildOrder:   1,
+				Enabled:      true,
+				LastModified: lastModified,
+				Status:       status,
+				Jobs:         []Job{job},
+			}},
+		}
+	}
+
+	from := map[int64]Pipeline{1: pipeline(1, 1000, StatusSuccess, "make")}
+	to := map[int64]Pipeline{2: pipeline(2, 2000, StatusFail, "make")}
+	assert.Empty(t, diffWorkflowRunPipelines(from, to))
+
+	to = map[int64]Pipeline{2: pipeline(2, 2000, StatusFail, "make test")}
+	assert.Equal(t, []string{"build"}, diffWorkflowRunPipelines(from, to))
+}
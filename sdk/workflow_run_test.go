@@ -230,6 +230,21 @@ func TestWorkflowQueue_Sort(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "test sort with ranks",
+			q: WorkflowQueue{
+				{ProjectID: 1, ID: 1, Queued: t10},
+				{ProjectID: 1, ID: 2, Queued: t11, QueueRank: 3},
+				{ProjectID: 2, ID: 3, Queued: t12, QueueRank: 1},
+				{ProjectID: 1, ID: 4, Queued: t13, QueueRank: 2},
+			},
+			expected: WorkflowQueue{
+				{ProjectID: 2, ID: 3, Queued: t12, QueueRank: 1},
+				{ProjectID: 1, ID: 4, Queued: t13, QueueRank: 2},
+				{ProjectID: 1, ID: 2, Queued: t11, QueueRank: 3},
+				{ProjectID: 1, ID: 1, Queued: t10},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -238,3 +253,20 @@ func TestWorkflowQueue_Sort(t *testing.T) {
 		})
 	}
 }
+
+func TestWorkflowNodeJobRunCanRetry(t *testing.T) {
+	j := WorkflowNodeJobRun{}
+	j.Job.Action.Retry = &JobRetry{MaxAttempts: 2, On: []string{JobRetryOnWorkerLost}}
+
+	assert.True(t, j.CanRetry(JobRetryOnWorkerLost))
+	assert.False(t, j.CanRetry(JobRetryOnFailure))
+
+	// requeues after a dead worker are not counted as attempts
+	j.Retry = 3
+	assert.Equal(t, 1, j.Attempt())
+	assert.True(t, j.CanRetry(JobRetryOnWorkerLost))
+
+	j.Attempts = 1
+	assert.Equal(t, 2, j.Attempt())
+	assert.False(t, j.CanRetry(JobRetryOnWorkerLost))
+}
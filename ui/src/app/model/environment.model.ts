@@ -12,8 +12,20 @@ export class Environment {
     last_modified: number;
     usage: Usage;
     from_repository: string;
+    freezes: Array<EnvironmentFreeze>;
 
     mute: boolean;
     editModeChanged: boolean;
     workflow_ascode_holder: Workflow;
 }
+
+export class EnvironmentFreeze {
+    name: string;
+    cron: string;
+    duration: string;
+    start: string;
+    end: string;
+    timezone: string;
+    policy: string;
+    override_group: string;
+}
@@ -17,13 +17,16 @@ export class PipelineStatus {
     static NEVER_BUILT = 'Never Built';
     static STOPPED = 'Stopped';
     static PENDING = 'Pending';
+    static WAITING_APPROVAL = 'WaitingApproval';
+    static FROZEN = 'Frozen';
 
     static neverRun(status: string) {
         return status === this.SKIPPED || status === this.NEVER_BUILT || status === this.SKIPPED || status === this.DISABLED;
     }
 
     static isActive(status: string) {
-        return status === this.WAITING || status === this.BUILDING || status === this.PENDING;
+        return status === this.WAITING || status === this.BUILDING || status === this.PENDING ||
+            status === this.WAITING_APPROVAL || status === this.FROZEN;
     }
 
     static isDone(status: string) {
@@ -9,6 +9,7 @@ export class Stage {
   status: string;
   build_order: number;
   enabled: boolean;
+  finally: boolean;
   jobs: Array<Job>;
   run_jobs: Array<WorkflowNodeJobRun>;
   conditions: WorkflowNodeConditions;
@@ -36,6 +36,8 @@ export class Workflow {
     usage: Usage;
     history_length: number;
     purge_tags: Array<string>;
+    auto_cancel: boolean;
+    priority: number;
     notifications: Array<WorkflowNotification>;
     from_repository: string;
     from_template: string;
@@ -354,6 +356,7 @@ export class WorkflowNodeHookConfigValue {
 // WorkflowTriggerConditions is either a lua script to check conditions or a set of WorkflowTriggerCondition
 export class WorkflowNodeConditions {
     lua_script: string;
+    expression: string;
     plain: Array<WorkflowNodeCondition>;
 }
 
@@ -636,6 +639,19 @@ export class WNodeContext {
     default_pipeline_parameters: Array<Parameter>;
     conditions: WorkflowNodeConditions;
     mutex: boolean;
+    concurrency: WNodeConcurrency;
+    approval: WNodeApproval;
+}
+
+export class WNodeApproval {
+    groups: Array<string>;
+    min_approvals: number;
+    expiry: number;
+}
+
+export class WNodeConcurrency {
+    name: string;
+    policy: string;
 }
 
 export class WNodeOutgoingHook {
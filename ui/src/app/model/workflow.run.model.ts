@@ -96,12 +96,21 @@ export class WorkflowNodeRun implements WithKey {
     execution_id: string;
     callback: WorkflowNodeOutgoingHookRunCallback;
     static_files: Array<WorkflowNodeRunStaticFiles>;
+    concurrency_group: string;
+    approvals: Array<WorkflowNodeRunApproval>;
 
     key(): string {
         return `${this.id}-${this.num}.${this.subnumber}`;
     }
 }
 
+export class WorkflowNodeRunApproval {
+    username: string;
+    approved: boolean;
+    comment: string;
+    date: string;
+}
+
 export class WorkflowNodeOutgoingHookRunCallback {
     workflow_node_outgoing_hook_id: number;
     start: Date;
@@ -151,6 +160,8 @@ export class WorkflowNodeJobRun {
     model: string;
     bookedby: Hatchery;
     spawninfos: Array<SpawnInfo>;
+    priority: number;
+    queue_rank: number;
 
     // UI infos for queue
     duration: string;
@@ -100,6 +100,10 @@ export class RequirementsFormComponent implements OnInit {
                         placeHolderValue = '4096';
                         helpMsg = this._translate.instant('requirement_help_memory');
                         break;
+                    case 'cpu':
+                        placeHolderValue = '2';
+                        helpMsg = this._translate.instant('requirement_help_cpu');
+                        break;
                     case 'os-architecture':
                         placeHolderName = this._translate.instant('requirement_placeholder_name_os-architecture');
                         placeHolderValue = 'linux-amd64';
@@ -164,6 +168,10 @@ export class RequirementsFormComponent implements OnInit {
                 // memory: memory_4096
                 this.newRequirement.name = 'memory_' + this.newRequirement.value;
                 break;
+            case 'cpu':
+                // cpu: cpu_2
+                this.newRequirement.name = 'cpu_' + this.newRequirement.value;
+                break;
             case 'model':
                 this.workerModelLinked = this.computeDisplayLinkWorkerModel();
                 this.newRequirement.name = this.newRequirement.value;
@@ -87,6 +87,10 @@ export class RequirementsListComponent extends Table<Requirement> implements OnI
                 // memory: memory_4096
                 req.name = 'memory_' + req.value;
                 break
+            case 'cpu':
+                // cpu: cpu_2
+                req.name = 'cpu_' + req.value;
+                break
             case 'model':
                 req.name = req.value;
                 break
@@ -113,7 +113,7 @@ export class WorkflowWizardOutgoingHookComponent implements OnInit {
                             .find(m => m.id === this.outgoingHook.outgoing_hook.hook_model_id);
                     }
                     this.displayConfig = Object.keys(this.outgoingHook.outgoing_hook.config).length !== 0;
-                    if (this.selectedOutgoingHookModel.name === 'Workflow') {
+                    if (this.isWorkflowModel()) {
                         this.updateWorkflowData(false);
                     }
                     this.availableWorkflows = this.project.workflow_names.filter(idName => idName.name !== this.workflow.name);
@@ -121,6 +121,12 @@ export class WorkflowWizardOutgoingHookComponent implements OnInit {
             });
     }
 
+    // The 'SubWorkflow' hooks are configured as the 'Workflow' hooks
+    isWorkflowModel(): boolean {
+        return this.selectedOutgoingHookModel && (this.selectedOutgoingHookModel.name === 'Workflow'
+            || this.selectedOutgoingHookModel.name === 'SubWorkflow');
+    }
+
     updateOutgoingHook(): void {
         if (!this.outgoingHook) {
             this.outgoingHook = new WNode();
@@ -134,7 +140,7 @@ export class WorkflowWizardOutgoingHookComponent implements OnInit {
 
 
         // Specific behavior for the 'workflow' hooks
-        if (this.selectedOutgoingHookModel.name === 'Workflow') {
+        if (this.isWorkflowModel()) {
             // Current limitation: trigger only workflow in the same project
             this.outgoingHook.outgoing_hook.config['target_project'].value = this.project.key;
             // Load the workflow for the current project, but exclude the current workflow
@@ -43,6 +43,7 @@ export class WorkflowNodeRunSummaryComponent implements OnInit {
     nodeRunNum: number;
     nodeRunSubNum: number;
     nodeRunStart: string;
+    nodeRunConcurrencyGroup: string;
 
     loading = false;
 
@@ -68,6 +69,7 @@ export class WorkflowNodeRunSummaryComponent implements OnInit {
                 this.nodeRunNum = nr.num;
                 this.nodeRunSubNum = nr.subnumber;
                 this.nodeRunStart = nr.start;
+                this.nodeRunConcurrencyGroup = nr.concurrency_group;
                 this.nodeRunStatus = nr.status;
                 if (!PipelineStatus.isActive(nr.status)) {
                     this.duration = this._durationService.duration(new Date(nr.start), new Date(nr.done));